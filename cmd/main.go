@@ -1,15 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"inspacemap/backend/config"
 	"inspacemap/backend/internal/delivery/http/handler"
+	"inspacemap/backend/internal/delivery/http/middleware"
 	"inspacemap/backend/internal/delivery/http/route"
+	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
 	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/cache"
+	"inspacemap/backend/pkg/mailer"
+	"inspacemap/backend/pkg/oidc"
+	"inspacemap/backend/pkg/ratelimit"
 	"inspacemap/backend/pkg/storage"
+	"inspacemap/backend/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -23,6 +38,20 @@ func main() {
 	config.ConnectDB()
 	db := config.DB
 
+	// Kunci JWT wajib dari konfigurasi di production; development boleh pakai secret acak per proses
+	jwtKeys, err := config.LoadJWTKeys()
+	if err != nil {
+		log.Fatal("Invalid JWT key configuration: ", err)
+	}
+	if jwtKeys != nil {
+		utils.SetJWTKeySet(jwtKeys)
+		log.Printf("🔑 JWT signing key %q loaded", jwtKeys.SigningKeyID())
+	} else if getEnv("APP_ENV", "production") != "development" {
+		log.Fatal("JWT_SECRET or JWT_PRIVATE_KEY_FILE must be set")
+	} else {
+		log.Println("⚠️  No JWT key configured, using an ephemeral secret (tokens die on restart)")
+	}
+
 	// 2. INIT REPOSITORIES (Data Access Layer)
 	userRepo := repository.NewUserRepository(db)
 	orgRepo := repository.NewOrganizationRepository(db)
@@ -30,6 +59,11 @@ func main() {
 	invitationRepo := repository.NewInvitationRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
 	permRepo := repository.NewPermissionRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	apiKeyRepo := repository.NewApiKeyRepository(db)
+	identityRepo := repository.NewUserIdentityRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
 
 	venueRepo := repository.NewVenueRepository(db)
 	floorRepo := repository.NewFloorRepository(db)
@@ -43,6 +77,7 @@ func main() {
 	areaGalleryRepo := repository.NewAreaGalleryRepository(db)
 
 	auditRepo := repository.NewAuditRepository(db)
+	ownershipRepo := repository.NewOwnershipRepository(db)
 
 	minioEndpoint := getEnv("MINIO_ENDPOINT", "http://localhost:9000")
 	minioAccess := getEnv("MINIO_ACCESS_KEY", "admin_inspacemap")
@@ -61,39 +96,152 @@ func main() {
 		storageProvider = storage.NewMinIOProvider(minioEndpoint, minioAccess, minioSecret, minioRegion)
 	}
 
+	// Cache manifest mobile (LRU in-memory). TTL menjaga instance lain tetap sinkron saat venue di-rename.
+	manifestCacheSize, _ := strconv.Atoi(getEnv("MANIFEST_CACHE_SIZE", "256"))
+	manifestCache := cache.NewLRU[*models.ManifestResponse](manifestCacheSize, 10*time.Minute)
+
+	// Cache permission per (org, user). TTL pendek: batas basi untuk instance lain setelah role berubah.
+	permissionCacheSize, _ := strconv.Atoi(getEnv("PERMISSION_CACHE_SIZE", "4096"))
+	permissionCache := cache.NewLRU[*models.ResolvedPermissions](permissionCacheSize, 30*time.Second)
+
+	// Email: smtp di production; file (.eml di MAIL_DIR) atau log untuk development
+	mailFrom := getEnv("MAIL_FROM", "InSpaceMap <no-reply@inspacemap.local>")
+	var mailSender service.Mailer
+	switch mailDriver := getEnv("MAIL_DRIVER", "log"); mailDriver {
+	case "smtp":
+		smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+		mailSender = mailer.NewSMTPMailer(getEnv("SMTP_HOST", "localhost"), smtpPort, getEnv("SMTP_USERNAME", ""), getEnv("SMTP_PASSWORD", ""), mailFrom)
+	case "file":
+		mailSender = mailer.NewFileMailer(getEnv("MAIL_DIR", "./tmp/mail"), mailFrom)
+	case "log":
+		mailSender = mailer.NewFileMailer("", mailFrom)
+	default:
+		log.Fatalf("Unknown MAIL_DRIVER %q (use smtp, file or log)", mailDriver)
+	}
+	appBaseURL := getEnv("APP_BASE_URL", "http://localhost:3000")
+	// Halaman frontend yang menerima redirect identity provider; daftarkan URL ini di setiap provider SSO
+	ssoRedirectURL := getEnv("SSO_REDIRECT_URL", strings.TrimRight(appBaseURL, "/")+"/sso/callback")
+	// Discovery/JWKS hanya ke alamat publik; SSO_ALLOW_PRIVATE_ISSUERS=true untuk IdP lokal saat development
+	oidcClient := oidc.NewClient(nil)
+	if getEnv("SSO_ALLOW_PRIVATE_ISSUERS", "false") == "true" {
+		oidcClient = oidc.NewClient(&http.Client{Timeout: 10 * time.Second})
+	}
+
+	// Rate limit: memory untuk satu instance; redis agar kuota & lockout dibagi antar instance
+	var rateLimitStore ratelimit.Store
+	switch storeDriver := getEnv("RATE_LIMIT_STORE", "memory"); storeDriver {
+	case "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "redis":
+		redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+		redisStore := ratelimit.NewRedisStore(getEnv("REDIS_ADDR", "localhost:6379"), getEnv("REDIS_PASSWORD", ""), redisDB)
+		if err := redisStore.Ping(context.Background()); err != nil {
+			log.Fatal("Redis rate limit store unreachable: ", err)
+		}
+		rateLimitStore = redisStore
+	default:
+		log.Fatalf("Unknown RATE_LIMIT_STORE %q (use memory or redis)", storeDriver)
+	}
+	rateLimits := &middleware.RateLimitConfig{
+		Store:    rateLimitStore,
+		Auth:     mustParseRule("RATE_LIMIT_AUTH", "20/1m"),
+		Refresh:  mustParseRule("RATE_LIMIT_REFRESH", "60/1m"),
+		Manifest: mustParseRule("RATE_LIMIT_MANIFEST", "300/1m"),
+		API:      mustParseRule("RATE_LIMIT_API", "600/1m"),
+		Lockout:  ratelimit.NewLockout(rateLimitStore),
+	}
+
 	// 4. INIT SERVICES (Business Logic Layer)
-	authService := service.NewAuthService(userRepo, orgRepo, orgMemberRepo, invitationRepo, roleRepo)
-	mediaService := service.NewMediaService(mediaRepo, storageProvider, minioBucket, cdnURL)
-	areaService := service.NewAreaService(areaRepo, areaGalleryRepo, graphRepo)
-	graphService := service.NewGraphService(graphRepo, revisionRepo, floorRepo, venueRepo)
-	venueService := service.NewVenueService(venueRepo)
-	teamService := service.NewTeamService(userRepo, invitationRepo, orgMemberRepo, roleRepo)
-	roleService := service.NewRoleService(roleRepo, permRepo)
-	venueGalleryService := service.NewVenueGalleryService(venueGalleryRepo)
-	areaGalleryService := service.NewAreaGalleryService(areaGalleryRepo)
+	permissionResolver := service.NewPermissionResolver(orgMemberRepo, permissionCache)
 	auditService := service.NewAuditService(auditRepo)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, refreshTokenRepo, mailSender, appBaseURL)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, orgRepo, orgMemberRepo, permissionResolver)
+	authService := service.NewAuthService(userRepo, orgRepo, orgMemberRepo, invitationRepo, roleRepo, refreshTokenRepo, permRepo, permissionResolver, accountService, twoFactorService)
+	mediaService := service.NewMediaService(mediaRepo, ownershipRepo, storageProvider, minioBucket, cdnURL, auditService)
+	areaService := service.NewAreaService(areaRepo, areaGalleryRepo, graphRepo, venueRepo, ownershipRepo, auditService)
+	graphService := service.NewGraphService(graphRepo, revisionRepo, floorRepo, venueRepo, ownershipRepo, manifestCache, auditService)
+	graphImportService := service.NewGraphImportService(graphRepo, revisionRepo, areaRepo, ownershipRepo)
+	venueService := service.NewVenueService(venueRepo, manifestCache, auditService)
+	venuePackageService := service.NewVenuePackageService(venueRepo, areaRepo, areaGalleryRepo, storageProvider, minioBucket, cdnURL)
+	bundleExtractMB, _ := strconv.Atoi(getEnv("BUNDLE_MAX_EXTRACTED_MB", "2048"))
+	if bundleExtractMB <= 0 {
+		bundleExtractMB = 2048
+	}
+	venueBundleService := service.NewVenueBundleService(venueRepo, revisionRepo, areaGalleryRepo, mediaRepo, storageProvider, minioBucket, cdnURL, int64(bundleExtractMB)<<20)
+	teamService := service.NewTeamService(userRepo, invitationRepo, orgMemberRepo, roleRepo, orgRepo, permissionResolver, mailSender, appBaseURL, auditService)
+	roleService := service.NewRoleService(roleRepo, permRepo, permissionResolver, auditService)
+	venueGalleryService := service.NewVenueGalleryService(venueGalleryRepo, ownershipRepo)
+	areaGalleryService := service.NewAreaGalleryService(areaGalleryRepo, ownershipRepo)
+	apiKeyService := service.NewApiKeyService(apiKeyRepo, permissionResolver)
+	ssoService := service.NewSSOService(orgRepo, userRepo, identityRepo, orgMemberRepo, roleRepo, permissionResolver, authService, oidcClient, net.DefaultResolver, ssoRedirectURL)
+
+	// Permission di database harus sama dengan registry di kode (jalankan seeder jika belum)
+	if err := roleService.VerifyPermissionRegistry(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	// Undangan yang lewat masa berlaku ditandai expired secara berkala
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := teamService.ExpireInvitations(context.Background()); err != nil {
+				log.Printf("expire invitations failed: %v", err)
+			} else if n > 0 {
+				log.Printf("%d invitation(s) marked expired", n)
+			}
+		}
+	}()
 
 	// 5. INIT HANDLERS (HTTP Transport Layer)
 	authHandler := handler.NewAuthHandler(authService)
+	accountHandler := handler.NewAccountHandler(accountService)
 	teamRoleHandler := handler.NewTeamRoleHandler(teamService, roleService)
-	venueHandler := handler.NewVenueHandler(venueService)
+	venueHandler := handler.NewVenueHandler(venueService, venuePackageService)
 	venueGalleryHandler := handler.NewVenueGalleryHandler(venueGalleryService) // Implementasi nanti
-	graphHandler := handler.NewGraphHandler(graphService)
+	venueBundleHandler := handler.NewVenueBundleHandler(venueBundleService)
+	graphHandler := handler.NewGraphHandler(graphService, graphImportService)
 	mediaHandler := handler.NewMediaHandler(mediaService)
 	areaHandler := handler.NewAreaHandler(areaService)
 	areaGalleryHandler := handler.NewAreaGalleryHandler(areaGalleryService) // Implementasi nanti
 	auditHandler := handler.NewAuditHandler(auditService)                   // Implementasi nanti
+	apiKeyHandler := handler.NewApiKeyHandler(apiKeyService)
+	ssoHandler := handler.NewSSOHandler(ssoService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, authService)
 	// 6. SETUP FIBER APP
+	// Body di atas BodyLimit di-stream, bukan ditolak server: batas per route dicek middleware.BodyLimit
+	// sebelum body dibaca, sehingga hanya import bundle yang boleh mengirim body besar
+	bodyLimitMB, _ := strconv.Atoi(getEnv("BODY_LIMIT_MB", "4"))
+	bundleLimitMB, _ := strconv.Atoi(getEnv("BUNDLE_MAX_SIZE_MB", "512"))
+	if bodyLimitMB <= 0 {
+		bodyLimitMB = 4
+	}
+	// Header IP klien hanya dibaca dari koneksi proxy yang dipercaya; tanpa ini klien bisa memalsukan
+	// X-Forwarded-For dan berganti bucket rate limit / lockout di setiap request
+	proxyHeader := getEnv("PROXY_HEADER", "")
+	trustedProxies := splitEnvList("TRUSTED_PROXIES")
+	if proxyHeader != "" && len(trustedProxies) == 0 {
+		log.Printf("⚠️  PROXY_HEADER %s is ignored until TRUSTED_PROXIES is set", proxyHeader)
+	}
+
 	app := fiber.New(fiber.Config{
 		AppName: "InSpaceMap API v1",
+		// Di belakang reverse proxy: header IP klien (mis. X-Real-IP) untuk rate limit per IP
+		ProxyHeader:                  proxyHeader,
+		EnableTrustedProxyCheck:      true,
+		TrustedProxies:               trustedProxies,
+		BodyLimit:                    bodyLimitMB << 20,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true, // Multipart dibaca dari stream saat handler memintanya
 	})
 
 	// Middleware Global
 	app.Use(logger.New())  // Logging request
 	app.Use(recover.New()) // Mencegah crash jika panic
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*", // Untuk development. Ubah domain spesifik saat prod.
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Tenant-ID",
+		AllowOrigins:  "*", // Untuk development. Ubah domain spesifik saat prod.
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-API-Key, X-Tenant-ID, X-Share-Token, If-None-Match, If-Modified-Since",
+		ExposeHeaders: "ETag, Last-Modified, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset",
 	}))
 
 	// 7. REGISTER ROUTES
@@ -101,25 +249,67 @@ func main() {
 		App:                 app,
 		TeamRoleHandler:     teamRoleHandler,
 		AuthHandler:         authHandler,
+		AccountHandler:      accountHandler,
 		AreaHandler:         areaHandler,
 		AreaGalleryHandler:  areaGalleryHandler,
 		VenueHandler:        venueHandler,
 		VenueGalleryHandler: venueGalleryHandler,
+		VenueBundleHandler:  venueBundleHandler,
 		GraphHandler:        graphHandler,
 		MediaHandler:        mediaHandler,
 		AuditHandler:        auditHandler,
+		ApiKeyHandler:       apiKeyHandler,
+		SSOHandler:          ssoHandler,
+		TwoFactorHandler:    twoFactorHandler,
+		PermissionResolver:  permissionResolver,
+		APIKeyAuthenticator: apiKeyService,
+		RateLimits:          rateLimits,
+		BodyLimit:           bodyLimitMB << 20,
+		BundleBodyLimit:     bundleLimitMB << 20,
+		AuditLogger:         auditService,
 	}
 	routeConfig.Setup()
 
 	// 8. START SERVER
+	// SIGINT/SIGTERM: selesaikan request berjalan, lalu tulis sisa antrean audit
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		<-stop
+		if err := app.Shutdown(); err != nil {
+			log.Printf("shutdown: %v", err)
+		}
+	}()
+
 	port := getEnv("PORT", "8080")
 	log.Printf("🚀 Server starting on port %s", port)
 	if err := app.Listen(":" + port); err != nil {
 		log.Fatal(err)
 	}
+	auditService.Close()
 }
 
 // Helper kecil untuk baca env di main
+// mustParseRule: Rule rate limit dari env ("jumlah/durasi"; "0" = tanpa batas)
+func mustParseRule(key, fallback string) ratelimit.Rule {
+	rule, err := ratelimit.ParseRule(getEnv(key, fallback))
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return rule
+}
+
+// splitEnvList: Daftar dipisah koma; kosong = nil
+func splitEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(getEnv(key, ""), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
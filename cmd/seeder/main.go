@@ -20,22 +20,8 @@ func main() {
 
 	log.Println("🌱 Seeding Database...")
 
-	permissions := []entity.Permission{
-
-		{Key: "venue:create", Description: "Create new venues", Group: "CMS"},
-		{Key: "venue:update", Description: "Update venue details", Group: "CMS"},
-		{Key: "venue:delete", Description: "Delete venues", Group: "CMS"},
-
-		{Key: "graph:edit", Description: "Edit nodes and edges", Group: "Graph"},
-		{Key: "graph:publish", Description: "Publish draft to live", Group: "Graph"},
-
-		{Key: "org:settings", Description: "Manage organization profile", Group: "Org"},
-		{Key: "org:billing", Description: "Manage billing and subscription", Group: "Org"},
-		{Key: "team:invite", Description: "Invite new members", Group: "Team"},
-		{Key: "team:manage", Description: "Change member roles", Group: "Team"},
-
-		{Key: "media:upload", Description: "Upload new assets", Group: "Media"},
-	}
+	// Permission selalu diambil dari registry di kode (lihat entity.PermissionRegistry)
+	permissions := entity.PermissionRegistry
 
 	for _, p := range permissions {
 
@@ -56,12 +42,16 @@ func main() {
 		{
 			Name:        "Owner",
 			Description: "Pemilik Organisasi (Super Admin)",
-			PermKeys:    []string{"venue:create", "venue:update", "venue:delete", "graph:edit", "graph:publish", "org:settings", "org:billing", "team:invite", "team:manage", "media:upload"},
+			PermKeys:    allPermissionKeys(),
 		},
 		{
 			Name:        "Editor",
 			Description: "Pengelola Konten (Peta & Info)",
-			PermKeys:    []string{"venue:create", "venue:update", "graph:edit", "graph:publish", "media:upload"},
+			PermKeys: permissionKeys(
+				entity.PermVenueCreate, entity.PermVenueUpdate,
+				entity.PermGraphEdit, entity.PermGraphPublish,
+				entity.PermMediaUpload, entity.PermMediaDelete,
+			),
 		},
 		{
 			Name:        "Viewer",
@@ -73,7 +63,7 @@ func main() {
 	for _, r := range roles {
 		var role entity.Role
 
-		if err := db.Where("name = ?", r.Name).First(&role).Error; err != nil {
+		if err := db.Where("name = ? AND organization_id IS NULL", r.Name).First(&role).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 
 				role = entity.Role{
@@ -114,12 +104,28 @@ func main() {
 	}
 }
 
+func allPermissionKeys() []string {
+	keys := make([]string, 0, len(entity.PermissionRegistry))
+	for _, p := range entity.PermissionRegistry {
+		keys = append(keys, p.Key)
+	}
+	return keys
+}
+
+func permissionKeys(perms ...entity.PermissionKey) []string {
+	keys := make([]string, 0, len(perms))
+	for _, p := range perms {
+		keys = append(keys, string(p))
+	}
+	return keys
+}
+
 func seedDevelopmentData(db *gorm.DB) {
 	// Get roles
 	var ownerRole, editorRole, viewerRole entity.Role
-	db.Where("name = ?", "Owner").First(&ownerRole)
-	db.Where("name = ?", "Editor").First(&editorRole)
-	db.Where("name = ?", "Viewer").First(&viewerRole)
+	db.Where("name = ? AND organization_id IS NULL", "Owner").First(&ownerRole)
+	db.Where("name = ? AND organization_id IS NULL", "Editor").First(&editorRole)
+	db.Where("name = ? AND organization_id IS NULL", "Viewer").First(&viewerRole)
 
 	// Sample users
 	users := []struct {
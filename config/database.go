@@ -60,6 +60,13 @@ func ConnectDB() {
 	}
 	log.Println("✅ Asset tables created")
 
+	// Index unik (organization_id, slug) gagal dibuat selama masih ada slug kembar dari sebelum index ada
+	if n, err := DedupeVenueSlugs(DB); err != nil {
+		log.Fatal("Migration Failed at venue slug dedupe: ", err)
+	} else if n > 0 {
+		log.Printf("⚠️ %d duplicate venue slug(s) renamed, old public URLs of those venues changed", n)
+	}
+
 	log.Println("Creating venue & graph tables...")
 	err = DB.AutoMigrate(
 		&entity.Venue{},
@@ -79,12 +86,23 @@ func ConnectDB() {
 		&entity.UserInvitation{},
 		&entity.ApiKey{},
 		&entity.RolePermission{},
+		&entity.RefreshToken{},
+		&entity.UserToken{},
+		&entity.UserIdentity{},
+		&entity.UserTwoFactor{},
+		&entity.UserRecoveryCode{},
 	)
 	if err != nil {
 		log.Fatal("Migration Failed at relation tables: ", err)
 	}
 	log.Println("✅ Relation tables created")
 
+	if n, err := HashLegacyInvitationTokens(DB); err != nil {
+		log.Fatal("Migration Failed at invitation token hashes: ", err)
+	} else if n > 0 {
+		log.Printf("✅ %d legacy invitation token(s) hashed", n)
+	}
+
 	log.Println("Creating feature tables...")
 	err = DB.AutoMigrate(
 		&entity.Area{},
@@ -100,6 +118,31 @@ func ConnectDB() {
 	log.Println("✅ Database Migration Completed")
 }
 
+// HashLegacyInvitationTokens: Undangan lama menyimpan token asli (UUID), sedangkan lookup sekarang memakai
+// hash SHA-256 (hex, 64 karakter). Token yang belum berbentuk hash di-hash di tempat supaya link lama tetap berlaku.
+func HashLegacyInvitationTokens(db *gorm.DB) (int64, error) {
+	res := db.Model(&entity.UserInvitation{}).
+		Where("token !~ '^[0-9a-f]{64}$'").
+		Update("token", gorm.Expr("encode(sha256(convert_to(token, 'UTF8')), 'hex')"))
+	return res.RowsAffected, res.Error
+}
+
+// DedupeVenueSlugs: Sebelum ada index unik, satu organisasi bisa punya beberapa venue dengan slug sama.
+// Venue tertua mempertahankan slug-nya, sisanya diberi akhiran 8 karakter pertama ID (tetap muat di varchar(100)).
+func DedupeVenueSlugs(db *gorm.DB) (int64, error) {
+	if !db.Migrator().HasTable(&entity.Venue{}) {
+		return 0, nil
+	}
+	res := db.Exec(`
+		UPDATE venues SET slug = left(venues.slug, 91) || '-' || left(venues.id::text, 8)
+		FROM (
+			SELECT id, row_number() OVER (PARTITION BY organization_id, slug ORDER BY created_at, id) AS rn
+			FROM venues WHERE deleted_at IS NULL
+		) dup
+		WHERE venues.id = dup.id AND dup.rn > 1`)
+	return res.RowsAffected, res.Error
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"inspacemap/backend/pkg/utils"
+)
+
+// publishedJWTSecrets: Secret yang pernah ikut ter-commit di contoh .env. Siapa pun bisa memalsukan token
+// dengan secret ini, jadi server menolak start walaupun panjangnya memenuhi syarat.
+var publishedJWTSecrets = []string{
+	"RAHASIA_DAPUR_SAAS_INSPACEMAP_2025",
+}
+
+// LoadJWTKeys: Kunci JWT dari environment.
+//
+//	JWT_KEY_ID           kid kunci aktif (default "primary")
+//	JWT_PRIVATE_KEY_FILE PEM RSA (RS256) / Ed25519 (EdDSA); diutamakan di atas JWT_SECRET
+//	JWT_SECRET           secret HS256 (min. 32 byte)
+//	JWT_VERIFY_KEYS      kunci lama yang masih diterima: "kid=/path/public.pem,kid2=/path/old.pem"
+//	JWT_VERIFY_SECRETS   secret HS256 lama yang masih diterima: "kid=secret,..."
+//
+// Mengembalikan (nil, nil) jika tidak ada kunci yang dikonfigurasi.
+func LoadJWTKeys() (*utils.JWTKeySet, error) {
+	kid := getEnv("JWT_KEY_ID", "primary")
+
+	var signing *utils.JWTKey
+	var err error
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		pemBytes, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return nil, fmt.Errorf("read JWT_PRIVATE_KEY_FILE: %w", readErr)
+		}
+		signing, err = utils.ParsePrivateKeyPEM(kid, pemBytes)
+	} else if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		signing, err = newHMACKey("JWT_SECRET", kid, secret)
+	} else {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var verifyOnly []*utils.JWTKey
+	for _, entry := range splitKeyList(os.Getenv("JWT_VERIFY_KEYS")) {
+		id, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("JWT_VERIFY_KEYS: expected kid=path, got %q", entry)
+		}
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_VERIFY_KEYS %s: %w", id, err)
+		}
+		// File lama boleh berupa private key; yang dipakai hanya bagian publiknya
+		key, err := utils.ParsePublicKeyPEM(id, pemBytes)
+		if err != nil {
+			if key, err = utils.ParsePrivateKeyPEM(id, pemBytes); err != nil {
+				return nil, err
+			}
+			key = key.VerifyOnly()
+		}
+		verifyOnly = append(verifyOnly, key)
+	}
+	for _, entry := range splitKeyList(os.Getenv("JWT_VERIFY_SECRETS")) {
+		id, secret, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.New("JWT_VERIFY_SECRETS: expected kid=secret")
+		}
+		key, err := newHMACKey("JWT_VERIFY_SECRETS", id, secret)
+		if err != nil {
+			return nil, err
+		}
+		verifyOnly = append(verifyOnly, key.VerifyOnly())
+	}
+
+	return utils.NewJWTKeySet(signing, verifyOnly...)
+}
+
+func newHMACKey(envName, kid, secret string) (*utils.JWTKey, error) {
+	for _, published := range publishedJWTSecrets {
+		if secret == published {
+			return nil, fmt.Errorf("%s %s: secret is publicly known, generate a new one (e.g. openssl rand -base64 48)", envName, kid)
+		}
+	}
+	return utils.NewHMACKey(kid, []byte(secret))
+}
+
+func splitKeyList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountHandler struct {
+	service service.AccountService
+}
+
+func NewAccountHandler(s service.AccountService) *AccountHandler {
+	return &AccountHandler{service: s}
+}
+
+// POST /api/v1/auth/password/forgot (Respons sama untuk email terdaftar maupun tidak)
+func (h *AccountHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return utils.SendError(c, 400, "email is required")
+	}
+
+	if err := h.service.RequestPasswordReset(c.Context(), req.Email); err != nil {
+		return utils.SendError(c, 500, err.Error())
+	}
+	return utils.SendSuccess(c, "If the email is registered, a password reset link has been sent")
+}
+
+// POST /api/v1/auth/password/reset (Semua sesi user dicabut setelah berhasil)
+func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return utils.SendError(c, 400, "token and password are required")
+	}
+
+	if err := h.service.ResetPassword(c.Context(), req.Token, req.Password); err != nil {
+		return sendAccountError(c, err)
+	}
+	return utils.SendSuccess(c, "Password has been reset, please log in again")
+}
+
+// POST /api/v1/auth/email/verify
+func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return utils.SendError(c, 400, "token is required")
+	}
+
+	if err := h.service.VerifyEmail(c.Context(), req.Token); err != nil {
+		return sendAccountError(c, err)
+	}
+	return utils.SendSuccess(c, "Email verified")
+}
+
+// POST /api/v1/auth/email/verify/resend (Kirim ulang link verifikasi ke email user token)
+func (h *AccountHandler) ResendVerification(c *fiber.Ctx) error {
+	if err := h.service.SendEmailVerification(c.Context(), getUserID(c)); err != nil {
+		return sendAccountError(c, err)
+	}
+	return utils.SendSuccess(c, "Verification email sent")
+}
+
+func sendAccountError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidEmailToken), errors.Is(err, service.ErrPasswordTooShort):
+		return utils.SendError(c, 400, err.Error())
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		return utils.SendError(c, 409, err.Error())
+	}
+	return sendServiceError(c, 500, err)
+}
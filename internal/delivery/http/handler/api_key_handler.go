@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ApiKeyHandler struct {
+	service service.ApiKeyService
+}
+
+func NewApiKeyHandler(s service.ApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{service: s}
+}
+
+// GET /api/v1/api-keys (Key asli tidak pernah ditampilkan lagi, hanya prefix)
+func (h *ApiKeyHandler) ListKeys(c *fiber.Ctx) error {
+	keys, err := h.service.ListKeys(c.Context(), getOrgID(c))
+	if err != nil {
+		return utils.SendError(c, 500, err.Error())
+	}
+	return utils.SendSuccess(c, keys)
+}
+
+// POST /api/v1/api-keys (Respons memuat key asli, hanya sekali)
+func (h *ApiKeyHandler) CreateKey(c *fiber.Ctx) error {
+	var req models.CreateApiKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid request body")
+	}
+
+	key, err := h.service.CreateKey(c.Context(), getOrgID(c), getUserID(c), getPermissions(c), req)
+	if err != nil {
+		return sendApiKeyError(c, err)
+	}
+	return utils.SendCreated(c, key)
+}
+
+// DELETE /api/v1/api-keys/:id
+func (h *ApiKeyHandler) RevokeKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid API key ID")
+	}
+
+	if err := h.service.RevokeKey(c.Context(), getOrgID(c), keyID); err != nil {
+		return sendApiKeyError(c, err)
+	}
+	return utils.SendSuccess(c, "API key revoked")
+}
+
+func sendApiKeyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAPIKeyRequest):
+		return utils.SendError(c, 400, err.Error())
+	case errors.Is(err, service.ErrAPIKeyScopeNotHeld):
+		return utils.SendError(c, 403, err.Error())
+	}
+	return sendServiceError(c, 500, err)
+}
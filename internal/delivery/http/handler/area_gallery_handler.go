@@ -25,7 +25,7 @@ func (h *AreaGalleryHandler) AddItems(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.AddGalleryItems(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Items added to area gallery")
 }
@@ -38,7 +38,7 @@ func (h *AreaGalleryHandler) Reorder(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.ReorderGallery(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Area gallery reordered")
 }
@@ -51,7 +51,7 @@ func (h *AreaGalleryHandler) UpdateItem(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.UpdateGalleryItem(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Item updated")
 }
@@ -62,7 +62,7 @@ func (h *AreaGalleryHandler) RemoveItem(c *fiber.Ctx) error {
 	mediaID, _ := uuid.Parse(c.Params("media_id"))
 
 	if err := h.service.RemoveGalleryItem(c.Context(), areaID, mediaID); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Item removed")
 }
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
 	"inspacemap/backend/pkg/utils"
@@ -24,14 +25,17 @@ func (h *AreaHandler) CreateArea(c *fiber.Ctx) error {
 	}
 	resp, err := h.service.CreateArea(c.Context(), req)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendCreated(c, resp)
 }
 
 func (h *AreaHandler) GetDetail(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
-	resp, err := h.service.GetAreaDetail(c.Context(), id)
+	resp, err := h.service.GetAreaDetail(c.Context(), id, manifestAccess(c))
+	if errors.Is(err, service.ErrInvalidShareLink) {
+		return utils.SendError(c, 403, err.Error())
+	}
 	if err != nil {
 		return utils.SendError(c, 404, "Area not found")
 	}
@@ -42,7 +46,7 @@ func (h *AreaHandler) GetVenueAreas(c *fiber.Ctx) error {
 	venueID, _ := uuid.Parse(c.Params("venue_id"))
 	resp, err := h.service.GetVenueAreas(c.Context(), venueID)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, resp)
 }
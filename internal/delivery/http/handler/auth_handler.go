@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
 
 	// Import hash utils
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -39,7 +42,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	// Update Service Anda agar menggunakan utils.HashPassword!
 	// (Saya asumsikan Anda sudah update service auth_service.go untuk pakai utils)
 
-	resp, err := h.service.Register(c.Context(), req)
+	resp, err := h.service.Register(c.Context(), req, clientInfo(c))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -54,7 +57,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	resp, err := h.service.Login(c.Context(), req)
+	resp, err := h.service.Login(c.Context(), req, clientInfo(c))
 	if err != nil {
 		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -69,10 +72,116 @@ func (h *AuthHandler) AcceptInvite(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	resp, err := h.service.AcceptInvitation(c.Context(), req)
+	resp, err := h.service.AcceptInvitation(c.Context(), req, clientInfo(c))
 	if err != nil {
+		if errors.Is(err, service.ErrInvitationUsed) || errors.Is(err, service.ErrInvitationRevoked) || errors.Is(err, service.ErrInvitationExpired) {
+			return c.Status(410).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(resp)
 }
+
+// POST /api/v1/auth/refresh (rotasi: refresh token lama tidak berlaku lagi)
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "refresh_token is required"})
+	}
+
+	resp, err := h.service.Refresh(c.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(resp)
+}
+
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "refresh_token is required"})
+	}
+
+	if err := h.service.Logout(c.Context(), req.RefreshToken); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return utils.SendSuccess(c, "Logged out")
+}
+
+// GET /api/v1/sessions (Sesi aktif milik user token)
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	sessions, err := h.service.ListSessions(c.Context(), getUserID(c), getSessionID(c))
+	if err != nil {
+		return utils.SendError(c, 500, err.Error())
+	}
+	return utils.SendSuccess(c, sessions)
+}
+
+// DELETE /api/v1/sessions/:id
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Session ID")
+	}
+
+	if err := h.service.RevokeSession(c.Context(), getUserID(c), id); err != nil {
+		return sendServiceError(c, 500, err)
+	}
+	return utils.SendSuccess(c, "Session revoked")
+}
+
+// DELETE /api/v1/sessions (Logout dari semua perangkat)
+func (h *AuthHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	if err := h.service.RevokeAllSessions(c.Context(), getUserID(c)); err != nil {
+		return utils.SendError(c, 500, err.Error())
+	}
+	return utils.SendSuccess(c, "All sessions revoked")
+}
+
+// GET /api/v1/me (Semua membership + role & permission di organisasi aktif)
+func (h *AuthHandler) Me(c *fiber.Ctx) error {
+	me, err := h.service.GetMe(c.Context(), getUserID(c), getOrgID(c))
+	if err != nil {
+		return sendServiceError(c, 500, err)
+	}
+	return utils.SendSuccess(c, me)
+}
+
+// POST /api/v1/auth/switch-org (Access token baru untuk organisasi lain, refresh token tetap)
+func (h *AuthHandler) SwitchOrg(c *fiber.Ctx) error {
+	var req models.SwitchOrgRequest
+	if err := c.BodyParser(&req); err != nil || req.OrganizationID == uuid.Nil {
+		return utils.SendError(c, 400, "organization_id is required")
+	}
+
+	resp, err := h.service.SwitchOrganization(c.Context(), getUserID(c), getSessionID(c), req.OrganizationID)
+	if errors.Is(err, service.ErrSessionRevoked) {
+		return utils.SendError(c, 401, err.Error())
+	}
+	if err != nil {
+		return sendServiceError(c, 500, err)
+	}
+	return c.JSON(resp)
+}
+
+// GET /.well-known/jwks.json (Public key untuk verifikasi token di service lain)
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(utils.CurrentJWTKeySet().JWKS())
+}
+
+// clientInfo: User-Agent dipotong sesuai panjang kolom sesi
+func clientInfo(c *fiber.Ctx) models.ClientInfo {
+	ua := c.Get(fiber.HeaderUserAgent)
+	if len(ua) > 255 {
+		ua = ua[:255]
+	}
+	return models.ClientInfo{UserAgent: ua, IPAddress: c.IP()}
+}
@@ -1,20 +1,25 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
 	"inspacemap/backend/pkg/utils"
+	"io"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type GraphHandler struct {
-	service service.GraphService
+	service  service.GraphService
+	importer service.GraphImportService
 }
 
-func NewGraphHandler(s service.GraphService) *GraphHandler {
-	return &GraphHandler{service: s}
+func NewGraphHandler(s service.GraphService, i service.GraphImportService) *GraphHandler {
+	return &GraphHandler{service: s, importer: i}
 }
 
 // --- EDITOR DATA ---
@@ -28,7 +33,7 @@ func (h *GraphHandler) GetEditorData(c *fiber.Ctx) error {
 
 	data, err := h.service.GetEditorData(c.Context(), venueID)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendSuccess(c, data)
@@ -51,12 +56,30 @@ func (h *GraphHandler) CreateFloor(c *fiber.Ctx) error {
 
 	resp, err := h.service.CreateFloor(c.Context(), venueID, req)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendCreated(c, resp)
 }
 
+// PUT /api/v1/editor/floors/:id/georeference
+// Body null = hapus kalibrasi GPS lantai
+func (h *GraphHandler) UpdateFloorGeoReference(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Floor ID")
+	}
+	var req *models.GeoReference
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid JSON")
+	}
+
+	if err := h.service.UpdateFloorGeoReference(c.Context(), id, req); err != nil {
+		return sendServiceError(c, 400, err)
+	}
+	return utils.SendSuccess(c, nil)
+}
+
 // --- NODES ---
 
 // POST /api/v1/editor/nodes
@@ -68,7 +91,7 @@ func (h *GraphHandler) CreateNode(c *fiber.Ctx) error {
 
 	resp, err := h.service.CreateNode(c.Context(), req)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendCreated(c, resp)
@@ -83,7 +106,7 @@ func (h *GraphHandler) UpdateNodePosition(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.UpdateNodePosition(c.Context(), id, req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, nil)
 }
@@ -97,7 +120,7 @@ func (h *GraphHandler) CalibrateNode(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.UpdateNodeCalibration(c.Context(), id, req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, nil)
 }
@@ -112,7 +135,7 @@ func (h *GraphHandler) ConnectNodes(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.ConnectNodes(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, nil)
 }
@@ -128,7 +151,136 @@ func (h *GraphHandler) Publish(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.PublishChanges(c.Context(), venueID, req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Graph Published Successfully")
 }
+
+// POST /api/v1/editor/:venue_id/rollback (Body: {"revision_id": "..."})
+func (h *GraphHandler) Rollback(c *fiber.Ctx) error {
+	venueID, _ := uuid.Parse(c.Params("venue_id"))
+	var req models.RollbackRevisionRequest
+	if err := c.BodyParser(&req); err != nil || req.RevisionID == uuid.Nil {
+		return utils.SendError(c, 400, "revision_id is required")
+	}
+
+	if err := h.service.RollbackLive(c.Context(), venueID, req); err != nil {
+		return sendServiceError(c, 500, err)
+	}
+	return utils.SendSuccess(c, "Live Revision Restored")
+}
+
+// --- EXPORT ---
+
+// GET /api/v1/editor/:venue_id/geojson?revision=draft|live|<revision_id>
+// Response berupa GeoJSON mentah (tanpa APIResponse) agar bisa langsung dibuka di tools GIS
+func (h *GraphHandler) ExportGeoJSON(c *fiber.Ctx) error {
+	venueID, err := uuid.Parse(c.Params("venue_id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Venue ID")
+	}
+
+	data, err := h.service.ExportGeoJSON(c.Context(), venueID, c.Query("revision"))
+	if err != nil {
+		return sendServiceError(c, 404, err)
+	}
+
+	if err := c.JSON(data); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, "application/geo+json")
+	return nil
+}
+
+// GET /api/v1/editor/:venue_id/imdf?category=shoppingcenter&country=ID&language=en
+// Arsip IMDF dari live revision, 422 berisi daftar field yang belum lengkap
+func (h *GraphHandler) ExportIMDF(c *fiber.Ctx) error {
+	venueID, err := uuid.Parse(c.Params("venue_id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Venue ID")
+	}
+	var req models.IMDFExportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid query params")
+	}
+
+	data, err := h.service.ExportIMDF(c.Context(), venueID, req)
+	var invalid *service.IMDFValidationError
+	if errors.As(err, &invalid) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(utils.APIResponse{
+			Success: false,
+			Message: "Venue is not ready for IMDF export",
+			Data:    invalid.Issues,
+		})
+	}
+	if err != nil {
+		return sendServiceError(c, 404, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+venueID.String()+`-imdf.zip"`)
+	return c.Send(data)
+}
+
+// --- IMPORT ---
+
+// POST /api/v1/editor/:venue_id/import?dry_run=true
+// - application/json: models.GraphImportDocument
+// - application/geo+json (atau ?format=geojson): FeatureCollection
+// - multipart/form-data: file "nodes" (wajib), "edges" & "areas" (opsional)
+func (h *GraphHandler) Import(c *fiber.Ctx) error {
+	venueID, err := uuid.Parse(c.Params("venue_id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Venue ID")
+	}
+	dryRun := c.QueryBool("dry_run")
+	contentType := c.Get(fiber.HeaderContentType)
+
+	var result *models.GraphImportResult
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		files := make(map[string]io.Reader)
+		for _, name := range []string{"nodes", "edges", "areas"} {
+			header, err := c.FormFile(name)
+			if err != nil {
+				continue
+			}
+			file, err := header.Open()
+			if err != nil {
+				return utils.SendError(c, 400, "Cannot read "+name+" file")
+			}
+			defer file.Close()
+			files[name] = file
+		}
+		result, err = h.importer.ImportCSV(c.Context(), venueID, files["nodes"], files["edges"], files["areas"], dryRun)
+
+	case c.Query("format") == "geojson" || strings.HasPrefix(contentType, "application/geo+json"):
+		var fc models.GeoJSONFeatureCollection
+		if err := json.Unmarshal(c.Body(), &fc); err != nil {
+			return utils.SendError(c, 400, "Invalid GeoJSON")
+		}
+		result, err = h.importer.ImportGeoJSON(c.Context(), venueID, fc, dryRun)
+
+	default:
+		var doc models.GraphImportDocument
+		if err := c.BodyParser(&doc); err != nil {
+			return utils.SendError(c, 400, "Invalid JSON")
+		}
+		result, err = h.importer.ImportJSON(c.Context(), venueID, doc, dryRun)
+	}
+	if err != nil {
+		return sendServiceError(c, 400, err)
+	}
+
+	if !result.Valid {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(utils.APIResponse{
+			Success: false,
+			Message: "Import has invalid rows",
+			Data:    result,
+		})
+	}
+	if dryRun {
+		return utils.SendSuccess(c, result)
+	}
+	return utils.SendCreated(c, result)
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"inspacemap/backend/internal/delivery/http/middleware"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -22,3 +25,24 @@ func getOrgID(c *fiber.Ctx) uuid.UUID {
 	}
 	return id
 }
+
+func getSessionID(c *fiber.Ctx) uuid.UUID {
+	id, ok := c.Locals(middleware.CtxSessionID).(uuid.UUID)
+	if !ok {
+		return uuid.Nil
+	}
+	return id
+}
+
+func getPermissions(c *fiber.Ctx) []string {
+	perms, _ := c.Locals(middleware.CtxPermissions).([]string)
+	return perms
+}
+
+// sendServiceError: Resource yang tidak ada / milik organisasi lain selalu 404, sisanya pakai status fallback
+func sendServiceError(c *fiber.Ctx, status int, err error) error {
+	if errors.Is(err, service.ErrNotFound) {
+		return utils.SendError(c, 404, err.Error())
+	}
+	return utils.SendError(c, status, err.Error())
+}
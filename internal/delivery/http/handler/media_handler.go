@@ -33,7 +33,7 @@ func (h *MediaHandler) InitUpload(c *fiber.Ctx) error {
 
 	resp, err := h.service.InitDirectUpload(c.Context(), orgID, req)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendSuccess(c, resp)
@@ -47,7 +47,7 @@ func (h *MediaHandler) ConfirmUpload(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.ConfirmUpload(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendSuccess(c, "Upload Confirmed")
@@ -69,7 +69,7 @@ func (h *MediaHandler) ListAssets(c *fiber.Ctx) error {
 
 	assets, total, err := h.service.ListAssets(c.Context(), query)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendSuccess(c, fiber.Map{
@@ -103,7 +103,7 @@ func (h *MediaHandler) DeleteAsset(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.DeleteAsset(c.Context(), id); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 
 	return utils.SendSuccess(c, "Asset deleted")
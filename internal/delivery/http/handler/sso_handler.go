@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SSOHandler struct {
+	service service.SSOService
+}
+
+func NewSSOHandler(s service.SSOService) *SSOHandler {
+	return &SSOHandler{service: s}
+}
+
+// POST /api/v1/auth/sso/start (Frontend menyimpan code_verifier lalu redirect ke authorization_url)
+func (h *SSOHandler) Start(c *fiber.Ctx) error {
+	var req models.SSOStartRequest
+	if err := c.BodyParser(&req); err != nil || req.Organization == "" || req.Provider == "" {
+		return utils.SendError(c, 400, "organization and provider are required")
+	}
+
+	resp, err := h.service.StartLogin(c.Context(), req)
+	if err != nil {
+		return sendSSOError(c, err)
+	}
+	return utils.SendSuccess(c, resp)
+}
+
+// POST /api/v1/auth/sso/callback (code & state dari redirect provider + code_verifier dari start)
+func (h *SSOHandler) Callback(c *fiber.Ctx) error {
+	var req models.SSOCallbackRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" || req.State == "" || req.CodeVerifier == "" {
+		return utils.SendError(c, 400, "code, state and code_verifier are required")
+	}
+
+	resp, err := h.service.CompleteLogin(c.Context(), req, clientInfo(c))
+	if err != nil {
+		return sendSSOError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// POST /api/v1/me/sso/link/start (Sama dengan /auth/sso/start, state terikat ke user yang sedang login)
+func (h *SSOHandler) StartLink(c *fiber.Ctx) error {
+	var req models.SSOStartRequest
+	if err := c.BodyParser(&req); err != nil || req.Organization == "" || req.Provider == "" {
+		return utils.SendError(c, 400, "organization and provider are required")
+	}
+
+	resp, err := h.service.StartLink(c.Context(), getUserID(c), req)
+	if err != nil {
+		return sendSSOError(c, err)
+	}
+	return utils.SendSuccess(c, resp)
+}
+
+// POST /api/v1/me/sso/link (code & state dari redirect provider + code_verifier dari link/start)
+func (h *SSOHandler) CompleteLink(c *fiber.Ctx) error {
+	var req models.SSOCallbackRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" || req.State == "" || req.CodeVerifier == "" {
+		return utils.SendError(c, 400, "code, state and code_verifier are required")
+	}
+
+	if err := h.service.CompleteLink(c.Context(), getUserID(c), req); err != nil {
+		return sendSSOError(c, err)
+	}
+	return utils.SendSuccess(c, "SSO identity linked")
+}
+
+// GET /api/v1/sso-settings (Client secret tidak ikut dikembalikan)
+func (h *SSOHandler) GetSettings(c *fiber.Ctx) error {
+	settings, err := h.service.GetSettings(c.Context(), getOrgID(c))
+	if err != nil {
+		return sendSSOError(c, err)
+	}
+	return utils.SendSuccess(c, settings)
+}
+
+// PUT /api/v1/sso-settings (client_secret kosong = secret lama provider yang sama dipertahankan)
+func (h *SSOHandler) UpdateSettings(c *fiber.Ctx) error {
+	var req models.SSOSettings
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid request body")
+	}
+
+	if err := h.service.UpdateSettings(c.Context(), getOrgID(c), req); err != nil {
+		return sendSSOError(c, err)
+	}
+	return utils.SendSuccess(c, "SSO settings updated")
+}
+
+// POST /api/v1/sso-settings/domains/:domain/verify (Cek record TXT domain auto-join)
+func (h *SSOHandler) VerifyDomain(c *fiber.Ctx) error {
+	resp, err := h.service.VerifyDomain(c.Context(), getOrgID(c), c.Params("domain"))
+	if err != nil {
+		return sendSSOError(c, err)
+	}
+	return utils.SendSuccess(c, resp)
+}
+
+func sendSSOError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidSSOState), errors.Is(err, service.ErrInvalidSSOSettings):
+		return utils.SendError(c, 400, err.Error())
+	case errors.Is(err, service.ErrSSOLoginFailed):
+		return utils.SendError(c, 401, err.Error())
+	case errors.Is(err, service.ErrSSOEmailNotVerified), errors.Is(err, service.ErrSSONotMember):
+		return utils.SendError(c, 403, err.Error())
+	case errors.Is(err, service.ErrSSOLinkRequired), errors.Is(err, service.ErrSSOIdentityInUse):
+		return utils.SendError(c, 409, err.Error())
+	case errors.Is(err, service.ErrSSODomainLookupFailed):
+		return utils.SendError(c, 502, err.Error())
+	}
+	return sendServiceError(c, 500, err)
+}
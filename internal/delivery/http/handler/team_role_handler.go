@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
 	"inspacemap/backend/pkg/utils"
@@ -21,14 +22,70 @@ func NewTeamRoleHandler(ts service.TeamService, rs service.RoleService) *TeamRol
 	}
 }
 
-func (h *TeamRoleHandler) ListSystemRoles(c *fiber.Ctx) error {
-	roles, err := h.roleService.GetSystemRoles(c.Context())
+// GET /api/v1/roles (Role bawaan + role custom organisasi token)
+func (h *TeamRoleHandler) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.roleService.ListRoles(c.Context(), getOrgID(c))
 	if err != nil {
 		return utils.SendError(c, 500, err.Error())
 	}
 	return utils.SendSuccess(c, roles)
 }
 
+// POST /api/v1/roles
+func (h *TeamRoleHandler) CreateRole(c *fiber.Ctx) error {
+	var req models.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid JSON")
+	}
+
+	resp, err := h.roleService.CreateRole(c.Context(), getOrgID(c), getPermissions(c), req)
+	if err != nil {
+		return sendRoleError(c, err)
+	}
+	return utils.SendCreated(c, resp)
+}
+
+// PUT /api/v1/roles/:id (partial)
+func (h *TeamRoleHandler) UpdateRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Role ID")
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid JSON")
+	}
+
+	if err := h.roleService.UpdateRole(c.Context(), getOrgID(c), id, getPermissions(c), req); err != nil {
+		return sendRoleError(c, err)
+	}
+	return utils.SendSuccess(c, nil)
+}
+
+// DELETE /api/v1/roles/:id
+func (h *TeamRoleHandler) DeleteRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Role ID")
+	}
+
+	if err := h.roleService.DeleteRole(c.Context(), getOrgID(c), id); err != nil {
+		return sendRoleError(c, err)
+	}
+	return utils.SendSuccess(c, nil)
+}
+
+func sendRoleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrRoleProtected), errors.Is(err, service.ErrPermissionNotHeld):
+		return utils.SendError(c, 403, err.Error())
+	case errors.Is(err, service.ErrRoleNameTaken), errors.Is(err, service.ErrRoleInUse):
+		return utils.SendError(c, 409, err.Error())
+	}
+	return sendServiceError(c, 400, err)
+}
+
 func (h *TeamRoleHandler) ListPermissions(c *fiber.Ctx) error {
 	perms, err := h.roleService.GetAvailablePermissions(c.Context())
 	if err != nil {
@@ -42,6 +99,9 @@ func (h *TeamRoleHandler) ListMembers(c *fiber.Ctx) error {
 	if err != nil {
 		return utils.SendError(c, 400, "Invalid Organization ID")
 	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
 
 	list, err := h.teamService.GetMembersList(c.Context(), orgID)
 	if err != nil {
@@ -55,6 +115,9 @@ func (h *TeamRoleHandler) InviteMember(c *fiber.Ctx) error {
 	if err != nil {
 		return utils.SendError(c, 400, "Invalid Organization ID")
 	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
 
 	inviterID := getUserID(c)
 
@@ -63,25 +126,96 @@ func (h *TeamRoleHandler) InviteMember(c *fiber.Ctx) error {
 		return utils.SendError(c, 400, "Invalid JSON")
 	}
 
-	if err := h.teamService.InviteMember(c.Context(), orgID, inviterID, req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+	if err := h.teamService.InviteMember(c.Context(), orgID, inviterID, getPermissions(c), req); err != nil {
+		return sendInvitationError(c, err)
 	}
 
 	return utils.SendSuccess(c, "Invitation sent successfully")
 }
 
+// GET /api/v1/orgs/:org_id/invitations?status=pending
+func (h *TeamRoleHandler) ListInvitations(c *fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("org_id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Organization ID")
+	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
+
+	list, err := h.teamService.ListInvitations(c.Context(), orgID, c.Query("status"))
+	if err != nil {
+		return sendInvitationError(c, err)
+	}
+	return utils.SendSuccess(c, list)
+}
+
+// POST /api/v1/orgs/:org_id/invitations/:id/resend (Token & masa berlaku baru)
+func (h *TeamRoleHandler) ResendInvitation(c *fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("org_id"))
+	inviteID, err2 := uuid.Parse(c.Params("id"))
+	if err != nil || err2 != nil {
+		return utils.SendError(c, 400, "Invalid ID format")
+	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
+
+	if err := h.teamService.ResendInvitation(c.Context(), orgID, inviteID); err != nil {
+		return sendInvitationError(c, err)
+	}
+	return utils.SendSuccess(c, "Invitation resent")
+}
+
+// DELETE /api/v1/orgs/:org_id/invitations/:id
+func (h *TeamRoleHandler) RevokeInvitation(c *fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("org_id"))
+	inviteID, err2 := uuid.Parse(c.Params("id"))
+	if err != nil || err2 != nil {
+		return utils.SendError(c, 400, "Invalid ID format")
+	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
+
+	if err := h.teamService.RevokeInvitation(c.Context(), orgID, inviteID); err != nil {
+		return sendInvitationError(c, err)
+	}
+	return utils.SendSuccess(c, "Invitation revoked")
+}
+
+func sendInvitationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidInvitationStatus):
+		return utils.SendError(c, 400, err.Error())
+	case errors.Is(err, service.ErrPermissionNotHeld):
+		return utils.SendError(c, 403, err.Error())
+	case errors.Is(err, service.ErrInvitationNotPending):
+		return utils.SendError(c, 409, err.Error())
+	case errors.Is(err, service.ErrInvitationEmailFailed):
+		return utils.SendError(c, 502, err.Error())
+	}
+	return sendServiceError(c, 500, err)
+}
+
 func (h *TeamRoleHandler) UpdateMemberRole(c *fiber.Ctx) error {
 	orgID, err := uuid.Parse(c.Params("org_id"))
 	if err != nil {
 		return utils.SendError(c, 400, "Invalid Organization ID")
 	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
 
 	var req models.UpdateUserRoleRequest
 	if err := c.BodyParser(&req); err != nil {
 		return utils.SendError(c, 400, "Invalid JSON")
 	}
 
-	if err := h.teamService.UpdateMemberRole(c.Context(), orgID, req); err != nil {
+	if err := h.teamService.UpdateMemberRole(c.Context(), orgID, getPermissions(c), req); err != nil {
+		if errors.Is(err, service.ErrPermissionNotHeld) {
+			return utils.SendError(c, 403, err.Error())
+		}
 		return utils.SendError(c, 500, err.Error())
 	}
 
@@ -95,8 +229,14 @@ func (h *TeamRoleHandler) RemoveMember(c *fiber.Ctx) error {
 	if err != nil || err2 != nil {
 		return utils.SendError(c, 400, "Invalid ID format")
 	}
+	if orgID != getOrgID(c) {
+		return utils.SendError(c, 404, "Organization not found")
+	}
 
-	if err := h.teamService.RemoveMember(c.Context(), orgID, targetUserID); err != nil {
+	if err := h.teamService.RemoveMember(c.Context(), orgID, getPermissions(c), targetUserID); err != nil {
+		if errors.Is(err, service.ErrPermissionNotHeld) {
+			return utils.SendError(c, 403, err.Error())
+		}
 		return utils.SendError(c, 500, err.Error())
 	}
 
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	service service.TwoFactorService
+	auth    service.AuthService
+}
+
+func NewTwoFactorHandler(s service.TwoFactorService, auth service.AuthService) *TwoFactorHandler {
+	return &TwoFactorHandler{service: s, auth: auth}
+}
+
+// POST /api/v1/auth/2fa/verify (challenge_token dari login + kode TOTP atau recovery code)
+func (h *TwoFactorHandler) VerifyLogin(c *fiber.Ctx) error {
+	var req models.TwoFactorVerifyRequest
+	if err := c.BodyParser(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		return utils.SendError(c, 400, "challenge_token and code are required")
+	}
+
+	resp, err := h.auth.VerifyTwoFactor(c.Context(), req, clientInfo(c))
+	if err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// GET /api/v1/me/2fa
+func (h *TwoFactorHandler) GetStatus(c *fiber.Ctx) error {
+	status, err := h.service.GetStatus(c.Context(), getUserID(c))
+	if err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, status)
+}
+
+// POST /api/v1/me/2fa/setup (Secret + otpauth URL untuk QR code; belum aktif sampai dikonfirmasi)
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	resp, err := h.service.BeginEnrollment(c.Context(), getUserID(c))
+	if err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, resp)
+}
+
+// POST /api/v1/me/2fa/confirm (Recovery code hanya ditampilkan di respons ini)
+func (h *TwoFactorHandler) Confirm(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return utils.SendError(c, 400, "code is required")
+	}
+
+	resp, err := h.service.ConfirmEnrollment(c.Context(), getUserID(c), req.Code)
+	if err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, resp)
+}
+
+// POST /api/v1/me/2fa/disable
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	var req models.TwoFactorDisableRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return utils.SendError(c, 400, "code is required")
+	}
+
+	if err := h.service.Disable(c.Context(), getUserID(c), req); err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, "Two-factor authentication disabled")
+}
+
+// POST /api/v1/me/2fa/recovery-codes (Ganti semua recovery code)
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return utils.SendError(c, 400, "code is required")
+	}
+
+	resp, err := h.service.RegenerateRecoveryCodes(c.Context(), getUserID(c), req.Code)
+	if err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, resp)
+}
+
+// GET /api/v1/two-factor-policy
+func (h *TwoFactorHandler) GetPolicy(c *fiber.Ctx) error {
+	policy, err := h.service.GetPolicy(c.Context(), getOrgID(c))
+	if err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, policy)
+}
+
+// PUT /api/v1/two-factor-policy
+func (h *TwoFactorHandler) UpdatePolicy(c *fiber.Ctx) error {
+	var req models.TwoFactorPolicy
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid request body")
+	}
+
+	if err := h.service.UpdatePolicy(c.Context(), getOrgID(c), getUserID(c), req); err != nil {
+		return sendTwoFactorError(c, err)
+	}
+	return utils.SendSuccess(c, "Two-factor policy updated")
+}
+
+func sendTwoFactorError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorChallenge), errors.Is(err, service.ErrInvalidTwoFactorCode),
+		errors.Is(err, service.ErrInvalidPassword):
+		return utils.SendError(c, 401, err.Error())
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled),
+		errors.Is(err, service.ErrTwoFactorNotStarted):
+		return utils.SendError(c, 409, err.Error())
+	}
+	return sendServiceError(c, 500, err)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type VenueBundleHandler struct {
+	service service.VenueBundleService
+}
+
+func NewVenueBundleHandler(s service.VenueBundleService) *VenueBundleHandler {
+	return &VenueBundleHandler{service: s}
+}
+
+// POST /api/v1/venues/:id/clone
+func (h *VenueBundleHandler) CloneVenue(c *fiber.Ctx) error {
+	venueID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Venue ID")
+	}
+	var req models.CloneVenueRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendError(c, 400, "Invalid JSON")
+		}
+	}
+
+	resp, err := h.service.CloneVenue(c.Context(), getOrgID(c), getUserID(c), venueID, req)
+	if errors.Is(err, service.ErrVenueNotFound) {
+		return utils.SendError(c, 404, err.Error())
+	}
+	if err != nil {
+		return utils.SendError(c, 400, err.Error())
+	}
+
+	return utils.SendCreated(c, resp)
+}
+
+// GET /api/v1/venues/:id/bundle?revisions=live|all
+func (h *VenueBundleHandler) ExportBundle(c *fiber.Ctx) error {
+	venueID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Venue ID")
+	}
+	var req models.VenueBundleExportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid query params")
+	}
+
+	write, err := h.service.ExportBundle(c.Context(), venueID, req)
+	if errors.Is(err, service.ErrVenueNotFound) {
+		return utils.SendError(c, 404, err.Error())
+	}
+	if err != nil {
+		return utils.SendError(c, 400, err.Error())
+	}
+
+	// Zip ditulis langsung ke koneksi (chunked) sambil media dibaca dari storage
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+venueID.String()+`-bundle.zip"`)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := write(w); err != nil {
+			// Status sudah terkirim; klien menerima zip terpotong yang gagal dibaca
+			log.Printf("export bundle %s: %v", venueID, err)
+		}
+	})
+	return nil
+}
+
+// POST /api/v1/venues/import?name=&slug=
+// Setiap media di bundle wajib ada file-nya; media selalu diupload ulang ke org importer.
+// - multipart/form-data: file "bundle"
+// - application/zip: raw body
+func (h *VenueBundleHandler) ImportBundle(c *fiber.Ctx) error {
+	orgID := getOrgID(c)
+	userID := getUserID(c)
+	if orgID == uuid.Nil || userID == uuid.Nil {
+		return utils.SendError(c, 401, "Unauthorized")
+	}
+	var req models.VenueBundleImportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid query params")
+	}
+
+	// Upload besar tidak ditampung di memori: multipart disimpan fasthttp ke file sementara,
+	// body zip mentah yang di-stream disalin ke file sementara (zip butuh io.ReaderAt)
+	var reader io.ReaderAt
+	var size int64
+	switch {
+	case strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm):
+		header, err := c.FormFile("bundle")
+		if err != nil {
+			return utils.SendError(c, 400, "File 'bundle' is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return utils.SendError(c, 400, "Cannot read bundle file")
+		}
+		defer file.Close()
+		reader, size = file, header.Size
+	case c.Request().IsBodyStream():
+		tmp, err := os.CreateTemp("", "venue-bundle-*.zip")
+		if err != nil {
+			return utils.SendError(c, 500, "Cannot store bundle file")
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if size, err = io.Copy(tmp, c.Request().BodyStream()); err != nil {
+			return utils.SendError(c, 400, "Cannot read bundle file")
+		}
+		reader = tmp
+	default:
+		body := c.Body()
+		reader, size = bytes.NewReader(body), int64(len(body))
+	}
+
+	result, err := h.service.ImportBundle(c.Context(), orgID, userID, reader, size, req)
+	if errors.Is(err, service.ErrInvalidBundle) {
+		return utils.SendError(c, 400, err.Error())
+	}
+	if errors.Is(err, service.ErrBundleTooLarge) {
+		return utils.SendError(c, 413, err.Error())
+	}
+	if err != nil {
+		return utils.SendError(c, 422, err.Error())
+	}
+
+	return utils.SendCreated(c, result)
+}
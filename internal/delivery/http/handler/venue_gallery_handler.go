@@ -24,7 +24,7 @@ func (h *VenueGalleryHandler) AddItems(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.AddGalleryItems(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Items added to venue gallery")
 }
@@ -36,7 +36,7 @@ func (h *VenueGalleryHandler) Reorder(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.ReorderGallery(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Venue gallery reordered")
 }
@@ -48,7 +48,7 @@ func (h *VenueGalleryHandler) UpdateItem(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.UpdateGalleryItem(c.Context(), req); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Item updated")
 }
@@ -65,7 +65,7 @@ func (h *VenueGalleryHandler) RemoveItem(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.RemoveGalleryItem(c.Context(), venueID, mediaID); err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendServiceError(c, 500, err)
 	}
 	return utils.SendSuccess(c, "Item removed")
 }
@@ -1,20 +1,26 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
 	"inspacemap/backend/pkg/utils"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type VenueHandler struct {
-	service service.VenueService
+	service        service.VenueService
+	packageService service.VenuePackageService
 }
 
-func NewVenueHandler(s service.VenueService) *VenueHandler {
-	return &VenueHandler{service: s}
+func NewVenueHandler(s service.VenueService, p service.VenuePackageService) *VenueHandler {
+	return &VenueHandler{service: s, packageService: p}
 }
 
 // POST /api/v1/venues (Admin Create)
@@ -24,30 +30,324 @@ func (h *VenueHandler) CreateVenue(c *fiber.Ctx) error {
 		return utils.SendError(c, 400, "Invalid JSON")
 	}
 
-	resp, err := h.service.CreateVenue(c.Context(), req)
+	resp, err := h.service.CreateVenue(c.Context(), getOrgID(c), req)
 	if err != nil {
-		return utils.SendError(c, 500, err.Error())
+		return sendVenueError(c, err)
 	}
 
 	return utils.SendCreated(c, resp)
 }
 
-// GET /api/v1/venues/:slug/manifest (Mobile App Read)
-func (h *VenueHandler) GetManifest(c *fiber.Ctx) error {
+// GET /api/v1/venues (Admin List, hanya venue milik organisasi token)
+func (h *VenueHandler) ListVenues(c *fiber.Ctx) error {
+	var query models.VenueQuery
+	if err := c.QueryParser(&query); err != nil {
+		return utils.SendError(c, 400, "Invalid query parameters")
+	}
+
+	venues, total, err := h.service.ListVenues(c.Context(), getOrgID(c), query)
+	if err != nil {
+		return utils.SendError(c, 400, err.Error())
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"venues": venues,
+		"total":  total,
+	})
+}
+
+// PUT /api/v1/venues/:id (Admin Update, partial)
+func (h *VenueHandler) UpdateVenue(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid UUID")
+	}
+
+	var req models.UpdateVenueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, 400, "Invalid JSON")
+	}
+
+	if err := h.service.UpdateVenue(c.Context(), getOrgID(c), id, req); err != nil {
+		return sendVenueError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil)
+}
+
+// DELETE /api/v1/venues/:id (Admin Delete, soft delete)
+func (h *VenueHandler) DeleteVenue(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid UUID")
+	}
+
+	if err := h.service.DeleteVenue(c.Context(), getOrgID(c), id); err != nil {
+		return sendVenueError(c, err)
+	}
+
+	return utils.SendSuccess(c, nil)
+}
+
+func sendVenueError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrVenueNotFound):
+		return utils.SendError(c, 404, err.Error())
+	case errors.Is(err, service.ErrVenueSlugTaken):
+		return utils.SendError(c, 409, err.Error())
+	}
+	return utils.SendError(c, 400, err.Error())
+}
+
+// legacyOrgSlugKey: Slug organisasi hasil resolve route lama (route baru membawanya di path)
+const legacyOrgSlugKey = "legacy_org_slug"
+
+// LegacyManifestSlug: Middleware route lama /api/v1/venues/:slug/... (deprecated, dipakai app mobile versi lama).
+// Slug venue hanya unik per organisasi: dilayani jika slug unik, 409 jika dipakai beberapa organisasi.
+func (h *VenueHandler) LegacyManifestSlug(c *fiber.Ctx) error {
 	slug := c.Params("slug")
-	if slug == "" {
+	orgSlug, err := h.service.ResolveLegacySlug(c.Context(), slug)
+	if errors.Is(err, service.ErrVenueSlugAmbiguous) {
+		return utils.SendError(c, 409, err.Error())
+	}
+	if err != nil {
+		return sendManifestError(c, err)
+	}
+
+	c.Locals(legacyOrgSlugKey, orgSlug)
+	c.Set("Deprecation", "true")
+	successor := "/api/v1/orgs/" + orgSlug + strings.TrimPrefix(c.Path(), "/api/v1")
+	c.Set(fiber.HeaderLink, "<"+successor+`>; rel="successor-version"`)
+	return c.Next()
+}
+
+func manifestOrgSlug(c *fiber.Ctx) string {
+	if orgSlug, ok := c.Locals(legacyOrgSlugKey).(string); ok {
+		return orgSlug
+	}
+	return c.Params("org_slug")
+}
+
+// GET /api/v1/orgs/:org_slug/venues/:slug/manifest (Mobile App Read)
+func (h *VenueHandler) GetManifest(c *fiber.Ctx) error {
+	orgSlug, slug := manifestOrgSlug(c), c.Params("slug")
+	if orgSlug == "" || slug == "" {
 		return utils.SendError(c, 400, "Slug is required")
 	}
 
-	manifest, err := h.service.GetMobileManifest(c.Context(), slug)
+	access := manifestAccess(c)
+	manifest, err := h.service.GetMobileManifest(c.Context(), orgSlug, slug, access)
 	if err != nil {
-		return utils.SendError(c, 404, "Venue not found or not published")
+		return sendManifestError(c, err)
+	}
+
+	// Content Negotiation: JSON default, MessagePack ringkas jika diminta via Accept
+	c.Vary(fiber.HeaderAccept)
+	format := c.Accepts(fiber.MIMEApplicationJSON, service.CompactManifestMediaType, "application/msgpack", "application/x-msgpack")
+	compact := format != "" && format != fiber.MIMEApplicationJSON
+
+	etagPart := ""
+	if compact {
+		etagPart = "msgpack"
+	}
+
+	// Conditional Request: mobile cukup dapat 304 jika peta belum berubah
+	if writeManifestCacheHeaders(c, access, manifestETag(manifest.RevisionID, manifest.LastUpdated, etagPart), manifest.LastUpdated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	if compact {
+		c.Set(fiber.HeaderContentType, service.CompactManifestMediaType)
+		return c.Send(service.EncodeCompactManifest(manifest))
 	}
 
 	// Khusus manifest, return raw struct agar strukturnya sesuai persis dengan DTO
 	return c.JSON(manifest)
 }
 
+// GET /api/v1/orgs/:org_slug/venues/:slug/manifest/index (Mobile: daftar lantai tanpa node)
+func (h *VenueHandler) GetManifestIndex(c *fiber.Ctx) error {
+	access := manifestAccess(c)
+	index, err := h.service.GetManifestIndex(c.Context(), manifestOrgSlug(c), c.Params("slug"), access)
+	if err != nil {
+		return sendManifestError(c, err)
+	}
+
+	if writeManifestCacheHeaders(c, access, manifestETag(index.RevisionID, index.LastUpdated, "index"), index.LastUpdated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return c.JSON(index)
+}
+
+// GET /api/v1/orgs/:org_slug/venues/:slug/manifest/floors/:floor_id (Mobile: unduh satu lantai)
+func (h *VenueHandler) GetManifestFloor(c *fiber.Ctx) error {
+	floorID, err := uuid.Parse(c.Params("floor_id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid Floor UUID")
+	}
+
+	access := manifestAccess(c)
+	floor, err := h.service.GetManifestFloor(c.Context(), manifestOrgSlug(c), c.Params("slug"), floorID, access)
+	if err != nil {
+		return sendManifestError(c, err)
+	}
+
+	if writeManifestCacheHeaders(c, access, manifestETag(floor.RevisionID, floor.LastUpdated, floorID.String()), floor.LastUpdated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return c.JSON(floor)
+}
+
+// GET /api/v1/orgs/:org_slug/venues/:slug/manifest/delta?from=<revision_id> (Mobile: perubahan sejak revisi yang dimiliki)
+func (h *VenueHandler) GetManifestDelta(c *fiber.Ctx) error {
+	fromRevisionID, err := uuid.Parse(c.Query("from"))
+	if err != nil {
+		return utils.SendError(c, 400, "Query 'from' must be a revision UUID")
+	}
+
+	access := manifestAccess(c)
+	delta, err := h.service.GetManifestDelta(c.Context(), manifestOrgSlug(c), c.Params("slug"), fromRevisionID, access)
+	if err != nil {
+		return sendManifestError(c, err)
+	}
+
+	if writeManifestCacheHeaders(c, access, manifestETag(delta.ToRevisionID, delta.LastUpdated, "from-"+fromRevisionID.String()), delta.LastUpdated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return c.JSON(delta)
+}
+
+// GET /api/v1/orgs/:org_slug/venues/:slug/package?panorama=full|preview|none (Kiosk / Field App Offline)
+func (h *VenueHandler) GetPackage(c *fiber.Ctx) error {
+	status, err := h.packageService.RequestPackage(c.Context(), manifestOrgSlug(c), c.Params("slug"), manifestAccess(c), c.Query("panorama"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPanoramaResolution) {
+			return utils.SendError(c, 400, err.Error())
+		}
+		return sendManifestError(c, err)
+	}
+
+	switch status.Status {
+	case models.PackageStatusReady:
+		// Client yang mengikuti redirect langsung dapat zip, sisanya bisa baca download_url
+		c.Location(status.DownloadURL)
+		return c.Status(fiber.StatusFound).JSON(utils.APIResponse{Success: true, Data: status})
+	case models.PackageStatusFailed:
+		return utils.SendError(c, 500, "Package build failed: "+status.Error)
+	}
+
+	// Masih dibuat di background
+	c.Set(fiber.HeaderRetryAfter, "5")
+	return c.Status(fiber.StatusAccepted).JSON(utils.APIResponse{Success: true, Data: status})
+}
+
+func manifestAccess(c *fiber.Ctx) models.ManifestAccess {
+	// Share link bisa dikirim via query (link langsung) atau header (mobile app)
+	shareToken := c.Query("share_token")
+	if shareToken == "" {
+		shareToken = c.Get("X-Share-Token")
+	}
+
+	return models.ManifestAccess{
+		ShareToken:     shareToken,
+		OrganizationID: getOrgID(c), // Terisi jika request membawa token login (OptionalAuth)
+	}
+}
+
+func sendManifestError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidShareLink):
+		return utils.SendError(c, 403, err.Error())
+	case errors.Is(err, service.ErrManifestRevisionGone):
+		return utils.SendError(c, 410, err.Error())
+	}
+	return utils.SendError(c, 404, "Venue not found or not published")
+}
+
+// writeManifestCacheHeaders memasang ETag/Last-Modified/Cache-Control dan
+// mengembalikan true jika client sudah punya versi terbaru (cukup balas 304).
+// Preview draft tidak punya validator: edit node/edge tidak mengubah revisi maupun waktu update venue.
+func writeManifestCacheHeaders(c *fiber.Ctx, access models.ManifestAccess, etag string, lastUpdated time.Time) bool {
+	if isDraftPreview(access) {
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return false
+	}
+
+	lastModified := lastUpdated.UTC().Truncate(time.Second)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	if access.ShareToken != "" || access.OrganizationID != uuid.Nil {
+		c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "public, no-cache")
+	}
+
+	return isManifestNotModified(c, etag, lastModified)
+}
+
+// isDraftPreview: Share link yang membuka draft (token sudah divalidasi service)
+func isDraftPreview(access models.ManifestAccess) bool {
+	if access.ShareToken == "" {
+		return false
+	}
+	share, err := utils.ParseShareToken(access.ShareToken)
+	return err == nil && share.IncludeDraft
+}
+
+// ETag berbasis revisi + waktu update: berubah saat publish atau saat data venue berubah.
+// part membedakan resource turunan (index, floor, delta) dari manifest penuh.
+func manifestETag(revisionID uuid.UUID, lastUpdated time.Time, part string) string {
+	if part == "" {
+		return fmt.Sprintf(`W/"%s-%d"`, revisionID.String(), lastUpdated.UnixNano())
+	}
+	return fmt.Sprintf(`W/"%s-%d-%s"`, revisionID.String(), lastUpdated.UnixNano(), part)
+}
+
+func isManifestNotModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	// If-None-Match lebih prioritas daripada If-Modified-Since (RFC 9110)
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.After(t)
+		}
+	}
+	return false
+}
+
+// POST /api/v1/venues/:id/share-links (Admin: Buat share link untuk venue private/draft)
+func (h *VenueHandler) CreateShareLink(c *fiber.Ctx) error {
+	venueID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.SendError(c, 400, "Invalid UUID")
+	}
+
+	var req models.CreateShareLinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendError(c, 400, "Invalid JSON")
+		}
+	}
+
+	resp, err := h.service.CreateShareLink(c.Context(), getOrgID(c), venueID, req)
+	if err != nil {
+		return utils.SendError(c, 400, err.Error())
+	}
+
+	return utils.SendCreated(c, resp)
+}
+
 // GET /api/v1/venues/:id (Admin Detail)
 func (h *VenueHandler) GetDetail(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -56,8 +356,10 @@ func (h *VenueHandler) GetDetail(c *fiber.Ctx) error {
 		return utils.SendError(c, 400, "Invalid UUID")
 	}
 
-	// TODO: Implement GetVenueDetail di service dulu (ini placeholder)
-	// detail, err := h.service.GetVenueDetail(c.Context(), id)
+	detail, err := h.service.GetVenueDetail(c.Context(), getOrgID(c), id)
+	if err != nil {
+		return sendVenueError(c, err)
+	}
 
-	return utils.SendSuccess(c, fiber.Map{"id": id, "status": "todo"})
+	return utils.SendSuccess(c, detail)
 }
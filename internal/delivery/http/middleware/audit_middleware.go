@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/pkg/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AuditLogger: Penulis log audit non-blocking (dipenuhi service.AuditService)
+type AuditLogger interface {
+	LogActivity(ctx context.Context, payload models.CreateAuditLogRequest)
+}
+
+// Audit: Pasang pelaku request ke context (dibaca snapshot before/after di service) lalu catat setiap
+// request POST/PUT/PATCH/DELETE beserta status akhirnya. Dipasang setelah Protected.
+// logger nil = hanya memasang pelaku.
+func Audit(logger AuditLogger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals(CtxUserID).(uuid.UUID)
+		keyID, _ := c.Locals(CtxAPIKeyID).(uuid.UUID)
+		// String dari fasthttp dipakai ulang setelah request selesai, sedangkan log ditulis belakangan
+		actor := utils.AuditActor{
+			UserID:    userID,
+			APIKeyID:  keyID,
+			IPAddress: strings.Clone(c.IP()),
+			UserAgent: strings.Clone(c.Get(fiber.HeaderUserAgent)),
+			RequestID: uuid.NewString(),
+		}
+		c.Locals(utils.AuditActorContextKey, actor)
+
+		if logger == nil || !isMutation(c.Method()) {
+			return c.Next()
+		}
+
+		err := c.Next()
+		orgID, _ := c.Locals(CtxOrgID).(uuid.UUID)
+		if orgID == uuid.Nil {
+			return err // Log audit selalu milik satu organisasi
+		}
+
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+
+		route := strings.TrimPrefix(c.Route().Path, "/api/v1")
+		details := map[string]interface{}{
+			"path":       strings.Clone(c.Path()),
+			"status":     status,
+			"request_id": actor.RequestID,
+		}
+		if keyID != uuid.Nil {
+			details["api_key_id"] = keyID.String()
+		}
+
+		logger.LogActivity(c.Context(), models.CreateAuditLogRequest{
+			OrganizationID: orgID,
+			UserID:         userID,
+			Action:         "HTTP_" + c.Method(),
+			Entity:         route,
+			EntityID:       lastRouteParam(c),
+			Details:        details,
+			IPAddress:      actor.IPAddress,
+			UserAgent:      actor.UserAgent,
+		})
+		return err
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// lastRouteParam: Parameter paling spesifik, mis. /orgs/:org_id/members/:user_id -> user_id
+func lastRouteParam(c *fiber.Ctx) string {
+	params := c.Route().Params
+	if len(params) == 0 {
+		return ""
+	}
+	return strings.Clone(c.Params(params[len(params)-1]))
+}
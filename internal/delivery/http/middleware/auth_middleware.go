@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"inspacemap/backend/internal/models"
 	"inspacemap/backend/pkg/utils"
 	"strings"
 
@@ -13,11 +15,38 @@ const (
 	CtxUserEmail   = "user_email"
 	CtxOrgID       = "org_id"      // Organisasi aktif di token
 	CtxPermissions = "permissions" // List []string
+	CtxSessionID   = "session_id"  // Family refresh token (claim sid)
+	CtxAPIKeyID    = "api_key_id"  // Terisi jika request diautentikasi lewat X-API-Key
+
+	// APIKeyHeader: Header untuk integrasi machine-to-machine
+	APIKeyHeader = "X-API-Key"
 )
 
-// Protected: Cek Token Validitas
-func Protected() fiber.Handler {
+// PermissionResolver: Sumber permission live per request (dipenuhi service.PermissionResolver)
+type PermissionResolver interface {
+	ResolvePermissions(ctx context.Context, userID, orgID uuid.UUID) (perms []string, isMember bool, err error)
+}
+
+// APIKeyAuthenticator: Validasi X-API-Key (dipenuhi service.ApiKeyService)
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.ApiKeyPrincipal, error)
+}
+
+// Protected: Cek Token Validitas.
+// Jika resolver diisi, permission diambil dari membership saat ini (perubahan role / pencabutan langsung berlaku);
+// resolver nil = pakai permission yang tertanam di token.
+// Jika apiKeys diisi, header X-API-Key diterima sebagai pengganti token: organisasi & permission diambil dari key.
+func Protected(resolver PermissionResolver, apiKeys APIKeyAuthenticator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if rawKey := c.Get(APIKeyHeader); rawKey != "" && apiKeys != nil {
+			principal, err := apiKeys.AuthenticateAPIKey(c.Context(), rawKey)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired API key"})
+			}
+			setAPIKeyLocals(c, principal)
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing authorization header"})
@@ -29,16 +58,104 @@ func Protected() fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
 		}
 
-		// Simpan data penting ke Context agar bisa dipakai Handler
-		c.Locals(CtxUserID, claims.UserID)
-		c.Locals(CtxUserEmail, claims.Email)
-		c.Locals(CtxOrgID, claims.OrganizationID)
-		c.Locals(CtxPermissions, claims.Permissions) // Simpan list permission
+		if err := setAuthLocals(c, claims, resolver); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve permissions"})
+		}
 
 		return c.Next()
 	}
 }
 
+// OptionalAuth: Seperti Protected, tapi request tanpa token / token invalid tetap diteruskan.
+// Dipakai di endpoint publik yang hasilnya bisa berbeda untuk anggota organisasi (misal manifest venue private).
+func OptionalAuth(resolver PermissionResolver, apiKeys APIKeyAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if rawKey := c.Get(APIKeyHeader); rawKey != "" && apiKeys != nil {
+			if principal, err := apiKeys.AuthenticateAPIKey(c.Context(), rawKey); err == nil {
+				setAPIKeyLocals(c, principal)
+			}
+			return c.Next()
+		}
+
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			return c.Next()
+		}
+
+		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
+		claims, err := utils.ParseToken(tokenString)
+		if err != nil {
+			return c.Next()
+		}
+
+		if err := setAuthLocals(c, claims, resolver); err != nil {
+			return c.Next() // Gagal evaluasi akses: perlakukan sebagai anonim
+		}
+
+		return c.Next()
+	}
+}
+
+// setAuthLocals: Simpan data penting ke Context agar bisa dipakai Handler
+func setAuthLocals(c *fiber.Ctx, claims *utils.JWTPayload, resolver PermissionResolver) error {
+	perms := claims.Permissions
+	orgID := claims.OrganizationID
+	if resolver != nil {
+		live, isMember, err := resolver.ResolvePermissions(c.Context(), claims.UserID, claims.OrganizationID)
+		if err != nil {
+			return err
+		}
+		perms = live
+		if !isMember {
+			// Sudah dikeluarkan dari organisasi token: tidak ada konteks organisasi sama sekali
+			orgID = uuid.Nil
+		}
+	}
+
+	c.Locals(CtxUserID, claims.UserID)
+	c.Locals(CtxUserEmail, claims.Email)
+	c.Locals(CtxOrgID, orgID)
+	c.Locals(utils.OrgContextKey, orgID) // Dibaca service lewat c.Context()
+	c.Locals(CtxPermissions, perms)      // Simpan list permission
+	c.Locals(CtxSessionID, claims.SessionID)
+	return nil
+}
+
+// setAPIKeyLocals: Principal API key tidak punya user maupun sesi (CtxUserID = uuid.Nil)
+func setAPIKeyLocals(c *fiber.Ctx, principal *models.ApiKeyPrincipal) {
+	c.Locals(CtxUserID, uuid.Nil)
+	c.Locals(CtxUserEmail, "")
+	c.Locals(CtxOrgID, principal.OrganizationID)
+	c.Locals(utils.OrgContextKey, principal.OrganizationID)
+	c.Locals(CtxPermissions, principal.Scopes)
+	c.Locals(CtxSessionID, uuid.Nil)
+	c.Locals(CtxAPIKeyID, principal.KeyID)
+}
+
+// RequireUser: Endpoint milik akun user (profil, sesi, kelola API key) tidak boleh diakses lewat API key
+func RequireUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userID, _ := c.Locals(CtxUserID).(uuid.UUID); userID == uuid.Nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "This endpoint requires a user session",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// RequireMember: Token harus punya organisasi aktif yang user masih menjadi anggotanya
+func RequireMember() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if orgID, _ := c.Locals(CtxOrgID).(uuid.UUID); orgID == uuid.Nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You are not a member of this organization",
+			})
+		}
+		return c.Next()
+	}
+}
+
 // RBAC Guard: Middleware Factory
 // Contoh penggunaan: api.Post("/venues", RequirePermission("venue:create"), CreateVenue)
 func RequirePermission(requiredPerm string) fiber.Handler {
@@ -84,7 +201,7 @@ func TenantGuard() fiber.Handler {
 		// Jika ada header, pastikan COCOK dengan token
 		if headerOrgIDStr != tokenOrgID.String() {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Token organization mismatch. Please switch organization context.",
+				"error": "Token organization mismatch. Switch organization via POST /api/v1/auth/switch-org.",
 			})
 		}
 
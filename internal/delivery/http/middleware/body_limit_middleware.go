@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit: Batas ukuran body per route. Server memakai StreamRequestBody, sehingga body di atas
+// BodyLimit fiber belum dibaca saat middleware ini jalan dan bisa ditolak 413 tanpa ditampung.
+// limit <= 0 = tanpa batas. skip: path yang memasang BodyLimit sendiri (mis. import bundle).
+func BodyLimit(limit int, skip ...string) fiber.Handler {
+	if limit <= 0 {
+		return passThrough
+	}
+	return func(c *fiber.Ctx) error {
+		for _, path := range skip {
+			if c.Path() == path {
+				return c.Next()
+			}
+		}
+
+		req := c.Request()
+		length := req.Header.ContentLength()
+		if length > limit {
+			return bodyTooLarge(c)
+		}
+		// Chunked: panjang baru diketahui setelah dibaca, baca maksimal limit+1 byte
+		if length < 0 && req.IsBodyStream() {
+			body, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(limit)+1))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot read request body"})
+			}
+			if len(body) > limit {
+				return bodyTooLarge(c)
+			}
+			req.SetBody(body)
+		}
+		return c.Next()
+	}
+}
+
+func bodyTooLarge(c *fiber.Ctx) error {
+	c.Context().SetConnectionClose() // Sisa body tidak dibaca
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Request body too large"})
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"encoding/json"
+	"inspacemap/backend/pkg/ratelimit"
+	"inspacemap/backend/pkg/utils"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RateLimitConfig: Kuota per kelompok endpoint. nil / rule kosong = tanpa batas.
+type RateLimitConfig struct {
+	Store    ratelimit.Store
+	Auth     ratelimit.Rule // Login, register, reset password, dsb. per IP
+	Refresh  ratelimit.Rule // Rotasi refresh token per IP (bucket sendiri, SPA me-refresh berkala)
+	Manifest ratelimit.Rule // Manifest & package publik per IP
+	API      ratelimit.Rule // Request terautentikasi per akun (user / API key)
+	// Kunci akun bertahap setelah gagal login / 2FA berulang; nil = tanpa lockout
+	Lockout *ratelimit.Lockout
+}
+
+// AuthLimit: Bucket per IP untuk endpoint autentikasi publik
+func (cfg *RateLimitConfig) AuthLimit() fiber.Handler {
+	if cfg == nil {
+		return passThrough
+	}
+	return RateLimit(cfg.Store, "auth", cfg.Auth, ClientIPKey)
+}
+
+// RefreshLimit: Bucket per IP untuk /auth/refresh
+func (cfg *RateLimitConfig) RefreshLimit() fiber.Handler {
+	if cfg == nil {
+		return passThrough
+	}
+	return RateLimit(cfg.Store, "refresh", cfg.Refresh, ClientIPKey)
+}
+
+// ManifestLimit: Bucket per IP untuk trafik manifest publik (terpisah dari kuota API)
+func (cfg *RateLimitConfig) ManifestLimit() fiber.Handler {
+	if cfg == nil {
+		return passThrough
+	}
+	return RateLimit(cfg.Store, "manifest", cfg.Manifest, ClientIPKey)
+}
+
+// APILimit: Bucket per akun; dipasang setelah Protected
+func (cfg *RateLimitConfig) APILimit() fiber.Handler {
+	if cfg == nil {
+		return passThrough
+	}
+	return RateLimit(cfg.Store, "api", cfg.API, AccountKey)
+}
+
+// LoginLockout: Lockout per akun dengan key dari account
+func (cfg *RateLimitConfig) LoginLockout(account func(*fiber.Ctx) string) fiber.Handler {
+	if cfg == nil || cfg.Lockout == nil {
+		return passThrough
+	}
+	return LoginLockout(cfg.Lockout, account)
+}
+
+func passThrough(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+// RateLimit: Fixed window per key. Header X-RateLimit-* selalu dikirim; saat kuota habis 429 + Retry-After.
+// Store error tidak memblokir request (fail open) supaya gangguan Redis tidak mematikan API.
+func RateLimit(store ratelimit.Store, bucket string, rule ratelimit.Rule, key func(*fiber.Ctx) string) fiber.Handler {
+	if store == nil || !rule.Enabled() {
+		return passThrough
+	}
+	return func(c *fiber.Ctx) error {
+		res, err := ratelimit.Allow(c.Context(), store, "rl:"+bucket+":"+key(c), rule)
+		if err != nil {
+			log.Printf("rate limit %s: %v", bucket, err)
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Set("X-RateLimit-Reset", retryAfterSeconds(res.RetryAfter))
+		if !res.Allowed {
+			c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(res.RetryAfter))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Too many requests, please retry later"})
+		}
+		return c.Next()
+	}
+}
+
+// LoginLockout: Tolak percobaan saat akun terkunci. Setiap percobaan dihitung sebelum handler jalan
+// (lihat ratelimit.Lockout.Attempt), respons sukses me-reset hitungan. Key kosong (body tidak valid) tidak dikunci.
+func LoginLockout(lockout *ratelimit.Lockout, account func(*fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := account(c)
+		if key == "" {
+			return c.Next()
+		}
+
+		wait, err := lockout.Attempt(c.Context(), key)
+		if err != nil {
+			log.Printf("login lockout: %v", err)
+		} else if wait > 0 {
+			c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(wait))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Too many failed attempts, account temporarily locked"})
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		switch status := c.Response().StatusCode(); {
+		case status == fiber.StatusUnauthorized:
+			if wait, err := lockout.Check(c.Context(), key); err != nil {
+				log.Printf("login lockout: %v", err)
+			} else if wait > 0 {
+				c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(wait))
+			}
+		case status < fiber.StatusMultipleChoices:
+			if err := lockout.Reset(c.Context(), key); err != nil {
+				log.Printf("login lockout: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// ClientIPKey: IP klien (atur ProxyHeader & TrustedProxies fiber jika di belakang reverse proxy)
+func ClientIPKey(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// AccountKey: API key, lalu user; request tanpa identitas jatuh ke IP
+func AccountKey(c *fiber.Ctx) string {
+	if keyID, ok := c.Locals(CtxAPIKeyID).(uuid.UUID); ok {
+		return "key:" + keyID.String()
+	}
+	if userID, ok := c.Locals(CtxUserID).(uuid.UUID); ok && userID != uuid.Nil {
+		return "user:" + userID.String()
+	}
+	return ClientIPKey(c)
+}
+
+// LoginAccountKey: Email dari body login (di-hash supaya alamat tidak tersimpan di store)
+func LoginAccountKey(c *fiber.Ctx) string {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(c.Body(), &body) != nil || body.Email == "" {
+		return ""
+	}
+	return "login:" + utils.HashToken(strings.ToLower(strings.TrimSpace(body.Email)))
+}
+
+// TwoFactorAccountKey: User pemilik challenge; tebakan kode dihitung per user, bukan per challenge
+func TwoFactorAccountKey(c *fiber.Ctx) string {
+	var body struct {
+		ChallengeToken string `json:"challenge_token"`
+	}
+	if json.Unmarshal(c.Body(), &body) != nil || body.ChallengeToken == "" {
+		return ""
+	}
+	userID, _, err := utils.ParseTwoFactorChallenge(body.ChallengeToken)
+	if err != nil {
+		return ""
+	}
+	return "2fa:" + userID.String()
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
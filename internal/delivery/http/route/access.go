@@ -0,0 +1,95 @@
+package route
+
+import (
+	"fmt"
+	"inspacemap/backend/internal/delivery/http/middleware"
+	"inspacemap/backend/internal/entity"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Akses route yang tidak membutuhkan permission RBAC
+const (
+	AccessPublic entity.PermissionKey = "public" // Tanpa login (atau login opsional)
+	AccessMember entity.PermissionKey = "member" // Cukup login sebagai anggota organisasi aktif
+	AccessSelf   entity.PermissionKey = "self"   // Login; hanya menyentuh data akun pemanggil sendiri (sesi, profil)
+)
+
+// routeTable: Semua route didaftarkan lewat add() sehingga akses tiap route tercatat.
+// Test memastikan setiap route di app punya entri di sini.
+type routeTable struct {
+	access map[string]entity.PermissionKey // "METHOD /path" -> akses
+}
+
+// add: Permission dari registry otomatis dipasangi middleware.RequirePermission,
+// AccessMember dipasangi middleware.RequireMember, AccessSelf dipasangi middleware.RequireUser.
+// Permission yang tidak ada di registry membuat aplikasi panic saat startup.
+func (t *routeTable) add(r fiber.Router, method, path string, access entity.PermissionKey, handlers ...fiber.Handler) {
+	switch {
+	case access == AccessPublic:
+	case access == AccessSelf:
+		handlers = append([]fiber.Handler{middleware.RequireUser()}, handlers...)
+	case access == AccessMember:
+		handlers = append([]fiber.Handler{middleware.RequireMember()}, handlers...)
+	case entity.IsRegisteredPermission(access):
+		handlers = append([]fiber.Handler{middleware.RequirePermission(string(access))}, handlers...)
+	default:
+		panic(fmt.Sprintf("route %s %s: unknown permission %q", method, path, access))
+	}
+
+	prefix := ""
+	if grp, ok := r.(*fiber.Group); ok {
+		prefix = grp.Prefix
+	}
+	t.access[routeKey(method, joinRoutePath(prefix, path))] = access
+
+	if method == fiber.MethodGet {
+		r.Add(fiber.MethodHead, path, handlers...) // Sama seperti router.Get bawaan fiber
+	}
+	r.Add(method, path, handlers...)
+}
+
+func (t *routeTable) get(r fiber.Router, path string, access entity.PermissionKey, handlers ...fiber.Handler) {
+	t.add(r, fiber.MethodGet, path, access, handlers...)
+}
+
+func (t *routeTable) post(r fiber.Router, path string, access entity.PermissionKey, handlers ...fiber.Handler) {
+	t.add(r, fiber.MethodPost, path, access, handlers...)
+}
+
+func (t *routeTable) put(r fiber.Router, path string, access entity.PermissionKey, handlers ...fiber.Handler) {
+	t.add(r, fiber.MethodPut, path, access, handlers...)
+}
+
+func (t *routeTable) patch(r fiber.Router, path string, access entity.PermissionKey, handlers ...fiber.Handler) {
+	t.add(r, fiber.MethodPatch, path, access, handlers...)
+}
+
+func (t *routeTable) delete(r fiber.Router, path string, access entity.PermissionKey, handlers ...fiber.Handler) {
+	t.add(r, fiber.MethodDelete, path, access, handlers...)
+}
+
+// RouteAccess: Salinan akses tiap route setelah Setup, key "METHOD /path" sesuai fiber.Route
+func (c *RouteConfig) RouteAccess() map[string]entity.PermissionKey {
+	access := make(map[string]entity.PermissionKey, len(c.routes.access))
+	for k, v := range c.routes.access {
+		access[k] = v
+	}
+	return access
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// joinRoutePath: Sama dengan cara fiber menggabungkan prefix group dan path route
+func joinRoutePath(prefix, path string) string {
+	if path == "" {
+		return prefix
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return strings.TrimRight(prefix, "/") + path
+}
@@ -3,86 +3,194 @@ package route
 import (
 	"inspacemap/backend/internal/delivery/http/handler"
 	"inspacemap/backend/internal/delivery/http/middleware"
+	"inspacemap/backend/internal/entity"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 )
 
 type RouteConfig struct {
 	App                 *fiber.App
 	TeamRoleHandler     *handler.TeamRoleHandler
 	AuthHandler         *handler.AuthHandler
+	AccountHandler      *handler.AccountHandler
 	VenueHandler        *handler.VenueHandler
 	VenueGalleryHandler *handler.VenueGalleryHandler
+	VenueBundleHandler  *handler.VenueBundleHandler
 	AreaHandler         *handler.AreaHandler
 	AreaGalleryHandler  *handler.AreaGalleryHandler
 	GraphHandler        *handler.GraphHandler
 	MediaHandler        *handler.MediaHandler
 	AuditHandler        *handler.AuditHandler
+	ApiKeyHandler       *handler.ApiKeyHandler
+	SSOHandler          *handler.SSOHandler
+	TwoFactorHandler    *handler.TwoFactorHandler
+
+	// Permission live per request; nil = permission dari token (test tanpa database)
+	PermissionResolver middleware.PermissionResolver
+	// Validasi header X-API-Key; nil = API key tidak diterima
+	APIKeyAuthenticator middleware.APIKeyAuthenticator
+	// Kuota auth / manifest / API + lockout login; nil = tanpa rate limit
+	RateLimits *middleware.RateLimitConfig
+	// Log audit setiap mutasi terautentikasi; nil = tidak dicatat
+	AuditLogger middleware.AuditLogger
+	// Batas body (byte) semua route & khusus import bundle; 0 = tidak dicek (fiber.Config.BodyLimit tetap berlaku)
+	BodyLimit       int
+	BundleBodyLimit int
+
+	routes routeTable
 }
 
+// bundleImportPath: Satu-satunya route dengan batas body lebih besar dari BodyLimit
+const bundleImportPath = "/api/v1/venues/import"
+
 func (c *RouteConfig) Setup() {
-	api := c.App.Group("/api/v1")
+	c.routes = routeTable{access: make(map[string]entity.PermissionKey)}
+	rt := &c.routes
 
-	auth := api.Group("/auth")
-	auth.Post("/login", c.AuthHandler.Login)
-	auth.Post("/register", c.AuthHandler.Register)
-	auth.Post("/invite/accept", c.AuthHandler.AcceptInvite)
+	c.App.Use(middleware.BodyLimit(c.BodyLimit, bundleImportPath))
+
+	rt.get(c.App, "/.well-known/jwks.json", AccessPublic, c.AuthHandler.JWKS)
 
-	api.Get("/venues/:slug/manifest", c.VenueHandler.GetManifest)
-	api.Get("/areas/:id", c.AreaHandler.GetDetail)
+	api := c.App.Group("/api/v1")
 
-	protected := api.Group("/", middleware.Protected())
-	protected.Get("/roles", c.TeamRoleHandler.ListSystemRoles)
-	protected.Get("/permissions", c.TeamRoleHandler.ListPermissions)
+	limits := c.RateLimits
+	auth := api.Group("/auth")
+	rt.post(auth, "/login", AccessPublic, limits.AuthLimit(), limits.LoginLockout(middleware.LoginAccountKey), c.AuthHandler.Login)
+	rt.post(auth, "/register", AccessPublic, limits.AuthLimit(), c.AuthHandler.Register)
+	rt.post(auth, "/invite/accept", AccessPublic, limits.AuthLimit(), c.AuthHandler.AcceptInvite)
+	rt.post(auth, "/refresh", AccessPublic, limits.RefreshLimit(), c.AuthHandler.Refresh)
+	rt.post(auth, "/logout", AccessPublic, c.AuthHandler.Logout) // Access token boleh sudah kedaluwarsa
+	rt.post(auth, "/password/forgot", AccessPublic, limits.AuthLimit(), c.AccountHandler.ForgotPassword)
+	rt.post(auth, "/password/reset", AccessPublic, limits.AuthLimit(), c.AccountHandler.ResetPassword)
+	rt.post(auth, "/email/verify", AccessPublic, limits.AuthLimit(), c.AccountHandler.VerifyEmail)
+	rt.post(auth, "/sso/start", AccessPublic, limits.AuthLimit(), c.SSOHandler.Start)
+	rt.post(auth, "/sso/callback", AccessPublic, limits.AuthLimit(), c.SSOHandler.Callback)
+	rt.post(auth, "/2fa/verify", AccessPublic, limits.AuthLimit(), limits.LoginLockout(middleware.TwoFactorAccountKey), c.TwoFactorHandler.VerifyLogin)
+
+	// Manifest mobile: gzip/brotli sesuai Accept-Encoding (payload besar, sering lewat jaringan seluler).
+	// Slug venue hanya unik per organisasi, jadi alamat publik selalu menyertakan slug organisasi.
+	manifest := api.Group("/orgs/:org_slug/venues/:slug/manifest", limits.ManifestLimit(), compress.New(compress.Config{Level: compress.LevelBestSpeed}), middleware.OptionalAuth(c.PermissionResolver, c.APIKeyAuthenticator))
+	rt.get(manifest, "/", AccessPublic, c.VenueHandler.GetManifest)
+	rt.get(manifest, "/index", AccessPublic, c.VenueHandler.GetManifestIndex)
+	rt.get(manifest, "/floors/:floor_id", AccessPublic, c.VenueHandler.GetManifestFloor)
+	rt.get(manifest, "/delta", AccessPublic, c.VenueHandler.GetManifestDelta)
+	rt.get(api, "/orgs/:org_slug/venues/:slug/package", AccessPublic, limits.ManifestLimit(), middleware.OptionalAuth(c.PermissionResolver, c.APIKeyAuthenticator), c.VenueHandler.GetPackage)
+
+	// Deprecated: alamat lama tanpa slug organisasi untuk app mobile yang sudah terpasang.
+	// Dilayani selama slug venue unik di seluruh organisasi, 409 jika ambigu.
+	legacyManifest := api.Group("/venues/:slug/manifest", limits.ManifestLimit(), compress.New(compress.Config{Level: compress.LevelBestSpeed}), middleware.OptionalAuth(c.PermissionResolver, c.APIKeyAuthenticator), c.VenueHandler.LegacyManifestSlug)
+	rt.get(legacyManifest, "/", AccessPublic, c.VenueHandler.GetManifest)
+	rt.get(legacyManifest, "/index", AccessPublic, c.VenueHandler.GetManifestIndex)
+	rt.get(legacyManifest, "/floors/:floor_id", AccessPublic, c.VenueHandler.GetManifestFloor)
+	rt.get(legacyManifest, "/delta", AccessPublic, c.VenueHandler.GetManifestDelta)
+	rt.get(api, "/venues/:slug/package", AccessPublic, limits.ManifestLimit(), middleware.OptionalAuth(c.PermissionResolver, c.APIKeyAuthenticator), c.VenueHandler.LegacyManifestSlug, c.VenueHandler.GetPackage)
+	rt.get(api, "/areas/:id", AccessPublic, limits.ManifestLimit(), middleware.OptionalAuth(c.PermissionResolver, c.APIKeyAuthenticator), c.AreaHandler.GetDetail)
+
+	protected := api.Group("/", middleware.Protected(c.PermissionResolver, c.APIKeyAuthenticator), limits.APILimit(), middleware.Audit(c.AuditLogger))
+	rt.get(protected, "/roles", AccessMember, c.TeamRoleHandler.ListRoles)
+	rt.get(protected, "/permissions", AccessMember, c.TeamRoleHandler.ListPermissions)
+	rt.get(protected, "/me", AccessSelf, c.AuthHandler.Me)
+	rt.post(protected, "/auth/switch-org", AccessSelf, c.AuthHandler.SwitchOrg)
+	rt.post(protected, "/auth/email/verify/resend", AccessSelf, c.AccountHandler.ResendVerification)
+	rt.get(protected, "/sessions", AccessSelf, c.AuthHandler.ListSessions)
+	rt.delete(protected, "/sessions", AccessSelf, c.AuthHandler.RevokeAllSessions)
+	rt.delete(protected, "/sessions/:id", AccessSelf, c.AuthHandler.RevokeSession)
+	rt.get(protected, "/me/2fa", AccessSelf, c.TwoFactorHandler.GetStatus)
+	rt.post(protected, "/me/2fa/setup", AccessSelf, c.TwoFactorHandler.Setup)
+	rt.post(protected, "/me/2fa/confirm", AccessSelf, c.TwoFactorHandler.Confirm)
+	rt.post(protected, "/me/2fa/disable", AccessSelf, c.TwoFactorHandler.Disable)
+	rt.post(protected, "/me/2fa/recovery-codes", AccessSelf, c.TwoFactorHandler.RegenerateRecoveryCodes)
+	// Penautan identitas SSO ke akun yang sudah ada: sesi login user adalah bukti kepemilikan akun
+	ssoLink := protected.Group("/me/sso", middleware.RequireUser())
+	rt.post(ssoLink, "/link/start", AccessSelf, c.SSOHandler.StartLink)
+	rt.post(ssoLink, "/link", AccessSelf, c.SSOHandler.CompleteLink)
 
 	tenant := protected.Group("/", middleware.TenantGuard())
 
 	media := tenant.Group("/media")
-	media.Post("/upload-init", c.MediaHandler.InitUpload)
-	media.Post("/confirm", c.MediaHandler.ConfirmUpload)
-	media.Get("/", c.MediaHandler.ListAssets)
-	media.Get("/:id", c.MediaHandler.GetAsset)
-	media.Delete("/:id", c.MediaHandler.DeleteAsset)
+	rt.post(media, "/upload-init", entity.PermMediaUpload, c.MediaHandler.InitUpload)
+	rt.post(media, "/confirm", entity.PermMediaUpload, c.MediaHandler.ConfirmUpload)
+	rt.get(media, "/", AccessMember, c.MediaHandler.ListAssets)
+	rt.get(media, "/:id", AccessMember, c.MediaHandler.GetAsset)
+	rt.delete(media, "/:id", entity.PermMediaDelete, c.MediaHandler.DeleteAsset)
 
 	venues := tenant.Group("/venues")
-	venues.Post("/", c.VenueHandler.CreateVenue)
+	rt.get(venues, "/", AccessMember, c.VenueHandler.ListVenues)
+	rt.post(venues, "/", entity.PermVenueCreate, c.VenueHandler.CreateVenue)
+	rt.post(venues, "/import", entity.PermVenueCreate, middleware.BodyLimit(c.BundleBodyLimit), c.VenueBundleHandler.ImportBundle)
+	rt.get(venues, "/:id", AccessMember, c.VenueHandler.GetDetail)
+	rt.put(venues, "/:id", entity.PermVenueUpdate, c.VenueHandler.UpdateVenue)
+	rt.delete(venues, "/:id", entity.PermVenueDelete, c.VenueHandler.DeleteVenue)
+	rt.post(venues, "/:id/share-links", entity.PermVenueUpdate, c.VenueHandler.CreateShareLink)
+	rt.post(venues, "/:id/clone", entity.PermVenueCreate, c.VenueBundleHandler.CloneVenue)
+	rt.get(venues, "/:id/bundle", entity.PermVenueUpdate, c.VenueBundleHandler.ExportBundle) // Berisi seluruh data venue
+	rt.get(venues, "/:venue_id/areas", AccessMember, c.AreaHandler.GetVenueAreas)
 
 	areas := tenant.Group("/areas")
-	areas.Post("/", c.AreaHandler.CreateArea)
-
-	tenant.Get("/venues/:venue_id/areas", c.AreaHandler.GetVenueAreas)
+	rt.post(areas, "/", entity.PermVenueUpdate, c.AreaHandler.CreateArea)
 
 	vGallery := tenant.Group("/gallery/venue")
-	vGallery.Post("/", c.VenueGalleryHandler.AddItems)
-	vGallery.Put("/reorder", c.VenueGalleryHandler.Reorder)
-	vGallery.Patch("/item", c.VenueGalleryHandler.UpdateItem)
-	vGallery.Delete("/:venue_id/:media_id", c.VenueGalleryHandler.RemoveItem)
+	rt.post(vGallery, "/", entity.PermVenueUpdate, c.VenueGalleryHandler.AddItems)
+	rt.put(vGallery, "/reorder", entity.PermVenueUpdate, c.VenueGalleryHandler.Reorder)
+	rt.patch(vGallery, "/item", entity.PermVenueUpdate, c.VenueGalleryHandler.UpdateItem)
+	rt.delete(vGallery, "/:venue_id/:media_id", entity.PermVenueUpdate, c.VenueGalleryHandler.RemoveItem)
 
 	aGallery := tenant.Group("/gallery/area")
-	aGallery.Post("/", c.AreaGalleryHandler.AddItems)
-	aGallery.Put("/reorder", c.AreaGalleryHandler.Reorder)
-	aGallery.Patch("/item", c.AreaGalleryHandler.UpdateItem)
-	aGallery.Delete("/:area_id/:media_id", c.AreaGalleryHandler.RemoveItem)
+	rt.post(aGallery, "/", entity.PermVenueUpdate, c.AreaGalleryHandler.AddItems)
+	rt.put(aGallery, "/reorder", entity.PermVenueUpdate, c.AreaGalleryHandler.Reorder)
+	rt.patch(aGallery, "/item", entity.PermVenueUpdate, c.AreaGalleryHandler.UpdateItem)
+	rt.delete(aGallery, "/:area_id/:media_id", entity.PermVenueUpdate, c.AreaGalleryHandler.RemoveItem)
 
 	orgs := tenant.Group("/orgs/:org_id")
-	orgs.Get("/members", c.TeamRoleHandler.ListMembers)
-	orgs.Post("/invite", c.TeamRoleHandler.InviteMember)
-	orgs.Patch("/members", c.TeamRoleHandler.UpdateMemberRole)
-	orgs.Delete("/members/:user_id", c.TeamRoleHandler.RemoveMember)
-
-	tenant.Get("/audit-logs", c.AuditHandler.GetLogs)
+	rt.get(orgs, "/members", AccessMember, c.TeamRoleHandler.ListMembers)
+	// Undangan mencatat user pengundang: API key (tanpa user) tidak boleh mengundang
+	rt.post(orgs, "/invite", entity.PermTeamInvite, middleware.RequireUser(), c.TeamRoleHandler.InviteMember)
+	rt.get(orgs, "/invitations", entity.PermTeamInvite, c.TeamRoleHandler.ListInvitations)
+	rt.post(orgs, "/invitations/:id/resend", entity.PermTeamInvite, middleware.RequireUser(), c.TeamRoleHandler.ResendInvitation)
+	rt.delete(orgs, "/invitations/:id", entity.PermTeamInvite, c.TeamRoleHandler.RevokeInvitation)
+	rt.patch(orgs, "/members", entity.PermTeamManage, c.TeamRoleHandler.UpdateMemberRole)
+	rt.delete(orgs, "/members/:user_id", entity.PermTeamManage, c.TeamRoleHandler.RemoveMember)
+
+	roles := tenant.Group("/roles")
+	rt.post(roles, "/", entity.PermTeamManage, c.TeamRoleHandler.CreateRole)
+	rt.put(roles, "/:id", entity.PermTeamManage, c.TeamRoleHandler.UpdateRole)
+	rt.delete(roles, "/:id", entity.PermTeamManage, c.TeamRoleHandler.DeleteRole)
+
+	rt.get(tenant, "/audit-logs", entity.PermOrgSettings, c.AuditHandler.GetLogs)
+
+	// Kelola API key hanya dari sesi user: key tidak bisa membuat / mencabut key lain
+	apiKeys := tenant.Group("/api-keys", middleware.RequireUser())
+	rt.get(apiKeys, "/", entity.PermOrgSettings, c.ApiKeyHandler.ListKeys)
+	rt.post(apiKeys, "/", entity.PermOrgSettings, c.ApiKeyHandler.CreateKey)
+	rt.delete(apiKeys, "/:id", entity.PermOrgSettings, c.ApiKeyHandler.RevokeKey)
+
+	// Pengaturan SSO menentukan siapa yang bisa masuk ke organisasi: hanya dari sesi user
+	sso := tenant.Group("/sso-settings", middleware.RequireUser())
+	rt.get(sso, "/", entity.PermOrgSettings, c.SSOHandler.GetSettings)
+	rt.put(sso, "/", entity.PermOrgSettings, c.SSOHandler.UpdateSettings)
+	rt.post(sso, "/domains/:domain/verify", entity.PermOrgSettings, c.SSOHandler.VerifyDomain)
+
+	twoFactorPolicy := tenant.Group("/two-factor-policy", middleware.RequireUser())
+	rt.get(twoFactorPolicy, "/", entity.PermOrgSettings, c.TwoFactorHandler.GetPolicy)
+	rt.put(twoFactorPolicy, "/", entity.PermOrgSettings, c.TwoFactorHandler.UpdatePolicy)
 
 	editor := tenant.Group("/editor")
 
-	editor.Get("/:venue_id", c.GraphHandler.GetEditorData)
+	rt.get(editor, "/:venue_id", AccessMember, c.GraphHandler.GetEditorData)
 
-	editor.Post("/floors", c.GraphHandler.CreateFloor)
+	rt.post(editor, "/floors", entity.PermGraphEdit, c.GraphHandler.CreateFloor)
+	rt.put(editor, "/floors/:id/georeference", entity.PermGraphEdit, c.GraphHandler.UpdateFloorGeoReference)
 
-	editor.Post("/nodes", c.GraphHandler.CreateNode)
-	editor.Put("/nodes/:id/position", c.GraphHandler.UpdateNodePosition)
-	editor.Put("/nodes/:id/calibration", c.GraphHandler.CalibrateNode)
+	rt.post(editor, "/nodes", entity.PermGraphEdit, c.GraphHandler.CreateNode)
+	rt.put(editor, "/nodes/:id/position", entity.PermGraphEdit, c.GraphHandler.UpdateNodePosition)
+	rt.put(editor, "/nodes/:id/calibration", entity.PermGraphEdit, c.GraphHandler.CalibrateNode)
 
-	editor.Post("/connections", c.GraphHandler.ConnectNodes)
+	rt.post(editor, "/connections", entity.PermGraphEdit, c.GraphHandler.ConnectNodes)
 
-	editor.Post("/:venue_id/publish", c.GraphHandler.Publish)
+	rt.post(editor, "/:venue_id/publish", entity.PermGraphPublish, c.GraphHandler.Publish)
+	rt.post(editor, "/:venue_id/rollback", entity.PermGraphPublish, c.GraphHandler.Rollback)
+	rt.get(editor, "/:venue_id/geojson", AccessMember, c.GraphHandler.ExportGeoJSON)
+	rt.get(editor, "/:venue_id/imdf", AccessMember, c.GraphHandler.ExportIMDF)
+	rt.post(editor, "/:venue_id/import", entity.PermGraphEdit, c.GraphHandler.Import)
 }
@@ -18,6 +18,15 @@ type Floor struct {
 	PixelsPerMeter float64 `gorm:"default:1.0"` 
 	GeoReference JSONMap `gorm:"type:jsonb"` 
 	IsActive bool `gorm:"default:true"` 
+	LineageID *uuid.UUID `gorm:"type:uuid;index"` // ID floor draft asal (tetap sama lintas publish)
 	Nodes []GraphNode `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Areas []Area      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
+
+// StableID: ID yang konsisten lintas revisi (floor draft = ID sendiri, floor live = ID draft asalnya)
+func (f *Floor) StableID() uuid.UUID {
+	if f.LineageID != nil {
+		return *f.LineageID
+	}
+	return f.ID
+}
@@ -43,9 +43,18 @@ type GraphNode struct {
 	Label          string
 	Properties     JSONMap `gorm:"type:jsonb"`
 	IsActive       bool    `gorm:"default:true"`
+	LineageID      *uuid.UUID `gorm:"type:uuid;index"` // ID node draft asal (tetap sama lintas publish)
 	OutgoingEdges  []GraphEdge `gorm:"foreignKey:FromNodeID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
+// StableID: ID yang konsisten lintas revisi (node draft = ID sendiri, node live = ID draft asalnya)
+func (n *GraphNode) StableID() uuid.UUID {
+	if n.LineageID != nil {
+		return *n.LineageID
+	}
+	return n.ID
+}
+
 type GraphEdge struct {
 	BaseEntity
 	FromNodeID uuid.UUID `gorm:"index;not null"`
@@ -32,9 +32,23 @@ type OrganizationMember struct {
 	Organization Organization `gorm:"foreignKey:OrganizationID"`
 }
 
+// ApiKey: Kredensial server-to-server milik organisasi. Key asli hanya ditampilkan sekali saat dibuat;
+// yang disimpan hanya hash SHA-256 + prefix untuk dikenali di UI.
 type ApiKey struct {
 	BaseEntity
-	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
-	Name           string
-	IsActive       bool      `gorm:"default:true"`
+	OrganizationID  uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Organization    *Organization `gorm:"foreignKey:OrganizationID"`
+	Name            string
+	Prefix          string     `gorm:"type:varchar(20)"`
+	KeyHash         string     `gorm:"type:varchar(64);uniqueIndex"`
+	Scopes          []string   `gorm:"type:jsonb;serializer:json"` // Subset PermissionRegistry
+	CreatedByUserID uuid.UUID  `gorm:"type:uuid"`
+	ExpiresAt       *time.Time // nil = tidak kedaluwarsa
+	LastUsedAt      *time.Time
+	IsActive        bool       `gorm:"default:true"`
+}
+
+// IsUsable: Belum dicabut dan belum kedaluwarsa
+func (k *ApiKey) IsUsable(now time.Time) bool {
+	return k.IsActive && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
 }
\ No newline at end of file
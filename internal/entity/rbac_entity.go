@@ -14,11 +14,41 @@ const (
 	PermGraphPublish PermissionKey = "graph:publish" // Publish Draft
 	PermOrgSettings  PermissionKey = "org:settings"  // Edit SSO, Logo
 	PermOrgBilling   PermissionKey = "org:billing"
-	PermUserInvite   PermissionKey = "user:invite"
+	PermTeamInvite   PermissionKey = "team:invite"
+	PermTeamManage   PermissionKey = "team:manage" // Ubah role & hapus member
 	PermMediaUpload  PermissionKey = "media:upload"
 	PermMediaDelete  PermissionKey = "media:delete"
 )
 
+// PermissionRegistry: Satu-satunya daftar permission yang dikenal aplikasi.
+// Seeder, pengecekan database saat startup, dan route memakai daftar ini.
+var PermissionRegistry = []Permission{
+	{Key: string(PermVenueCreate), Description: "Create new venues", Group: "CMS"},
+	{Key: string(PermVenueUpdate), Description: "Update venue details", Group: "CMS"},
+	{Key: string(PermVenueDelete), Description: "Delete venues", Group: "CMS"},
+
+	{Key: string(PermGraphEdit), Description: "Edit nodes and edges", Group: "Graph"},
+	{Key: string(PermGraphPublish), Description: "Publish draft to live", Group: "Graph"},
+
+	{Key: string(PermOrgSettings), Description: "Manage organization profile", Group: "Org"},
+	{Key: string(PermOrgBilling), Description: "Manage billing and subscription", Group: "Org"},
+	{Key: string(PermTeamInvite), Description: "Invite new members", Group: "Team"},
+	{Key: string(PermTeamManage), Description: "Change member roles", Group: "Team"},
+
+	{Key: string(PermMediaUpload), Description: "Upload new assets", Group: "Media"},
+	{Key: string(PermMediaDelete), Description: "Delete assets", Group: "Media"},
+}
+
+// IsRegisteredPermission: true jika key ada di PermissionRegistry
+func IsRegisteredPermission(key PermissionKey) bool {
+	for _, p := range PermissionRegistry {
+		if p.Key == string(key) {
+			return true
+		}
+	}
+	return false
+}
+
 type Permission struct {
 	BaseEntity
 	Key         string `gorm:"type:varchar(50);uniqueIndex;not null"` // e.g. "venue:create"
@@ -28,9 +58,23 @@ type Permission struct {
 
 type Role struct {
 	BaseEntity
-	Name        string       `gorm:"type:varchar(50);not null"`
-	Description string       `gorm:"type:varchar(255)"`
-	Permissions []Permission `gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OrganizationID *uuid.UUID   `gorm:"type:uuid;index"` // nil = role bawaan sistem (Owner/Editor/Viewer), dipakai semua organisasi
+	Name           string       `gorm:"type:varchar(50);not null"`
+	Description    string       `gorm:"type:varchar(255)"`
+	Permissions    []Permission `gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// SystemRoleOwner: Nama role bawaan Owner (role custom organisasi boleh memakai nama yang sama)
+const SystemRoleOwner = "Owner"
+
+// IsSystem: Role bawaan tidak bisa diubah/dihapus lewat API organisasi
+func (r *Role) IsSystem() bool {
+	return r.OrganizationID == nil
+}
+
+// IsOwner: Hanya role Owner bawaan sistem, bukan role custom yang kebetulan bernama "Owner"
+func (r *Role) IsOwner() bool {
+	return r.IsSystem() && r.Name == SystemRoleOwner
 }
 
 type RolePermission struct {
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken: Satu baris per refresh token yang pernah diterbitkan.
+// Token hasil rotasi tetap disimpan (RotatedAt terisi) supaya pemakaian ulang bisa dideteksi.
+// Semua token dari satu login berbagi FamilyID, yang sekaligus menjadi ID sesi.
+type RefreshToken struct {
+	BaseEntity
+	UserID         uuid.UUID `gorm:"type:uuid;index;not null"`
+	User           *User     `gorm:"foreignKey:UserID"`
+	OrganizationID uuid.UUID `gorm:"type:uuid"` // Organisasi aktif saat token diterbitkan
+	FamilyID       uuid.UUID `gorm:"type:uuid;index;not null"`
+	TokenHash      string    `gorm:"type:varchar(64);uniqueIndex;not null"` // SHA-256, token asli tidak pernah disimpan
+
+	UserAgent string `gorm:"type:varchar(255)"`
+	IPAddress string `gorm:"type:varchar(50)"`
+
+	SessionStartedAt time.Time
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time `gorm:"index"`
+	RotatedAt        *time.Time
+	RevokedAt        *time.Time
+}
+
+// IsActive: Belum di-rotate, belum dicabut dan belum kedaluwarsa
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
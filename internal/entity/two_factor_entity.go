@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTwoFactor: Secret TOTP user. EnabledAt kosong = pendaftaran belum dikonfirmasi dengan kode pertama.
+type UserTwoFactor struct {
+	BaseEntity
+	UserID       uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	Secret       string    `gorm:"type:varchar(64);not null"` // Base32, tidak pernah dikembalikan setelah pendaftaran
+	EnabledAt    *time.Time
+	LastUsedStep int64 // Periode TOTP terakhir yang dipakai; kode dari periode yang sama / lebih lama ditolak
+}
+
+// UserRecoveryCode: Kode cadangan sekali pakai saat authenticator hilang. Hanya hash yang disimpan.
+type UserRecoveryCode struct {
+	BaseEntity
+	UserID   uuid.UUID `gorm:"type:uuid;index:idx_recovery_code;not null"`
+	CodeHash string    `gorm:"type:varchar(64);index:idx_recovery_code;not null"`
+	UsedAt   *time.Time
+}
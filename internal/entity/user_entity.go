@@ -22,9 +22,19 @@ type User struct {
 	FullName     string `gorm:"type:varchar(100)"`
 	AvatarURL    string `gorm:"type:text"`
 	IsEmailVerified bool   `gorm:"default:false"`
+	TwoFactorEnabled bool  `gorm:"default:false"` // Salinan status UserTwoFactor untuk cek login / permission tanpa join
 	Memberships []OrganizationMember `gorm:"foreignKey:UserID"`
 }
 
+// Status UserInvitation
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// UserInvitation: Token disimpan sebagai hash SHA-256; token asli hanya ada di email undangan
 type UserInvitation struct {
 	BaseEntity
 	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity: Akun di identity provider OIDC yang ditautkan ke user.
+// Login SSO berikutnya dicocokkan lewat (issuer, subject), bukan email yang bisa berubah di provider.
+type UserIdentity struct {
+	BaseEntity
+	UserID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	Issuer      string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_subject"`
+	Subject     string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_subject"`
+	Email       string    `gorm:"type:varchar(255)"` // Email dari provider saat terakhir login
+	LastLoginAt time.Time
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTokenPurpose string
+
+const (
+	TokenPurposePasswordReset     UserTokenPurpose = "password_reset"
+	TokenPurposeEmailVerification UserTokenPurpose = "email_verification"
+)
+
+// UserToken: Token sekali pakai yang dikirim lewat email (reset password, verifikasi email).
+// Hanya hash yang disimpan; UsedAt terisi saat token dipakai atau digantikan token baru.
+type UserToken struct {
+	BaseEntity
+	UserID    uuid.UUID        `gorm:"type:uuid;index;not null"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(30);index;not null"`
+	TokenHash string           `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// IsUsable: Belum dipakai dan belum kedaluwarsa
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -6,10 +6,10 @@ import (
 
 type Venue struct {
 	BaseEntity
-	OrganizationID   uuid.UUID        `gorm:"index;not null"`
+	OrganizationID   uuid.UUID        `gorm:"index;uniqueIndex:idx_venue_org_slug,where:deleted_at IS NULL;not null"`
 	Organization     Organization     `gorm:"foreignKey:OrganizationID"`
 	Name             string           `gorm:"type:varchar(100);not null"`
-	Slug             string           `gorm:"type:varchar(100);index"`
+	Slug             string           `gorm:"type:varchar(100);index;uniqueIndex:idx_venue_org_slug,where:deleted_at IS NULL"` // Unik per organisasi
 	Description      string           `gorm:"type:text"`
 	Address          string           `gorm:"type:text"`
 	City             string           `gorm:"type:varchar(100)"`
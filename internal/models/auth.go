@@ -20,9 +20,12 @@ type RegisterRequest struct {
 
 type AuthResponse struct {
 	AccessToken  string      `json:"access_token"`
-	RefreshToken string      `json:"refresh_token"`
+	RefreshToken string      `json:"refresh_token,omitempty"` // Kosong saat switch-org: refresh token lama tetap dipakai
 	ExpiresIn    int         `json:"expires_in"`
 	User         UserDetail  `json:"user"`
+	// Login dua langkah: token kosong, kirim challenge_token + kode ke POST /auth/2fa/verify
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 type InviteUserRequest struct {
@@ -37,11 +40,130 @@ type AcceptInviteRequest struct {
 }
 
 type CreateApiKeyRequest struct {
-	Name string `json:"name" validate:"required,min=3"`
+	Name      string     `json:"name" validate:"required,min=3"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"` // Permission key, harus dimiliki pembuat
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`             // Kosong = tidak kedaluwarsa
 }
 
+type ApiKeyDetail struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Awal key, untuk mengenali key tanpa menyimpan secret
+	Scopes     []string   `json:"scopes"`
+	IsActive   bool       `json:"is_active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ApiKeyResponse: Satu-satunya respons yang memuat key asli
 type ApiKeyResponse struct {
-	Key       uuid.UUID `json:"api_key"` // The secret key
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+	ApiKeyDetail
+	Key string `json:"api_key"`
+}
+
+// ApiKeyPrincipal: Identitas request yang diautentikasi dengan X-API-Key
+type ApiKeyPrincipal struct {
+	KeyID          uuid.UUID
+	OrganizationID uuid.UUID
+	Scopes         []string
+}
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// ClientInfo: Perangkat & IP pemanggil, dicatat pada sesi
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionDetail struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	StartedAt  time.Time `json:"started_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Sesi yang dipakai request ini
+}
+
+type SwitchOrgRequest struct {
+	OrganizationID uuid.UUID `json:"organization_id" validate:"required"`
+}
+
+// MeResponse: Profil user + semua membership, dengan role & permission di organisasi aktif token
+type MeResponse struct {
+	User                 UserDetail `json:"user"`
+	ActiveOrganizationID uuid.UUID  `json:"active_organization_id"`
+	ActiveRoleName       string     `json:"active_role_name"`
+	Permissions          []string   `json:"permissions"`
+	// Organisasi aktif mewajibkan 2FA untuk role user: graph:publish / org:settings ditahan sampai 2FA aktif
+	TwoFactorEnrollmentRequired bool `json:"two_factor_enrollment_required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// SSOStartRequest: Organisasi (slug) + ID provider di SSOSettings organisasi tersebut
+type SSOStartRequest struct {
+	Organization string `json:"organization" validate:"required"`
+	Provider     string `json:"provider" validate:"required"`
+}
+
+// SSOStartResponse: Frontend menyimpan code_verifier (mis. sessionStorage) lalu redirect ke authorization_url
+type SSOStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	CodeVerifier     string `json:"code_verifier"`
+	ExpiresIn        int    `json:"expires_in"`
+}
+
+// SSOCallbackRequest: Code & state dari redirect identity provider + verifier dari SSOStartResponse
+type SSOCallbackRequest struct {
+	Code         string `json:"code" validate:"required"`
+	State        string `json:"state" validate:"required"`
+	CodeVerifier string `json:"code_verifier" validate:"required"`
+}
+
+// TwoFactorVerifyRequest: Langkah kedua login. Code = kode TOTP 6 digit atau recovery code.
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse: Secret hanya ditampilkan sekali, saat pendaftaran
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"` // Untuk QR code
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorDisableRequest: Password wajib untuk akun yang punya password (akun SSO cukup kode)
+type TwoFactorDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse: Kode asli hanya muncul di respons ini; server menyimpan hash
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -3,12 +3,13 @@ package models
 import "github.com/google/uuid"
 
 type CreateFloorRequest struct {
-	Name           string     `json:"name" validate:"required"`
-	LevelIndex     int        `json:"level_index" validate:"required"`
-	MapImageID     *uuid.UUID `json:"map_image_id" validate:"required"`
-	PixelsPerMeter float64    `json:"pixels_per_meter" validate:"gt=0"`
-	MapWidth       int        `json:"map_width" validate:"gt=0"`
-	MapHeight      int        `json:"map_height" validate:"gt=0"`
+	Name           string        `json:"name" validate:"required"`
+	LevelIndex     int           `json:"level_index" validate:"required"`
+	MapImageID     *uuid.UUID    `json:"map_image_id" validate:"required"`
+	PixelsPerMeter float64       `json:"pixels_per_meter" validate:"gt=0"`
+	MapWidth       int           `json:"map_width" validate:"gt=0"`
+	MapHeight      int           `json:"map_height" validate:"gt=0"`
+	GeoReference   *GeoReference `json:"geo_reference,omitempty"`
 }
 
 type UpdateFloorRequest struct {
@@ -19,6 +20,26 @@ type UpdateFloorRequest struct {
 	IsActive       *bool      `json:"is_active"`
 }
 
+// GeoReference: Kalibrasi peta lantai (pixel) ke koordinat WGS84. Pilih salah satu mode:
+//   - Anchor: pixel (anchor_x, anchor_y) berada di (latitude, longitude), arah "atas" gambar
+//     menghadap bearing (derajat searah jarum jam dari utara), skala memakai Floor.PixelsPerMeter.
+//   - Control points: minimal 3 titik pixel <-> lat/lng (tidak segaris), dihitung affine least squares.
+type GeoReference struct {
+	AnchorX       float64           `json:"anchor_x"`
+	AnchorY       float64           `json:"anchor_y"`
+	Latitude      float64           `json:"latitude" validate:"min=-90,max=90"`
+	Longitude     float64           `json:"longitude" validate:"min=-180,max=180"`
+	Bearing       float64           `json:"bearing"`
+	ControlPoints []GeoControlPoint `json:"control_points,omitempty"`
+}
+
+type GeoControlPoint struct {
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Latitude  float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude float64 `json:"longitude" validate:"min=-180,max=180"`
+}
+
 type FloorAdminDetail struct {
 	ID             uuid.UUID       `json:"id"`
 	Name           string          `json:"name"`
@@ -0,0 +1,30 @@
+package models
+
+// GeoJSON (RFC 7946). Koordinat selalu [longitude, latitude] dalam WGS84.
+
+const (
+	GeoJSONFeatureCollectionType = "FeatureCollection"
+	GeoJSONFeatureType           = "Feature"
+	GeoJSONPoint                 = "Point"
+	GeoJSONLineString            = "LineString"
+	GeoJSONPolygon               = "Polygon"
+)
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+	// Foreign member: floor yang dilewati karena belum punya georeference, dll
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"` // Point: [lng, lat], LineString: [[lng, lat], ...], Polygon: [[[lng, lat], ...]]
+}
@@ -10,6 +10,11 @@ type PublishDraftRequest struct {
 	Note string `json:"note" validate:"max=255"`
 }
 
+// RollbackRevisionRequest: Revisi published milik venue yang dijadikan live kembali
+type RollbackRevisionRequest struct {
+	RevisionID uuid.UUID `json:"revision_id" validate:"required"`
+}
+
 type RevisionHistoryItem struct {
 	ID        uuid.UUID `json:"id"` 
 	Status    string    `json:"status"`
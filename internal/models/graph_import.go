@@ -0,0 +1,68 @@
+package models
+
+import "github.com/google/uuid"
+
+// GraphImportDocument: Skema JSON import graph ke draft.
+//   - Referensi lantai: floor_id (UUID floor draft) atau level (Floor.LevelIndex).
+//   - "id" adalah ID eksternal (bebas, unik per jenis). Edge & node.area_id merujuk ID eksternal,
+//     atau UUID node/area yang sudah ada di venue.
+//   - Koordinat x/y dalam pixel peta lantai.
+//
+// CSV: nodes.csv & edges.csv (opsional areas.csv) dengan header sesuai nama field JSON.
+// GeoJSON: format yang sama dengan export (properties.feature_type node/edge/area), koordinat
+// WGS84 dikonversi ke pixel memakai georeference lantai.
+type GraphImportDocument struct {
+	Areas []ImportArea `json:"areas"`
+	Nodes []ImportNode `json:"nodes"`
+	Edges []ImportEdge `json:"edges"`
+}
+
+type ImportArea struct {
+	ExternalID  string     `json:"id"`
+	FloorID     *uuid.UUID `json:"floor_id,omitempty"`
+	Level       *int       `json:"level,omitempty"`
+	Name        string     `json:"name"`
+	Category    string     `json:"category"`
+	Description string     `json:"description"`
+	X           float64    `json:"x"`
+	Y           float64    `json:"y"`
+}
+
+type ImportNode struct {
+	ExternalID      string     `json:"id"`
+	FloorID         *uuid.UUID `json:"floor_id,omitempty"`
+	Level           *int       `json:"level,omitempty"`
+	X               float64    `json:"x"`
+	Y               float64    `json:"y"`
+	Label           string     `json:"label"`
+	AreaID          string     `json:"area_id"`
+	PanoramaAssetID *uuid.UUID `json:"panorama_asset_id"`
+	RotationOffset  float64    `json:"rotation_offset"`
+}
+
+type ImportEdge struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Type          string `json:"type"` // default "walk"
+	Bidirectional bool   `json:"bidirectional"`
+}
+
+// ImportRowError: Row 1-based. JSON/GeoJSON = index item, CSV = nomor baris file (header = 1).
+type ImportRowError struct {
+	Source  string `json:"source"` // areas, nodes, edges, nodes.csv, features, ...
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type GraphImportResult struct {
+	DryRun     bool                 `json:"dry_run"`
+	Valid      bool                 `json:"valid"`
+	AreaCount  int                  `json:"area_count"`
+	NodeCount  int                  `json:"node_count"`
+	EdgeCount  int                  `json:"edge_count"`
+	Errors     []ImportRowError     `json:"errors"`
+	AreaIDs    map[string]uuid.UUID `json:"area_ids,omitempty"` // ID eksternal -> UUID baru
+	NodeIDs    map[string]uuid.UUID `json:"node_ids,omitempty"`
+	RevisionID uuid.UUID            `json:"revision_id"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "github.com/google/uuid"
+
+// Apple Indoor Mapping Data Format (IMDF) 1.0.0
+
+const IMDFVersion = "1.0.0"
+
+// IMDFExportRequest: Data venue yang belum ada di entity tapi wajib di IMDF
+type IMDFExportRequest struct {
+	Category string `query:"category"` // IMDF venue category, e.g. "shoppingcenter"
+	Country  string `query:"country"`  // ISO 3166-1 alpha-2, e.g. "ID"
+	Language string `query:"language"` // Bahasa label (default "en")
+}
+
+type IMDFManifest struct {
+	Version     string      `json:"version"`
+	Created     string      `json:"created"`
+	GeneratedBy string      `json:"generated_by"`
+	Language    string      `json:"language"`
+	Extensions  interface{} `json:"extensions"`
+}
+
+type IMDFFeatureCollection struct {
+	Type     string        `json:"type"`
+	Name     string        `json:"name"`
+	Features []IMDFFeature `json:"features"`
+}
+
+// IMDFFeature: GeoJSON Feature + feature_type. Geometry nil untuk address/building.
+type IMDFFeature struct {
+	ID          uuid.UUID              `json:"id"`
+	Type        string                 `json:"type"`
+	FeatureType string                 `json:"feature_type"`
+	Geometry    *GeoJSONGeometry       `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+}
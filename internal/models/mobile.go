@@ -9,6 +9,7 @@ import (
 type ManifestResponse struct {
 	VenueID     uuid.UUID   `json:"venue_id"`
 	VenueName   string      `json:"venue_name"`
+	RevisionID  uuid.UUID   `json:"revision_id"`
 	LastUpdated time.Time   `json:"last_updated"`
 	Floors      []FloorData `json:"floors"`
 	StartNodeID uuid.UUID   `json:"start_node_id"`
@@ -43,3 +44,137 @@ type NeighborData struct {
 	Type         string    `json:"type"`      // 'walk', 'stairs' (Untuk icon panah beda)
 	IsActive     bool      `json:"is_active"` // Jika false, jangan gambar panah
 }
+
+// ManifestAccess: Konteks akses saat mobile/web meminta manifest
+type ManifestAccess struct {
+	ShareToken     string    // Token share link (query ?share_token= atau header X-Share-Token)
+	OrganizationID uuid.UUID // Org aktif dari JWT (uuid.Nil jika request anonim)
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresInHours int  `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+	IncludeDraft   bool `json:"include_draft"` // Preview draft yang belum dipublish
+}
+
+type ShareLinkResponse struct {
+	Token        string    `json:"token"`
+	ManifestPath string    `json:"manifest_path"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	IncludeDraft bool      `json:"include_draft"`
+}
+
+// =================================================================
+// MANIFEST PER-FLOOR & DELTA
+// =================================================================
+
+// ManifestIndexResponse: Ringkasan manifest (tanpa node) agar mobile bisa unduh lantai sesuai kebutuhan
+type ManifestIndexResponse struct {
+	VenueID     uuid.UUID      `json:"venue_id"`
+	VenueName   string         `json:"venue_name"`
+	RevisionID  uuid.UUID      `json:"revision_id"`
+	LastUpdated time.Time      `json:"last_updated"`
+	StartNodeID uuid.UUID      `json:"start_node_id"`
+	Floors      []FloorSummary `json:"floors"`
+}
+
+type FloorSummary struct {
+	ID          uuid.UUID `json:"id"`
+	LevelName   string    `json:"name"`
+	LevelIndex  int       `json:"level_index"`
+	MapImageURL string    `json:"map_image_url"`
+	MapWidth    int       `json:"width"`
+	MapHeight   int       `json:"height"`
+	NodeCount   int       `json:"node_count"`
+}
+
+// ManifestFloorResponse: Satu lantai lengkap dengan node-nya (field FloorData di-flatten di JSON)
+type ManifestFloorResponse struct {
+	VenueID     uuid.UUID `json:"venue_id"`
+	RevisionID  uuid.UUID `json:"revision_id"`
+	LastUpdated time.Time `json:"last_updated"`
+	FloorData
+}
+
+// ManifestDeltaResponse: Perubahan dari revisi yang sudah dimiliki client ke revisi terbaru.
+// ID floor & node stabil lintas publish, jadi client cukup upsert/hapus berdasarkan ID.
+type ManifestDeltaResponse struct {
+	VenueID         uuid.UUID       `json:"venue_id"`
+	VenueName       string          `json:"venue_name"`
+	FromRevisionID  uuid.UUID       `json:"from_revision_id"`
+	ToRevisionID    uuid.UUID       `json:"to_revision_id"`
+	LastUpdated     time.Time       `json:"last_updated"`
+	StartNodeID     uuid.UUID       `json:"start_node_id"`
+	UpsertedFloors  []FloorSummary  `json:"upserted_floors"`
+	RemovedFloorIDs []uuid.UUID     `json:"removed_floor_ids"`
+	UpsertedNodes   []FloorNodeData `json:"upserted_nodes"`
+	RemovedNodeIDs  []uuid.UUID     `json:"removed_node_ids"`
+}
+
+// FloorNodeData: NodeData + lantai tempatnya berada (field NodeData di-flatten di JSON)
+type FloorNodeData struct {
+	FloorID uuid.UUID `json:"floor_id"`
+	NodeData
+}
+
+// =================================================================
+// OFFLINE PACKAGE (KIOSK / FIELD APP)
+// =================================================================
+
+// Resolusi panorama di dalam paket offline
+const (
+	PackagePanoramaFull    = "full"    // File asli
+	PackagePanoramaPreview = "preview" // Thumbnail (fallback ke file asli jika tidak ada)
+	PackagePanoramaNone    = "none"    // Tidak ikut dipaketkan, manifest tetap menunjuk URL online
+)
+
+// Status paket offline
+const (
+	PackageStatusBuilding = "building"
+	PackageStatusReady    = "ready"
+	PackageStatusFailed   = "failed"
+)
+
+type VenuePackageStatus struct {
+	Status             string     `json:"status"`
+	VenueID            uuid.UUID  `json:"venue_id"`
+	RevisionID         uuid.UUID  `json:"revision_id"`
+	PanoramaResolution string     `json:"panorama_resolution"`
+	DownloadURL        string     `json:"download_url,omitempty"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	Error              string     `json:"error,omitempty"`
+}
+
+// PackageContents: Isi package.json di dalam zip (daftar file + checksum untuk verifikasi offline)
+type PackageContents struct {
+	FormatVersion      int           `json:"format_version"`
+	VenueID            uuid.UUID     `json:"venue_id"`
+	VenueSlug          string        `json:"venue_slug"`
+	RevisionID         uuid.UUID     `json:"revision_id"`
+	GeneratedAt        time.Time     `json:"generated_at"`
+	PanoramaResolution string        `json:"panorama_resolution"`
+	Files              []PackageFile `json:"files"`
+}
+
+type PackageFile struct {
+	Path        string     `json:"path"`
+	MediaID     *uuid.UUID `json:"media_id,omitempty"`
+	SizeInBytes int64      `json:"size"`
+	SHA256      string     `json:"sha256"`             // Dihitung saat paket dibuat
+	Checksum    string     `json:"checksum,omitempty"` // MediaAsset.Checksum saat upload
+}
+
+// PackageArea: Data area lengkap untuk areas.json (versi online dipecah ke beberapa endpoint)
+type PackageArea struct {
+	ID            uuid.UUID           `json:"id"`
+	FloorID       uuid.UUID           `json:"floor_id"`
+	Name          string              `json:"name"`
+	Slug          string              `json:"slug"`
+	Label         string              `json:"label,omitempty"`
+	Description   string              `json:"description"`
+	Category      string              `json:"category"`
+	Coordinates   GeoPoint            `json:"coordinates"`
+	MapX          float64             `json:"map_x"`
+	MapY          float64             `json:"map_y"`
+	CoverImageURL string              `json:"cover_image_url,omitempty"`
+	Gallery       []AreaGalleryDetail `json:"gallery"`
+}
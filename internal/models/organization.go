@@ -61,3 +61,39 @@ type UpdateOrganizationRequest struct {
 	Website  *string                `json:"website" validate:"omitempty,url"`
 	Settings map[string]interface{} `json:"settings"`
 }
+
+// SSOSettings: Organization.Settings["sso"]
+type SSOSettings struct {
+	Providers       []SSOProvider `json:"providers"`
+	AutoJoinDomains []string      `json:"auto_join_domains,omitempty"` // Email domain yang otomatis jadi anggota saat login SSO
+	DefaultRoleID   *uuid.UUID    `json:"default_role_id,omitempty"`   // Role anggota auto-join; kosong = Viewer
+
+	// Auto-join baru berlaku setelah domain diverifikasi lewat record DNS TXT. Kedua field diisi server
+	// dan diabaikan saat update.
+	VerifiedDomains         []string `json:"verified_domains,omitempty"`
+	DomainVerificationToken string   `json:"domain_verification_token,omitempty"` // Isi record TXT, lihat SSODomainVerification
+}
+
+// SSODomainVerification: Record DNS yang harus dibuat admin sebelum domain auto-join aktif
+type SSODomainVerification struct {
+	Domain   string `json:"domain"`
+	Record   string `json:"record"` // Nama record TXT, mis. _inspacemap-verification.acme.com
+	Value    string `json:"value"`
+	Verified bool   `json:"verified"`
+}
+
+// SSOProvider: Client secret tidak pernah dikembalikan API; kosong saat update = secret lama dipakai
+type SSOProvider struct {
+	ID           string   `json:"id" validate:"required"` // Dipakai di SSOStartRequest.Provider
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer" validate:"required,url"`
+	ClientID     string   `json:"client_id" validate:"required"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// TwoFactorPolicy: Organization.Settings["require_two_factor"]
+type TwoFactorPolicy struct {
+	// Anggota dengan graph:publish / org:settings kehilangan kedua permission itu sampai 2FA aktif
+	RequireTwoFactor bool `json:"require_two_factor"`
+}
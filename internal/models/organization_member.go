@@ -8,5 +8,6 @@ type OrgMemberDetail struct {
 	OrganizationID uuid.UUID `json:"organization_id"`
 	Name           string    `json:"name"`
 	Slug           string    `json:"slug"`
+	RoleID         uuid.UUID `json:"role_id"`
 	RoleName       string    `json:"role_name"` 
 }
\ No newline at end of file
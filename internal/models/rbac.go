@@ -10,6 +10,13 @@ type CreateRoleRequest struct {
 	PermissionIDs []uuid.UUID `json:"permission_ids" validate:"required,min=1"`
 }
 
+// UpdateRoleRequest: Partial update role custom, field nil tidak diubah
+type UpdateRoleRequest struct {
+	Name          *string      `json:"name,omitempty" validate:"omitempty,min=3"`
+	Description   *string      `json:"description,omitempty"`
+	PermissionIDs *[]uuid.UUID `json:"permission_ids,omitempty" validate:"omitempty,min=1"`
+}
+
 type UpdateUserRoleRequest struct {
 	TargetUserID uuid.UUID `json:"target_user_id" validate:"required"`
 	NewRoleID    uuid.UUID `json:"new_role_id" validate:"required"`
@@ -18,6 +25,8 @@ type UpdateUserRoleRequest struct {
 type RoleDetail struct {
 	ID          uuid.UUID `json:"id"`
 	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	IsSystem    bool      `json:"is_system"` // Role bawaan, tidak bisa diubah/dihapus
 	Permissions []string  `json:"permissions"`
 }
 
@@ -31,3 +40,9 @@ type PermissionItem struct {
 	Key         string    `json:"key"`
 	Description string    `json:"description"`
 }
+
+// ResolvedPermissions: Hasil evaluasi akses user di satu organisasi
+type ResolvedPermissions struct {
+	IsMember    bool
+	Permissions []string
+}
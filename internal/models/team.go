@@ -17,3 +17,17 @@ type TeamMemberDetail struct {
 
 	JoinedAt time.Time `json:"joined_at"`
 }
+
+type InvitationDetail struct {
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	RoleID   uuid.UUID `json:"role_id"`
+	RoleName string    `json:"role_name"`
+	Status   string    `json:"status"` // pending, accepted, revoked, expired
+
+	InvitedByName string `json:"invited_by_name,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
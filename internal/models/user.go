@@ -9,6 +9,8 @@ type UserDetail struct {
 	Email          string       `json:"email"`
 	FullName       string       `json:"full_name"`
 	AvatarURL      string       `json:"avatar_url"`
+	IsEmailVerified bool        `json:"is_email_verified"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
 	Organizations  []OrgMemberDetail `json:"organizations"`
 }
 
@@ -74,23 +74,23 @@ type VenueListItem struct {
 }
 
 type VenueFilter struct {
-	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
-	Name           *string    `json:"name,omitempty"`
-	Slug           *string    `json:"slug,omitempty"`
-	Description    *string    `json:"description,omitempty"`
-	Address        *string    `json:"address,omitempty"`
-	City           *string    `json:"city,omitempty"`
-	Province       *string    `json:"province,omitempty"`
-	PostalCode     *string    `json:"postal_code,omitempty"`
-	Visibility     *string    `json:"visibility,omitempty"`
-	IsLive         *bool      `json:"is_live,omitempty"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty" query:"organization_id"`
+	Name           *string    `json:"name,omitempty" query:"name"`
+	Slug           *string    `json:"slug,omitempty" query:"slug"`
+	Description    *string    `json:"description,omitempty" query:"description"`
+	Address        *string    `json:"address,omitempty" query:"address"`
+	City           *string    `json:"city,omitempty" query:"city"`
+	Province       *string    `json:"province,omitempty" query:"province"`
+	PostalCode     *string    `json:"postal_code,omitempty" query:"postal_code"`
+	Visibility     *string    `json:"visibility,omitempty" query:"visibility"`
+	IsLive         *bool      `json:"is_live,omitempty" query:"is_live"`
 }
 
 type VenueQuery struct {
 	VenueFilter
-	Limit  *int    `json:"limit,omitempty"`
-	Offset *int    `json:"offset,omitempty"`
-	Sort   *string `json:"sort,omitempty"`
+	Limit  *int    `json:"limit,omitempty" query:"limit"`
+	Offset *int    `json:"offset,omitempty" query:"offset"`
+	Sort   *string `json:"sort,omitempty" query:"sort"`
 }
 
 type VenueQueryCursor struct {
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Venue bundle: Zip berisi bundle.json + media/<id><ext> untuk
+// memindahkan venue antar organisasi / instance (staging -> production).
+
+const (
+	VenueBundleVersion  = 1
+	VenueBundleFileName = "bundle.json"
+
+	BundleRevisionsLive = "live" // Hanya live revision (draft dibuat ulang dari live saat import)
+	BundleRevisionsAll  = "all"  // Semua revisi: published, archived & draft
+)
+
+type VenueBundleExportRequest struct {
+	Revisions string `query:"revisions"` // live (default) | all
+}
+
+type VenueBundleImportRequest struct {
+	Name string `query:"name"` // Default: nama venue sumber
+	Slug string `query:"slug"` // Default: slug sumber (dibuat unik otomatis)
+}
+
+type VenueBundleImportResult struct {
+	VenueID       uuid.UUID `json:"venue_id"`
+	Slug          string    `json:"slug"`
+	RevisionCount int       `json:"revision_count"`
+	AreaCount     int       `json:"area_count"`
+	MediaCount    int       `json:"media_count"`
+	UploadedMedia int       `json:"uploaded_media"`
+}
+
+// CloneVenueRequest: Duplikasi venue sebagai template di organisasi yang sama
+type CloneVenueRequest struct {
+	Name string `json:"name"` // Default: "<nama sumber> (Copy)"
+	Slug string `json:"slug"` // Default: "<slug sumber>-copy" (dibuat unik otomatis)
+}
+
+type CloneVenueResponse struct {
+	VenueID         uuid.UUID `json:"venue_id"`
+	Slug            string    `json:"slug"`
+	LiveRevisionID  uuid.UUID `json:"live_revision_id"`
+	DraftRevisionID uuid.UUID `json:"draft_revision_id"`
+}
+
+type VenueBundle struct {
+	Version         int                 `json:"version"`
+	ExportedAt      time.Time           `json:"exported_at"`
+	SourceVenueID   uuid.UUID           `json:"source_venue_id"`
+	Venue           BundleVenue         `json:"venue"`
+	Media           []BundleMedia       `json:"media"`
+	Gallery         []BundleGalleryItem `json:"gallery"`
+	Areas           []BundleArea        `json:"areas"`
+	Revisions       []BundleRevision    `json:"revisions"`
+	LiveRevisionID  uuid.UUID           `json:"live_revision_id"`
+	DraftRevisionID *uuid.UUID          `json:"draft_revision_id,omitempty"`
+}
+
+type BundleVenue struct {
+	Name         string     `json:"name"`
+	Slug         string     `json:"slug"`
+	Description  string     `json:"description"`
+	Address      string     `json:"address"`
+	City         string     `json:"city"`
+	Province     string     `json:"province"`
+	PostalCode   string     `json:"postal_code"`
+	Visibility   string     `json:"visibility"`
+	Latitude     float64    `json:"latitude"`
+	Longitude    float64    `json:"longitude"`
+	CoverImageID *uuid.UUID `json:"cover_image_id,omitempty"`
+}
+
+// BundleMedia: Referensi media sumber. File = path file media di dalam zip.
+type BundleMedia struct {
+	ID           uuid.UUID `json:"id"`
+	Bucket       string    `json:"bucket"`
+	Key          string    `json:"key"`
+	PublicURL    string    `json:"public_url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	Type         string    `json:"type"`
+	SizeInBytes  int64     `json:"size_in_bytes"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	BlurHash     string    `json:"blur_hash"`
+	AltText      string    `json:"alt_text"`
+	Checksum     string    `json:"checksum"`
+	File         string    `json:"file,omitempty"`
+}
+
+type BundleGalleryItem struct {
+	MediaAssetID uuid.UUID `json:"media_asset_id"`
+	SortOrder    int       `json:"sort_order"`
+	Caption      string    `json:"caption"`
+	IsVisible    bool      `json:"is_visible"`
+	IsFeatured   bool      `json:"is_featured,omitempty"`
+}
+
+type BundleArea struct {
+	ID           uuid.UUID           `json:"id"`
+	FloorID      uuid.UUID           `json:"floor_id"` // Stable ID floor
+	Name         string              `json:"name"`
+	Slug         string              `json:"slug"`
+	Label        string              `json:"label"`
+	Description  string              `json:"description"`
+	Category     string              `json:"category"`
+	Latitude     float64             `json:"latitude"`
+	Longitude    float64             `json:"longitude"`
+	MapX         float64             `json:"map_x"`
+	MapY         float64             `json:"map_y"`
+	CoverImageID *uuid.UUID          `json:"cover_image_id,omitempty"`
+	Gallery      []BundleGalleryItem `json:"gallery"`
+}
+
+type BundleRevision struct {
+	ID          uuid.UUID     `json:"id"`
+	Status      string        `json:"status"`
+	Note        string        `json:"note"`
+	CreatedAt   time.Time     `json:"created_at"`
+	StartNodeID *uuid.UUID    `json:"start_node_id,omitempty"`
+	Floors      []BundleFloor `json:"floors"`
+}
+
+type BundleFloor struct {
+	ID             uuid.UUID              `json:"id"`
+	LineageID      *uuid.UUID             `json:"lineage_id,omitempty"`
+	Name           string                 `json:"name"`
+	LevelIndex     int                    `json:"level_index"`
+	MapImageID     *uuid.UUID             `json:"map_image_id,omitempty"`
+	MapWidth       int                    `json:"map_width"`
+	MapHeight      int                    `json:"map_height"`
+	PixelsPerMeter float64                `json:"pixels_per_meter"`
+	GeoReference   map[string]interface{} `json:"geo_reference,omitempty"`
+	IsActive       bool                   `json:"is_active"`
+	Nodes          []BundleNode           `json:"nodes"`
+}
+
+type BundleNode struct {
+	ID              uuid.UUID              `json:"id"`
+	LineageID       *uuid.UUID             `json:"lineage_id,omitempty"`
+	X               float64                `json:"x"`
+	Y               float64                `json:"y"`
+	AreaID          *uuid.UUID             `json:"area_id,omitempty"`
+	PanoramaAssetID uuid.UUID              `json:"panorama_asset_id"`
+	RotationOffset  float64                `json:"rotation_offset"`
+	Label           string                 `json:"label"`
+	Properties      map[string]interface{} `json:"properties,omitempty"`
+	IsActive        bool                   `json:"is_active"`
+	Edges           []BundleEdge           `json:"edges"`
+}
+
+type BundleEdge struct {
+	ToNodeID uuid.UUID `json:"to_node_id"`
+	Heading  float64   `json:"heading"`
+	Distance float64   `json:"distance"`
+	Type     string    `json:"type"`
+	IsActive bool      `json:"is_active"`
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepo struct {
+	BaseRepository[entity.ApiKey, uuid.UUID]
+	db *gorm.DB
+}
+
+func NewApiKeyRepository(db *gorm.DB) ApiKeyRepository {
+	return &apiKeyRepo{
+		BaseRepository: NewBaseRepository[entity.ApiKey, uuid.UUID](db),
+		db:             db,
+	}
+}
+
+func (r *apiKeyRepo) GetByHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	var key entity.ApiKey
+	err := r.db.WithContext(ctx).
+		Preload("Organization").
+		Where("key_hash = ?", keyHash).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.ApiKey, error) {
+	var keys []entity.ApiKey
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("created_at desc").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke: Dibatasi organisasi pemanggil; 0 baris = key tidak ada / milik organisasi lain
+func (r *apiKeyRepo) Revoke(ctx context.Context, orgID, id uuid.UUID) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.ApiKey{}).
+		Where("id = ? AND organization_id = ?", id, orgID).
+		Update("is_active", false)
+	return res.RowsAffected, res.Error
+}
+
+// TouchLastUsed: UpdateColumn supaya updated_at tidak ikut berubah di setiap request
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.ApiKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
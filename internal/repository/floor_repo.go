@@ -58,6 +58,13 @@ func (r *floorRepo) UpdateFloorMap(ctx context.Context, id uuid.UUID, mapImageID
 }
 
 
+// UpdateGeoReference: nil = hapus kalibrasi GPS lantai
+func (r *floorRepo) UpdateGeoReference(ctx context.Context, id uuid.UUID, geoReference entity.JSONMap) error {
+	return r.db.WithContext(ctx).Model(&entity.Floor{}).
+		Where("id = ?", id).
+		Update("geo_reference", geoReference).Error
+}
+
 func (r *floorRepo) FilterFloors(ctx context.Context, filter models.FloorFilter) ([]entity.Floor, error) {
 	var floors []entity.Floor
 	query := r.buildFilterQuery(ctx, filter)
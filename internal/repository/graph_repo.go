@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type graphRepo struct {
@@ -17,6 +18,14 @@ func NewGraphRepository(db *gorm.DB) GraphRepository {
 	return &graphRepo{db: db}
 }
 
+func (r *graphRepo) GetNode(ctx context.Context, id uuid.UUID) (*entity.GraphNode, error) {
+	var node entity.GraphNode
+	if err := r.db.WithContext(ctx).First(&node, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
 func (r *graphRepo) CreateNode(ctx context.Context, node *entity.GraphNode) error {
 	return r.db.WithContext(ctx).Create(node).Error
 }
@@ -63,6 +72,28 @@ func (r *graphRepo) ConnectNodes(ctx context.Context, edge *entity.GraphEdge) er
 	return r.db.WithContext(ctx).Create(edge).Error
 }
 
+// ImportGraph: Simpan hasil bulk import dalam satu transaksi (ID sudah di-generate oleh service)
+func (r *graphRepo) ImportGraph(ctx context.Context, areas []entity.Area, nodes []entity.GraphNode, edges []entity.GraphEdge) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(areas) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(areas, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(nodes) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(nodes, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(edges) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(edges, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *graphRepo) DeleteEdge(ctx context.Context, fromID, toID uuid.UUID) error {
 	return r.db.WithContext(ctx).
 		Where("from_node_id = ? AND to_node_id = ?", fromID, toID).
@@ -4,6 +4,7 @@ import (
 	"context"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -38,33 +39,87 @@ type OrganizationMemberRepository interface {
 	RemoveMember(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) error
 	UpdateRole(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, roleID uuid.UUID) error
 	GetMember(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (*entity.OrganizationMember, error)
+	FindMember(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (*entity.OrganizationMember, error) // nil, nil jika bukan anggota
 	GetMembersByOrg(ctx context.Context, orgID uuid.UUID) ([]entity.OrganizationMember, error)
 	GetMembersByUser(ctx context.Context, userID uuid.UUID) ([]entity.OrganizationMember, error)
 }
 
 type UserInvitationRepository interface {
 	BaseRepository[entity.UserInvitation, uuid.UUID]
-	GetByToken(ctx context.Context, token string) (*entity.UserInvitation, error)
+	GetByToken(ctx context.Context, tokenHash string) (*entity.UserInvitation, error) // Semua status, pemanggil yang memeriksa
 	GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.UserInvitation, error)
 	GetByRoleID(ctx context.Context, roleID uuid.UUID) ([]entity.UserInvitation, error)
 	GetByEmail(ctx context.Context, email string) ([]entity.UserInvitation, error)
 	GetByInviterID(ctx context.Context, inviterID uuid.UUID) ([]entity.UserInvitation, error)
 	GetByStatus(ctx context.Context, orgID uuid.UUID, status string) ([]entity.UserInvitation, error)
 	RevokeInvitation(ctx context.Context, id uuid.UUID) error
+	Accept(ctx context.Context, inviteID uuid.UUID, member *entity.OrganizationMember) (bool, error) // false = undangan sudah tidak pending
+	MarkExpired(ctx context.Context, now time.Time) (int64, error)
 }
 
 type RoleRepository interface {
 	BaseRepository[entity.Role, uuid.UUID]
-	GetByName(ctx context.Context, name string) (*entity.Role, error)
-	GetAll(ctx context.Context) ([]entity.Role, error) // List untuk dropdown di frontend
+	GetByName(ctx context.Context, name string) (*entity.Role, error)                // Hanya role bawaan sistem
+	GetAll(ctx context.Context) ([]entity.Role, error)                               // List untuk dropdown di frontend
+	GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.Role, error) // Role sistem + role custom organisasi
 	GetPermissions(ctx context.Context, roleID uuid.UUID) ([]entity.Permission, error)
+	UpdateWithPermissions(ctx context.Context, role *entity.Role, perms []entity.Permission) error // perms nil = permission tidak diubah
+	CountAssignments(ctx context.Context, roleID uuid.UUID) (members int64, pendingInvites int64, err error)
 }
 
 type PermissionRepository interface {
 	BaseRepository[entity.Permission, uuid.UUID]
 	GetAll(ctx context.Context) ([]entity.Permission, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Permission, error)
 	GetByUserAndOrg(ctx context.Context, userID, orgID uuid.UUID) ([]entity.Permission, error)
 }
+
+// RefreshTokenRepository: Refresh token berotasi; satu family = satu sesi login
+type RefreshTokenRepository interface {
+	BaseRepository[entity.RefreshToken, uuid.UUID]
+	GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	Rotate(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) (bool, error) // false = token sudah dirotasi request lain
+	GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]entity.RefreshToken, error)
+	RevokeFamily(ctx context.Context, userID, familyID uuid.UUID) (int64, error)
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID) error
+	SetOrganization(ctx context.Context, userID, familyID, orgID uuid.UUID) (int64, error) // Organisasi yang dipakai saat refresh berikutnya; 0 = sesi sudah dicabut
+}
+
+// UserTokenRepository: Token email sekali pakai (reset password, verifikasi email)
+type UserTokenRepository interface {
+	BaseRepository[entity.UserToken, uuid.UUID]
+	GetByHash(ctx context.Context, purpose entity.UserTokenPurpose, tokenHash string) (*entity.UserToken, error)
+	Consume(ctx context.Context, id uuid.UUID) (bool, error) // false = sudah dipakai / kedaluwarsa
+	InvalidateByUser(ctx context.Context, userID uuid.UUID, purpose entity.UserTokenPurpose) error
+}
+
+// UserIdentityRepository: Tautan user ke akun identity provider (SSO)
+type UserIdentityRepository interface {
+	BaseRepository[entity.UserIdentity, uuid.UUID]
+	GetBySubject(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error)
+}
+
+// TwoFactorRepository: Secret TOTP & recovery code user
+type TwoFactorRepository interface {
+	GetByUser(ctx context.Context, userID uuid.UUID) (*entity.UserTwoFactor, error)
+	SavePending(ctx context.Context, tf *entity.UserTwoFactor) error
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) (bool, error) // false = tidak ada pendaftaran pending
+	Disable(ctx context.Context, userID uuid.UUID) error
+	ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) // false = kode periode ini sudah dipakai
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) // false = tidak ada / sudah dipakai
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// ApiKeyRepository: API key organisasi, dicari lewat hash key
+type ApiKeyRepository interface {
+	BaseRepository[entity.ApiKey, uuid.UUID]
+	GetByHash(ctx context.Context, keyHash string) (*entity.ApiKey, error)
+	GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.ApiKey, error)
+	Revoke(ctx context.Context, orgID, id uuid.UUID) (int64, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
 type AuthRepository interface {
 	FindUserByEmail(ctx context.Context, email string) (*entity.User, error)
 	ValidateAPIKey(ctx context.Context, keyHash string) (*entity.ApiKey, error)
@@ -74,7 +129,12 @@ type VenueRepository interface {
 	BaseRepository[entity.Venue, uuid.UUID]
 	GetBySlug(ctx context.Context, slug string) (*entity.Venue, error)
 	GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.Venue, error)
-	GetLiveManifestData(venueSlug string) (*entity.Venue, error)
+	GetManifestInfo(ctx context.Context, orgSlug, slug string) (*entity.Venue, error)
+	GetOrgSlugsByVenueSlug(ctx context.Context, slug string, limit int) ([]string, error)
+	GetLiveManifestData(orgSlug, venueSlug string) (*entity.Venue, error)
+	GetDraftManifestData(orgSlug, venueSlug string) (*entity.Venue, error)
+	GetRevisionManifestData(ctx context.Context, venueID, revisionID uuid.UUID) (*entity.GraphRevision, error)
+	CreateCopy(ctx context.Context, src *VenueCopy) (*entity.Venue, error)
 	FilterVenues(ctx context.Context, filter models.VenueFilter) ([]entity.Venue, error)
 	PagedVenues(ctx context.Context, query models.VenueQuery) ([]entity.Venue, int64, error)
 	CursorVenues(ctx context.Context, query models.VenueQueryCursor) ([]entity.Venue, string, error)
@@ -92,18 +152,23 @@ type VenueGalleryRepository interface {
 	CursorVenueGalleries(ctx context.Context, query models.VenueGalleryCursor) ([]entity.VenueGalleryItem, string, error)
 }
 type GraphRepository interface {
+	GetNode(ctx context.Context, id uuid.UUID) (*entity.GraphNode, error)
 	CreateNode(ctx context.Context, node *entity.GraphNode) error
 	UpdateNodePosition(ctx context.Context, id uuid.UUID, x, y float64) error
 	UpdateNodeCalibration(ctx context.Context, id uuid.UUID, offset float64) error
 	DeleteNode(ctx context.Context, id uuid.UUID) error
 	ConnectNodes(ctx context.Context, edge *entity.GraphEdge) error
 	DeleteEdge(ctx context.Context, fromID, toID uuid.UUID) error
+	ImportGraph(ctx context.Context, areas []entity.Area, nodes []entity.GraphNode, edges []entity.GraphEdge) error
 }
 
 type GraphRevisionRepository interface {
 	BaseRepository[entity.GraphRevision, uuid.UUID]
 	CreateDraft(ctx context.Context, venueID uuid.UUID) (*entity.GraphRevision, error)
 	PublishDraft(ctx context.Context, revisionID uuid.UUID, note string) error
+	// SetLiveRevision: Hanya revisi published milik venue; 0 baris = revisi tidak ditemukan
+	SetLiveRevision(ctx context.Context, venueID, revisionID uuid.UUID) (int64, error)
+	GetFullGraph(ctx context.Context, revisionID uuid.UUID) (*entity.GraphRevision, error)
 	GetDraftByFloorID(ctx context.Context, floorID uuid.UUID) (*entity.GraphRevision, error)
 	GetDraftByVenueID(ctx context.Context, venueID uuid.UUID) (*entity.GraphRevision, error)
 	GetDraftByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.GraphRevision, error)
@@ -147,6 +212,7 @@ type FloorRepository interface {
 	GetByVenueID(ctx context.Context, venueID uuid.UUID) ([]entity.Floor, error)
 	GetByGraphRevisionID(ctx context.Context, revisionID uuid.UUID) ([]entity.Floor, error)
 	UpdateFloorMap(ctx context.Context, id uuid.UUID, mapImageID *uuid.UUID, pixelsPerMeter float64) error
+	UpdateGeoReference(ctx context.Context, id uuid.UUID, geoReference entity.JSONMap) error
 	FilterFloors(ctx context.Context, filter models.FloorFilter) ([]entity.Floor, error)
 	PagedFloors(ctx context.Context, query models.FloorQuery) ([]entity.Floor, int64, error)
 	CursorFloors(ctx context.Context, query models.FloorQueryCursor) ([]entity.Floor, string, error)
@@ -166,3 +232,12 @@ type AuditLogRepository interface {
 	PagedAuditLogs(ctx context.Context, query models.AuditLogQuery) ([]entity.AuditLog, int64, error)
 	CursorAuditLogs(ctx context.Context, query models.AuditLogQueryCursor) ([]entity.AuditLog, string, error)
 }
+
+// OwnershipRepository: Cek apakah resource milik organisasi tertentu (query di-scope organization_id)
+type OwnershipRepository interface {
+	VenueBelongsTo(ctx context.Context, orgID, venueID uuid.UUID) (bool, error)
+	FloorBelongsTo(ctx context.Context, orgID, floorID uuid.UUID) (bool, error)
+	NodeBelongsTo(ctx context.Context, orgID, nodeID uuid.UUID) (bool, error)
+	AreaBelongsTo(ctx context.Context, orgID, areaID uuid.UUID) (bool, error)
+	MediaBelongsTo(ctx context.Context, orgID, mediaID uuid.UUID) (bool, error)
+}
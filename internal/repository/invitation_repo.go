@@ -4,6 +4,8 @@ import (
 	"context"
 	"inspacemap/backend/internal/entity"
 
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -21,14 +23,14 @@ func NewInvitationRepository(db *gorm.DB) UserInvitationRepository {
 }
 
 
-func (r *invitationRepo) GetByToken(ctx context.Context, token string) (*entity.UserInvitation, error) {
+func (r *invitationRepo) GetByToken(ctx context.Context, tokenHash string) (*entity.UserInvitation, error) {
 	var invite entity.UserInvitation
 	
 
 	err := r.db.WithContext(ctx).
 		Preload("Role").
 		Preload("Organization").
-		Where("token = ? AND status = ?", token, "pending"). 
+		Where("token = ?", tokenHash).
 		First(&invite).Error
 
 	if err != nil {
@@ -41,6 +43,7 @@ func (r *invitationRepo) GetByOrganizationID(ctx context.Context, orgID uuid.UUI
 	var invites []entity.UserInvitation
 	err := r.db.WithContext(ctx).
 		Preload("Role"). 
+		Preload("InvitedByUser").
 		Where("organization_id = ?", orgID).
 		Order("created_at desc").
 		Find(&invites).Error
@@ -58,7 +61,10 @@ func (r *invitationRepo) GetByEmail(ctx context.Context, email string) ([]entity
 func (r *invitationRepo) GetByStatus(ctx context.Context, orgID uuid.UUID, status string) ([]entity.UserInvitation, error) {
 	var invites []entity.UserInvitation
 	err := r.db.WithContext(ctx).
+		Preload("Role").
+		Preload("InvitedByUser").
 		Where("organization_id = ? AND status = ?", orgID, status).
+		Order("created_at desc").
 		Find(&invites).Error
 	return invites, err
 }
@@ -67,7 +73,7 @@ func (r *invitationRepo) RevokeInvitation(ctx context.Context, id uuid.UUID) err
 	return r.db.WithContext(ctx).
 		Model(&entity.UserInvitation{}).
 		Where("id = ?", id).
-		Update("status", "revoked").Error
+		Update("status", entity.InvitationRevoked).Error
 }
 
 func (r *invitationRepo) GetByRoleID(ctx context.Context, roleID uuid.UUID) ([]entity.UserInvitation, error) {
@@ -84,4 +90,40 @@ func (r *invitationRepo) GetByInviterID(ctx context.Context, inviterID uuid.UUID
 		Where("invited_by_user_id = ?", inviterID).
 		Find(&invites).Error
 	return invites, err
-}
\ No newline at end of file
+}
+
+// Accept: Tandai undangan accepted dan tambahkan member dalam satu transaksi.
+// Update dikondisikan pada status pending sehingga satu token tidak bisa dipakai dua kali.
+func (r *invitationRepo) Accept(ctx context.Context, inviteID uuid.UUID, member *entity.OrganizationMember) (bool, error) {
+	accepted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entity.UserInvitation{}).
+			Where("id = ? AND status = ?", inviteID, entity.InvitationPending).
+			Updates(map[string]interface{}{"status": entity.InvitationAccepted, "accepted_at": time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		err := tx.FirstOrCreate(member, entity.OrganizationMember{
+			OrganizationID: member.OrganizationID,
+			UserID:         member.UserID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		accepted = true
+		return nil
+	})
+	return accepted, err
+}
+
+// MarkExpired: Undangan pending yang lewat ExpiresAt menjadi expired
+func (r *invitationRepo) MarkExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.UserInvitation{}).
+		Where("status = ? AND expires_at < ?", entity.InvitationPending, now).
+		Update("status", entity.InvitationExpired)
+	return res.RowsAffected, res.Error
+}
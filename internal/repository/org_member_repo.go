@@ -50,6 +50,23 @@ func (r *orgMemberRepo) GetMember(ctx context.Context, orgID uuid.UUID, userID u
 	return &member, err
 }
 
+// FindMember: Seperti GetMember, tapi "bukan anggota" bukan error (dipakai pengecekan akses per request).
+// Organization & User ikut dimuat untuk kebijakan wajib 2FA.
+func (r *orgMemberRepo) FindMember(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (*entity.OrganizationMember, error) {
+	var members []entity.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Preload("Role.Permissions").
+		Preload("Organization").
+		Preload("User").
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Limit(1).
+		Find(&members).Error
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	return &members[0], nil
+}
+
 func (r *orgMemberRepo) GetMembersByOrg(ctx context.Context, orgID uuid.UUID) ([]entity.OrganizationMember, error) {
 	var members []entity.OrganizationMember
 	err := r.db.WithContext(ctx).
@@ -64,7 +81,7 @@ func (r *orgMemberRepo) GetMembersByUser(ctx context.Context, userID uuid.UUID)
 	var memberships []entity.OrganizationMember
 	err := r.db.WithContext(ctx).
 		Preload("Organization"). // Load nama organisasi
-		Preload("Role").
+		Preload("Role.Permissions").
 		Where("user_id = ?", userID).
 		Find(&memberships).Error
 	return memberships, err
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ownershipRepo struct {
+	db *gorm.DB
+}
+
+func NewOwnershipRepository(db *gorm.DB) OwnershipRepository {
+	return &ownershipRepo{db: db}
+}
+
+func (r *ownershipRepo) VenueBelongsTo(ctx context.Context, orgID, venueID uuid.UUID) (bool, error) {
+	return hasRow(r.db.WithContext(ctx).Model(&entity.Venue{}).
+		Where("venues.id = ? AND venues.organization_id = ?", venueID, orgID))
+}
+
+// Floor, Node & Area tidak menyimpan organization_id: scope lewat venue induknya
+func (r *ownershipRepo) FloorBelongsTo(ctx context.Context, orgID, floorID uuid.UUID) (bool, error) {
+	return hasRow(r.db.WithContext(ctx).Model(&entity.Floor{}).
+		Joins("JOIN venues ON venues.id = floors.venue_id AND venues.deleted_at IS NULL").
+		Where("floors.id = ? AND venues.organization_id = ?", floorID, orgID))
+}
+
+func (r *ownershipRepo) NodeBelongsTo(ctx context.Context, orgID, nodeID uuid.UUID) (bool, error) {
+	return hasRow(r.db.WithContext(ctx).Model(&entity.GraphNode{}).
+		Joins("JOIN floors ON floors.id = graph_nodes.floor_id AND floors.deleted_at IS NULL").
+		Joins("JOIN venues ON venues.id = floors.venue_id AND venues.deleted_at IS NULL").
+		Where("graph_nodes.id = ? AND venues.organization_id = ?", nodeID, orgID))
+}
+
+func (r *ownershipRepo) AreaBelongsTo(ctx context.Context, orgID, areaID uuid.UUID) (bool, error) {
+	return hasRow(r.db.WithContext(ctx).Model(&entity.Area{}).
+		Joins("JOIN venues ON venues.id = areas.venue_id AND venues.deleted_at IS NULL").
+		Where("areas.id = ? AND venues.organization_id = ?", areaID, orgID))
+}
+
+func (r *ownershipRepo) MediaBelongsTo(ctx context.Context, orgID, mediaID uuid.UUID) (bool, error) {
+	return hasRow(r.db.WithContext(ctx).Model(&entity.MediaAsset{}).
+		Where("media_assets.id = ? AND media_assets.organization_id = ?", mediaID, orgID))
+}
+
+func hasRow(query *gorm.DB) (bool, error) {
+	var count int64
+	if err := query.Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -22,7 +22,8 @@ func NewRoleRepository(db *gorm.DB) RoleRepository {
 
 func (r *roleRepo) GetByName(ctx context.Context, name string) (*entity.Role, error) {
 	var role entity.Role
-	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+	// Role custom organisasi boleh bernama sama dengan role organisasi lain, jadi lookup by name hanya untuk role sistem
+	if err := r.db.WithContext(ctx).Where("name = ? AND organization_id IS NULL", name).First(&role).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
@@ -35,6 +36,17 @@ func (r *roleRepo) GetAll(ctx context.Context) ([]entity.Role, error) {
 	return roles, err
 }
 
+func (r *roleRepo) GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.Role, error) {
+	var roles []entity.Role
+
+	err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Where("organization_id IS NULL OR organization_id = ?", orgID).
+		Order("organization_id IS NOT NULL, name asc"). // Role sistem dulu
+		Find(&roles).Error
+	return roles, err
+}
+
 func (r *roleRepo) GetPermissions(ctx context.Context, roleID uuid.UUID) ([]entity.Permission, error) {
 	var perms []entity.Permission
 	err := r.db.WithContext(ctx).
@@ -44,6 +56,33 @@ func (r *roleRepo) GetPermissions(ctx context.Context, roleID uuid.UUID) ([]enti
 	return perms, err
 }
 
+func (r *roleRepo) UpdateWithPermissions(ctx context.Context, role *entity.Role, perms []entity.Permission) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		if perms == nil {
+			return nil
+		}
+		return tx.Model(role).Association("Permissions").Replace(perms)
+	})
+}
+
+func (r *roleRepo) CountAssignments(ctx context.Context, roleID uuid.UUID) (int64, int64, error) {
+	var members, pendingInvites int64
+	if err := r.db.WithContext(ctx).Model(&entity.OrganizationMember{}).
+		Where("role_id = ?", roleID).
+		Count(&members).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&entity.UserInvitation{}).
+		Where("role_id = ? AND status = ?", roleID, "pending").
+		Count(&pendingInvites).Error; err != nil {
+		return 0, 0, err
+	}
+	return members, pendingInvites, nil
+}
+
 type permissionRepo struct {
 	BaseRepository[entity.Permission, uuid.UUID]
 	db *gorm.DB
@@ -62,12 +101,18 @@ func (r *permissionRepo) GetAll(ctx context.Context) ([]entity.Permission, error
 	return perms, err
 }
 
+func (r *permissionRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Permission, error) {
+	var perms []entity.Permission
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&perms).Error
+	return perms, err
+}
+
 func (r *permissionRepo) GetByUserAndOrg(ctx context.Context, userID, orgID uuid.UUID) ([]entity.Permission, error) {
 	var perms []entity.Permission
 	err := r.db.WithContext(ctx).
 		Table("permissions").
 		Joins("JOIN role_permissions rp ON rp.permission_id = permissions.id").
-		Joins("JOIN organization_members om ON om.role_id = rp.role_id").
+		Joins("JOIN organization_members om ON om.role_id = rp.role_id AND om.deleted_at IS NULL"). // Member yang sudah dikeluarkan tidak punya akses
 		Where("om.user_id = ? AND om.organization_id = ?", userID, orgID).
 		Distinct().
 		Find(&perms).Error
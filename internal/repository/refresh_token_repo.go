@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepo struct {
+	BaseRepository[entity.RefreshToken, uuid.UUID]
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepo{
+		BaseRepository: NewBaseRepository[entity.RefreshToken, uuid.UUID](db),
+		db:             db,
+	}
+}
+
+func (r *refreshTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate: Tandai token lama sudah dipakai lalu simpan penggantinya dalam satu transaksi.
+// Update dikondisikan pada rotated_at IS NULL sehingga dua request refresh paralel
+// dengan token yang sama tidak bisa sama-sama berhasil.
+func (r *refreshTokenRepo) Rotate(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entity.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("rotated_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *refreshTokenRepo) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]entity.RefreshToken, error) {
+	var tokens []entity.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, userID, familyID uuid.UUID) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+func (r *refreshTokenRepo) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepo) SetOrganization(ctx context.Context, userID, familyID, orgID uuid.UUID) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND rotated_at IS NULL AND revoked_at IS NULL", userID, familyID).
+		Update("organization_id", orgID)
+	return res.RowsAffected, res.Error
+}
@@ -326,83 +326,12 @@ func (r *revisionRepo) PublishDraft(ctx context.Context, venueID uuid.UUID, note
 			return err
 		}
 
-		// 4. CLONING (Mapping UUID Lama -> UUID Baru)
-		nodeIDMap := make(map[uuid.UUID]uuid.UUID)
-		var newStartNodeID *uuid.UUID
-
-		for _, floor := range draft.Floors {
-			// Clone Floor
-			newFloor := entity.Floor{
-				GraphRevisionID: newLiveRev.ID,
-				VenueID:         venueID,
-				Name:            floor.Name,
-				LevelIndex:      floor.LevelIndex,
-				MapImageID:      floor.MapImageID,
-				PixelsPerMeter:  floor.PixelsPerMeter,
-				IsActive:        floor.IsActive,
-				MapWidth:        floor.MapWidth,
-				MapHeight:       floor.MapHeight,
-			}
-			if err := tx.Create(&newFloor).Error; err != nil {
-				return err
-			}
-
-			// Clone Nodes
-			for _, node := range floor.Nodes {
-				newNode := entity.GraphNode{
-					FloorID:         newFloor.ID,
-					AreaID:          node.AreaID,
-					X:               node.X,
-					Y:               node.Y,
-					PanoramaAssetID: node.PanoramaAssetID,
-					RotationOffset:  node.RotationOffset,
-					Label:           node.Label,
-					Properties:      node.Properties,
-					IsActive:        node.IsActive,
-				}
-				if err := tx.Create(&newNode).Error; err != nil {
-					return err
-				}
-
-				nodeIDMap[node.ID] = newNode.ID
-
-				// Cek Start Node
-				if draft.StartNodeID != nil && node.ID == *draft.StartNodeID {
-					id := newNode.ID
-					newStartNodeID = &id
-				}
-			}
-		}
-
-		// 5. Reconstruct Edges
-		for _, floor := range draft.Floors {
-			for _, node := range floor.Nodes {
-				for _, edge := range node.OutgoingEdges {
-					newFrom, ok1 := nodeIDMap[edge.FromNodeID]
-					newTo, ok2 := nodeIDMap[edge.ToNodeID]
-					if ok1 && ok2 {
-						newEdge := entity.GraphEdge{
-							FromNodeID: newFrom,
-							ToNodeID:   newTo,
-							Heading:    edge.Heading,
-							Distance:   edge.Distance,
-							Type:       edge.Type,
-							IsActive:   edge.IsActive,
-						}
-						if err := tx.Create(&newEdge).Error; err != nil {
-							return err
-						}
-					}
-				}
-			}
-		}
-
-		// 6. Finalisasi Pointer
-		if newStartNodeID != nil {
-			tx.Model(&newLiveRev).Update("start_node_id", *newStartNodeID)
+		// 4. CLONING (Floors, Nodes, Edges + Start Node)
+		if err := cloneGraph(tx, &draft, &newLiveRev, nil); err != nil {
+			return err
 		}
 
-		// Update Venue agar menunjuk ke Live Revision yang baru
+		// 5. Update Venue agar menunjuk ke Live Revision yang baru
 		if err := tx.Model(&venue).Update("live_revision_id", newLiveRev.ID).Error; err != nil {
 			return err
 		}
@@ -414,6 +343,189 @@ func (r *revisionRepo) PublishDraft(ctx context.Context, venueID uuid.UUID, note
 	})
 }
 
+func (r *revisionRepo) SetLiveRevision(ctx context.Context, venueID, revisionID uuid.UUID) (int64, error) {
+	published := r.db.Model(&entity.GraphRevision{}).
+		Select("1").
+		Where("id = ? AND venue_id = ? AND status = ?", revisionID, venueID, entity.StatusPublished)
+	res := r.db.WithContext(ctx).
+		Model(&entity.Venue{}).
+		Where("id = ? AND EXISTS (?)", venueID, published).
+		Update("live_revision_id", revisionID)
+	return res.RowsAffected, res.Error
+}
+
+// GetFullGraph: Revisi lengkap (termasuk node/edge nonaktif) untuk export & clone
+func (r *revisionRepo) GetFullGraph(ctx context.Context, revisionID uuid.UUID) (*entity.GraphRevision, error) {
+	var revision entity.GraphRevision
+	err := r.db.WithContext(ctx).
+		Preload("Floors", func(db *gorm.DB) *gorm.DB {
+			return db.Order("level_index asc")
+		}).
+		Preload("Floors.Nodes").
+		Preload("Floors.Nodes.OutgoingEdges").
+		First(&revision, "id = ?", revisionID).Error
+	return &revision, err
+}
+
+// graphRemap: Pemetaan ID sumber -> ID baru saat graph disalin ke venue lain (clone/import).
+// nil (PublishDraft) = area, media & lineage tetap sama dengan sumber.
+type graphRemap struct {
+	stable map[uuid.UUID]uuid.UUID
+	areas  map[uuid.UUID]uuid.UUID
+	media  map[uuid.UUID]uuid.UUID
+}
+
+func newGraphRemap(media map[uuid.UUID]uuid.UUID) *graphRemap {
+	return &graphRemap{
+		stable: make(map[uuid.UUID]uuid.UUID),
+		areas:  make(map[uuid.UUID]uuid.UUID),
+		media:  media,
+	}
+}
+
+// stableID: Lineage baru per lineage sumber, konsisten untuk semua revisi yang disalin
+func (m *graphRemap) stableID(id uuid.UUID) uuid.UUID {
+	if m == nil {
+		return id
+	}
+	mapped, ok := m.stable[id]
+	if !ok {
+		mapped = uuid.New()
+		m.stable[id] = mapped
+	}
+	return mapped
+}
+
+// copiedStableID: Lineage baru tanpa membuat yang belum ada; ok = false jika lineage sumber tidak ikut disalin
+func (m *graphRemap) copiedStableID(id uuid.UUID) (uuid.UUID, bool) {
+	mapped, ok := m.stable[id]
+	return mapped, ok
+}
+
+// areaID: Area yang tidak ikut disalin dilepas dari node
+func (m *graphRemap) areaID(id *uuid.UUID) *uuid.UUID {
+	if m == nil || id == nil {
+		return id
+	}
+	if mapped, ok := m.areas[*id]; ok {
+		return &mapped
+	}
+	return nil
+}
+
+// mediaID: ok = false untuk media yang tidak ikut disalin (mis. ID milik org lain di bundle buatan sendiri)
+func (m *graphRemap) mediaID(id uuid.UUID) (uuid.UUID, bool) {
+	if m == nil || m.media == nil {
+		return id, true
+	}
+	mapped, ok := m.media[id]
+	return mapped, ok
+}
+
+// mediaPtr: Media yang tidak ikut disalin dilepas (nil)
+func (m *graphRemap) mediaPtr(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	if mapped, ok := m.mediaID(*id); ok {
+		return &mapped
+	}
+	return nil
+}
+
+// cloneGraph: Deep copy floors, nodes & edges dari src ke revisi dst (sudah tersimpan).
+// Floor/node draft memakai lineage sebagai ID-nya sendiri, revisi lain menyimpannya di LineageID.
+func cloneGraph(tx *gorm.DB, src, dst *entity.GraphRevision, remap *graphRemap) error {
+	nodeIDMap := make(map[uuid.UUID]uuid.UUID)
+	var newStartNodeID *uuid.UUID
+
+	for _, floor := range src.Floors {
+		floorLineage := remap.stableID(floor.StableID())
+		newFloor := entity.Floor{
+			GraphRevisionID: dst.ID,
+			VenueID:         dst.VenueID,
+			Name:            floor.Name,
+			LevelIndex:      floor.LevelIndex,
+			MapImageID:      remap.mediaPtr(floor.MapImageID),
+			PixelsPerMeter:  floor.PixelsPerMeter,
+			IsActive:        floor.IsActive,
+			MapWidth:        floor.MapWidth,
+			MapHeight:       floor.MapHeight,
+			GeoReference:    floor.GeoReference,
+			LineageID:       &floorLineage,
+		}
+		if dst.Status == entity.StatusDraft {
+			newFloor.ID, newFloor.LineageID = floorLineage, nil
+		}
+		if err := tx.Create(&newFloor).Error; err != nil {
+			return err
+		}
+
+		for _, node := range floor.Nodes {
+			// Panorama wajib ada: node yang panoramanya tidak ikut disalin dibuang beserta edge-nya
+			panoramaID, ok := remap.mediaID(node.PanoramaAssetID)
+			if !ok {
+				continue
+			}
+			nodeLineage := remap.stableID(node.StableID())
+			newNode := entity.GraphNode{
+				FloorID:         newFloor.ID,
+				AreaID:          remap.areaID(node.AreaID),
+				X:               node.X,
+				Y:               node.Y,
+				PanoramaAssetID: panoramaID,
+				RotationOffset:  node.RotationOffset,
+				Label:           node.Label,
+				Properties:      node.Properties,
+				IsActive:        node.IsActive,
+				LineageID:       &nodeLineage,
+			}
+			if dst.Status == entity.StatusDraft {
+				newNode.ID, newNode.LineageID = nodeLineage, nil
+			}
+			if err := tx.Create(&newNode).Error; err != nil {
+				return err
+			}
+
+			nodeIDMap[node.ID] = newNode.ID
+			if src.StartNodeID != nil && node.ID == *src.StartNodeID {
+				id := newNode.ID
+				newStartNodeID = &id
+			}
+		}
+	}
+
+	// Edges dibuat setelah semua node ada (bisa lintas lantai)
+	for _, floor := range src.Floors {
+		for _, node := range floor.Nodes {
+			for _, edge := range node.OutgoingEdges {
+				newFrom, ok1 := nodeIDMap[edge.FromNodeID]
+				newTo, ok2 := nodeIDMap[edge.ToNodeID]
+				if !ok1 || !ok2 {
+					continue
+				}
+				newEdge := entity.GraphEdge{
+					FromNodeID: newFrom,
+					ToNodeID:   newTo,
+					Heading:    edge.Heading,
+					Distance:   edge.Distance,
+					Type:       edge.Type,
+					IsActive:   edge.IsActive,
+				}
+				if err := tx.Create(&newEdge).Error; err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if newStartNodeID != nil {
+		dst.StartNodeID = newStartNodeID
+		return tx.Model(dst).Update("start_node_id", *newStartNodeID).Error
+	}
+	return nil
+}
+
 // --- QUERY BUILDER ---
 func (r *revisionRepo) buildFilterQuery(ctx context.Context, f models.FilterGraphRevision) *gorm.DB {
 	db := r.db.WithContext(ctx)
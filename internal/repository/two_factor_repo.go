@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type twoFactorRepo struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepo{db: db}
+}
+
+func (r *twoFactorRepo) GetByUser(ctx context.Context, userID uuid.UUID) (*entity.UserTwoFactor, error) {
+	var tf entity.UserTwoFactor
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&tf).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// SavePending: Ganti secret pendaftaran yang belum dikonfirmasi (hard delete karena user_id unik)
+func (r *twoFactorRepo) SavePending(ctx context.Context, tf *entity.UserTwoFactor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("user_id = ? AND enabled_at IS NULL", tf.UserID).
+			Delete(&entity.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(tf).Error
+	})
+}
+
+// Enable: Aktifkan secret pending, simpan recovery code dan tandai user dalam satu transaksi.
+// false = tidak ada pendaftaran pending (sudah aktif oleh request lain / belum mulai).
+func (r *twoFactorRepo) Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) (bool, error) {
+	enabled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entity.UserTwoFactor{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "last_used_step": step})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.User{}).Where("id = ?", userID).Update("two_factor_enabled", true).Error; err != nil {
+			return err
+		}
+		enabled = true
+		return nil
+	})
+	return enabled, err
+}
+
+func (r *twoFactorRepo) Disable(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.User{}).Where("id = ?", userID).Update("two_factor_enabled", false).Error
+	})
+}
+
+// ConsumeStep: Update dikondisikan pada last_used_step < step, jadi satu kode TOTP hanya bisa dipakai sekali
+// walau dua request mengirimnya bersamaan.
+func (r *twoFactorRepo) ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.UserTwoFactor{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *twoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (r *twoFactorRepo) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&entity.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *twoFactorRepo) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// replaceRecoveryCodes: Kode lama (terpakai maupun belum) tidak berlaku lagi
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]entity.UserRecoveryCode, 0, len(codeHashes))
+	for _, h := range codeHashes {
+		codes = append(codes, entity.UserRecoveryCode{UserID: userID, CodeHash: h})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type userIdentityRepo struct {
+	BaseRepository[entity.UserIdentity, uuid.UUID]
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepo{
+		BaseRepository: NewBaseRepository[entity.UserIdentity, uuid.UUID](db),
+		db:             db,
+	}
+}
+
+func (r *userIdentityRepo) GetBySubject(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
@@ -25,7 +25,7 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*entity.User,
 	err := r.db.WithContext(ctx).
 		Preload("Memberships").
 		Preload("Memberships.Organization").
-		Preload("Memberships.Role").
+		Preload("Memberships.Role.Permissions").
 		Where("email = ?", email).
 		First(&user).Error
 	
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type userTokenRepo struct {
+	BaseRepository[entity.UserToken, uuid.UUID]
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepo{
+		BaseRepository: NewBaseRepository[entity.UserToken, uuid.UUID](db),
+		db:             db,
+	}
+}
+
+func (r *userTokenRepo) GetByHash(ctx context.Context, purpose entity.UserTokenPurpose, tokenHash string) (*entity.UserToken, error) {
+	var token entity.UserToken
+	err := r.db.WithContext(ctx).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume: Update dikondisikan pada used_at IS NULL sehingga token hanya bisa dipakai sekali,
+// termasuk saat dua request memakai token yang sama bersamaan.
+func (r *userTokenRepo) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	res := r.db.WithContext(ctx).
+		Model(&entity.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *userTokenRepo) InvalidateByUser(ctx context.Context, userID uuid.UUID, purpose entity.UserTokenPurpose) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VenueCopy: Sumber deep copy venue (clone dalam organisasi / import bundle).
+// Semua ID di dalamnya milik sumber; CreateCopy men-generate ID baru dan me-remap relasinya.
+type VenueCopy struct {
+	Venue       entity.Venue            // Metadata venue baru (OrganizationID & Slug sudah final)
+	Media       []entity.MediaAsset     // MediaAsset baru (ID sudah final) yang dibuat bersama venue
+	MediaIDs    map[uuid.UUID]uuid.UUID // Media sumber -> media baru (yang tidak terdaftar dilepas), nil = media yang sama
+	Gallery     []entity.VenueGalleryItem
+	Areas       []entity.Area            // FloorID = stable ID floor sumber
+	AreaGallery []entity.AreaGalleryItem // AreaID = area sumber
+	Revisions   []entity.GraphRevision   // Floors.Nodes.OutgoingEdges lengkap
+
+	LiveRevisionID  uuid.UUID  // ID revisi sumber yang menjadi live
+	DraftRevisionID *uuid.UUID // ID revisi sumber yang menjadi draft, nil = draft disalin dari live
+	CreatedByID     uuid.UUID
+}
+
+// CreateCopy: Satu transaksi, memakai cloneGraph yang sama dengan PublishDraft
+func (r *venueRepo) CreateCopy(ctx context.Context, src *VenueCopy) (*entity.Venue, error) {
+	venue := src.Venue
+	venue.ID = uuid.New()
+	remap := newGraphRemap(src.MediaIDs)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Media & Venue
+		if len(src.Media) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&src.Media).Error; err != nil {
+				return err
+			}
+		}
+		venue.CoverImageID = remap.mediaPtr(venue.CoverImageID)
+		venue.LiveRevisionID, venue.DraftRevisionID = uuid.Nil, nil
+		if err := tx.Omit(clause.Associations).Create(&venue).Error; err != nil {
+			return err
+		}
+
+		var gallery []entity.VenueGalleryItem
+		for _, item := range src.Gallery {
+			mediaID, ok := remap.mediaID(item.MediaAssetID)
+			if !ok {
+				continue
+			}
+			gallery = append(gallery, entity.VenueGalleryItem{
+				VenueID:      venue.ID,
+				MediaAssetID: mediaID,
+				SortOrder:    item.SortOrder,
+				Caption:      item.Caption,
+				IsVisible:    item.IsVisible,
+				IsFeatured:   item.IsFeatured,
+			})
+		}
+		if len(gallery) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&gallery).Error; err != nil {
+				return err
+			}
+		}
+
+		// 2. Areas (FloorID ikut lineage floor baru, node.AreaID di-remap di cloneGraph).
+		// Lineage floor yang disalin dipesan dulu; area di lantai lain (mis. hanya ada di draft) tidak ikut disalin.
+		for _, rev := range src.Revisions {
+			for _, floor := range rev.Floors {
+				remap.stableID(floor.StableID())
+			}
+		}
+		for _, area := range src.Areas {
+			floorID, ok := remap.copiedStableID(area.FloorID)
+			if !ok {
+				continue
+			}
+			newArea := entity.Area{
+				BaseEntity:   entity.BaseEntity{ID: uuid.New()},
+				VenueID:      venue.ID,
+				FloorID:      floorID,
+				Name:         area.Name,
+				Slug:         area.Slug,
+				Label:        area.Label,
+				Description:  area.Description,
+				Latitude:     area.Latitude,
+				Longitude:    area.Longitude,
+				MapX:         area.MapX,
+				MapY:         area.MapY,
+				Category:     area.Category,
+				CoverImageID: remap.mediaPtr(area.CoverImageID),
+			}
+			if err := tx.Omit(clause.Associations).Create(&newArea).Error; err != nil {
+				return err
+			}
+			remap.areas[area.ID] = newArea.ID
+		}
+
+		var areaGallery []entity.AreaGalleryItem
+		for _, item := range src.AreaGallery {
+			areaID, ok := remap.areas[item.AreaID]
+			if !ok {
+				continue
+			}
+			mediaID, ok := remap.mediaID(item.MediaAssetID)
+			if !ok {
+				continue
+			}
+			areaGallery = append(areaGallery, entity.AreaGalleryItem{
+				AreaID:       areaID,
+				MediaAssetID: mediaID,
+				SortOrder:    item.SortOrder,
+				Caption:      item.Caption,
+				IsVisible:    item.IsVisible,
+			})
+		}
+		if len(areaGallery) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&areaGallery).Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. Revisions
+		var live *entity.GraphRevision
+		for i := range src.Revisions {
+			rev := &src.Revisions[i]
+			newRev, err := copyRevision(tx, rev, &venue, rev.Status, src.CreatedByID, remap)
+			if err != nil {
+				return err
+			}
+			if rev.ID == src.LiveRevisionID {
+				venue.LiveRevisionID = newRev.ID
+				live = rev
+			}
+			if src.DraftRevisionID != nil && rev.ID == *src.DraftRevisionID {
+				venue.DraftRevisionID = &newRev.ID
+			}
+		}
+		if live == nil {
+			return errors.New("live revision is not part of the copy")
+		}
+
+		// Venue hasil copy selalu punya draft yang bisa langsung diedit
+		if venue.DraftRevisionID == nil {
+			draft, err := copyRevision(tx, live, &venue, entity.StatusDraft, src.CreatedByID, remap)
+			if err != nil {
+				return err
+			}
+			venue.DraftRevisionID = &draft.ID
+		}
+
+		return tx.Model(&venue).Updates(map[string]interface{}{
+			"live_revision_id":  venue.LiveRevisionID,
+			"draft_revision_id": venue.DraftRevisionID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+func copyRevision(tx *gorm.DB, src *entity.GraphRevision, venue *entity.Venue, status entity.RevisionStatus, createdBy uuid.UUID, remap *graphRemap) (*entity.GraphRevision, error) {
+	newRev := entity.GraphRevision{
+		OrganizationID: venue.OrganizationID,
+		CreatedByID:    createdBy,
+		VenueID:        venue.ID,
+		Status:         status,
+		Note:           src.Note,
+	}
+	if err := tx.Omit(clause.Associations).Create(&newRev).Error; err != nil {
+		return nil, err
+	}
+	return &newRev, cloneGraph(tx, src, &newRev, remap)
+}
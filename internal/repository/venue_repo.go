@@ -25,6 +25,7 @@ func NewVenueRepository(db *gorm.DB) VenueRepository {
 func (r *venueRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Venue, error) {
 	var venue entity.Venue
 	err := r.db.WithContext(ctx).
+		Preload("Organization"). // Slug organisasi untuk URL publik
 		Preload("CoverImage").
 		Preload("Gallery.MediaAsset").
 		Preload("PointsOfInterest"). // Load Area/POI
@@ -176,10 +177,47 @@ func (r *venueRepo) buildFilterQuery(ctx context.Context, f models.VenueFilter)
 	return db
 }
 
-func (r *venueRepo) GetLiveManifestData(venueSlug string) (*entity.Venue, error) {
+// whereVenueSlug: Slug venue hanya unik per organisasi, jadi lookup publik selalu lewat slug organisasi
+func (r *venueRepo) whereVenueSlug(db *gorm.DB, orgSlug, venueSlug string) *gorm.DB {
+	orgID := r.db.Model(&entity.Organization{}).Select("id").Where("slug = ?", orgSlug)
+	return db.Where("organization_id = (?) AND slug = ?", orgID, venueSlug)
+}
+
+// GetOrgSlugsByVenueSlug: Slug organisasi pemilik venue ber-slug tersebut (untuk route manifest lama tanpa slug organisasi)
+func (r *venueRepo) GetOrgSlugsByVenueSlug(ctx context.Context, slug string, limit int) ([]string, error) {
+	var orgSlugs []string
+	err := r.db.WithContext(ctx).
+		Model(&entity.Venue{}).
+		Joins("JOIN organizations ON organizations.id = venues.organization_id").
+		Where("venues.slug = ?", slug).
+		Order("organizations.slug").
+		Limit(limit).
+		Pluck("organizations.slug", &orgSlugs).Error
+	return orgSlugs, err
+}
+
+// GetManifestInfo: Query ringan (tanpa preload) untuk cek akses & versi manifest sebelum ambil data lengkap
+func (r *venueRepo) GetManifestInfo(ctx context.Context, orgSlug, slug string) (*entity.Venue, error) {
 	var venue entity.Venue
+	err := r.whereVenueSlug(r.db.WithContext(ctx), orgSlug, slug).
+		Select("id", "organization_id", "slug", "visibility", "live_revision_id", "draft_revision_id", "updated_at").
+		First(&venue).Error
 
-	err := r.db.
+	if err != nil {
+		return nil, err
+	}
+
+	if venue.LiveRevisionID == uuid.Nil {
+		return nil, errors.New("venue has no published version yet")
+	}
+
+	return &venue, nil
+}
+
+func (r *venueRepo) GetLiveManifestData(orgSlug, venueSlug string) (*entity.Venue, error) {
+	var venue entity.Venue
+
+	err := r.whereVenueSlug(r.db, orgSlug, venueSlug).
 		Preload("LiveRevision").
 		Preload("LiveRevision.Floors").
 		Preload("LiveRevision.Floors.MapImage").
@@ -190,7 +228,6 @@ func (r *venueRepo) GetLiveManifestData(venueSlug string) (*entity.Venue, error)
 		Preload("LiveRevision.Floors.Nodes.OutgoingEdges", func(db *gorm.DB) *gorm.DB {
 			return db.Where("is_active = ?", true)
 		}).
-		Where("slug = ?", venueSlug).
 		First(&venue).Error
 
 	if err != nil {
@@ -204,6 +241,60 @@ func (r *venueRepo) GetLiveManifestData(venueSlug string) (*entity.Venue, error)
 	return &venue, nil
 }
 
+func (r *venueRepo) GetDraftManifestData(orgSlug, venueSlug string) (*entity.Venue, error) {
+	var venue entity.Venue
+
+	// Struktur preload sama dengan Live, tapi sumbernya DRAFT (untuk preview via share link)
+	err := r.whereVenueSlug(r.db, orgSlug, venueSlug).
+		Preload("DraftRevision").
+		Preload("DraftRevision.Floors").
+		Preload("DraftRevision.Floors.MapImage").
+		Preload("DraftRevision.Floors.Nodes", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("Panorama").Where("is_active = ?", true)
+		}).
+		Preload("DraftRevision.Floors.Nodes.Area").
+		Preload("DraftRevision.Floors.Nodes.OutgoingEdges", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true)
+		}).
+		First(&venue).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	if venue.DraftRevisionID == nil || venue.DraftRevision == nil {
+		return nil, errors.New("venue has no draft to preview")
+	}
+
+	return &venue, nil
+}
+
+// GetRevisionManifestData: Data manifest lengkap untuk revisi tertentu (live lama / archived) milik venue,
+// dipakai sebagai titik awal perhitungan delta manifest
+func (r *venueRepo) GetRevisionManifestData(ctx context.Context, venueID, revisionID uuid.UUID) (*entity.GraphRevision, error) {
+	var revision entity.GraphRevision
+
+	err := r.db.WithContext(ctx).
+		Preload("Floors").
+		Preload("Floors.MapImage").
+		Preload("Floors.Nodes", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("Panorama").Where("is_active = ?", true)
+		}).
+		Preload("Floors.Nodes.Area").
+		Preload("Floors.Nodes.OutgoingEdges", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true)
+		}).
+		Where("id = ? AND venue_id = ?", revisionID, venueID).
+		Where("status IN ?", []entity.RevisionStatus{entity.StatusPublished, entity.StatusArchived}).
+		First(&revision).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
 func (r *venueRepo) GetDraftDataUUID(venueID uuid.UUID) (*entity.GraphRevision, error) {
 	var venue entity.Venue
 	if err := r.db.First(&venue, "id = ?", venueID).Error; err != nil {
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PasswordResetTokenTTL     = time.Hour
+	EmailVerificationTokenTTL = 48 * time.Hour
+	MinPasswordLength         = 8
+
+	// PasswordResetSendTimeout: Batas waktu background job pembuatan token + kirim email reset
+	PasswordResetSendTimeout = 30 * time.Second
+)
+
+var (
+	// ErrInvalidEmailToken: Token reset / verifikasi tidak dikenal, sudah dipakai atau kedaluwarsa
+	ErrInvalidEmailToken = errors.New("invalid or expired token")
+	// ErrEmailAlreadyVerified: Tidak perlu mengirim ulang email verifikasi
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	// ErrPasswordTooShort: Password baru di bawah MinPasswordLength
+	ErrPasswordTooShort = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+)
+
+type accountService struct {
+	userRepo    repository.UserRepository
+	tokenRepo   repository.UserTokenRepository
+	refreshRepo repository.RefreshTokenRepository
+	mailer      Mailer
+	appBaseURL  string
+}
+
+// NewAccountService: appBaseURL = URL frontend, dasar link di email (mis. https://app.inspacemap.com)
+func NewAccountService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	mailer Mailer,
+	appBaseURL string,
+) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		refreshRepo: refreshRepo,
+		mailer:      mailer,
+		appBaseURL:  appBaseURL,
+	}
+}
+
+// RequestPasswordReset: Selalu berhasil untuk email yang tidak terdaftar supaya
+// endpoint tidak bisa dipakai untuk menebak akun. Token & email dibuat di background
+// supaya waktu respons email terdaftar sama dengan yang tidak; kegagalan hanya di-log.
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return nil
+	}
+
+	// Context request selesai begitu respons dikirim, jadi job memakai timeout sendiri
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), PasswordResetSendTimeout)
+	go func() {
+		defer cancel()
+		if err := s.sendPasswordReset(jobCtx, user); err != nil {
+			log.Printf("password reset email to user %s failed: %v", user.ID, err)
+		}
+	}()
+	return nil
+}
+
+func (s *accountService) sendPasswordReset(ctx context.Context, user *entity.User) error {
+	token, err := s.issueToken(ctx, user.ID, entity.TokenPurposePasswordReset, PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	data := emailData{Name: user.FullName, Link: emailLink(s.appBaseURL, "/reset-password", token), ExpiresIn: "1 hour"}
+	return sendEmail(ctx, s.mailer, user.Email, passwordResetEmail, data)
+}
+
+// ResetPassword: Token hanya berlaku sekali. Semua sesi dicabut karena password lama
+// mungkin sudah bocor; link dari inbox sekaligus membuktikan email milik user.
+func (s *accountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if len(newPassword) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+	// Hash lebih dulu supaya kegagalan di sini tidak menghanguskan token
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	user, err := s.consumeToken(ctx, entity.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hashed
+	user.IsEmailVerified = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return s.refreshRepo.RevokeAllByUser(ctx, user.ID)
+}
+
+func (s *accountService) SendEmailVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if user.IsEmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueToken(ctx, user.ID, entity.TokenPurposeEmailVerification, EmailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	data := emailData{Name: user.FullName, Link: emailLink(s.appBaseURL, "/verify-email", token), ExpiresIn: "48 hours"}
+	return sendEmail(ctx, s.mailer, user.Email, emailVerificationEmail, data)
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	user, err := s.consumeToken(ctx, entity.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified {
+		return nil
+	}
+	user.IsEmailVerified = true
+	return s.userRepo.Update(ctx, user)
+}
+
+// issueToken: Token lama dengan tujuan yang sama dibatalkan, hanya link terbaru yang berlaku
+func (s *accountService) issueToken(ctx context.Context, userID uuid.UUID, purpose entity.UserTokenPurpose, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.InvalidateByUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	raw, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	token := &entity.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// consumeToken: Tandai token terpakai lalu kembalikan pemiliknya
+func (s *accountService) consumeToken(ctx context.Context, purpose entity.UserTokenPurpose, raw string) (*entity.User, error) {
+	if raw == "" {
+		return nil, ErrInvalidEmailToken
+	}
+	token, err := s.tokenRepo.GetByHash(ctx, purpose, utils.HashToken(raw))
+	if err != nil || !token.IsUsable(time.Now()) {
+		return nil, ErrInvalidEmailToken
+	}
+
+	consumed, err := s.tokenRepo.Consume(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidEmailToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, ErrInvalidEmailToken
+	}
+	return user, nil
+}
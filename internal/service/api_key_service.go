@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeyPrefix: Awalan semua key supaya mudah dikenali (mis. oleh secret scanner)
+	APIKeyPrefix = "ism_"
+	// apiKeyDisplayLength: Panjang awal key yang disimpan & ditampilkan di list
+	apiKeyDisplayLength = 12
+	// apiKeyTouchInterval: last_used_at cukup akurat per menit, tidak perlu ditulis di setiap request
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKey: Key tidak dikenal, dicabut, kedaluwarsa atau organisasinya nonaktif
+	ErrInvalidAPIKey = errors.New("invalid or expired API key")
+	// ErrAPIKeyNotFound: Key tidak ada atau milik organisasi lain
+	ErrAPIKeyNotFound = fmt.Errorf("api key %w", ErrNotFound)
+	// ErrInvalidAPIKeyRequest: Nama / scope / masa berlaku tidak valid
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+	// ErrAPIKeyScopeNotHeld: Pembuat key tidak boleh memberi scope yang tidak ia miliki
+	ErrAPIKeyScopeNotHeld = errors.New("cannot grant a scope you do not have")
+)
+
+type apiKeyService struct {
+	repo         repository.ApiKeyRepository
+	permResolver PermissionResolver
+}
+
+func NewApiKeyService(repo repository.ApiKeyRepository, permResolver PermissionResolver) ApiKeyService {
+	return &apiKeyService{repo: repo, permResolver: permResolver}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, orgID, creatorID uuid.UUID, creatorPerms []string, req models.CreateApiKeyRequest) (*models.ApiKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if len(name) < 3 {
+		return nil, fmt.Errorf("%w: name must be at least 3 characters", ErrInvalidAPIKeyRequest)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyRequest)
+	}
+	scopes, err := validateScopes(req.Scopes, creatorPerms)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	raw := APIKeyPrefix + secret
+
+	key := &entity.ApiKey{
+		OrganizationID:  orgID,
+		Name:            name,
+		Prefix:          raw[:apiKeyDisplayLength],
+		KeyHash:         utils.HashToken(raw),
+		Scopes:          scopes,
+		CreatedByUserID: creatorID,
+		ExpiresAt:       req.ExpiresAt,
+		IsActive:        true,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &models.ApiKeyResponse{ApiKeyDetail: mapApiKey(key), Key: raw}, nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context, orgID uuid.UUID) ([]models.ApiKeyDetail, error) {
+	keys, err := s.repo.GetByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	details := make([]models.ApiKeyDetail, 0, len(keys))
+	for i := range keys {
+		details = append(details, mapApiKey(&keys[i]))
+	}
+	return details, nil
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, orgID, keyID uuid.UUID) error {
+	n, err := s.repo.Revoke(ctx, orgID, keyID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey: Scope yang sudah tidak ada di registry diabaikan. Key tidak pernah melebihi
+// permission pembuatnya saat ini: role diturunkan = scope ikut menyempit, keluar organisasi = key mati.
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.ApiKeyPrincipal, error) {
+	if !strings.HasPrefix(rawKey, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.GetByHash(ctx, utils.HashToken(rawKey))
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if !key.IsUsable(now) || key.Organization == nil || !key.Organization.IsActive {
+		return nil, ErrInvalidAPIKey
+	}
+	creatorPerms, isMember, err := s.permResolver.ResolvePermissions(ctx, key.CreatedByUserID, key.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Printf("api key %s: update last_used_at failed: %v", key.ID, err)
+		}
+	}
+
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		if entity.IsRegisteredPermission(entity.PermissionKey(scope)) && slices.Contains(creatorPerms, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return &models.ApiKeyPrincipal{KeyID: key.ID, OrganizationID: key.OrganizationID, Scopes: scopes}, nil
+}
+
+// validateScopes: Scope harus ada di registry dan dimiliki pembuat key (tanpa duplikat)
+func validateScopes(requested, held []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if !entity.IsRegisteredPermission(entity.PermissionKey(scope)) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+		if !slices.Contains(held, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyScopeNotHeld, scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func mapApiKey(key *entity.ApiKey) models.ApiKeyDetail {
+	return models.ApiKeyDetail{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		IsActive:   key.IsActive,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
 
 	"github.com/google/uuid"
 )
@@ -14,23 +15,37 @@ type areaService struct {
 	areaRepo    repository.AreaRepository
 	galleryRepo repository.AreaGalleryRepository
 	nodeRepo    repository.GraphRepository // Butuh ini untuk cari Nearest Node
+	venueRepo   repository.VenueRepository // Cek visibility venue untuk detail area publik
+	ownership   repository.OwnershipRepository
+	audit       AuditService
 }
 
 func NewAreaService(
 	aRepo repository.AreaRepository,
 	gRepo repository.AreaGalleryRepository,
 	nRepo repository.GraphRepository, // Asumsi ada method find nearest
+	vRepo repository.VenueRepository,
+	ownership repository.OwnershipRepository,
+	audit AuditService,
 ) AreaService {
 	return &areaService{
 		areaRepo:    aRepo,
 		galleryRepo: gRepo,
 		nodeRepo:    nRepo,
+		venueRepo:   vRepo,
+		ownership:   ownership,
+		audit:       audit,
 	}
 }
 
 func (s *areaService) CreateArea(ctx context.Context, req models.CreateAreaRequest) (*models.IDResponse, error) {
-	// TODO: Validasi VenueID (biasanya dari URL param di handler, lalu inject ke struct req atau argumen terpisah)
-	// Asumsi req.VenueID sudah diisi dari handler
+	// Area tanpa lantai tidak bisa dicek kepemilikannya
+	if req.FloorID == nil {
+		return nil, errors.New("floor_id is required")
+	}
+	if err := s.requireOwnedRefs(ctx, req); err != nil {
+		return nil, err
+	}
 
 	// 1. Mapping DTO -> Entity
 	area := entity.Area{
@@ -57,6 +72,13 @@ func (s *areaService) CreateArea(ctx context.Context, req models.CreateAreaReque
 }
 
 func (s *areaService) UpdateArea(ctx context.Context, id uuid.UUID, req models.CreateAreaRequest) error {
+	if err := requireOwned(ctx, "area", s.ownership.AreaBelongsTo, id); err != nil {
+		return err
+	}
+	if err := s.requireOwnedRefs(ctx, req); err != nil {
+		return err
+	}
+
 	// 1. Get Existing
 	area, err := s.areaRepo.GetByID(ctx, id)
 	if err != nil {
@@ -79,17 +101,66 @@ func (s *areaService) UpdateArea(ctx context.Context, id uuid.UUID, req models.C
 	return s.areaRepo.Update(ctx, area)
 }
 
+// requireOwnedRefs: Lantai & cover image yang dirujuk area harus milik organisasi yang sama
+func (s *areaService) requireOwnedRefs(ctx context.Context, req models.CreateAreaRequest) error {
+	if req.FloorID != nil {
+		if err := requireOwned(ctx, "floor", s.ownership.FloorBelongsTo, *req.FloorID); err != nil {
+			return err
+		}
+	}
+	if req.CoverImageID != nil {
+		if err := requireOwned(ctx, "cover image", s.ownership.MediaBelongsTo, *req.CoverImageID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *areaService) DeleteArea(ctx context.Context, id uuid.UUID) error {
-	return s.areaRepo.Delete(ctx, id)
+	if err := requireOwned(ctx, "area", s.ownership.AreaBelongsTo, id); err != nil {
+		return err
+	}
+
+	// Snapshot hanya dibaca jika audit aktif; gagal dibaca tidak membatalkan penghapusan
+	var before map[string]interface{}
+	if s.audit != nil {
+		if area, err := s.areaRepo.GetByID(ctx, id); err == nil {
+			before = map[string]interface{}{
+				"name":     area.Name,
+				"category": area.Category,
+				"venue_id": area.VenueID,
+				"floor_id": area.FloorID,
+			}
+		}
+	}
+
+	if err := s.areaRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "AREA_DELETE", "Area", id, before, nil)
+	return nil
 }
 
-// GetAreaDetail: Dipanggil saat user klik Pin di Peta Mobile App
-func (s *areaService) GetAreaDetail(ctx context.Context, id uuid.UUID) (*models.AreaDetail, error) {
+// GetAreaDetail: Dipanggil saat user klik Pin di Peta Mobile App.
+// Endpoint publik: aturan aksesnya sama dengan manifest venue pemilik area.
+func (s *areaService) GetAreaDetail(ctx context.Context, id uuid.UUID, access models.ManifestAccess) (*models.AreaDetail, error) {
+	share, err := parseShareAccess(access)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. Ambil Info Dasar
 	area, err := s.areaRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	venue, err := s.venueRepo.GetByID(ctx, area.VenueID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAreaAccess(venue, share, access.OrganizationID); err != nil {
+		return nil, err
+	}
 
 	// 2. Ambil Gallery (Foto-foto ruangan)
 	galleryItems, _ := s.galleryRepo.GetByAreaID(ctx, id)
@@ -122,6 +193,9 @@ func (s *areaService) GetAreaDetail(ctx context.Context, id uuid.UUID) (*models.
 
 // GetVenueAreas: List Pin untuk Peta Google Maps
 func (s *areaService) GetVenueAreas(ctx context.Context, venueID uuid.UUID) ([]models.AreaPinDetail, error) {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return nil, err
+	}
 	areas, err := s.areaRepo.GetByVenueID(ctx, venueID)
 	if err != nil {
 		return nil, err
@@ -145,3 +219,12 @@ func (s *areaService) GetVenueAreas(ctx context.Context, venueID uuid.UUID) ([]m
 	}
 	return pins, nil
 }
+
+// checkAreaAccess: Tanpa share link hanya area dari venue yang sudah dipublish dan boleh dilihat requester.
+// Share link untuk venue tersebut membuka area-nya; venue yang belum dipublish hanya lewat share link draft.
+func checkAreaAccess(venue *entity.Venue, share *utils.ShareLinkPayload, requesterOrgID uuid.UUID) error {
+	if venue.LiveRevisionID == uuid.Nil && (share == nil || !share.IncludeDraft) {
+		return errors.New("venue not found or not published")
+	}
+	return checkManifestAccess(venue, share, requesterOrgID)
+}
@@ -2,22 +2,44 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// auditQueueSize: Batas log yang menunggu ditulis
+	auditQueueSize    = 1024
+	auditWriteTimeout = 5 * time.Second
+)
+
 type auditService struct {
 	auditRepo repository.AuditLogRepository
+	queue     chan entity.AuditLog
+	done      chan struct{}
+	mu        sync.RWMutex // Menjaga send vs close antrean
+	closed    bool
+	dropped   atomic.Int64
 }
 
 func NewAuditService(repo repository.AuditLogRepository) AuditService {
-	return &auditService{
+	s := &auditService{
 		auditRepo: repo,
+		queue:     make(chan entity.AuditLog, auditQueueSize),
+		done:      make(chan struct{}),
 	}
+	go s.worker()
+	return s
 }
 
 // 1. GetActivityLogs (Read)
@@ -64,28 +86,112 @@ func (s *auditService) GetActivityLogs(ctx context.Context, orgID uuid.UUID, req
 }
 
 // 2. LogActivity (Write - Async)
+// Non-blocking: masuk antrean terbatas; saat antrean penuh log dibuang (dicatat di console) supaya
+// lonjakan mutasi tidak menahan request atau menumpuk goroutine.
 func (s *auditService) LogActivity(ctx context.Context, req models.CreateAuditLogRequest) {
-	// Jalankan di Goroutine agar Non-Blocking
-	// Kita copy context atau buat context background baru agar tidak cancel saat HTTP request selesai
-	go func(payload models.CreateAuditLogRequest) {
-		// Buat Entity
-		logEntry := entity.AuditLog{
-			OrganizationID: payload.OrganizationID,
-			UserID:         payload.UserID,
-			Action:         payload.Action,
-			Entity:         payload.Entity,
-			EntityID:       payload.EntityID,
-			Details:        payload.Details,
-			IPAddress:      payload.IPAddress,
-			UserAgent:      payload.UserAgent,
-			// CreatedAt otomatis
+	logEntry := entity.AuditLog{
+		OrganizationID: req.OrganizationID,
+		UserID:         req.UserID,
+		Action:         truncate(req.Action, 50),
+		Entity:         truncate(req.Entity, 50),
+		EntityID:       truncate(req.EntityID, 50),
+		Details:        req.Details,
+		IPAddress:      truncate(req.IPAddress, 50),
+		UserAgent:      truncate(req.UserAgent, 255),
+		// CreatedAt otomatis
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		log.Printf("[AUDIT ERROR] Service closed, dropping %s %s", req.Action, req.EntityID)
+		return
+	}
+	select {
+	case s.queue <- logEntry:
+	default:
+		if n := s.dropped.Add(1); n == 1 || n%100 == 0 {
+			log.Printf("[AUDIT ERROR] Queue full, %d log(s) dropped so far", n)
 		}
+	}
+}
+
+// 3. RecordChange (Snapshot dari service)
+func (s *auditService) RecordChange(ctx context.Context, action, entityName, entityID string, before, after any) {
+	actor, ok := utils.AuditActorFromContext(ctx)
+	orgID := utils.OrgIDFromContext(ctx)
+	if !ok || orgID == uuid.Nil {
+		return // Bukan dari request yang diaudit (job background, seeder)
+	}
+
+	details := map[string]interface{}{"request_id": actor.RequestID}
+	if before != nil {
+		details["before"] = auditSnapshot(before)
+	}
+	if after != nil {
+		details["after"] = auditSnapshot(after)
+	}
+	if actor.APIKeyID != uuid.Nil {
+		details["api_key_id"] = actor.APIKeyID.String()
+	}
+
+	s.LogActivity(ctx, models.CreateAuditLogRequest{
+		OrganizationID: orgID,
+		UserID:         actor.UserID,
+		Action:         action,
+		Entity:         entityName,
+		EntityID:       entityID,
+		Details:        details,
+		IPAddress:      actor.IPAddress,
+		UserAgent:      actor.UserAgent,
+	})
+}
 
-		// Simpan (Gunakan context.Background karena request asli mungkin sudah selesai)
-		if err := s.auditRepo.Create(context.Background(), &logEntry); err != nil {
+func (s *auditService) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	<-s.done
+}
+
+// worker: Satu penulis; context request sudah selesai saat log ditulis, jadi pakai context sendiri
+func (s *auditService) worker() {
+	defer close(s.done)
+	for logEntry := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		if err := s.auditRepo.Create(ctx, &logEntry); err != nil {
 			// Jika gagal log, kita cuma bisa print error ke console server
 			// Tidak boleh membatalkan transaksi bisnis utama
 			log.Printf("[AUDIT ERROR] Failed to write log: %v", err)
 		}
-	}(req)
+		cancel()
+	}
+}
+
+// recordChange: Dipanggil service setelah mutasi berhasil; audit nil = audit dimatikan (test / tool)
+func recordChange(ctx context.Context, audit AuditService, action, entityName string, entityID uuid.UUID, before, after any) {
+	if audit == nil {
+		return
+	}
+	audit.RecordChange(ctx, action, entityName, entityID.String(), before, after)
+}
+
+// auditSnapshot: Di-serialize saat dicatat supaya perubahan objek setelahnya tidak ikut terekam
+func auditSnapshot(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("snapshot unavailable: %v", err))
+	}
+	return data
+}
+
+// truncate: Batas panjang kolom varchar; potongan rune di ujung dibuang agar tetap UTF-8 valid
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotOrgMember: Organisasi tidak ada atau user bukan anggotanya (tidak dibedakan)
+var ErrNotOrgMember = fmt.Errorf("organization %w", ErrNotFound)
+
+// loadUserWithMemberships: User + membership (organisasi, role, permission) dari database, bukan dari token
+func (s *authService) loadUserWithMemberships(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	memberships, err := s.orgMemberRepo.GetMembersByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Memberships = memberships
+	return user, nil
+}
+
+// activePermissions: Permission dihitung ulang dari role membership saat ini
+func (s *authService) activePermissions(ctx context.Context, userID, orgID uuid.UUID) ([]string, error) {
+	perms, err := s.permRepo.GetByUserAndOrg(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(perms))
+	for _, p := range perms {
+		keys = append(keys, p.Key)
+	}
+	return keys, nil
+}
+
+func findMembership(user *entity.User, orgID uuid.UUID) *entity.OrganizationMember {
+	for i := range user.Memberships {
+		if user.Memberships[i].OrganizationID == orgID {
+			return &user.Memberships[i]
+		}
+	}
+	return nil
+}
+
+func (s *authService) GetMe(ctx context.Context, userID, activeOrgID uuid.UUID) (*models.MeResponse, error) {
+	user, err := s.loadUserWithMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.MeResponse{User: mapUserDetail(user), Permissions: []string{}}
+
+	// Membership bisa sudah dicabut setelah token terbit: jangan laporkan org yang tidak lagi diikuti
+	if m := findMembership(user, activeOrgID); m != nil {
+		perms, err := s.activePermissions(ctx, userID, activeOrgID)
+		if err != nil {
+			return nil, err
+		}
+		resp.ActiveOrganizationID = activeOrgID
+		resp.ActiveRoleName = m.Role.Name
+		resp.Permissions, resp.TwoFactorEnrollmentRequired = applyTwoFactorPolicy(&m.Organization, user, perms)
+	}
+	return resp, nil
+}
+
+// SwitchOrganization: Access token baru untuk organisasi lain milik user.
+// Sesi (refresh token family) ikut dipindah supaya refresh berikutnya tetap di organisasi ini.
+func (s *authService) SwitchOrganization(ctx context.Context, userID, sessionID, orgID uuid.UUID) (*models.AuthResponse, error) {
+	// Token tanpa sesi (mis. API key) tidak punya sesi yang bisa dipindah atau dicabut
+	if sessionID == uuid.Nil {
+		return nil, ErrSessionRevoked
+	}
+	user, err := s.loadUserWithMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	m := findMembership(user, orgID)
+	if m == nil {
+		return nil, ErrNotOrgMember
+	}
+
+	perms, err := s.activePermissions(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	perms, _ = applyTwoFactorPolicy(&m.Organization, user, perms)
+
+	// Sesi yang sudah dicabut tidak boleh menerbitkan token baru
+	n, err := s.refreshRepo.SetOrganization(ctx, userID, sessionID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if n != 1 {
+		return nil, ErrSessionRevoked
+	}
+
+	token, err := utils.GenerateToken(user.ID, user.Email, orgID, m.Role.Name, perms, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		AccessToken: token,
+		ExpiresIn:   int(utils.AccessTokenTTL.Seconds()),
+		User:        mapUserDetail(user),
+	}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
 	"inspacemap/backend/pkg/utils"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,11 @@ type authService struct {
 	orgMemberRepo  repository.OrganizationMemberRepository
 	invitationRepo repository.UserInvitationRepository
 	roleRepo       repository.RoleRepository
+	refreshRepo    repository.RefreshTokenRepository
+	permRepo       repository.PermissionRepository
+	permResolver   PermissionResolver
+	accounts       AccountService
+	twoFactor      TwoFactorService
 }
 
 func NewAuthService(
@@ -26,6 +32,11 @@ func NewAuthService(
 	orgMemberRepo repository.OrganizationMemberRepository,
 	invitationRepo repository.UserInvitationRepository,
 	roleRepo repository.RoleRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	permRepo repository.PermissionRepository,
+	permResolver PermissionResolver,
+	accounts AccountService,
+	twoFactor TwoFactorService,
 ) AuthService {
 	return &authService{
 		userRepo:       userRepo,
@@ -33,10 +44,15 @@ func NewAuthService(
 		orgMemberRepo:  orgMemberRepo,
 		invitationRepo: invitationRepo,
 		roleRepo:       roleRepo,
+		refreshRepo:    refreshRepo,
+		permRepo:       permRepo,
+		permResolver:   permResolver,
+		accounts:       accounts,
+		twoFactor:      twoFactor,
 	}
 }
 
-func (s *authService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
+func (s *authService) Login(ctx context.Context, req models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, errors.New("invalid email or password")
@@ -47,11 +63,10 @@ func (s *authService) Login(ctx context.Context, req models.LoginRequest) (*mode
 		return nil, errors.New("invalid email or password")
 	}
 	
-	token, _ := s.generateAuthResponse(user)
-	return token, nil
+	return s.sessionOrChallenge(ctx, user, uuid.Nil, client)
 }
 
-func (s *authService) Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error) {
+func (s *authService) Register(ctx context.Context, req models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 
 	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
@@ -64,7 +79,7 @@ func (s *authService) Register(ctx context.Context, req models.RegisterRequest)
 	if err := s.orgRepo.Create(ctx, &newOrg); err != nil {
 		return nil, err
 	}
-	ownerRole, err := s.roleRepo.GetByName(ctx, "Owner")
+	ownerRole, err := s.roleRepo.GetByName(ctx, entity.SystemRoleOwner)
 	if err != nil {
 		return nil, errors.New("system error: owner role not found")
 	}
@@ -93,19 +108,32 @@ func (s *authService) Register(ctx context.Context, req models.RegisterRequest)
 		return nil, err
 	}
 
+	// Registrasi tetap berhasil walau email gagal terkirim; user bisa minta kirim ulang
+	if err := s.accounts.SendEmailVerification(ctx, newUser.ID); err != nil {
+		log.Printf("verification email for user %s failed: %v", newUser.ID, err)
+	}
+
 	fullUser, _ := s.userRepo.GetByEmail(ctx, newUser.Email)
-	return s.generateAuthResponse(fullUser)
+	return s.startSession(ctx, fullUser, uuid.Nil, client)
 }
 
-func (s *authService) AcceptInvitation(ctx context.Context, req models.AcceptInviteRequest) (*models.AuthResponse, error) {
+func (s *authService) AcceptInvitation(ctx context.Context, req models.AcceptInviteRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 
-	invite, err := s.invitationRepo.GetByToken(ctx, req.Token)
+	invite, err := s.invitationRepo.GetByToken(ctx, utils.HashToken(req.Token))
 	if err != nil {
-		return nil, errors.New("invalid or expired invitation token")
+		return nil, ErrInvalidInvitation
 	}
 
+	switch invite.Status {
+	case entity.InvitationAccepted:
+		return nil, ErrInvitationUsed
+	case entity.InvitationRevoked:
+		return nil, ErrInvitationRevoked
+	case entity.InvitationExpired:
+		return nil, ErrInvitationExpired
+	}
 	if invite.ExpiresAt.Before(time.Now()) {
-		return nil, errors.New("invitation expired")
+		return nil, ErrInvitationExpired
 	}
 
 	var targetUserID uuid.UUID
@@ -137,86 +165,95 @@ func (s *authService) AcceptInvitation(ctx context.Context, req models.AcceptInv
 		UserID:         targetUserID,
 		RoleID:         invite.RoleID,
 	}
-	if err := s.orgMemberRepo.AddMember(ctx, &member); err != nil {
+	accepted, err := s.invitationRepo.Accept(ctx, invite.ID, &member)
+	if err != nil {
 		return nil, err
 	}
+	if !accepted {
+		// Request lain sudah memakai token ini lebih dulu
+		return nil, ErrInvitationUsed
+	}
+	// Hasil "bukan anggota" yang mungkin masih di-cache
+	s.permResolver.InvalidateMember(invite.OrganizationID, targetUserID)
 	fullUser, _ := s.userRepo.GetByEmail(ctx, invite.Email)
-	return s.generateAuthResponse(fullUser)
+	// Token undangan bukan faktor kedua: user lama yang memakai 2FA tetap diminta kode
+	return s.sessionOrChallenge(ctx, fullUser, uuid.Nil, client)
 }
 
-// func (s *authService) generateAuthResponse(user *entity.User) (*models.AuthResponse, error) {
-// 	var orgs []models.OrgMemberDetail
-// 	for _, m := range user.Memberships {
-// 		orgs = append(orgs, models.OrgMemberDetail{
-// 			OrganizationID: m.OrganizationID,
-// 			Name:           m.Organization.Name,
-// 			Slug:           m.Organization.Slug,
-// 			RoleName:       m.Role.Name,
-// 		})
-// 	}
-
-// 	userDetail := models.UserDetail{
-// 		ID:            user.ID,
-// 		Email:         user.Email,
-// 		FullName:      user.FullName,
-// 		AvatarURL:     user.AvatarURL,
-// 		Organizations: orgs,
-// 	}
-
-// 	token := "mock-jwt-token-xyz"
-
-//	return &models.AuthResponse{
-//		AccessToken: token,
-//		RefreshToken: "mock-refresh-token",
-//		ExpiresIn: 3600,
-//		User: userDetail,
-//	}, nil
-func (s *authService) generateAuthResponse(user *entity.User) (*models.AuthResponse, error) {
-	var orgs []models.OrgMemberDetail
-
-	// Cari Org Default (yang pertama) untuk dijadikan konteks token awal
-	var defaultOrgID uuid.UUID
-	var defaultRoleName string
+// generateAuthResponse: Access token + refresh token baru untuk sesi.
+// Konteks organisasi mengikuti session.OrganizationID jika user masih anggota, selain itu membership pertama.
+// Field token pada session diisi di sini; menyimpannya tanggung jawab pemanggil.
+func (s *authService) generateAuthResponse(user *entity.User, session *entity.RefreshToken) (*models.AuthResponse, error) {
+	// Cari Org aktif untuk dijadikan konteks token
+	var activeOrgID uuid.UUID
+	var activeRoleName string
 	var permissions []string
-
+	active := -1
 	for i, m := range user.Memberships {
-		orgs = append(orgs, models.OrgMemberDetail{
-			OrganizationID: m.OrganizationID,
-			Name:           m.Organization.Name,
-			Slug:           m.Organization.Slug,
-			RoleName:       m.Role.Name,
-		})
+		if m.OrganizationID == session.OrganizationID {
+			active = i
+			break
+		}
+	}
+	if active == -1 && len(user.Memberships) > 0 {
+		active = 0
+	}
 
-		// Set default ke index 0
-		if i == 0 {
-			defaultOrgID = m.OrganizationID
-			defaultRoleName = m.Role.Name
+	if active >= 0 {
+		m := user.Memberships[active]
+		activeOrgID = m.OrganizationID
+		activeRoleName = m.Role.Name
 
-			// Ambil Permission Strings dari Role
-			for _, p := range m.Role.Permissions {
-				permissions = append(permissions, p.Key)
-			}
+		// Ambil Permission Strings dari Role
+		for _, p := range m.Role.Permissions {
+			permissions = append(permissions, p.Key)
 		}
+		permissions, _ = applyTwoFactorPolicy(&m.Organization, user, permissions)
 	}
 
-	// Generate Token yang sudah "dibumbui" Permission
-	token, err := utils.GenerateToken(user.ID, user.Email, defaultOrgID, defaultRoleName, permissions)
+	refreshToken, err := utils.GenerateOpaqueToken()
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	session.OrganizationID = activeOrgID
+	session.TokenHash = utils.HashToken(refreshToken)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(utils.RefreshTokenTTL)
 
-	userDetail := models.UserDetail{
-		ID:            user.ID,
-		Email:         user.Email,
-		FullName:      user.FullName,
-		AvatarURL:     user.AvatarURL,
-		Organizations: orgs,
+	// Generate Token yang sudah "dibumbui" Permission
+	token, err := utils.GenerateToken(user.ID, user.Email, activeOrgID, activeRoleName, permissions, session.FamilyID)
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.AuthResponse{
 		AccessToken:  token,
-		RefreshToken: "mock-refresh",
-		ExpiresIn:    3600 * 24,
-		User:         userDetail,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		User:         mapUserDetail(user),
 	}, nil
 }
+
+func mapUserDetail(user *entity.User) models.UserDetail {
+	orgs := make([]models.OrgMemberDetail, 0, len(user.Memberships))
+	for _, m := range user.Memberships {
+		orgs = append(orgs, models.OrgMemberDetail{
+			OrganizationID: m.OrganizationID,
+			Name:           m.Organization.Name,
+			Slug:           m.Organization.Slug,
+			RoleID:         m.RoleID,
+			RoleName:       m.Role.Name,
+		})
+	}
+
+	return models.UserDetail{
+		ID:              user.ID,
+		Email:           user.Email,
+		FullName:        user.FullName,
+		AvatarURL:       user.AvatarURL,
+		IsEmailVerified: user.IsEmailVerified,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		Organizations:   orgs,
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidRefreshToken: Token tidak dikenal, sudah dicabut atau kedaluwarsa
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused: Token yang sudah dirotasi dipakai lagi, seluruh sesi dicabut
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, session revoked")
+	// ErrSessionNotFound: Sesi tidak ada atau milik user lain
+	ErrSessionNotFound = fmt.Errorf("session %w", ErrNotFound)
+	// ErrSessionRevoked: Access token masih berlaku tetapi sesi yang menerbitkannya sudah dicabut / logout
+	ErrSessionRevoked = errors.New("session has been revoked, please log in again")
+)
+
+// RefreshReuseGrace: Token yang baru saja dirotasi dan dipakai lagi dalam jendela ini dianggap request paralel
+// dari client yang sama (mis. dua tab), cukup ditolak tanpa mencabut sesi
+const RefreshReuseGrace = 20 * time.Second
+
+// startSession: Family refresh token baru untuk login / register / accept invite.
+// orgID = organisasi aktif awal; uuid.Nil (atau bukan anggota) = membership pertama.
+func (s *authService) startSession(ctx context.Context, user *entity.User, orgID uuid.UUID, client models.ClientInfo) (*models.AuthResponse, error) {
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	session := &entity.RefreshToken{
+		UserID:           user.ID,
+		OrganizationID:   orgID,
+		FamilyID:         uuid.New(),
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		SessionStartedAt: time.Now(),
+	}
+	resp, err := s.generateAuthResponse(user, session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// StartSession: Sesi untuk user yang sudah diautentikasi di luar Login (mis. SSO).
+// User dengan 2FA aktif tetap mendapat challenge, bukan sesi.
+func (s *authService) StartSession(ctx context.Context, userID, orgID uuid.UUID, client models.ClientInfo) (*models.AuthResponse, error) {
+	user, err := s.loadUserWithMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionOrChallenge(ctx, user, orgID, client)
+}
+
+// sessionOrChallenge: Faktor pertama sudah lolos; 2FA aktif = challenge untuk VerifyTwoFactor, selain itu sesi langsung
+func (s *authService) sessionOrChallenge(ctx context.Context, user *entity.User, orgID uuid.UUID, client models.ClientInfo) (*models.AuthResponse, error) {
+	if user == nil || !user.TwoFactorEnabled {
+		return s.startSession(ctx, user, orgID, client)
+	}
+	challenge, err := utils.GenerateTwoFactorChallenge(user.ID, orgID, TwoFactorChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int(TwoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// VerifyTwoFactor: Langkah kedua login; sesi baru dibuka setelah kode TOTP / recovery code valid
+func (s *authService) VerifyTwoFactor(ctx context.Context, req models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	userID, orgID, err := utils.ParseTwoFactorChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+	if err := s.twoFactor.VerifyCode(ctx, userID, req.Code); err != nil {
+		return nil, err
+	}
+	user, err := s.loadUserWithMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, user, orgID, client)
+}
+
+// Refresh: Tukar refresh token dengan pasangan token baru (rotasi).
+// Token lama yang dipakai ulang berarti token bocor: seluruh family dicabut, kecuali masih dalam RefreshReuseGrace.
+func (s *authService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.AuthResponse, error) {
+	current, err := s.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.RotatedAt != nil {
+		if current.RevokedAt == nil && time.Since(*current.RotatedAt) < RefreshReuseGrace {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, s.revokeReusedFamily(ctx, current)
+	}
+	if !current.IsActive(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.loadUserWithMemberships(ctx, current.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	next := &entity.RefreshToken{
+		UserID:           current.UserID,
+		OrganizationID:   current.OrganizationID,
+		FamilyID:         current.FamilyID,
+		UserAgent:        current.UserAgent,
+		IPAddress:        current.IPAddress,
+		SessionStartedAt: current.SessionStartedAt,
+	}
+	if client.UserAgent != "" {
+		next.UserAgent = client.UserAgent
+	}
+	if client.IPAddress != "" {
+		next.IPAddress = client.IPAddress
+	}
+
+	resp, err := s.generateAuthResponse(user, next)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.refreshRepo.Rotate(ctx, current, next)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Request paralel dengan token yang sama sudah merotasinya lebih dulu: yang kalah cukup 401
+		return nil, ErrInvalidRefreshToken
+	}
+	return resp, nil
+}
+
+func (s *authService) revokeReusedFamily(ctx context.Context, token *entity.RefreshToken) error {
+	if _, err := s.refreshRepo.RevokeFamily(ctx, token.UserID, token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout: Cabut sesi pemilik refresh token. Token tidak dikenal dianggap sudah logout.
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return nil
+	}
+	_, err = s.refreshRepo.RevokeFamily(ctx, token.UserID, token.FamilyID)
+	return err
+}
+
+func (s *authService) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]models.SessionDetail, error) {
+	tokens, err := s.refreshRepo.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.SessionDetail, 0, len(tokens))
+	for _, t := range tokens {
+		sessions = append(sessions, models.SessionDetail{
+			ID:         t.FamilyID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			StartedAt:  t.SessionStartedAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.FamilyID == currentSessionID,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession: Access token sesi tersebut tetap berlaku sampai kedaluwarsa (maks. utils.AccessTokenTTL)
+func (s *authService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	n, err := s.refreshRepo.RevokeFamily(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *authService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return s.refreshRepo.RevokeAllByUser(ctx, userID)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+)
+
+// emailTemplate: Subject + versi teks & HTML dari satu email transaksional
+type emailTemplate struct {
+	subject string
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+func newEmailTemplate(name, subject, text, html string) *emailTemplate {
+	return &emailTemplate{
+		subject: subject,
+		text:    texttemplate.Must(texttemplate.New(name).Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New(name).Parse(html)),
+	}
+}
+
+// emailData: Variabel yang tersedia di semua template
+type emailData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+// invitationEmailData: Variabel template undangan tim
+type invitationEmailData struct {
+	OrganizationName string
+	InviterName      string
+	RoleName         string
+	Link             string
+	ExpiresIn        string
+}
+
+// emailLink: Link frontend dengan token sebagai query parameter
+func emailLink(baseURL, path, token string) string {
+	return strings.TrimRight(baseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendEmail: Render template lalu kirim lewat mailer
+func sendEmail(ctx context.Context, mailer Mailer, to string, tpl *emailTemplate, data any) error {
+	var text, html bytes.Buffer
+	if err := tpl.text.Execute(&text, data); err != nil {
+		return err
+	}
+	if err := tpl.html.Execute(&html, data); err != nil {
+		return err
+	}
+	return mailer.Send(ctx, to, tpl.subject, text.String(), html.String())
+}
+
+var passwordResetEmail = newEmailTemplate("password_reset",
+	"Reset your InSpaceMap password",
+	`Hi {{.Name}},
+
+We received a request to reset your InSpaceMap password. Open the link below to choose a new one:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}} and can only be used once. If you did not request this, you can ignore this email.
+`,
+	`<p>Hi {{.Name}},</p>
+<p>We received a request to reset your InSpaceMap password.</p>
+<p><a href="{{.Link}}">Choose a new password</a></p>
+<p>The link expires in {{.ExpiresIn}} and can only be used once. If you did not request this, you can ignore this email.</p>
+`)
+
+var emailVerificationEmail = newEmailTemplate("email_verification",
+	"Verify your InSpaceMap email address",
+	`Hi {{.Name}},
+
+Please confirm that this is your email address by opening the link below:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}}.
+`,
+	`<p>Hi {{.Name}},</p>
+<p>Please confirm that this is your email address.</p>
+<p><a href="{{.Link}}">Verify email address</a></p>
+<p>The link expires in {{.ExpiresIn}}.</p>
+`)
+
+var invitationEmail = newEmailTemplate("invitation",
+	"You have been invited to join a team on InSpaceMap",
+	`Hi,
+
+{{if .InviterName}}{{.InviterName}} has invited you{{else}}You have been invited{{end}} to join {{.OrganizationName}} on InSpaceMap as {{.RoleName}}.
+
+Accept the invitation:
+
+{{.Link}}
+
+The invitation expires in {{.ExpiresIn}}. If you were not expecting it, you can ignore this email.
+`,
+	`<p>Hi,</p>
+<p>{{if .InviterName}}{{.InviterName}} has invited you{{else}}You have been invited{{end}} to join <strong>{{.OrganizationName}}</strong> on InSpaceMap as {{.RoleName}}.</p>
+<p><a href="{{.Link}}">Accept the invitation</a></p>
+<p>The invitation expires in {{.ExpiresIn}}. If you were not expecting it, you can ignore this email.</p>
+`)
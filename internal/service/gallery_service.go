@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
@@ -15,16 +15,23 @@ import (
 // =================================================================
 
 type venueGalleryService struct {
-	repo repository.VenueGalleryRepository
+	repo      repository.VenueGalleryRepository
+	ownership repository.OwnershipRepository
 }
 
-func NewVenueGalleryService(repo repository.VenueGalleryRepository) VenueGalleryService {
-	return &venueGalleryService{repo: repo}
+func NewVenueGalleryService(repo repository.VenueGalleryRepository, ownership repository.OwnershipRepository) VenueGalleryService {
+	return &venueGalleryService{repo: repo, ownership: ownership}
 }
 
 func (s *venueGalleryService) AddGalleryItems(ctx context.Context, req models.AddGalleryVenueItemsRequest) error {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, req.VenueID); err != nil {
+		return err
+	}
+
 	var items []entity.VenueGalleryItem
+	var mediaIDs []uuid.UUID
 	for _, item := range req.Items {
+		mediaIDs = append(mediaIDs, item.MediaAssetID)
 		items = append(items, entity.VenueGalleryItem{
 			VenueID:      req.VenueID,
 			MediaAssetID: item.MediaAssetID,
@@ -34,14 +41,24 @@ func (s *venueGalleryService) AddGalleryItems(ctx context.Context, req models.Ad
 			IsFeatured:   item.IsFeatured,
 		})
 	}
+	if err := requireOwned(ctx, "media asset", s.ownership.MediaBelongsTo, mediaIDs...); err != nil {
+		return err
+	}
 	return s.repo.AddVenueItems(ctx, items)
 }
 
 func (s *venueGalleryService) ReorderGallery(ctx context.Context, req models.ReorderVenueGalleryRequest) error {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, req.VenueID); err != nil {
+		return err
+	}
 	return s.repo.ReorderVenueItems(ctx, req.VenueID, req.MediaAssetIDs)
 }
 
 func (s *venueGalleryService) UpdateGalleryItem(ctx context.Context, req models.UpdateVenueGalleryItemRequest) error {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, req.VenueID); err != nil {
+		return err
+	}
+
 	// Strategi Fetch-Merge-Update untuk Partial Update yang aman
 	// 1. Ambil semua item (biasanya gallery tidak terlalu banyak, jadi ini aman)
 	existingItems, err := s.repo.GetByVenueID(ctx, req.VenueID)
@@ -59,7 +76,7 @@ func (s *venueGalleryService) UpdateGalleryItem(ctx context.Context, req models.
 	}
 
 	if targetItem == nil {
-		return errors.New("gallery item not found")
+		return fmt.Errorf("gallery item %w", ErrNotFound)
 	}
 
 	// 3. Merge perubahan (Hanya field yang dikirim user)
@@ -81,20 +98,30 @@ func (s *venueGalleryService) UpdateGalleryItem(ctx context.Context, req models.
 }
 
 func (s *venueGalleryService) RemoveGalleryItem(ctx context.Context, targetID, mediaID uuid.UUID) error {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, targetID); err != nil {
+		return err
+	}
 	return s.repo.RemoveVenueItem(ctx, targetID, mediaID)
 }
 
 type areaGalleryService struct {
-	repo repository.AreaGalleryRepository
+	repo      repository.AreaGalleryRepository
+	ownership repository.OwnershipRepository
 }
 
-func NewAreaGalleryService(repo repository.AreaGalleryRepository) AreaGalleryService {
-	return &areaGalleryService{repo: repo}
+func NewAreaGalleryService(repo repository.AreaGalleryRepository, ownership repository.OwnershipRepository) AreaGalleryService {
+	return &areaGalleryService{repo: repo, ownership: ownership}
 }
 
 func (s *areaGalleryService) AddGalleryItems(ctx context.Context, req models.AddAreaGalleryItemsRequest) error {
+	if err := requireOwned(ctx, "area", s.ownership.AreaBelongsTo, req.AreaID); err != nil {
+		return err
+	}
+
 	var items []entity.AreaGalleryItem
+	var mediaIDs []uuid.UUID
 	for _, item := range req.Items {
+		mediaIDs = append(mediaIDs, item.MediaAssetID)
 		items = append(items, entity.AreaGalleryItem{
 			AreaID:       req.AreaID,
 			MediaAssetID: item.MediaAssetID,
@@ -104,14 +131,24 @@ func (s *areaGalleryService) AddGalleryItems(ctx context.Context, req models.Add
 			// Area tidak punya IsFeatured
 		})
 	}
+	if err := requireOwned(ctx, "media asset", s.ownership.MediaBelongsTo, mediaIDs...); err != nil {
+		return err
+	}
 	return s.repo.AddAreaItems(ctx, items)
 }
 
 func (s *areaGalleryService) ReorderGallery(ctx context.Context, req models.ReorderAreaGalleryRequest) error {
+	if err := requireOwned(ctx, "area", s.ownership.AreaBelongsTo, req.AreaID); err != nil {
+		return err
+	}
 	return s.repo.ReorderAreaItems(ctx, req.AreaID, req.MediaAssetIDs)
 }
 
 func (s *areaGalleryService) UpdateGalleryItem(ctx context.Context, req models.UpdateAreaGalleryItemRequest) error {
+	if err := requireOwned(ctx, "area", s.ownership.AreaBelongsTo, req.AreaID); err != nil {
+		return err
+	}
+
 	// Strategi Fetch-Merge-Update
 	existingItems, err := s.repo.GetByAreaID(ctx, req.AreaID)
 	if err != nil {
@@ -127,7 +164,7 @@ func (s *areaGalleryService) UpdateGalleryItem(ctx context.Context, req models.U
 	}
 
 	if targetItem == nil {
-		return errors.New("gallery item not found")
+		return fmt.Errorf("gallery item %w", ErrNotFound)
 	}
 
 	if req.Caption != nil {
@@ -144,5 +181,8 @@ func (s *areaGalleryService) UpdateGalleryItem(ctx context.Context, req models.U
 }
 
 func (s *areaGalleryService) RemoveGalleryItem(ctx context.Context, targetID, mediaID uuid.UUID) error {
+	if err := requireOwned(ctx, "area", s.ownership.AreaBelongsTo, targetID); err != nil {
+		return err
+	}
 	return s.repo.RemoveAreaItem(ctx, targetID, mediaID)
 }
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"math"
+)
+
+const earthRadiusMeters = 6378137.0 // WGS84 semi-major axis
+
+// geoTransform: Konversi posisi pixel di peta lantai -> (longitude, latitude)
+type geoTransform func(x, y float64) (lng, lat float64)
+
+// parseGeoReference: Floor.GeoReference (jsonb) -> DTO. nil jika lantai belum dikalibrasi.
+func parseGeoReference(raw entity.JSONMap) (*models.GeoReference, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var geo models.GeoReference
+	if err := json.Unmarshal(data, &geo); err != nil {
+		return nil, errors.New("invalid geo reference")
+	}
+	return &geo, nil
+}
+
+func geoReferenceToJSONMap(geo *models.GeoReference) (entity.JSONMap, error) {
+	if geo == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(geo)
+	if err != nil {
+		return nil, err
+	}
+	var raw entity.JSONMap
+	err = json.Unmarshal(data, &raw)
+	return raw, err
+}
+
+// newGeoTransform: Validasi georeference sekaligus menyiapkan fungsi konversinya
+func newGeoTransform(geo *models.GeoReference, pixelsPerMeter float64) (geoTransform, error) {
+	if geo == nil {
+		return nil, errors.New("floor has no geo reference")
+	}
+
+	if len(geo.ControlPoints) > 0 {
+		return newAffineGeoTransform(geo.ControlPoints)
+	}
+
+	if geo.Latitude < -90 || geo.Latitude > 90 || geo.Longitude < -180 || geo.Longitude > 180 {
+		return nil, errors.New("geo reference anchor is outside WGS84 range")
+	}
+	if geo.Latitude == 0 && geo.Longitude == 0 {
+		return nil, errors.New("geo reference anchor latitude/longitude is required")
+	}
+	if pixelsPerMeter <= 0 {
+		return nil, errors.New("floor pixels_per_meter must be greater than 0")
+	}
+
+	bearing := geo.Bearing * math.Pi / 180
+	sinB, cosB := math.Sin(bearing), math.Cos(bearing)
+	cosLat := math.Cos(geo.Latitude * math.Pi / 180)
+
+	return func(x, y float64) (float64, float64) {
+		// Sumbu gambar: X ke kanan, Y ke bawah. Putar ke arah timur/utara sesuai bearing.
+		right := (x - geo.AnchorX) / pixelsPerMeter
+		up := (geo.AnchorY - y) / pixelsPerMeter
+		east := right*cosB + up*sinB
+		north := -right*sinB + up*cosB
+
+		// Pendekatan bidang datar lokal: cukup akurat untuk skala gedung
+		lat := geo.Latitude + (north/earthRadiusMeters)*180/math.Pi
+		lng := geo.Longitude + (east/(earthRadiusMeters*cosLat))*180/math.Pi
+		return lng, lat
+	}, nil
+}
+
+// newAffineGeoTransform: lng = a*x + b*y + c, lat = d*x + e*y + f (least squares dari control points)
+func newAffineGeoTransform(points []models.GeoControlPoint) (geoTransform, error) {
+	if len(points) < 3 {
+		return nil, errors.New("geo reference needs at least 3 control points")
+	}
+	for _, p := range points {
+		if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+			return nil, errors.New("geo reference control point is outside WGS84 range")
+		}
+	}
+
+	// Normal equation (M * coef = rhs), M sama untuk lng & lat
+	var m [3][3]float64
+	var rhsLng, rhsLat [3]float64
+	for _, p := range points {
+		row := [3]float64{p.X, p.Y, 1}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				m[i][j] += row[i] * row[j]
+			}
+			rhsLng[i] += row[i] * p.Longitude
+			rhsLat[i] += row[i] * p.Latitude
+		}
+	}
+
+	lngCoef, okLng := solve3x3(m, rhsLng)
+	latCoef, okLat := solve3x3(m, rhsLat)
+	if !okLng || !okLat {
+		return nil, errors.New("geo reference control points must not be collinear")
+	}
+
+	return func(x, y float64) (float64, float64) {
+		return lngCoef[0]*x + lngCoef[1]*y + lngCoef[2], latCoef[0]*x + latCoef[1]*y + latCoef[2]
+	}, nil
+}
+
+// solve3x3: Aturan Cramer, false jika matriks singular
+func solve3x3(m [3][3]float64, rhs [3]float64) ([3]float64, bool) {
+	det := det3(m)
+	scale := 0.0
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			scale = math.Max(scale, math.Abs(m[i][j]))
+		}
+	}
+	if scale == 0 || math.Abs(det) <= 1e-12*scale*scale*scale {
+		return [3]float64{}, false
+	}
+
+	var out [3]float64
+	for col := 0; col < 3; col++ {
+		mc := m
+		for row := 0; row < 3; row++ {
+			mc[row][col] = rhs[row]
+		}
+		out[col] = det3(mc) / det
+	}
+	return out, true
+}
+
+func det3(m [3][3]float64) float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// roundCoordinate: 7 desimal (~1 cm), cukup untuk indoor & menjaga ukuran GeoJSON
+func roundCoordinate(v float64) float64 {
+	return math.Round(v*1e7) / 1e7
+}
+
+// invertGeoTransform: (longitude, latitude) -> pixel. Kedua mode transform bersifat affine,
+// jadi koefisiennya bisa diambil dari tiga titik sampel.
+func invertGeoTransform(transform geoTransform) (func(lng, lat float64) (x, y float64), error) {
+	const step = 1000.0 // pixel, agar koefisien tidak tenggelam di presisi float
+	c, f := transform(0, 0)
+	lngX, latX := transform(step, 0)
+	lngY, latY := transform(0, step)
+	a, d := (lngX-c)/step, (latX-f)/step
+	b, e := (lngY-c)/step, (latY-f)/step
+
+	det := a*e - b*d
+	if det == 0 {
+		return nil, errors.New("geo reference cannot be inverted")
+	}
+	return func(lng, lat float64) (float64, float64) {
+		dl, dp := lng-c, lat-f
+		return (e*dl - b*dp) / det, (a*dp - d*dl) / det
+	}, nil
+}
@@ -0,0 +1,635 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type graphImportService struct {
+	graphRepo    repository.GraphRepository
+	revisionRepo repository.GraphRevisionRepository
+	areaRepo     repository.AreaRepository
+	ownership    repository.OwnershipRepository
+}
+
+func NewGraphImportService(
+	graphRepo repository.GraphRepository,
+	revisionRepo repository.GraphRevisionRepository,
+	areaRepo repository.AreaRepository,
+	ownership repository.OwnershipRepository,
+) GraphImportService {
+	return &graphImportService{
+		graphRepo:    graphRepo,
+		revisionRepo: revisionRepo,
+		areaRepo:     areaRepo,
+		ownership:    ownership,
+	}
+}
+
+// rowRef: Asal baris setiap item dokumen, untuk pesan error per baris
+type rowRef struct {
+	source string
+	row    int
+}
+
+// importBatch: Dokumen hasil parsing (JSON/CSV/GeoJSON) + posisi barisnya
+type importBatch struct {
+	doc      models.GraphImportDocument
+	areaRows []rowRef
+	nodeRows []rowRef
+	edgeRows []rowRef
+	errors   []models.ImportRowError
+}
+
+func (b *importBatch) fail(ref rowRef, field, format string, args ...interface{}) {
+	b.errors = append(b.errors, models.ImportRowError{Source: ref.source, Row: ref.row, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (s *graphImportService) ImportJSON(ctx context.Context, venueID uuid.UUID, doc models.GraphImportDocument, dryRun bool) (*models.GraphImportResult, error) {
+	draft, err := s.loadDraft(ctx, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &importBatch{doc: doc}
+	for i := range doc.Areas {
+		batch.areaRows = append(batch.areaRows, rowRef{"areas", i + 1})
+	}
+	for i := range doc.Nodes {
+		batch.nodeRows = append(batch.nodeRows, rowRef{"nodes", i + 1})
+	}
+	for i := range doc.Edges {
+		batch.edgeRows = append(batch.edgeRows, rowRef{"edges", i + 1})
+	}
+	return s.importBatch(ctx, venueID, draft, batch, dryRun)
+}
+
+// ImportCSV: nodes wajib, edges & areas opsional (nil)
+func (s *graphImportService) ImportCSV(ctx context.Context, venueID uuid.UUID, nodes, edges, areas io.Reader, dryRun bool) (*models.GraphImportResult, error) {
+	if nodes == nil {
+		return nil, errors.New("nodes.csv is required")
+	}
+	draft, err := s.loadDraft(ctx, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &importBatch{}
+	if areas != nil {
+		readCSV(batch, "areas.csv", areas, func(ref rowRef, row csvRow) {
+			area := models.ImportArea{
+				ExternalID:  row.get("id"),
+				Name:        row.get("name"),
+				Category:    row.get("category"),
+				Description: row.get("description"),
+			}
+			ok := row.floor(batch, ref, &area.FloorID, &area.Level)
+			ok = row.float(batch, ref, "x", &area.X) && ok
+			ok = row.float(batch, ref, "y", &area.Y) && ok
+			if ok {
+				batch.doc.Areas = append(batch.doc.Areas, area)
+				batch.areaRows = append(batch.areaRows, ref)
+			}
+		})
+	}
+	readCSV(batch, "nodes.csv", nodes, func(ref rowRef, row csvRow) {
+		node := models.ImportNode{
+			ExternalID: row.get("id"),
+			Label:      row.get("label"),
+			AreaID:     row.get("area_id"),
+		}
+		ok := row.floor(batch, ref, &node.FloorID, &node.Level)
+		ok = row.float(batch, ref, "x", &node.X) && ok
+		ok = row.float(batch, ref, "y", &node.Y) && ok
+		ok = row.float(batch, ref, "rotation_offset", &node.RotationOffset) && ok
+		ok = row.uuid(batch, ref, "panorama_asset_id", &node.PanoramaAssetID) && ok
+		if ok {
+			batch.doc.Nodes = append(batch.doc.Nodes, node)
+			batch.nodeRows = append(batch.nodeRows, ref)
+		}
+	})
+	if edges != nil {
+		readCSV(batch, "edges.csv", edges, func(ref rowRef, row csvRow) {
+			edge := models.ImportEdge{From: row.get("from"), To: row.get("to"), Type: row.get("type")}
+			if v := row.get("bidirectional"); v != "" {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					batch.fail(ref, "bidirectional", "must be true or false")
+					return
+				}
+				edge.Bidirectional = b
+			}
+			batch.doc.Edges = append(batch.doc.Edges, edge)
+			batch.edgeRows = append(batch.edgeRows, ref)
+		})
+	}
+
+	return s.importBatch(ctx, venueID, draft, batch, dryRun)
+}
+
+// ImportGeoJSON: Point (node/area) & LineString (edge), koordinat WGS84 -> pixel via georeference lantai
+func (s *graphImportService) ImportGeoJSON(ctx context.Context, venueID uuid.UUID, fc models.GeoJSONFeatureCollection, dryRun bool) (*models.GraphImportResult, error) {
+	draft, err := s.loadDraft(ctx, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &importBatch{}
+	inverses := make(map[uuid.UUID]func(lng, lat float64) (float64, float64))
+	for i, feature := range fc.Features {
+		ref := rowRef{"features", i + 1}
+		props := feature.Properties
+		featureType := propString(props, "feature_type")
+		if featureType == "" {
+			featureType = map[string]string{models.GeoJSONPoint: "node", models.GeoJSONLineString: "edge"}[feature.Geometry.Type]
+		}
+
+		if featureType == "edge" {
+			edge := models.ImportEdge{
+				From: firstNonEmpty(propString(props, "from_node_id"), propString(props, "from")),
+				To:   firstNonEmpty(propString(props, "to_node_id"), propString(props, "to")),
+				Type: propString(props, "type"),
+			}
+			edge.Bidirectional, _ = props["bidirectional"].(bool)
+			batch.doc.Edges = append(batch.doc.Edges, edge)
+			batch.edgeRows = append(batch.edgeRows, ref)
+			continue
+		}
+		if featureType != "node" && featureType != "area" {
+			batch.fail(ref, "feature_type", "unsupported feature_type %q", featureType)
+			continue
+		}
+
+		// Point -> lantai draft -> pixel
+		coords, ok := feature.Geometry.Coordinates.([]interface{})
+		if feature.Geometry.Type != models.GeoJSONPoint || !ok || len(coords) < 2 {
+			batch.fail(ref, "geometry", "%s must be a Point", featureType)
+			continue
+		}
+		lng, okLng := coords[0].(float64)
+		lat, okLat := coords[1].(float64)
+		if !okLng || !okLat {
+			batch.fail(ref, "geometry", "coordinates must be numbers")
+			continue
+		}
+		floor := geoJSONFloor(draft, props)
+		if floor == nil {
+			batch.fail(ref, "level", "no draft floor matches floor_id/level")
+			continue
+		}
+		inverse, ok := inverses[floor.ID]
+		if !ok {
+			geo, err := parseGeoReference(floor.GeoReference)
+			var transform geoTransform
+			if err == nil {
+				transform, err = newGeoTransform(geo, floor.PixelsPerMeter)
+			}
+			if err == nil {
+				inverse, err = invertGeoTransform(transform)
+			}
+			if err != nil {
+				batch.fail(ref, "level", "floor %q: %v", floor.Name, err)
+				continue
+			}
+			inverses[floor.ID] = inverse
+		}
+		x, y := inverse(lng, lat)
+		x, y = math.Round(x*1000)/1000, math.Round(y*1000)/1000 // buang noise float hasil inverse
+		floorID := floor.ID
+
+		if featureType == "area" {
+			batch.doc.Areas = append(batch.doc.Areas, models.ImportArea{
+				ExternalID:  firstNonEmpty(propString(props, "id"), strings.TrimPrefix(feature.ID, "area:")),
+				FloorID:     &floorID,
+				Name:        propString(props, "name"),
+				Category:    propString(props, "category"),
+				Description: propString(props, "description"),
+				X:           x,
+				Y:           y,
+			})
+			batch.areaRows = append(batch.areaRows, ref)
+			continue
+		}
+
+		node := models.ImportNode{
+			ExternalID: firstNonEmpty(propString(props, "id"), strings.TrimPrefix(feature.ID, "node:")),
+			FloorID:    &floorID,
+			X:          x,
+			Y:          y,
+			Label:      propString(props, "label"),
+			AreaID:     propString(props, "area_id"),
+		}
+		node.RotationOffset, _ = props["rotation_offset"].(float64)
+		if v := propString(props, "panorama_asset_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				batch.fail(ref, "panorama_asset_id", "must be a UUID")
+				continue
+			}
+			node.PanoramaAssetID = &id
+		}
+		batch.doc.Nodes = append(batch.doc.Nodes, node)
+		batch.nodeRows = append(batch.nodeRows, ref)
+	}
+
+	return s.importBatch(ctx, venueID, draft, batch, dryRun)
+}
+
+func (s *graphImportService) loadDraft(ctx context.Context, venueID uuid.UUID) (*entity.GraphRevision, error) {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return nil, err
+	}
+	draft, err := s.revisionRepo.GetDraftByVenueID(ctx, venueID)
+	if err != nil {
+		return nil, errors.New("venue has no draft revision, open the editor first")
+	}
+	return draft, nil
+}
+
+// importBatch: Validasi semua baris, lalu simpan sekaligus (kecuali dry run / ada error)
+func (s *graphImportService) importBatch(ctx context.Context, venueID uuid.UUID, draft *entity.GraphRevision, batch *importBatch, dryRun bool) (*models.GraphImportResult, error) {
+	floorsByID := make(map[uuid.UUID]*entity.Floor)
+	floorsByLevel := make(map[int]*entity.Floor)
+	positions := make(map[uuid.UUID][2]float64)
+	existingEdges := make(map[string]bool)
+	for i := range draft.Floors {
+		floor := &draft.Floors[i]
+		floorsByID[floor.ID] = floor
+		if floor.IsActive {
+			floorsByLevel[floor.LevelIndex] = floor
+		}
+		for _, node := range floor.Nodes {
+			positions[node.ID] = [2]float64{node.X, node.Y}
+			for _, edge := range node.OutgoingEdges {
+				existingEdges[edge.FromNodeID.String()+">"+edge.ToNodeID.String()] = true
+			}
+		}
+	}
+	resolveFloor := func(ref rowRef, floorID *uuid.UUID, level *int) *entity.Floor {
+		switch {
+		case floorID != nil:
+			if floor, ok := floorsByID[*floorID]; ok {
+				return floor
+			}
+			batch.fail(ref, "floor_id", "floor is not part of the venue draft")
+		case level != nil:
+			if floor, ok := floorsByLevel[*level]; ok {
+				return floor
+			}
+			batch.fail(ref, "level", "no active draft floor at level %d", *level)
+		default:
+			batch.fail(ref, "level", "floor_id or level is required")
+		}
+		return nil
+	}
+
+	existingAreas := make(map[uuid.UUID]bool)
+	venueAreas, err := s.areaRepo.GetByVenueID(ctx, venueID)
+	if err != nil {
+		return nil, err
+	}
+	for _, area := range venueAreas {
+		existingAreas[area.ID] = true
+	}
+
+	// 1. Areas
+	areaIDs := make(map[string]uuid.UUID)
+	var areas []entity.Area
+	for i, item := range batch.doc.Areas {
+		ref := batch.areaRows[i]
+		valid := true
+		if item.Name == "" {
+			batch.fail(ref, "name", "name is required")
+			valid = false
+		}
+		if item.ExternalID != "" {
+			if _, dup := areaIDs[item.ExternalID]; dup {
+				batch.fail(ref, "id", "duplicate area id %q", item.ExternalID)
+				valid = false
+			}
+		}
+		if item.X < 0 || item.Y < 0 {
+			batch.fail(ref, "x", "coordinates cannot be negative")
+			valid = false
+		}
+		floor := resolveFloor(ref, item.FloorID, item.Level)
+		if floor == nil || !valid {
+			continue
+		}
+
+		area := entity.Area{
+			BaseEntity:  entity.BaseEntity{ID: uuid.New()},
+			VenueID:     venueID,
+			FloorID:     floor.ID,
+			Name:        item.Name,
+			Category:    item.Category,
+			Description: item.Description,
+			MapX:        item.X,
+			MapY:        item.Y,
+		}
+		if item.ExternalID != "" {
+			areaIDs[item.ExternalID] = area.ID
+		}
+		areas = append(areas, area)
+	}
+
+	// 2. Nodes
+	nodeIDs := make(map[string]uuid.UUID)
+	panoramas := make(map[uuid.UUID]bool)
+	var nodes []entity.GraphNode
+	for i, item := range batch.doc.Nodes {
+		ref := batch.nodeRows[i]
+		valid := true
+		if item.ExternalID == "" {
+			batch.fail(ref, "id", "id is required")
+			valid = false
+		} else if _, dup := nodeIDs[item.ExternalID]; dup {
+			batch.fail(ref, "id", "duplicate node id %q", item.ExternalID)
+			valid = false
+		}
+		if item.X < 0 || item.Y < 0 {
+			batch.fail(ref, "x", "coordinates cannot be negative")
+			valid = false
+		}
+		if item.PanoramaAssetID == nil {
+			batch.fail(ref, "panorama_asset_id", "panorama_asset_id is required")
+			valid = false
+		} else if exists, checked := panoramas[*item.PanoramaAssetID]; !checked || !exists {
+			if !checked {
+				// Panorama milik organisasi lain dianggap tidak ada
+				err := requireOwned(ctx, "media asset", s.ownership.MediaBelongsTo, *item.PanoramaAssetID)
+				exists = err == nil
+				panoramas[*item.PanoramaAssetID] = exists
+			}
+			if !exists {
+				batch.fail(ref, "panorama_asset_id", "media asset not found")
+				valid = false
+			}
+		}
+		var areaID *uuid.UUID
+		if item.AreaID != "" {
+			if id, ok := areaIDs[item.AreaID]; ok {
+				areaID = &id
+			} else if id, err := uuid.Parse(item.AreaID); err == nil && existingAreas[id] {
+				areaID = &id
+			} else {
+				batch.fail(ref, "area_id", "unknown area %q", item.AreaID)
+				valid = false
+			}
+		}
+		floor := resolveFloor(ref, item.FloorID, item.Level)
+		if floor == nil || !valid {
+			if _, seen := nodeIDs[item.ExternalID]; item.ExternalID != "" && !seen {
+				nodeIDs[item.ExternalID] = uuid.Nil // tandai agar edge tidak melaporkan "unknown node" lagi
+			}
+			continue
+		}
+
+		node := entity.GraphNode{
+			BaseEntity:      entity.BaseEntity{ID: uuid.New()},
+			FloorID:         floor.ID,
+			X:               item.X,
+			Y:               item.Y,
+			AreaID:          areaID,
+			PanoramaAssetID: *item.PanoramaAssetID,
+			RotationOffset:  item.RotationOffset,
+			Label:           item.Label,
+			IsActive:        true,
+		}
+		nodeIDs[item.ExternalID] = node.ID
+		positions[node.ID] = [2]float64{node.X, node.Y}
+		nodes = append(nodes, node)
+	}
+
+	// 3. Edges (heading & distance dihitung seperti GraphRepository.ConnectNodes)
+	resolveNode := func(ref rowRef, field, key string) (uuid.UUID, bool) {
+		if key == "" {
+			batch.fail(ref, field, "%s is required", field)
+			return uuid.Nil, false
+		}
+		if id, ok := nodeIDs[key]; ok {
+			return id, id != uuid.Nil
+		}
+		if id, err := uuid.Parse(key); err == nil {
+			if _, ok := positions[id]; ok {
+				return id, true
+			}
+		}
+		batch.fail(ref, field, "unknown node %q", key)
+		return uuid.Nil, false
+	}
+	newEdges := make(map[string]bool)
+	var edges []entity.GraphEdge
+	for i, item := range batch.doc.Edges {
+		ref := batch.edgeRows[i]
+		from, okFrom := resolveNode(ref, "from", item.From)
+		to, okTo := resolveNode(ref, "to", item.To)
+		if !okFrom || !okTo {
+			continue
+		}
+		if from == to {
+			batch.fail(ref, "to", "cannot connect node to itself")
+			continue
+		}
+		edgeType := strings.ToLower(item.Type)
+		if edgeType == "" {
+			edgeType = "walk"
+		}
+
+		pairs := [][2]uuid.UUID{{from, to}}
+		if item.Bidirectional {
+			pairs = append(pairs, [2]uuid.UUID{to, from})
+		}
+		for _, pair := range pairs {
+			key := pair[0].String() + ">" + pair[1].String()
+			if existingEdges[key] || newEdges[key] {
+				batch.fail(ref, "to", "connection %s -> %s already exists", item.From, item.To)
+				continue
+			}
+			newEdges[key] = true
+			heading, distance := edgeHeadingDistance(positions[pair[0]], positions[pair[1]])
+			edges = append(edges, entity.GraphEdge{
+				BaseEntity: entity.BaseEntity{ID: uuid.New()},
+				FromNodeID: pair[0],
+				ToNodeID:   pair[1],
+				Heading:    heading,
+				Distance:   distance,
+				Type:       edgeType,
+				IsActive:   true,
+			})
+		}
+	}
+
+	result := &models.GraphImportResult{
+		DryRun:     dryRun,
+		Valid:      len(batch.errors) == 0,
+		AreaCount:  len(areas),
+		NodeCount:  len(nodes),
+		EdgeCount:  len(edges),
+		Errors:     batch.errors,
+		RevisionID: draft.ID,
+	}
+	if result.Errors == nil {
+		result.Errors = []models.ImportRowError{}
+	}
+	if dryRun || !result.Valid {
+		return result, nil
+	}
+
+	if err := s.graphRepo.ImportGraph(ctx, areas, nodes, edges); err != nil {
+		return nil, err
+	}
+	result.AreaIDs, result.NodeIDs = areaIDs, nodeIDs
+	return result, nil
+}
+
+// edgeHeadingDistance: Heading 0 = atas peta, searah jarum jam (sama dengan GraphRepository.ConnectNodes)
+func edgeHeadingDistance(from, to [2]float64) (float64, float64) {
+	dx, dy := to[0]-from[0], to[1]-from[1]
+	heading := math.Atan2(dx, -dy) * (180 / math.Pi)
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, math.Sqrt(dx*dx + dy*dy)
+}
+
+// --- CSV HELPERS ---
+
+type csvRow map[string]string
+
+func (r csvRow) get(column string) string {
+	return strings.TrimSpace(r[column])
+}
+
+func (r csvRow) float(batch *importBatch, ref rowRef, column string, out *float64) bool {
+	v := r.get(column)
+	if v == "" {
+		return true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		batch.fail(ref, column, "must be a number")
+		return false
+	}
+	*out = f
+	return true
+}
+
+func (r csvRow) uuid(batch *importBatch, ref rowRef, column string, out **uuid.UUID) bool {
+	v := r.get(column)
+	if v == "" {
+		return true
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		batch.fail(ref, column, "must be a UUID")
+		return false
+	}
+	*out = &id
+	return true
+}
+
+func (r csvRow) floor(batch *importBatch, ref rowRef, floorID **uuid.UUID, level **int) bool {
+	ok := r.uuid(batch, ref, "floor_id", floorID)
+	if v := r.get("level"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			batch.fail(ref, "level", "must be an integer")
+			return false
+		}
+		*level = &n
+	}
+	return ok
+}
+
+// readCSV: Baris pertama = header (nama kolom = nama field JSON), row = nomor baris di file
+func readCSV(batch *importBatch, source string, r io.Reader, handle func(ref rowRef, row csvRow)) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		batch.fail(rowRef{source, 1}, "", "cannot read header: %v", err)
+		return
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			batch.fail(rowRef{source, line}, "", "invalid CSV: %v", err)
+			return
+		}
+
+		row := make(csvRow, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = value
+			}
+		}
+		handle(rowRef{source, line}, row)
+	}
+}
+
+// --- GEOJSON HELPERS ---
+
+func geoJSONFloor(draft *entity.GraphRevision, props map[string]interface{}) *entity.Floor {
+	if v := propString(props, "floor_id"); v != "" {
+		if id, err := uuid.Parse(v); err == nil {
+			for i := range draft.Floors {
+				if draft.Floors[i].ID == id || draft.Floors[i].StableID() == id {
+					return &draft.Floors[i]
+				}
+			}
+		}
+	}
+	if level, ok := props["level"].(float64); ok {
+		for i := range draft.Floors {
+			if draft.Floors[i].IsActive && float64(draft.Floors[i].LevelIndex) == level {
+				return &draft.Floors[i]
+			}
+		}
+	}
+	return nil
+}
+
+func propString(props map[string]interface{}, key string) string {
+	switch v := props[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
@@ -11,10 +12,13 @@ import (
 )
 
 type graphService struct {
-	graphRepo    repository.GraphRepository
-	revisionRepo repository.GraphRevisionRepository
-	floorRepo    repository.FloorRepository
-	venueRepo    repository.VenueRepository
+	graphRepo     repository.GraphRepository
+	revisionRepo  repository.GraphRevisionRepository
+	floorRepo     repository.FloorRepository
+	venueRepo     repository.VenueRepository
+	ownership     repository.OwnershipRepository
+	manifestCache ManifestCache
+	audit         AuditService
 }
 
 func NewGraphService(
@@ -22,12 +26,18 @@ func NewGraphService(
 	rRepo repository.GraphRevisionRepository,
 	fRepo repository.FloorRepository,
 	vRepo repository.VenueRepository,
+	ownership repository.OwnershipRepository,
+	manifestCache ManifestCache,
+	audit AuditService,
 ) GraphService {
 	return &graphService{
-		graphRepo:    gRepo,
-		revisionRepo: rRepo,
-		floorRepo:    fRepo,
-		venueRepo:    vRepo,
+		graphRepo:     gRepo,
+		revisionRepo:  rRepo,
+		floorRepo:     fRepo,
+		venueRepo:     vRepo,
+		ownership:     ownership,
+		manifestCache: manifestCache,
+		audit:         audit,
 	}
 }
 
@@ -36,6 +46,10 @@ func NewGraphService(
 // =================================================================
 
 func (s *graphService) CreateFloor(ctx context.Context, venueID uuid.UUID, req models.CreateFloorRequest) (*models.IDResponse, error) {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return nil, err
+	}
+
 	// A. Pastikan kita bekerja di DRAFT Revision
 	draft, err := s.revisionRepo.GetDraftByVenueID(ctx, venueID)
 	if err != nil {
@@ -57,6 +71,15 @@ func (s *graphService) CreateFloor(ctx context.Context, venueID uuid.UUID, req m
 		PixelsPerMeter:  req.PixelsPerMeter,
 	}
 
+	if req.GeoReference != nil {
+		if _, err := newGeoTransform(req.GeoReference, req.PixelsPerMeter); err != nil {
+			return nil, err
+		}
+		if floor.GeoReference, err = geoReferenceToJSONMap(req.GeoReference); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.floorRepo.Create(ctx, &floor); err != nil {
 		return nil, err
 	}
@@ -65,6 +88,10 @@ func (s *graphService) CreateFloor(ctx context.Context, venueID uuid.UUID, req m
 }
 
 func (s *graphService) UpdateFloorMap(ctx context.Context, floorID uuid.UUID, req models.UpdateFloorRequest) error {
+	if err := requireOwned(ctx, "floor", s.ownership.FloorBelongsTo, floorID); err != nil {
+		return err
+	}
+
 	// A. Security Check: Pastikan Floor ini milik DRAFT
 	if _, err := s.revisionRepo.GetDraftByFloorID(ctx, floorID); err != nil {
 		return errors.New("cannot edit floor: it belongs to a published version or does not exist")
@@ -75,11 +102,47 @@ func (s *graphService) UpdateFloorMap(ctx context.Context, floorID uuid.UUID, re
 	return s.floorRepo.UpdateFloorMap(ctx, floorID, req.MapImageID, req.PixelsPerMeter)
 }
 
+// UpdateFloorGeoReference: Set/hapus (req nil) kalibrasi GPS lantai draft
+func (s *graphService) UpdateFloorGeoReference(ctx context.Context, floorID uuid.UUID, req *models.GeoReference) error {
+	if err := requireOwned(ctx, "floor", s.ownership.FloorBelongsTo, floorID); err != nil {
+		return err
+	}
+	if _, err := s.revisionRepo.GetDraftByFloorID(ctx, floorID); err != nil {
+		return errors.New("cannot edit floor: it belongs to a published version or does not exist")
+	}
+
+	if req != nil {
+		floor, err := s.floorRepo.GetByID(ctx, floorID)
+		if err != nil {
+			return errors.New("floor not found")
+		}
+		// Validasi dulu agar export GeoJSON tidak gagal belakangan
+		if _, err := newGeoTransform(req, floor.PixelsPerMeter); err != nil {
+			return err
+		}
+	}
+
+	geoReference, err := geoReferenceToJSONMap(req)
+	if err != nil {
+		return err
+	}
+	return s.floorRepo.UpdateGeoReference(ctx, floorID, geoReference)
+}
+
 // =================================================================
 // 2. NODE OPERATIONS
 // =================================================================
 
 func (s *graphService) CreateNode(ctx context.Context, req models.CreateNodeRequest) (*models.IDResponse, error) {
+	if err := requireOwned(ctx, "floor", s.ownership.FloorBelongsTo, req.FloorID); err != nil {
+		return nil, err
+	}
+	if req.PanoramaAssetID != uuid.Nil {
+		if err := requireOwned(ctx, "panorama asset", s.ownership.MediaBelongsTo, req.PanoramaAssetID); err != nil {
+			return nil, err
+		}
+	}
+
 	// A. Security Check: Floor harus ada di Draft
 	if _, err := s.revisionRepo.GetDraftByFloorID(ctx, req.FloorID); err != nil {
 		return nil, errors.New("cannot create node: target floor is not in draft mode")
@@ -103,22 +166,58 @@ func (s *graphService) CreateNode(ctx context.Context, req models.CreateNodeRequ
 	if err := s.graphRepo.CreateNode(ctx, &node); err != nil {
 		return nil, err
 	}
+	recordChange(ctx, s.audit, "NODE_CREATE", "GraphNode", node.ID, nil, node)
 
 	return &models.IDResponse{ID: node.ID}, nil
 }
 
 func (s *graphService) UpdateNodePosition(ctx context.Context, nodeID uuid.UUID, req models.UpdateNodePositionRequest) error {
-	// Validasi ownership draft bisa dilakukan dengan query node -> floor -> revision
-	// Untuk performa, kita asumsikan UI sudah membatasi, atau bisa tambah check di sini.
-	return s.graphRepo.UpdateNodePosition(ctx, nodeID, req.X, req.Y)
+	if err := requireOwned(ctx, "node", s.ownership.NodeBelongsTo, nodeID); err != nil {
+		return err
+	}
+	before := s.nodeSnapshot(ctx, nodeID)
+	if err := s.graphRepo.UpdateNodePosition(ctx, nodeID, req.X, req.Y); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "NODE_UPDATE", "GraphNode", nodeID, before, map[string]float64{"x": req.X, "y": req.Y})
+	return nil
 }
 
 func (s *graphService) UpdateNodeCalibration(ctx context.Context, nodeID uuid.UUID, req models.UpdateNodeCalibrationRequest) error {
-	return s.graphRepo.UpdateNodeCalibration(ctx, nodeID, req.RotationOffset)
+	if err := requireOwned(ctx, "node", s.ownership.NodeBelongsTo, nodeID); err != nil {
+		return err
+	}
+	before := s.nodeSnapshot(ctx, nodeID)
+	if err := s.graphRepo.UpdateNodeCalibration(ctx, nodeID, req.RotationOffset); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "NODE_UPDATE", "GraphNode", nodeID, before, map[string]float64{"rotation_offset": req.RotationOffset})
+	return nil
 }
 
 func (s *graphService) DeleteNode(ctx context.Context, nodeID uuid.UUID) error {
-	return s.graphRepo.DeleteNode(ctx, nodeID)
+	if err := requireOwned(ctx, "node", s.ownership.NodeBelongsTo, nodeID); err != nil {
+		return err
+	}
+	before := s.nodeSnapshot(ctx, nodeID)
+	if err := s.graphRepo.DeleteNode(ctx, nodeID); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "NODE_DELETE", "GraphNode", nodeID, before, nil)
+	return nil
+}
+
+// nodeSnapshot: Kondisi node sebelum diubah, hanya dibaca jika audit aktif.
+// Gagal dibaca tidak membatalkan operasi (snapshot before kosong).
+func (s *graphService) nodeSnapshot(ctx context.Context, nodeID uuid.UUID) *entity.GraphNode {
+	if s.audit == nil {
+		return nil
+	}
+	node, err := s.graphRepo.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil
+	}
+	return node
 }
 
 // =================================================================
@@ -129,6 +228,9 @@ func (s *graphService) ConnectNodes(ctx context.Context, req models.ConnectNodes
 	if req.FromNodeID == req.ToNodeID {
 		return errors.New("cannot connect node to itself")
 	}
+	if err := requireOwned(ctx, "node", s.ownership.NodeBelongsTo, req.FromNodeID, req.ToNodeID); err != nil {
+		return err
+	}
 
 	// A. Validasi Cross-Graph (Sudah ada di Repo level, tapi bisa double check di sini)
 	// Repository graphRepo.ConnectNodes sudah kita pasang logic kalkulasi Heading & Distance.
@@ -143,6 +245,7 @@ func (s *graphService) ConnectNodes(ctx context.Context, req models.ConnectNodes
 	if err := s.graphRepo.ConnectNodes(ctx, &edge); err != nil {
 		return err
 	}
+	recordChange(ctx, s.audit, "EDGE_CREATE", "GraphEdge", edge.ID, nil, edge)
 
 	// Opsional: Jika ingin Bi-Directional (Dua arah otomatis)
 	// edgeBack := entity.GraphEdge{ FromNodeID: req.ToNodeID, ToNodeID: req.FromNodeID, ... }
@@ -152,7 +255,14 @@ func (s *graphService) ConnectNodes(ctx context.Context, req models.ConnectNodes
 }
 
 func (s *graphService) DeleteConnection(ctx context.Context, fromID, toID uuid.UUID) error {
-	return s.graphRepo.DeleteEdge(ctx, fromID, toID)
+	if err := requireOwned(ctx, "node", s.ownership.NodeBelongsTo, fromID, toID); err != nil {
+		return err
+	}
+	if err := s.graphRepo.DeleteEdge(ctx, fromID, toID); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "EDGE_DELETE", "GraphEdge", fromID, map[string]uuid.UUID{"from_node_id": fromID, "to_node_id": toID}, nil)
+	return nil
 }
 
 // =================================================================
@@ -161,6 +271,10 @@ func (s *graphService) DeleteConnection(ctx context.Context, fromID, toID uuid.U
 
 // GetEditorData: Mengambil data DRAFT lengkap untuk ditampilkan di Canvas Web Admin
 func (s *graphService) GetEditorData(ctx context.Context, venueID uuid.UUID) (*models.ManifestResponse, error) {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return nil, err
+	}
+
 	// 1. Ambil Draft (Auto-create jika tidak ada)
 	draft, err := s.revisionRepo.GetDraftByVenueID(ctx, venueID)
 	if err != nil {
@@ -246,6 +360,7 @@ func (s *graphService) GetEditorData(ctx context.Context, venueID uuid.UUID) (*m
 	return &models.ManifestResponse{
 		VenueID:     venueID,
 		VenueName:   venueName,
+		RevisionID:  draft.ID,
 		LastUpdated: draft.CreatedAt,
 		StartNodeID: startNodeID,
 		Floors:      floorDTOs,
@@ -253,6 +368,231 @@ func (s *graphService) GetEditorData(ctx context.Context, venueID uuid.UUID) (*m
 }
 
 func (s *graphService) PublishChanges(ctx context.Context, venueID uuid.UUID, req models.PublishDraftRequest) error {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return err
+	}
+
+	// Revisi live sebelumnya untuk snapshot audit
+	var before map[string]uuid.UUID
+	if s.audit != nil {
+		if venue, err := s.venueRepo.GetByID(ctx, venueID); err == nil {
+			before = map[string]uuid.UUID{"live_revision_id": venue.LiveRevisionID}
+		}
+	}
+
 	// Panggil Repository untuk melakukan Deep Copy Transaction
-	return s.revisionRepo.PublishDraft(ctx, venueID, req.Note)
+	if err := s.revisionRepo.PublishDraft(ctx, venueID, req.Note); err != nil {
+		return err
+	}
+
+	// Live revision berganti: buang manifest lama dari cache
+	after := map[string]interface{}{"note": req.Note}
+	if venue, err := s.venueRepo.GetByID(ctx, venueID); err == nil {
+		s.manifestCache.DeletePrefix(manifestCachePrefix(venue.ID))
+		after["live_revision_id"] = venue.LiveRevisionID
+	}
+	recordChange(ctx, s.audit, "GRAPH_PUBLISH", "Venue", venueID, before, after)
+	return nil
+}
+
+// RollbackLive: Jadikan revisi published sebelumnya live kembali. Draft tidak disentuh.
+func (s *graphService) RollbackLive(ctx context.Context, venueID uuid.UUID, req models.RollbackRevisionRequest) error {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return err
+	}
+	venue, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil {
+		return ErrVenueNotFound
+	}
+
+	updated, err := s.revisionRepo.SetLiveRevision(ctx, venueID, req.RevisionID)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("published revision %w", ErrNotFound)
+	}
+
+	// Live revision berganti: buang manifest lama dari cache
+	s.manifestCache.DeletePrefix(manifestCachePrefix(venueID))
+	recordChange(ctx, s.audit, "GRAPH_ROLLBACK", "Venue", venueID,
+		map[string]uuid.UUID{"live_revision_id": venue.LiveRevisionID},
+		map[string]uuid.UUID{"live_revision_id": req.RevisionID})
+	return nil
+}
+
+// =================================================================
+// 5. EXPORT
+// =================================================================
+
+// ExportGeoJSON: revision = "draft" (default), "live", atau UUID revisi published/archived
+func (s *graphService) ExportGeoJSON(ctx context.Context, venueID uuid.UUID, revision string) (*models.GeoJSONFeatureCollection, error) {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return nil, err
+	}
+
+	// Venue dibutuhkan untuk live revision ID & area (PointsOfInterest)
+	venue, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+
+	var graph *entity.GraphRevision
+	switch revision {
+	case "", "draft":
+		graph, err = s.revisionRepo.GetDraftByVenueID(ctx, venueID)
+	case "live":
+		if venue.LiveRevisionID == uuid.Nil {
+			return nil, errors.New("venue has no published version yet")
+		}
+		graph, err = s.venueRepo.GetRevisionManifestData(ctx, venueID, venue.LiveRevisionID)
+	default:
+		revisionID, parseErr := uuid.Parse(revision)
+		if parseErr != nil {
+			return nil, errors.New("revision must be 'draft', 'live' or a revision UUID")
+		}
+		graph, err = s.venueRepo.GetRevisionManifestData(ctx, venueID, revisionID)
+	}
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+
+	return buildGeoJSON(graph, venue.PointsOfInterest), nil
+}
+
+type geoFloor struct {
+	floor     *entity.Floor
+	transform geoTransform
+}
+
+// buildGeoJSON: Node -> Point, Edge -> LineString, Area -> Point (pin). ID memakai StableID seperti manifest.
+func buildGeoJSON(graph *entity.GraphRevision, areas []entity.Area) *models.GeoJSONFeatureCollection {
+	fc := &models.GeoJSONFeatureCollection{
+		Type:     models.GeoJSONFeatureCollectionType,
+		Features: []models.GeoJSONFeature{},
+	}
+
+	// 1. Siapkan transform per lantai (lantai tanpa georeference dilewati dengan warning)
+	floors := make(map[uuid.UUID]geoFloor)
+	for i := range graph.Floors {
+		floor := &graph.Floors[i]
+		geo, err := parseGeoReference(floor.GeoReference)
+		if err == nil {
+			var transform geoTransform
+			if transform, err = newGeoTransform(geo, floor.PixelsPerMeter); err == nil {
+				floors[floor.StableID()] = geoFloor{floor: floor, transform: transform}
+				continue
+			}
+		}
+		fc.Warnings = append(fc.Warnings, fmt.Sprintf("floor %q (level %d) skipped: %v", floor.Name, floor.LevelIndex, err))
+	}
+
+	// 2. Nodes
+	type placedNode struct {
+		stableID uuid.UUID
+		coord    []float64
+		floor    *entity.Floor
+	}
+	placed := make(map[uuid.UUID]placedNode)
+	for i := range graph.Floors {
+		gf, ok := floors[graph.Floors[i].StableID()]
+		if !ok {
+			continue
+		}
+		for _, node := range gf.floor.Nodes {
+			if !node.IsActive {
+				continue
+			}
+			p := placedNode{stableID: node.StableID(), coord: geoPoint(gf.transform, node.X, node.Y), floor: gf.floor}
+			placed[node.ID] = p
+
+			props := floorProperties("node", gf.floor)
+			props["label"] = node.Label
+			props["rotation_offset"] = node.RotationOffset
+			if node.AreaID != nil {
+				props["area_id"] = *node.AreaID
+			}
+			if node.Panorama != nil {
+				props["panorama_url"] = node.Panorama.PublicURL
+			}
+			fc.Features = append(fc.Features, models.GeoJSONFeature{
+				Type:       models.GeoJSONFeatureType,
+				ID:         "node:" + p.stableID.String(),
+				Geometry:   models.GeoJSONGeometry{Type: models.GeoJSONPoint, Coordinates: p.coord},
+				Properties: props,
+			})
+		}
+	}
+
+	// 3. Edges (termasuk antar lantai, misal tangga/lift)
+	for i := range graph.Floors {
+		for _, node := range graph.Floors[i].Nodes {
+			from, ok := placed[node.ID]
+			if !ok {
+				continue
+			}
+			for _, edge := range node.OutgoingEdges {
+				to, ok := placed[edge.ToNodeID]
+				if !ok {
+					continue
+				}
+				props := floorProperties("edge", from.floor)
+				props["from_node_id"] = from.stableID
+				props["to_node_id"] = to.stableID
+				props["to_level"] = to.floor.LevelIndex
+				props["type"] = edge.Type
+				props["heading"] = edge.Heading
+				props["distance"] = edge.Distance
+				props["is_active"] = edge.IsActive
+				fc.Features = append(fc.Features, models.GeoJSONFeature{
+					Type:       models.GeoJSONFeatureType,
+					ID:         "edge:" + from.stableID.String() + ":" + to.stableID.String(),
+					Geometry:   models.GeoJSONGeometry{Type: models.GeoJSONLineString, Coordinates: [][]float64{from.coord, to.coord}},
+					Properties: props,
+				})
+			}
+		}
+	}
+
+	// 4. Area pins: posisi peta (MapX/MapY) jika lantainya terkalibrasi, fallback ke lat/lng area
+	for _, area := range areas {
+		var coord []float64
+		var floor *entity.Floor
+		if gf, ok := floors[area.FloorID]; ok {
+			coord, floor = geoPoint(gf.transform, area.MapX, area.MapY), gf.floor
+		} else if area.Latitude != 0 || area.Longitude != 0 {
+			coord = []float64{roundCoordinate(area.Longitude), roundCoordinate(area.Latitude)}
+		} else {
+			fc.Warnings = append(fc.Warnings, fmt.Sprintf("area %q skipped: no coordinates", area.Name))
+			continue
+		}
+
+		props := floorProperties("area", floor)
+		props["name"] = area.Name
+		props["category"] = area.Category
+		props["floor_id"] = area.FloorID
+		fc.Features = append(fc.Features, models.GeoJSONFeature{
+			Type:       models.GeoJSONFeatureType,
+			ID:         "area:" + area.ID.String(),
+			Geometry:   models.GeoJSONGeometry{Type: models.GeoJSONPoint, Coordinates: coord},
+			Properties: props,
+		})
+	}
+
+	return fc
+}
+
+func geoPoint(transform geoTransform, x, y float64) []float64 {
+	lng, lat := transform(x, y)
+	return []float64{roundCoordinate(lng), roundCoordinate(lat)}
+}
+
+func floorProperties(featureType string, floor *entity.Floor) map[string]interface{} {
+	props := map[string]interface{}{"feature_type": featureType}
+	if floor != nil {
+		props["floor_id"] = floor.StableID()
+		props["level"] = floor.LevelIndex
+		props["level_name"] = floor.Name
+	}
+	return props
 }
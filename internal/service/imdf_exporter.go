@@ -0,0 +1,549 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	imdfUnitMarginMeters   = 1.5 // Area tidak punya polygon: footprint = hull titik area + margin ini
+	imdfOpeningWidthMeters = 1.0
+)
+
+// IMDFValidationError: Daftar field wajib IMDF yang belum terpenuhi
+type IMDFValidationError struct {
+	Issues []string
+}
+
+func (e *IMDFValidationError) Error() string {
+	return "venue is not ready for IMDF export: " + strings.Join(e.Issues, "; ")
+}
+
+var imdfVenueCategories = map[string]bool{
+	"airport": true, "airport.intl": true, "aquarium": true, "businesscampus": true, "casino": true,
+	"communitycenter": true, "conventioncenter": true, "governmentfacility": true, "healthcarefacility": true,
+	"hotel": true, "museum": true, "parkingfacility": true, "resort": true, "retailstore": true,
+	"shoppingcenter": true, "stadium": true, "stripmall": true, "theater": true, "themepark": true,
+	"trainstation": true, "transitstation": true, "university": true,
+}
+
+// Area.Category bebas (input editor), dipetakan ke kategori unit IMDF. Default "room".
+var imdfUnitCategories = map[string]string{
+	"auditorium": "auditorium", "classroom": "classroom", "conferenceroom": "conferenceroom",
+	"elevator": "elevator", "lift": "elevator", "escalator": "escalator", "firstaid": "firstaid",
+	"fitnessroom": "fitnessroom", "gym": "fitnessroom", "foodservice": "foodservice", "food": "foodservice",
+	"restaurant": "foodservice", "cafe": "foodservice", "foodcourt": "foodservice", "kitchen": "kitchen",
+	"library": "library", "lobby": "lobby", "lounge": "lounge", "office": "office", "parking": "parking",
+	"parkir": "parking", "restroom": "restroom", "toilet": "restroom", "wc": "restroom", "stairs": "stairs",
+	"tangga": "stairs", "storage": "storage", "theater": "theater", "walkway": "walkway", "corridor": "walkway",
+	"waitingroom": "waitingroom",
+}
+
+// Area dengan kategori ini juga diekspor sebagai amenity (pin) di dalam unit-nya
+var imdfAmenityCategories = map[string]string{
+	"restroom": "restroom", "toilet": "restroom", "wc": "restroom", "atm": "atm",
+	"information": "information", "info": "information", "helpdesk": "information",
+	"prayerroom": "prayerroom", "prayer": "prayerroom", "mushola": "prayerroom", "musholla": "prayerroom",
+	"firstaid": "firstaid", "parking": "parking", "parkir": "parking", "elevator": "elevator",
+	"lift": "elevator", "escalator": "escalator", "stairs": "stairs", "tangga": "stairs",
+}
+
+// Edge penghubung antar lantai -> amenity pada node asalnya
+var imdfConnectorEdgeTypes = map[string]string{"stairs": "stairs", "elevator": "elevator", "escalator": "escalator"}
+
+var (
+	countryCodePattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	provinceCodePattern = regexp.MustCompile(`^[A-Z]{2}-[A-Z0-9]{1,3}$`)
+)
+
+// ExportIMDF: Live revision -> arsip zip IMDF (Apple Maps indoor)
+func (s *graphService) ExportIMDF(ctx context.Context, venueID uuid.UUID, req models.IMDFExportRequest) ([]byte, error) {
+	if err := requireOwnedVenue(ctx, s.ownership.VenueBelongsTo, venueID); err != nil {
+		return nil, err
+	}
+	venue, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+	if venue.LiveRevisionID == uuid.Nil {
+		return nil, errors.New("venue has no published version yet")
+	}
+
+	graph, err := s.venueRepo.GetRevisionManifestData(ctx, venueID, venue.LiveRevisionID)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+
+	collections, err := buildIMDF(venue, graph, req)
+	if err != nil {
+		return nil, err
+	}
+	return writeIMDFArchive(collections, req.Language)
+}
+
+type imdfLevel struct {
+	floor     *entity.Floor
+	transform geoTransform
+	outline   [][]float64
+}
+
+type imdfBuilder struct {
+	lang   string
+	issues []string
+}
+
+func (b *imdfBuilder) invalid(format string, args ...interface{}) {
+	b.issues = append(b.issues, fmt.Sprintf(format, args...))
+}
+
+// label: IMDF LABELS = {"<bahasa>": "<teks>"} atau null
+func (b *imdfBuilder) label(name string) interface{} {
+	if name == "" {
+		return nil
+	}
+	return map[string]string{b.lang: name}
+}
+
+// buildIMDF: Validasi sekaligus menyusun feature collection sesuai urutan file di arsip
+func buildIMDF(venue *entity.Venue, graph *entity.GraphRevision, req models.IMDFExportRequest) ([]models.IMDFFeatureCollection, error) {
+	b := &imdfBuilder{lang: req.Language}
+	if b.lang == "" {
+		b.lang = "en"
+	}
+
+	// 1. Venue & address
+	category := strings.ToLower(req.Category)
+	if !imdfVenueCategories[category] {
+		b.invalid("category %q is not a valid IMDF venue category", req.Category)
+	}
+	country := strings.ToUpper(req.Country)
+	if !countryCodePattern.MatchString(country) {
+		b.invalid("country must be an ISO 3166-1 alpha-2 code")
+	}
+	if venue.Name == "" {
+		b.invalid("venue name is required")
+	}
+	if venue.Address == "" {
+		b.invalid("venue address is required")
+	}
+	if venue.City == "" {
+		b.invalid("venue city is required (IMDF address locality)")
+	}
+	if venue.Latitude == 0 && venue.Longitude == 0 {
+		b.invalid("venue latitude/longitude is required (IMDF display_point)")
+	}
+
+	// 2. Level: setiap lantai aktif wajib terkalibrasi & punya ukuran peta untuk outline
+	levels := make(map[uuid.UUID]*imdfLevel)
+	var levelOrder []*imdfLevel
+	ordinals := make(map[int]string)
+	for i := range graph.Floors {
+		floor := &graph.Floors[i]
+		if !floor.IsActive {
+			continue
+		}
+		if floor.Name == "" {
+			b.invalid("floor at level %d has no name", floor.LevelIndex)
+		}
+		if other, ok := ordinals[floor.LevelIndex]; ok {
+			b.invalid("floors %q and %q share level %d (IMDF ordinal must be unique)", other, floor.Name, floor.LevelIndex)
+		}
+		ordinals[floor.LevelIndex] = floor.Name
+
+		if floor.MapWidth <= 0 || floor.MapHeight <= 0 {
+			b.invalid("floor %q: map_width and map_height are required for the level outline", floor.Name)
+			continue
+		}
+		geo, err := parseGeoReference(floor.GeoReference)
+		if err != nil {
+			b.invalid("floor %q: %v", floor.Name, err)
+			continue
+		}
+		transform, err := newGeoTransform(geo, floor.PixelsPerMeter)
+		if err != nil {
+			b.invalid("floor %q: %v", floor.Name, err)
+			continue
+		}
+
+		w, h := float64(floor.MapWidth), float64(floor.MapHeight)
+		level := &imdfLevel{floor: floor, transform: transform}
+		level.outline = convexHull([][]float64{
+			geoPoint(transform, 0, 0), geoPoint(transform, w, 0),
+			geoPoint(transform, w, h), geoPoint(transform, 0, h),
+		})
+		levels[floor.StableID()] = level
+		levelOrder = append(levelOrder, level)
+	}
+	if len(ordinals) == 0 {
+		b.invalid("live revision has no active floors")
+	}
+
+	// Node aktif per area (area tidak punya polygon, node-nya yang membentuk footprint)
+	areaNodes := make(map[uuid.UUID][]entity.GraphNode)
+	for _, level := range levelOrder {
+		for _, node := range level.floor.Nodes {
+			if node.IsActive && node.AreaID != nil {
+				areaNodes[*node.AreaID] = append(areaNodes[*node.AreaID], node)
+			}
+		}
+	}
+
+	// 3. Unit (+ amenity dari kategori area)
+	var units, amenities []models.IMDFFeature
+	exportedUnits := make(map[uuid.UUID]bool)
+	for _, area := range venue.PointsOfInterest {
+		if area.Name == "" {
+			b.invalid("area %s has no name", area.ID)
+		}
+		level, ok := levels[area.FloorID]
+		if !ok {
+			if !floorInRevision(graph, area.FloorID) {
+				b.invalid("area %q is not on an active floor of the live revision", area.Name)
+			}
+			continue // Lantai tidak valid sudah dilaporkan di atas
+		}
+
+		// Titik area: posisi node-nya, fallback ke pin MapX/MapY
+		var points [][2]float64
+		for _, node := range areaNodes[area.ID] {
+			points = append(points, [2]float64{node.X, node.Y})
+		}
+		display := [2]float64{area.MapX, area.MapY}
+		if len(points) == 0 {
+			points = append(points, display)
+		} else if area.MapX == 0 && area.MapY == 0 {
+			display = centroid(points)
+		}
+
+		margin := imdfUnitMarginMeters * level.floor.PixelsPerMeter
+		var corners [][]float64
+		for _, p := range points {
+			corners = append(corners,
+				geoPoint(level.transform, p[0]-margin, p[1]-margin), geoPoint(level.transform, p[0]+margin, p[1]-margin),
+				geoPoint(level.transform, p[0]+margin, p[1]+margin), geoPoint(level.transform, p[0]-margin, p[1]+margin),
+			)
+		}
+		displayPoint := pointGeometry(geoPoint(level.transform, display[0], display[1]))
+
+		exportedUnits[area.ID] = true
+		units = append(units, imdfFeature(area.ID, "unit", polygonGeometry(convexHull(corners)), map[string]interface{}{
+			"category":      imdfUnitCategory(area.Category),
+			"restriction":   nil,
+			"accessibility": nil,
+			"name":          b.label(area.Name),
+			"alt_name":      b.label(area.Label),
+			"level_id":      level.floor.StableID(),
+			"display_point": displayPoint,
+		}))
+
+		if amenityCategory, ok := imdfAmenityCategories[normalizeCategory(area.Category)]; ok {
+			amenities = append(amenities, imdfAmenity(uuid.NewSHA1(area.ID, []byte("amenity")), amenityCategory, b.label(area.Name), displayPoint.Coordinates.([]float64), area.ID))
+		}
+	}
+
+	// 4. Amenity dari node tangga/lift/eskalator & opening dari edge yang melintasi batas area
+	var openings []models.IMDFFeature
+	nodes := make(map[uuid.UUID]entity.GraphNode)
+	for _, level := range levelOrder {
+		for _, node := range level.floor.Nodes {
+			nodes[node.ID] = node
+		}
+	}
+	seenOpenings := make(map[string]bool)
+	for _, level := range levelOrder {
+		for _, node := range level.floor.Nodes {
+			if !node.IsActive {
+				continue
+			}
+			connectors := make(map[string]bool)
+			for _, edge := range node.OutgoingEdges {
+				if !edge.IsActive {
+					continue
+				}
+				if category, ok := imdfConnectorEdgeTypes[edge.Type]; ok && node.AreaID != nil && exportedUnits[*node.AreaID] && !connectors[category] {
+					connectors[category] = true
+					amenities = append(amenities, imdfAmenity(uuid.NewSHA1(node.StableID(), []byte(category)), category, nil, geoPoint(level.transform, node.X, node.Y), *node.AreaID))
+				}
+
+				to, ok := nodes[edge.ToNodeID]
+				if !ok || !to.IsActive || to.FloorID != node.FloorID || !crossesUnit(node.AreaID, to.AreaID, exportedUnits) {
+					continue
+				}
+				key := openingKey(node.StableID(), to.StableID())
+				if seenOpenings[key] {
+					continue
+				}
+				if opening, ok := imdfOpening(uuid.NewSHA1(venue.ID, []byte(key)), level, node, to); ok {
+					seenOpenings[key] = true
+					openings = append(openings, opening)
+				}
+			}
+		}
+	}
+
+	if len(b.issues) > 0 {
+		return nil, &IMDFValidationError{Issues: b.issues}
+	}
+
+	// 5. Address, venue, building, footprint, level
+	addressID := uuid.NewSHA1(venue.ID, []byte("address"))
+	buildingID := uuid.NewSHA1(venue.ID, []byte("building"))
+	var province interface{}
+	if provinceCodePattern.MatchString(venue.Province) {
+		province = venue.Province
+	}
+	var postalCode interface{}
+	if venue.PostalCode != "" {
+		postalCode = venue.PostalCode
+	}
+	address := imdfFeature(addressID, "address", nil, map[string]interface{}{
+		"address":            venue.Address,
+		"unit":               nil,
+		"locality":           venue.City,
+		"province":           province,
+		"country":            country,
+		"postal_code":        postalCode,
+		"postal_code_ext":    nil,
+		"postal_code_vanity": nil,
+	})
+
+	var venuePoints [][]float64
+	var levelFeatures []models.IMDFFeature
+	for _, level := range levelOrder {
+		venuePoints = append(venuePoints, level.outline[:len(level.outline)-1]...)
+		levelFeatures = append(levelFeatures, imdfFeature(level.floor.StableID(), "level", polygonGeometry(level.outline), map[string]interface{}{
+			"category":      "unspecified",
+			"restriction":   nil,
+			"outdoor":       false,
+			"ordinal":       level.floor.LevelIndex,
+			"name":          b.label(level.floor.Name),
+			"short_name":    b.label(fmt.Sprintf("%d", level.floor.LevelIndex)),
+			"display_point": nil,
+			"address_id":    nil,
+			"building_ids":  []uuid.UUID{buildingID},
+		}))
+	}
+	outline := polygonGeometry(convexHull(venuePoints))
+	venuePoint := pointGeometry([]float64{roundCoordinate(venue.Longitude), roundCoordinate(venue.Latitude)})
+
+	venueFeature := imdfFeature(venue.ID, "venue", outline, map[string]interface{}{
+		"category":      category,
+		"restriction":   nil,
+		"name":          b.label(venue.Name),
+		"alt_name":      nil,
+		"hours":         nil,
+		"phone":         nil,
+		"website":       nil,
+		"display_point": venuePoint,
+		"address_id":    addressID,
+	})
+	building := imdfFeature(buildingID, "building", nil, map[string]interface{}{
+		"name":          b.label(venue.Name),
+		"alt_name":      nil,
+		"category":      "unspecified",
+		"restriction":   nil,
+		"display_point": venuePoint,
+		"address_id":    addressID,
+	})
+	footprint := imdfFeature(uuid.NewSHA1(venue.ID, []byte("footprint")), "footprint", outline, map[string]interface{}{
+		"category":     "ground",
+		"name":         nil,
+		"building_ids": []uuid.UUID{buildingID},
+	})
+
+	return []models.IMDFFeatureCollection{
+		imdfCollection("address", address),
+		imdfCollection("venue", venueFeature),
+		imdfCollection("building", building),
+		imdfCollection("footprint", footprint),
+		imdfCollection("level", levelFeatures...),
+		imdfCollection("unit", units...),
+		imdfCollection("amenity", amenities...),
+		imdfCollection("opening", openings...),
+	}, nil
+}
+
+// writeIMDFArchive: manifest.json + <feature_type>.geojson
+func writeIMDFArchive(collections []models.IMDFFeatureCollection, language string) ([]byte, error) {
+	if language == "" {
+		language = "en"
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, v interface{}) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	manifest := models.IMDFManifest{
+		Version:     models.IMDFVersion,
+		Created:     time.Now().UTC().Format(time.RFC3339),
+		GeneratedBy: "InSpaceMap",
+		Language:    language,
+	}
+	if err := write("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	for _, collection := range collections {
+		if err := write(collection.Name+".geojson", collection); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func imdfCollection(name string, features ...models.IMDFFeature) models.IMDFFeatureCollection {
+	if features == nil {
+		features = []models.IMDFFeature{}
+	}
+	return models.IMDFFeatureCollection{Type: models.GeoJSONFeatureCollectionType, Name: name, Features: features}
+}
+
+func imdfFeature(id uuid.UUID, featureType string, geometry *models.GeoJSONGeometry, props map[string]interface{}) models.IMDFFeature {
+	return models.IMDFFeature{ID: id, Type: models.GeoJSONFeatureType, FeatureType: featureType, Geometry: geometry, Properties: props}
+}
+
+func imdfAmenity(id uuid.UUID, category string, name interface{}, coord []float64, unitID uuid.UUID) models.IMDFFeature {
+	return imdfFeature(id, "amenity", pointGeometry(coord), map[string]interface{}{
+		"category":       category,
+		"accessibility":  nil,
+		"name":           name,
+		"alt_name":       nil,
+		"hours":          nil,
+		"phone":          nil,
+		"website":        nil,
+		"unit_ids":       []uuid.UUID{unitID},
+		"address_id":     nil,
+		"correlation_id": nil,
+	})
+}
+
+// imdfOpening: Garis selebar pintu, tegak lurus edge di titik tengahnya
+func imdfOpening(id uuid.UUID, level *imdfLevel, from, to entity.GraphNode) (models.IMDFFeature, bool) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return models.IMDFFeature{}, false
+	}
+	mx, my := (from.X+to.X)/2, (from.Y+to.Y)/2
+	half := imdfOpeningWidthMeters / 2 * level.floor.PixelsPerMeter
+	px, py := -dy/length*half, dx/length*half
+
+	line := &models.GeoJSONGeometry{
+		Type:        models.GeoJSONLineString,
+		Coordinates: [][]float64{geoPoint(level.transform, mx-px, my-py), geoPoint(level.transform, mx+px, my+py)},
+	}
+	return imdfFeature(id, "opening", line, map[string]interface{}{
+		"category":       "pedestrian",
+		"accessibility":  nil,
+		"access_control": nil,
+		"door":           nil,
+		"name":           nil,
+		"alt_name":       nil,
+		"display_point":  pointGeometry(geoPoint(level.transform, mx, my)),
+		"level_id":       level.floor.StableID(),
+	}), true
+}
+
+// crossesUnit: Edge berpindah area, minimal salah satu sisinya unit yang diekspor
+func crossesUnit(from, to *uuid.UUID, units map[uuid.UUID]bool) bool {
+	if from != nil && to != nil && *from == *to {
+		return false
+	}
+	return (from != nil && units[*from]) || (to != nil && units[*to])
+}
+
+func openingKey(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+func floorInRevision(graph *entity.GraphRevision, stableID uuid.UUID) bool {
+	for i := range graph.Floors {
+		if graph.Floors[i].IsActive && graph.Floors[i].StableID() == stableID {
+			return true
+		}
+	}
+	return false
+}
+
+func imdfUnitCategory(category string) string {
+	if mapped, ok := imdfUnitCategories[normalizeCategory(category)]; ok {
+		return mapped
+	}
+	return "room"
+}
+
+func normalizeCategory(category string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(category))
+}
+
+func centroid(points [][2]float64) [2]float64 {
+	var c [2]float64
+	for _, p := range points {
+		c[0] += p[0] / float64(len(points))
+		c[1] += p[1] / float64(len(points))
+	}
+	return c
+}
+
+func pointGeometry(coord []float64) *models.GeoJSONGeometry {
+	return &models.GeoJSONGeometry{Type: models.GeoJSONPoint, Coordinates: coord}
+}
+
+func polygonGeometry(ring [][]float64) *models.GeoJSONGeometry {
+	return &models.GeoJSONGeometry{Type: models.GeoJSONPolygon, Coordinates: [][][]float64{ring}}
+}
+
+// convexHull: Monotone chain, hasil ring tertutup berlawanan arah jarum jam (RFC 7946)
+func convexHull(points [][]float64) [][]float64 {
+	pts := append([][]float64(nil), points...)
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i][0] != pts[j][0] {
+			return pts[i][0] < pts[j][0]
+		}
+		return pts[i][1] < pts[j][1]
+	})
+	cross := func(o, a, b []float64) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+
+	hull := make([][]float64, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	for i, lower := len(pts)-2, len(hull)+1; i >= 0; i-- {
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], pts[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, pts[i])
+	}
+	// Titik terakhir = titik pertama (ring tertutup)
+	return hull
+}
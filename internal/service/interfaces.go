@@ -4,26 +4,85 @@ import (
 	"context"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
+	"inspacemap/backend/pkg/oidc"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type AuthService interface {
-	Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error)
-	Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error)
-	AcceptInvitation(ctx context.Context, req models.AcceptInviteRequest) (*models.AuthResponse, error)
+	Login(ctx context.Context, req models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	Register(ctx context.Context, req models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	AcceptInvitation(ctx context.Context, req models.AcceptInviteRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.AuthResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]models.SessionDetail, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+	GetMe(ctx context.Context, userID, activeOrgID uuid.UUID) (*models.MeResponse, error)
+	SwitchOrganization(ctx context.Context, userID, sessionID, orgID uuid.UUID) (*models.AuthResponse, error)
+	StartSession(ctx context.Context, userID, orgID uuid.UUID, client models.ClientInfo) (*models.AuthResponse, error)
+	VerifyTwoFactor(ctx context.Context, req models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error)
 }
+
+// TwoFactorService: TOTP + recovery code per user, dan kebijakan wajib 2FA per organisasi
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, userID uuid.UUID) (*models.TwoFactorStatus, error)
+	BeginEnrollment(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetupResponse, error)
+	ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID uuid.UUID, req models.TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error)
+	VerifyCode(ctx context.Context, userID uuid.UUID, code string) error
+	GetPolicy(ctx context.Context, orgID uuid.UUID) (*models.TwoFactorPolicy, error)
+	UpdatePolicy(ctx context.Context, orgID, userID uuid.UUID, req models.TwoFactorPolicy) error
+}
+
+// AccountService: Alur akun lewat email (reset password, verifikasi email)
+type AccountService interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	SendEmailVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string) error
+}
+
+// ApiKeyService: Key machine-to-machine per organisasi. Key mentah hanya dikembalikan sekali saat dibuat.
+type ApiKeyService interface {
+	CreateKey(ctx context.Context, orgID, creatorID uuid.UUID, creatorPerms []string, req models.CreateApiKeyRequest) (*models.ApiKeyResponse, error)
+	ListKeys(ctx context.Context, orgID uuid.UUID) ([]models.ApiKeyDetail, error)
+	RevokeKey(ctx context.Context, orgID, keyID uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.ApiKeyPrincipal, error)
+}
+
+// SSOService: Login OIDC per organisasi (authorization code + PKCE) dan pengaturannya di Organization.Settings
+type SSOService interface {
+	StartLogin(ctx context.Context, req models.SSOStartRequest) (*models.SSOStartResponse, error)
+	CompleteLogin(ctx context.Context, req models.SSOCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	StartLink(ctx context.Context, userID uuid.UUID, req models.SSOStartRequest) (*models.SSOStartResponse, error)
+	CompleteLink(ctx context.Context, userID uuid.UUID, req models.SSOCallbackRequest) error
+	GetSettings(ctx context.Context, orgID uuid.UUID) (*models.SSOSettings, error)
+	UpdateSettings(ctx context.Context, orgID uuid.UUID, req models.SSOSettings) error
+	VerifyDomain(ctx context.Context, orgID uuid.UUID, domain string) (*models.SSODomainVerification, error)
+}
+
 type TeamService interface {
-	InviteMember(ctx context.Context, orgID uuid.UUID, inviterID uuid.UUID, req models.InviteUserRequest) error
-	RemoveMember(ctx context.Context, orgID uuid.UUID, targetUserID uuid.UUID) error
-	UpdateMemberRole(ctx context.Context, orgID uuid.UUID, req models.UpdateUserRoleRequest) error
+	InviteMember(ctx context.Context, orgID uuid.UUID, inviterID uuid.UUID, inviterPerms []string, req models.InviteUserRequest) error
+	RemoveMember(ctx context.Context, orgID uuid.UUID, grantorPerms []string, targetUserID uuid.UUID) error
+	UpdateMemberRole(ctx context.Context, orgID uuid.UUID, grantorPerms []string, req models.UpdateUserRoleRequest) error
 	GetMembersList(ctx context.Context, orgID uuid.UUID) ([]models.TeamMemberDetail, error)
+	ListInvitations(ctx context.Context, orgID uuid.UUID, status string) ([]models.InvitationDetail, error)
+	ResendInvitation(ctx context.Context, orgID, inviteID uuid.UUID) error
+	RevokeInvitation(ctx context.Context, orgID, inviteID uuid.UUID) error
+	ExpireInvitations(ctx context.Context) (int64, error)
 }
 
 type RoleService interface {
-	GetSystemRoles(ctx context.Context) ([]models.RoleDetail, error)
+	ListRoles(ctx context.Context, orgID uuid.UUID) ([]models.RoleDetail, error)
+	CreateRole(ctx context.Context, orgID uuid.UUID, grantorPerms []string, req models.CreateRoleRequest) (*models.IDResponse, error)
+	UpdateRole(ctx context.Context, orgID, roleID uuid.UUID, grantorPerms []string, req models.UpdateRoleRequest) error
+	DeleteRole(ctx context.Context, orgID, roleID uuid.UUID) error
 	GetAvailablePermissions(ctx context.Context) ([]models.PermissionNode, error)
+	VerifyPermissionRegistry(ctx context.Context) error
 }
 
 type GraphService interface {
@@ -35,7 +94,25 @@ type GraphService interface {
 	DeleteNode(ctx context.Context, nodeID uuid.UUID) error
 	DeleteConnection(ctx context.Context, fromID, toID uuid.UUID) error
 	GetEditorData(ctx context.Context, venueID uuid.UUID) (*models.ManifestResponse, error)
+	UpdateFloorGeoReference(ctx context.Context, floorID uuid.UUID, req *models.GeoReference) error
+	ExportGeoJSON(ctx context.Context, venueID uuid.UUID, revision string) (*models.GeoJSONFeatureCollection, error)
+	ExportIMDF(ctx context.Context, venueID uuid.UUID, req models.IMDFExportRequest) ([]byte, error)
 	PublishChanges(ctx context.Context, venueID uuid.UUID, req models.PublishDraftRequest) error
+	RollbackLive(ctx context.Context, venueID uuid.UUID, req models.RollbackRevisionRequest) error
+}
+
+// GraphImportService: Bulk import node/edge/area ke draft (JSON, CSV, GeoJSON)
+type GraphImportService interface {
+	ImportJSON(ctx context.Context, venueID uuid.UUID, doc models.GraphImportDocument, dryRun bool) (*models.GraphImportResult, error)
+	ImportCSV(ctx context.Context, venueID uuid.UUID, nodes, edges, areas io.Reader, dryRun bool) (*models.GraphImportResult, error)
+	ImportGeoJSON(ctx context.Context, venueID uuid.UUID, fc models.GeoJSONFeatureCollection, dryRun bool) (*models.GraphImportResult, error)
+}
+
+// VenueBundleService: Export/import venue lengkap (zip) antar organisasi / instance, dan clone venue
+type VenueBundleService interface {
+	CloneVenue(ctx context.Context, orgID, userID, venueID uuid.UUID, req models.CloneVenueRequest) (*models.CloneVenueResponse, error)
+	ExportBundle(ctx context.Context, venueID uuid.UUID, req models.VenueBundleExportRequest) (func(w io.Writer) error, error)
+	ImportBundle(ctx context.Context, orgID, userID uuid.UUID, r io.ReaderAt, size int64, req models.VenueBundleImportRequest) (*models.VenueBundleImportResult, error)
 }
 
 type OrganizationService interface {
@@ -48,9 +125,55 @@ type OrganizationService interface {
 
 type StorageProvider interface {
 	GetPresignedPutURL(ctx context.Context, bucket, key, contentType string, expiry time.Duration) (string, error)
+	GetPresignedGetURL(ctx context.Context, bucket, key string, expiry time.Duration) (string, error)
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	PutObject(ctx context.Context, bucket, key, contentType string, body io.Reader, size int64) error
+	ObjectExists(ctx context.Context, bucket, key string) (bool, error)
 	DeleteObject(ctx context.Context, bucket, key string) error
 }
 
+// Mailer: Pengirim email (implementasi: pkg/mailer SMTP, atau file/log untuk development & test).
+// htmlBody boleh kosong untuk email teks saja.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, textBody, htmlBody string) error
+}
+
+// OIDCClient: Klien identity provider OpenID Connect (implementasi: pkg/oidc)
+type OIDCClient interface {
+	AuthCodeURL(ctx context.Context, provider oidc.ProviderConfig, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, provider oidc.ProviderConfig, code, codeVerifier, nonce string) (*oidc.Claims, error)
+}
+
+// TXTResolver: DNS lookup untuk verifikasi domain SSO (dipenuhi *net.Resolver)
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// ManifestCache: Cache manifest mobile (implementasi default: pkg/cache LRU in-memory).
+// Key berformat "<venue_id>:<live_revision_id>" sehingga publish otomatis menghasilkan key baru.
+type ManifestCache interface {
+	Get(key string) (*models.ManifestResponse, bool)
+	Set(key string, manifest *models.ManifestResponse)
+	DeletePrefix(prefix string)
+}
+
+// PermissionCache: Cache hasil PermissionResolver (implementasi default: pkg/cache LRU in-memory).
+// Key berformat "<org_id>:<user_id>" sehingga satu organisasi bisa di-invalidate sekaligus.
+type PermissionCache interface {
+	Get(key string) (*models.ResolvedPermissions, bool)
+	Set(key string, perms *models.ResolvedPermissions)
+	Delete(key string)
+	DeletePrefix(prefix string)
+}
+
+// PermissionResolver: Permission user dievaluasi per request dari membership saat ini, bukan dari token.
+// Service yang mengubah role / membership wajib memanggil Invalidate*.
+type PermissionResolver interface {
+	ResolvePermissions(ctx context.Context, userID, orgID uuid.UUID) (perms []string, isMember bool, err error)
+	InvalidateMember(orgID, userID uuid.UUID)
+	InvalidateOrganization(orgID uuid.UUID)
+}
+
 type MediaService interface {
 	InitDirectUpload(ctx context.Context, orgID uuid.UUID, req models.PresignedUploadRequest) (*models.PresignedUploadResponse, error)
 	ConfirmUpload(ctx context.Context, req models.ConfirmUploadRequest) error
@@ -63,18 +186,29 @@ type AreaService interface {
 	CreateArea(ctx context.Context, req models.CreateAreaRequest) (*models.IDResponse, error)
 	UpdateArea(ctx context.Context, id uuid.UUID, req models.CreateAreaRequest) error // Re-use create request for update fields
 	DeleteArea(ctx context.Context, id uuid.UUID) error
-	GetAreaDetail(ctx context.Context, id uuid.UUID) (*models.AreaDetail, error)
+	GetAreaDetail(ctx context.Context, id uuid.UUID, access models.ManifestAccess) (*models.AreaDetail, error)
 	GetVenueAreas(ctx context.Context, venueID uuid.UUID) ([]models.AreaPinDetail, error)
 }
 
 type VenueService interface {
-	CreateVenue(ctx context.Context, req models.CreateVenueRequest) (*models.IDResponse, error)
-	UpdateVenue(ctx context.Context, id uuid.UUID, req models.UpdateVenueRequest) error
-	DeleteVenue(ctx context.Context, id uuid.UUID) error
-	GetVenueDetail(ctx context.Context, id uuid.UUID) (*models.VenueDetail, error)
+	CreateVenue(ctx context.Context, orgID uuid.UUID, req models.CreateVenueRequest) (*models.IDResponse, error)
+	UpdateVenue(ctx context.Context, orgID, id uuid.UUID, req models.UpdateVenueRequest) error
+	DeleteVenue(ctx context.Context, orgID, id uuid.UUID) error
+	GetVenueDetail(ctx context.Context, orgID, id uuid.UUID) (*models.VenueDetail, error)
 	GetVenueBySlug(ctx context.Context, slug string) (*models.VenueDetail, error)
-	ListVenues(ctx context.Context, query models.VenueQuery) ([]models.VenueListItem, int64, error)
-	GetMobileManifest(ctx context.Context, slug string) (*models.ManifestResponse, error)
+	ListVenues(ctx context.Context, orgID uuid.UUID, query models.VenueQuery) ([]models.VenueListItem, int64, error)
+	// Manifest publik dialamatkan dengan slug organisasi + slug venue (slug venue hanya unik per organisasi)
+	GetMobileManifest(ctx context.Context, orgSlug, slug string, access models.ManifestAccess) (*models.ManifestResponse, error)
+	ResolveLegacySlug(ctx context.Context, slug string) (string, error)
+	GetManifestIndex(ctx context.Context, orgSlug, slug string, access models.ManifestAccess) (*models.ManifestIndexResponse, error)
+	GetManifestFloor(ctx context.Context, orgSlug, slug string, floorID uuid.UUID, access models.ManifestAccess) (*models.ManifestFloorResponse, error)
+	GetManifestDelta(ctx context.Context, orgSlug, slug string, fromRevisionID uuid.UUID, access models.ManifestAccess) (*models.ManifestDeltaResponse, error)
+	CreateShareLink(ctx context.Context, orgID uuid.UUID, venueID uuid.UUID, req models.CreateShareLinkRequest) (*models.ShareLinkResponse, error)
+}
+
+// VenuePackageService: Paket offline (zip) untuk kiosk / field app
+type VenuePackageService interface {
+	RequestPackage(ctx context.Context, orgSlug, slug string, access models.ManifestAccess, resolution string) (*models.VenuePackageStatus, error)
 }
 type VenueGalleryService interface {
 	ReorderGallery(ctx context.Context, req models.ReorderVenueGalleryRequest) error
@@ -90,7 +224,12 @@ type AreaGalleryService interface {
 	RemoveGalleryItem(ctx context.Context, targetID, mediaID uuid.UUID) error
 }
 
+// AuditService: Log ditulis lewat antrean terbatas di background; request tidak menunggu database audit.
 type AuditService interface {
 	GetActivityLogs(ctx context.Context, orgID uuid.UUID, query models.AuditLogQueryCursor) (*models.AuditListResponse, error)
 	LogActivity(ctx context.Context, payload models.CreateAuditLogRequest)
+	// RecordChange: Snapshot before/after dari service; pelaku & organisasi diambil dari context request
+	RecordChange(ctx context.Context, action, entityName, entityID string, before, after any)
+	// Close: Tolak log baru dan tunggu antrean tersimpan (shutdown / test)
+	Close()
 }
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/pkg/msgpack"
+
+	"github.com/google/uuid"
+)
+
+// CompactManifestMediaType: Format biner manifest (MessagePack) untuk jaringan seluler.
+// JSON tetap menjadi default; format ini hanya dikirim jika diminta lewat header Accept.
+const (
+	CompactManifestMediaType = "application/vnd.inspacemap.manifest+msgpack"
+	CompactManifestVersion   = 1
+)
+
+// EncodeCompactManifest: Encode ManifestResponse ke MessagePack dengan tabel index.
+// UUID ditulis sekali sebagai 16 byte biner, relasi antar data memakai index array:
+//
+//	{
+//	  "v": 1, "venue_id": bin16, "venue_name": str, "revision_id": bin16,
+//	  "last_updated": unix millis, "start": node index (-1 jika tidak ada),
+//	  "edge_types": [str],
+//	  "areas":  [[id bin16, name]],
+//	  "floors": [[id bin16, name, level_index, map_image_url, width, height, first_node, node_count]],
+//	  "nodes":  [[id bin16, x, y, panorama_url, rotation_offset f32, area index | -1, label]],
+//	  "edges":  [[from node, to node, heading f32, distance f32, edge_type index, is_active]]
+//	}
+//
+// Node disusun berurutan per floor (floor ke-i = nodes[first_node : first_node+node_count]).
+// Edge ke node di luar manifest (node nonaktif) tidak ikut dikirim karena tidak bisa dinavigasi.
+func EncodeCompactManifest(m *models.ManifestResponse) []byte {
+	// 1. Bangun tabel index
+	nodeIndex := make(map[uuid.UUID]int)
+	areaIndex := make(map[uuid.UUID]int)
+	var areas []models.NodeData
+	typeIndex := make(map[string]int)
+	var edgeTypes []string
+	edgeCount := 0
+
+	for _, floor := range m.Floors {
+		for _, node := range floor.Nodes {
+			nodeIndex[node.ID] = len(nodeIndex)
+			if node.AreaID != nil {
+				if _, ok := areaIndex[*node.AreaID]; !ok {
+					areaIndex[*node.AreaID] = len(areas)
+					areas = append(areas, node)
+				}
+			}
+		}
+	}
+	for _, floor := range m.Floors {
+		for _, node := range floor.Nodes {
+			for _, n := range node.Neighbors {
+				if _, ok := nodeIndex[n.TargetNodeID]; !ok {
+					continue
+				}
+				edgeCount++
+				if _, ok := typeIndex[n.Type]; !ok {
+					typeIndex[n.Type] = len(edgeTypes)
+					edgeTypes = append(edgeTypes, n.Type)
+				}
+			}
+		}
+	}
+
+	// 2. Tulis payload
+	enc := msgpack.NewEncoder(64 * (len(nodeIndex) + 1))
+	enc.WriteMapHeader(11)
+
+	enc.WriteString("v")
+	enc.WriteInt(CompactManifestVersion)
+	enc.WriteString("venue_id")
+	writeUUID(enc, m.VenueID)
+	enc.WriteString("venue_name")
+	enc.WriteString(m.VenueName)
+	enc.WriteString("revision_id")
+	writeUUID(enc, m.RevisionID)
+	enc.WriteString("last_updated")
+	enc.WriteInt(m.LastUpdated.UnixMilli())
+
+	enc.WriteString("start")
+	if idx, ok := nodeIndex[m.StartNodeID]; ok {
+		enc.WriteInt(int64(idx))
+	} else {
+		enc.WriteInt(-1)
+	}
+
+	enc.WriteString("edge_types")
+	enc.WriteArrayHeader(len(edgeTypes))
+	for _, t := range edgeTypes {
+		enc.WriteString(t)
+	}
+
+	enc.WriteString("areas")
+	enc.WriteArrayHeader(len(areas))
+	for _, node := range areas {
+		enc.WriteArrayHeader(2)
+		writeUUID(enc, *node.AreaID)
+		enc.WriteString(node.AreaName)
+	}
+
+	enc.WriteString("floors")
+	enc.WriteArrayHeader(len(m.Floors))
+	firstNode := 0
+	for _, floor := range m.Floors {
+		enc.WriteArrayHeader(8)
+		writeUUID(enc, floor.ID)
+		enc.WriteString(floor.LevelName)
+		enc.WriteInt(int64(floor.LevelIndex))
+		enc.WriteString(floor.MapImageURL)
+		enc.WriteInt(int64(floor.MapWidth))
+		enc.WriteInt(int64(floor.MapHeight))
+		enc.WriteInt(int64(firstNode))
+		enc.WriteInt(int64(len(floor.Nodes)))
+		firstNode += len(floor.Nodes)
+	}
+
+	enc.WriteString("nodes")
+	enc.WriteArrayHeader(len(nodeIndex))
+	for _, floor := range m.Floors {
+		for _, node := range floor.Nodes {
+			enc.WriteArrayHeader(7)
+			writeUUID(enc, node.ID)
+			enc.WriteInt(int64(node.X))
+			enc.WriteInt(int64(node.Y))
+			enc.WriteString(node.PanoramaURL)
+			enc.WriteFloat32(float32(node.RotationOffset))
+			if node.AreaID != nil {
+				enc.WriteInt(int64(areaIndex[*node.AreaID]))
+			} else {
+				enc.WriteInt(-1)
+			}
+			enc.WriteString(node.Label)
+		}
+	}
+
+	enc.WriteString("edges")
+	enc.WriteArrayHeader(edgeCount)
+	for _, floor := range m.Floors {
+		for _, node := range floor.Nodes {
+			for _, n := range node.Neighbors {
+				to, ok := nodeIndex[n.TargetNodeID]
+				if !ok {
+					continue
+				}
+				enc.WriteArrayHeader(6)
+				enc.WriteInt(int64(nodeIndex[node.ID]))
+				enc.WriteInt(int64(to))
+				enc.WriteFloat32(float32(n.Heading))
+				enc.WriteFloat32(float32(n.Distance))
+				enc.WriteInt(int64(typeIndex[n.Type]))
+				enc.WriteBool(n.IsActive)
+			}
+		}
+	}
+
+	return enc.Bytes()
+}
+
+func writeUUID(enc *msgpack.Encoder, id uuid.UUID) {
+	enc.WriteBinary(id[:])
+}
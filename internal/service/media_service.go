@@ -7,6 +7,7 @@ import (
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
 	"path/filepath"
 	"time"
 
@@ -15,7 +16,9 @@ import (
 
 type mediaService struct {
 	mediaRepo repository.MediaAssetRepository
+	ownership repository.OwnershipRepository
 	storage   StorageProvider // Injected Dependency (S3/MinIO)
+	audit     AuditService
 
 	// Config (Sebaiknya dari Env)
 	bucketName string
@@ -24,15 +27,19 @@ type mediaService struct {
 
 func NewMediaService(
 	repo repository.MediaAssetRepository,
+	ownership repository.OwnershipRepository,
 	storage StorageProvider,
 	bucketName string,
 	cdnBaseURL string,
+	audit AuditService,
 ) MediaService {
 	return &mediaService{
 		mediaRepo:  repo,
+		ownership:  ownership,
 		storage:    storage,
 		bucketName: bucketName,
 		cdnBaseURL: cdnBaseURL,
+		audit:      audit,
 	}
 }
 
@@ -91,6 +98,10 @@ func (s *mediaService) InitDirectUpload(ctx context.Context, orgID uuid.UUID, re
 
 // 2. Confirm Upload
 func (s *mediaService) ConfirmUpload(ctx context.Context, req models.ConfirmUploadRequest) error {
+	if err := requireOwned(ctx, "asset", s.ownership.MediaBelongsTo, req.AssetID); err != nil {
+		return err
+	}
+
 	// Ambil asset
 	asset, err := s.mediaRepo.GetByID(ctx, req.AssetID)
 	if err != nil {
@@ -111,16 +122,30 @@ func (s *mediaService) ConfirmUpload(ctx context.Context, req models.ConfirmUplo
 
 // 3. Get Asset
 func (s *mediaService) GetAsset(ctx context.Context, id uuid.UUID) (*entity.MediaAsset, error) {
+	if err := requireOwned(ctx, "asset", s.ownership.MediaBelongsTo, id); err != nil {
+		return nil, err
+	}
 	return s.mediaRepo.GetByID(ctx, id)
 }
 
 // 4. List Assets
 func (s *mediaService) ListAssets(ctx context.Context, query models.MediaAssetQuery) ([]entity.MediaAsset, int64, error) {
+	// Selalu di-scope ke organisasi pemanggil, organization_id dari client diabaikan
+	orgID := utils.OrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		return nil, 0, errors.New("organization context is required")
+	}
+	query.OrganizationID = &orgID
+
 	return s.mediaRepo.PagedMediaAssets(ctx, query)
 }
 
 // 5. Delete Asset
 func (s *mediaService) DeleteAsset(ctx context.Context, id uuid.UUID) error {
+	if err := requireOwned(ctx, "asset", s.ownership.MediaBelongsTo, id); err != nil {
+		return err
+	}
+
 	asset, err := s.mediaRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
@@ -131,5 +156,14 @@ func (s *mediaService) DeleteAsset(ctx context.Context, id uuid.UUID) error {
 	_ = s.storage.DeleteObject(ctx, asset.Bucket, asset.Key)
 
 	// B. Hapus dari DB (Soft Delete)
-	return s.mediaRepo.Delete(ctx, id)
+	if err := s.mediaRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "MEDIA_DELETE", "MediaAsset", asset.ID, map[string]interface{}{
+		"file_name": asset.FileName,
+		"type":      asset.Type,
+		"key":       asset.Key,
+		"size":      asset.SizeInBytes,
+	}, nil)
+	return nil
 }
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound: Resource tidak ada ATAU milik organisasi lain.
+// Sengaja tidak dibedakan agar tenant lain tidak bisa menebak ID yang valid.
+var ErrNotFound = errors.New("not found")
+
+// belongsToFunc: Salah satu method repository.OwnershipRepository
+type belongsToFunc func(ctx context.Context, orgID, id uuid.UUID) (bool, error)
+
+// requireOwned: Organisasi pemanggil diambil dari context (dipasang middleware auth).
+// Context tanpa organisasi selalu ditolak.
+func requireOwned(ctx context.Context, resource string, belongsTo belongsToFunc, ids ...uuid.UUID) error {
+	orgID := utils.OrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		return fmt.Errorf("%s %w", resource, ErrNotFound)
+	}
+	for _, id := range ids {
+		ok, err := belongsTo(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s %w", resource, ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// requireOwnedVenue: Sama dengan requireOwned, tapi memakai ErrVenueNotFound yang sudah dikenal handler venue
+func requireOwnedVenue(ctx context.Context, belongsTo belongsToFunc, venueID uuid.UUID) error {
+	if err := requireOwned(ctx, "venue", belongsTo, venueID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrVenueNotFound
+		}
+		return err
+	}
+	return nil
+}
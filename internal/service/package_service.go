@@ -0,0 +1,455 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	packageFormatVersion = 1
+	packageDownloadTTL   = 15 * time.Minute
+	packageBuildTimeout  = 30 * time.Minute
+)
+
+var ErrInvalidPanoramaResolution = errors.New("panorama resolution must be one of: full, preview, none")
+
+type packageJob struct {
+	status string
+	err    error
+}
+
+type venuePackageService struct {
+	venueRepo       repository.VenueRepository
+	areaRepo        repository.AreaRepository
+	areaGalleryRepo repository.AreaGalleryRepository
+	storage         StorageProvider
+
+	bucketName string
+	cdnBaseURL string // Untuk menurunkan object key dari ThumbnailURL
+
+	// Job yang sedang berjalan / baru gagal, key = object key paket
+	mu   sync.Mutex
+	jobs map[string]*packageJob
+}
+
+func NewVenuePackageService(
+	vRepo repository.VenueRepository,
+	aRepo repository.AreaRepository,
+	agRepo repository.AreaGalleryRepository,
+	storage StorageProvider,
+	bucketName string,
+	cdnBaseURL string,
+) VenuePackageService {
+	return &venuePackageService{
+		venueRepo:       vRepo,
+		areaRepo:        aRepo,
+		areaGalleryRepo: agRepo,
+		storage:         storage,
+		bucketName:      bucketName,
+		cdnBaseURL:      strings.TrimSuffix(cdnBaseURL, "/"),
+		jobs:            make(map[string]*packageJob),
+	}
+}
+
+// RequestPackage: Kembalikan link download jika paket revisi live sudah ada di storage,
+// jika belum, mulai build di background (satu job per venue+revisi+resolusi).
+func (s *venuePackageService) RequestPackage(ctx context.Context, orgSlug, slug string, access models.ManifestAccess, resolution string) (*models.VenuePackageStatus, error) {
+	if resolution == "" {
+		resolution = models.PackagePanoramaFull
+	}
+	switch resolution {
+	case models.PackagePanoramaFull, models.PackagePanoramaPreview, models.PackagePanoramaNone:
+	default:
+		return nil, ErrInvalidPanoramaResolution
+	}
+
+	// 1. Aturan akses sama dengan manifest (visibility, tenant, share link)
+	share, err := parseShareAccess(access)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.venueRepo.GetManifestInfo(ctx, orgSlug, slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManifestAccess(info, share, access.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	key := packageObjectKey(info.ID, info.LiveRevisionID, resolution)
+	status := &models.VenuePackageStatus{
+		VenueID:            info.ID,
+		RevisionID:         info.LiveRevisionID,
+		PanoramaResolution: resolution,
+	}
+
+	// 2. Job yang masih berjalan / gagal (gagal dilaporkan sekali, request berikutnya mencoba ulang)
+	s.mu.Lock()
+	job, found := s.jobs[key]
+	if found && job.status == models.PackageStatusFailed {
+		delete(s.jobs, key)
+	}
+	s.mu.Unlock()
+
+	if found {
+		status.Status = job.status
+		if job.err != nil {
+			status.Error = job.err.Error()
+		}
+		return status, nil
+	}
+
+	// 3. Paket untuk revisi ini sudah pernah dibuat (key berubah otomatis setiap publish)
+	exists, err := s.storage.ObjectExists(ctx, s.bucketName, key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		url, err := s.storage.GetPresignedGetURL(ctx, s.bucketName, key, packageDownloadTTL)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := time.Now().Add(packageDownloadTTL)
+		status.Status = models.PackageStatusReady
+		status.DownloadURL = url
+		status.ExpiresAt = &expiresAt
+		return status, nil
+	}
+
+	// 4. Mulai build baru
+	s.mu.Lock()
+	if _, running := s.jobs[key]; !running {
+		s.jobs[key] = &packageJob{status: models.PackageStatusBuilding}
+		go s.runBuild(info.ID, info.LiveRevisionID, resolution, key)
+	}
+	s.mu.Unlock()
+
+	status.Status = models.PackageStatusBuilding
+	return status, nil
+}
+
+func (s *venuePackageService) runBuild(venueID, revisionID uuid.UUID, resolution, key string) {
+	// Context request sudah selesai saat job berjalan, jadi pakai context sendiri
+	ctx, cancel := context.WithTimeout(context.Background(), packageBuildTimeout)
+	defer cancel()
+
+	err := s.buildPackage(ctx, venueID, revisionID, resolution, key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Printf("offline package %s failed: %v", key, err)
+		s.jobs[key] = &packageJob{status: models.PackageStatusFailed, err: err}
+		return
+	}
+	delete(s.jobs, key)
+}
+
+func (s *venuePackageService) buildPackage(ctx context.Context, venueID, revisionID uuid.UUID, resolution, key string) error {
+	// 1. Ambil semua data
+	venue, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil {
+		return err
+	}
+	revision, err := s.venueRepo.GetRevisionManifestData(ctx, venueID, revisionID)
+	if err != nil {
+		return err
+	}
+	areas, err := s.areaRepo.GetByVenueID(ctx, venueID)
+	if err != nil {
+		return err
+	}
+	areaGallery, err := s.areaGalleryRepo.GetByVenueID(ctx, venueID)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "venue-package-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	generatedAt := time.Now().UTC()
+	pw := &packageWriter{
+		zw:         zip.NewWriter(tmp),
+		modified:   generatedAt,
+		localPaths: make(map[string]string),
+		written:    make(map[string]bool),
+	}
+
+	// 2. Media: peta lantai, panorama, cover & gallery
+	for _, floor := range revision.Floors {
+		if floor.MapImage != nil {
+			if err := s.addAsset(ctx, pw, "maps", floor.MapImage, false); err != nil {
+				return err
+			}
+		}
+		if resolution == models.PackagePanoramaNone {
+			continue
+		}
+		for _, node := range floor.Nodes {
+			if node.Panorama != nil {
+				if err := s.addAsset(ctx, pw, "panoramas", node.Panorama, resolution == models.PackagePanoramaPreview); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if venue.CoverImage != nil {
+		if err := s.addAsset(ctx, pw, "gallery", venue.CoverImage, false); err != nil {
+			return err
+		}
+	}
+	for i := range venue.Gallery {
+		if err := s.addAsset(ctx, pw, "gallery", &venue.Gallery[i].MediaAsset, false); err != nil {
+			return err
+		}
+	}
+	for i := range areas {
+		if areas[i].CoverImage != nil {
+			if err := s.addAsset(ctx, pw, "areas", areas[i].CoverImage, false); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range areaGallery {
+		if !areaGallery[i].IsVisible {
+			continue
+		}
+		if err := s.addAsset(ctx, pw, "areas", &areaGallery[i].MediaAsset, false); err != nil {
+			return err
+		}
+	}
+
+	// 3. Data JSON dengan URL yang sudah menunjuk ke file lokal di dalam zip
+	manifest := buildManifest(venue, revision)
+	for i := range manifest.Floors {
+		floor := &manifest.Floors[i]
+		floor.MapImageURL = pw.localURL(floor.MapImageURL)
+		for j := range floor.Nodes {
+			floor.Nodes[j].PanoramaURL = pw.localURL(floor.Nodes[j].PanoramaURL)
+		}
+	}
+
+	detail := mapVenueDetail(venue)
+	detail.CoverImageURL = pw.localURL(detail.CoverImageURL)
+	for i := range detail.Gallery {
+		detail.Gallery[i].URL = pw.localURL(detail.Gallery[i].URL)
+		detail.Gallery[i].ThumbnailURL = pw.localURL(detail.Gallery[i].ThumbnailURL)
+	}
+	for i := range detail.PointsOfInterest {
+		detail.PointsOfInterest[i].ThumbnailURL = pw.localURL(detail.PointsOfInterest[i].ThumbnailURL)
+	}
+
+	if err := pw.addJSON("manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := pw.addJSON("venue.json", detail); err != nil {
+		return err
+	}
+	if err := pw.addJSON("areas.json", mapPackageAreas(areas, areaGallery, pw.localURL)); err != nil {
+		return err
+	}
+
+	// 4. package.json terakhir karena berisi checksum semua file lain
+	contents := models.PackageContents{
+		FormatVersion:      packageFormatVersion,
+		VenueID:            venue.ID,
+		VenueSlug:          venue.Slug,
+		RevisionID:         revision.ID,
+		GeneratedAt:        generatedAt,
+		PanoramaResolution: resolution,
+		Files:              pw.files,
+	}
+	if err := pw.addJSON("package.json", contents); err != nil {
+		return err
+	}
+	if err := pw.zw.Close(); err != nil {
+		return err
+	}
+
+	// 5. Upload ke object storage
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.storage.PutObject(ctx, s.bucketName, key, "application/zip", tmp, size)
+}
+
+// addAsset: Salin file media dari storage ke zip. preferPreview memakai thumbnail jika tersedia.
+func (s *venuePackageService) addAsset(ctx context.Context, pw *packageWriter, dir string, asset *entity.MediaAsset, preferPreview bool) error {
+	if asset == nil || asset.ID == uuid.Nil {
+		return nil
+	}
+
+	objectKey, suffix, checksum := asset.Key, "", asset.Checksum
+	if preferPreview {
+		if thumbKey, ok := s.objectKeyFromURL(asset.ThumbnailURL); ok {
+			// Checksum upload hanya berlaku untuk file asli
+			objectKey, suffix, checksum = thumbKey, "-preview", ""
+		}
+	}
+
+	path := fmt.Sprintf("media/%s/%s%s%s", dir, asset.ID, suffix, filepath.Ext(objectKey))
+	pw.mapURL(asset.PublicURL, path)
+	pw.mapURL(asset.ThumbnailURL, path)
+	if pw.written[path] {
+		return nil
+	}
+
+	bucket := asset.Bucket
+	if bucket == "" {
+		bucket = s.bucketName
+	}
+	body, err := s.storage.GetObject(ctx, bucket, objectKey)
+	if err != nil {
+		return fmt.Errorf("failed to read media %s: %w", asset.ID, err)
+	}
+	defer body.Close()
+
+	mediaID := asset.ID
+	return pw.addFile(path, body, &mediaID, checksum)
+}
+
+// objectKeyFromURL: URL publik = cdnBaseURL + "/" + key (lihat InitDirectUpload)
+func (s *venuePackageService) objectKeyFromURL(url string) (string, bool) {
+	prefix := s.cdnBaseURL + "/"
+	if url == "" || !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
+
+func packageObjectKey(venueID, revisionID uuid.UUID, resolution string) string {
+	return fmt.Sprintf("packages/%s/%s-%s.zip", venueID, revisionID, resolution)
+}
+
+func mapPackageAreas(areas []entity.Area, gallery []entity.AreaGalleryItem, localURL func(string) string) []models.PackageArea {
+	galleryByArea := make(map[uuid.UUID][]models.AreaGalleryDetail)
+	for _, item := range gallery {
+		if !item.IsVisible {
+			continue
+		}
+		galleryByArea[item.AreaID] = append(galleryByArea[item.AreaID], models.AreaGalleryDetail{
+			MediaID:      item.MediaAssetID,
+			URL:          localURL(item.MediaAsset.PublicURL),
+			ThumbnailURL: localURL(item.MediaAsset.ThumbnailURL),
+			Caption:      item.Caption,
+			SortOrder:    item.SortOrder,
+		})
+	}
+
+	result := make([]models.PackageArea, 0, len(areas))
+	for _, area := range areas {
+		coverURL := ""
+		if area.CoverImage != nil {
+			coverURL = localURL(area.CoverImage.PublicURL)
+		}
+		items := galleryByArea[area.ID]
+		if items == nil {
+			items = []models.AreaGalleryDetail{}
+		}
+
+		result = append(result, models.PackageArea{
+			ID:            area.ID,
+			FloorID:       area.FloorID,
+			Name:          area.Name,
+			Slug:          area.Slug,
+			Label:         area.Label,
+			Description:   area.Description,
+			Category:      area.Category,
+			Coordinates:   models.GeoPoint{Latitude: area.Latitude, Longitude: area.Longitude},
+			MapX:          area.MapX,
+			MapY:          area.MapY,
+			CoverImageURL: coverURL,
+			Gallery:       items,
+		})
+	}
+	return result
+}
+
+// packageWriter: zip.Writer + pencatatan checksum & pemetaan URL online -> path lokal
+type packageWriter struct {
+	zw         *zip.Writer
+	modified   time.Time
+	files      []models.PackageFile
+	localPaths map[string]string
+	written    map[string]bool
+}
+
+func (w *packageWriter) mapURL(url, path string) {
+	if url == "" {
+		return
+	}
+	if _, ok := w.localPaths[url]; !ok {
+		w.localPaths[url] = path
+	}
+}
+
+func (w *packageWriter) localURL(url string) string {
+	if path, ok := w.localPaths[url]; ok {
+		return path
+	}
+	return url
+}
+
+func (w *packageWriter) addFile(path string, r io.Reader, mediaID *uuid.UUID, checksum string) error {
+	// Gambar sudah terkompresi (jpg/png/webp), cukup disimpan apa adanya
+	method := zip.Store
+	if strings.HasSuffix(path, ".json") {
+		method = zip.Deflate
+	}
+
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{Name: path, Method: method, Modified: w.modified})
+	if err != nil {
+		return err
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(fw, hasher), r)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	w.written[path] = true
+	w.files = append(w.files, models.PackageFile{
+		Path:        path,
+		MediaID:     mediaID,
+		SizeInBytes: size,
+		SHA256:      hex.EncodeToString(hasher.Sum(nil)),
+		Checksum:    checksum,
+	})
+	return nil
+}
+
+func (w *packageWriter) addJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.addFile(path, bytes.NewReader(data), nil, "")
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type permissionResolver struct {
+	orgMemberRepo repository.OrganizationMemberRepository
+	cache         PermissionCache
+}
+
+// NewPermissionResolver: TTL cache sebaiknya pendek (detik); invalidasi hanya berlaku di instance ini,
+// instance lain mengikuti setelah TTL habis.
+func NewPermissionResolver(orgMemberRepo repository.OrganizationMemberRepository, cache PermissionCache) PermissionResolver {
+	return &permissionResolver{orgMemberRepo: orgMemberRepo, cache: cache}
+}
+
+func permissionCacheKey(orgID, userID uuid.UUID) string {
+	return orgID.String() + ":" + userID.String()
+}
+
+func (r *permissionResolver) ResolvePermissions(ctx context.Context, userID, orgID uuid.UUID) ([]string, bool, error) {
+	key := permissionCacheKey(orgID, userID)
+	if cached, ok := r.cache.Get(key); ok {
+		return cached.Permissions, cached.IsMember, nil
+	}
+
+	resolved := &models.ResolvedPermissions{Permissions: []string{}}
+	if orgID != uuid.Nil {
+		member, err := r.orgMemberRepo.FindMember(ctx, orgID, userID)
+		if err != nil {
+			return nil, false, err
+		}
+		if member != nil {
+			resolved.IsMember = true
+			for _, p := range member.Role.Permissions {
+				resolved.Permissions = append(resolved.Permissions, p.Key)
+			}
+			resolved.Permissions, _ = applyTwoFactorPolicy(&member.Organization, &member.User, resolved.Permissions)
+		}
+	}
+
+	// Hasil "bukan anggota" ikut di-cache supaya token lama tidak membanjiri database
+	r.cache.Set(key, resolved)
+	return resolved.Permissions, resolved.IsMember, nil
+}
+
+func (r *permissionResolver) InvalidateMember(orgID, userID uuid.UUID) {
+	r.cache.Delete(permissionCacheKey(orgID, userID))
+}
+
+func (r *permissionResolver) InvalidateOrganization(orgID uuid.UUID) {
+	r.cache.DeletePrefix(orgID.String() + ":")
+}
@@ -2,46 +2,235 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 type roleService struct {
 	roleRepo       repository.RoleRepository
 	permissionRepo repository.PermissionRepository // [FIX] Tambahkan dependensi ini
+	permResolver   PermissionResolver
+	audit          AuditService
 }
 
 // [FIX] Update Constructor untuk menerima PermissionRepo
-func NewRoleService(rRepo repository.RoleRepository, pRepo repository.PermissionRepository) RoleService {
+func NewRoleService(rRepo repository.RoleRepository, pRepo repository.PermissionRepository, permResolver PermissionResolver, audit AuditService) RoleService {
 	return &roleService{
 		roleRepo:       rRepo,
 		permissionRepo: pRepo,
+		permResolver:   permResolver,
+		audit:          audit,
 	}
 }
 
-func (s *roleService) GetSystemRoles(ctx context.Context) ([]models.RoleDetail, error) {
-	roles, err := s.roleRepo.GetAll(ctx)
+var (
+	// ErrRoleNotFound: Role tidak ada atau role custom milik organisasi lain
+	ErrRoleNotFound = fmt.Errorf("role %w", ErrNotFound)
+	// ErrRoleProtected: Role bawaan (Owner/Editor/Viewer) dipakai semua organisasi
+	ErrRoleProtected = errors.New("built-in roles cannot be modified or deleted")
+	// ErrRoleNameTaken: Nama role unik per organisasi, termasuk terhadap nama role bawaan
+	ErrRoleNameTaken = errors.New("role name is already used in this organization")
+	// ErrRoleInUse: Role masih dipakai member atau undangan pending
+	ErrRoleInUse = errors.New("role is still assigned")
+	// ErrPermissionNotHeld: Role yang dibuat, diubah, atau diberikan tidak boleh melebihi permission pemberinya
+	ErrPermissionNotHeld = errors.New("cannot grant a permission you do not have")
+)
+
+// ListRoles: Role bawaan + role custom organisasi
+func (s *roleService) ListRoles(ctx context.Context, orgID uuid.UUID) ([]models.RoleDetail, error) {
+	roles, err := s.roleRepo.GetByOrganizationID(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
 
-	var details []models.RoleDetail
+	details := make([]models.RoleDetail, 0, len(roles))
+	for i := range roles {
+		details = append(details, mapRoleDetail(&roles[i]))
+	}
+	return details, nil
+}
+
+func (s *roleService) CreateRole(ctx context.Context, orgID uuid.UUID, grantorPerms []string, req models.CreateRoleRequest) (*models.IDResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureRoleNameAvailable(ctx, orgID, uuid.Nil, name); err != nil {
+		return nil, err
+	}
+	perms, err := s.resolvePermissions(ctx, req.PermissionIDs, grantorPerms)
+	if err != nil {
+		return nil, err
+	}
+
+	role := entity.Role{
+		OrganizationID: &orgID,
+		Name:           name,
+		Description:    req.Description,
+		Permissions:    perms,
+	}
+	if err := s.roleRepo.Create(ctx, &role); err != nil {
+		return nil, err
+	}
+	recordChange(ctx, s.audit, "ROLE_CREATE", "Role", role.ID, nil, roleAuditSnapshot(&role, role.Permissions))
+	return &models.IDResponse{ID: role.ID}, nil
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, orgID, roleID uuid.UUID, grantorPerms []string, req models.UpdateRoleRequest) error {
+	role, err := s.getCustomRole(ctx, orgID, roleID)
+	if err != nil {
+		return err
+	}
+	before := roleAuditSnapshot(role, role.Permissions)
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := s.ensureRoleNameAvailable(ctx, orgID, role.ID, name); err != nil {
+			return err
+		}
+		role.Name = name
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	var perms []entity.Permission
+	if req.PermissionIDs != nil {
+		if perms, err = s.resolvePermissions(ctx, *req.PermissionIDs, grantorPerms); err != nil {
+			return err
+		}
+	}
+	if err := s.roleRepo.UpdateWithPermissions(ctx, role, perms); err != nil {
+		return err
+	}
+
+	// Role custom hanya dipakai organisasinya sendiri
+	if perms != nil {
+		s.permResolver.InvalidateOrganization(orgID)
+	} else {
+		perms = role.Permissions
+	}
+	recordChange(ctx, s.audit, "ROLE_UPDATE", "Role", role.ID, before, roleAuditSnapshot(role, perms))
+	return nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, orgID, roleID uuid.UUID) error {
+	role, err := s.getCustomRole(ctx, orgID, roleID)
+	if err != nil {
+		return err
+	}
+
+	members, invites, err := s.roleRepo.CountAssignments(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+	if members > 0 || invites > 0 {
+		return fmt.Errorf("%w to %d member(s) and %d pending invitation(s)", ErrRoleInUse, members, invites)
+	}
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return err
+	}
+	recordChange(ctx, s.audit, "ROLE_DELETE", "Role", role.ID, roleAuditSnapshot(role, role.Permissions), nil)
+	return nil
+}
+
+// roleAuditSnapshot: Nama + key permission (ID permission tidak bermakna saat log dibaca)
+func roleAuditSnapshot(role *entity.Role, perms []entity.Permission) map[string]interface{} {
+	keys := make([]string, 0, len(perms))
+	for _, p := range perms {
+		keys = append(keys, p.Key)
+	}
+	sort.Strings(keys)
+	return map[string]interface{}{
+		"name":        role.Name,
+		"description": role.Description,
+		"permissions": keys,
+	}
+}
+
+// getCustomRole: Hanya role custom milik organisasi ini yang boleh diubah
+func (s *roleService) getCustomRole(ctx context.Context, orgID, roleID uuid.UUID) (*entity.Role, error) {
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if role.IsSystem() {
+		return nil, ErrRoleProtected
+	}
+	if *role.OrganizationID != orgID {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (s *roleService) ensureRoleNameAvailable(ctx context.Context, orgID, roleID uuid.UUID, name string) error {
+	if len(name) < 3 {
+		return errors.New("role name must be at least 3 characters")
+	}
+	roles, err := s.roleRepo.GetByOrganizationID(ctx, orgID)
+	if err != nil {
+		return err
+	}
 	for _, r := range roles {
-		perms, _ := s.roleRepo.GetPermissions(ctx, r.ID)
+		if r.ID != roleID && strings.EqualFold(r.Name, name) {
+			return ErrRoleNameTaken
+		}
+	}
+	return nil
+}
+
+// resolvePermissions: Semua ID harus ada di katalog permission dan dimiliki pemberi role
+func (s *roleService) resolvePermissions(ctx context.Context, ids []uuid.UUID, grantorPerms []string) ([]entity.Permission, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("role must have at least one permission")
+	}
+	perms, err := s.permissionRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
 
-		var permKeys []string
-		for _, p := range perms {
-			permKeys = append(permKeys, p.Key)
+	found := make(map[uuid.UUID]bool, len(perms))
+	for _, p := range perms {
+		found[p.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("unknown permission %s", id)
 		}
+	}
+	if err := requireHeldPermissions(perms, grantorPerms); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
 
-		details = append(details, models.RoleDetail{
-			ID:          r.ID,
-			Name:        r.Name,
-			Permissions: permKeys,
-		})
+// requireHeldPermissions: Seperti validateScopes API key, pemberi tidak bisa menaikkan hak aksesnya lewat role
+func requireHeldPermissions(perms []entity.Permission, held []string) error {
+	for _, p := range perms {
+		if !slices.Contains(held, p.Key) {
+			return fmt.Errorf("%w: %s", ErrPermissionNotHeld, p.Key)
+		}
 	}
+	return nil
+}
 
-	return details, nil
+func mapRoleDetail(r *entity.Role) models.RoleDetail {
+	permKeys := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		permKeys = append(permKeys, p.Key)
+	}
+	return models.RoleDetail{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		IsSystem:    r.IsSystem(),
+		Permissions: permKeys,
+	}
 }
 
 // [FIX] Implementasi Method yang Hilang
@@ -79,3 +268,35 @@ func (s *roleService) GetAvailablePermissions(ctx context.Context) ([]models.Per
 
 	return nodes, nil
 }
+
+// VerifyPermissionRegistry: Dipanggil saat startup. Permission di database harus sama persis
+// dengan entity.PermissionRegistry, jika tidak route yang dijaga permission tsb tidak bisa diakses siapa pun.
+func (s *roleService) VerifyPermissionRegistry(ctx context.Context) error {
+	perms, err := s.permissionRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	inDB := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		inDB[p.Key] = true
+	}
+
+	var missing, unknown []string
+	for _, p := range entity.PermissionRegistry {
+		if !inDB[p.Key] {
+			missing = append(missing, p.Key)
+		}
+		delete(inDB, p.Key)
+	}
+	for key := range inDB {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+
+	if len(missing) == 0 && len(unknown) == 0 {
+		return nil
+	}
+	return fmt.Errorf("permission registry mismatch (run the seeder): missing in database [%s], not in registry [%s]",
+		strings.Join(missing, ", "), strings.Join(unknown, ", "))
+}
@@ -0,0 +1,560 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/oidc"
+	"inspacemap/backend/pkg/utils"
+	"log"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// SSOStateTTL: Batas waktu antara StartLogin dan callback dari identity provider
+	SSOStateTTL = 10 * time.Minute
+	// ssoSettingsKey: Lokasi SSOSettings di Organization.Settings
+	ssoSettingsKey = "sso"
+	// ssoDefaultRole: Role anggota auto-join jika SSOSettings.DefaultRoleID kosong
+	ssoDefaultRole = "Viewer"
+	// ssoDomainRecordPrefix & ssoDomainRecordValue: Record TXT "<prefix><domain>" berisi "<value><token>"
+	ssoDomainRecordPrefix = "_inspacemap-verification."
+	ssoDomainRecordValue  = "inspacemap-domain-verification="
+)
+
+var (
+	// ErrSSOProviderNotFound: Organisasi tidak ada / nonaktif atau provider tidak dikonfigurasi (tidak dibedakan)
+	ErrSSOProviderNotFound = fmt.Errorf("sso provider %w", ErrNotFound)
+	// ErrInvalidSSOState: State palsu / kedaluwarsa atau code_verifier bukan pasangannya
+	ErrInvalidSSOState = errors.New("invalid or expired sso state")
+	// ErrSSOLoginFailed: Identity provider tidak bisa dihubungi atau menolak code / ID token
+	ErrSSOLoginFailed = errors.New("sso login failed")
+	// ErrSSOEmailNotVerified: Akun baru / penautan akun butuh email yang diverifikasi provider
+	ErrSSOEmailNotVerified = errors.New("identity provider did not return a verified email")
+	// ErrSSONotMember: Bukan anggota dan domain email tidak termasuk auto-join yang sudah diverifikasi
+	ErrSSONotMember = errors.New("account is not a member of this organization")
+	// ErrSSOLinkRequired: Email sama dengan akun lokal belum membuktikan kepemilikan akun; tautkan dari sesi login
+	ErrSSOLinkRequired = errors.New("an account with this email already exists, sign in and link this identity from your account first")
+	// ErrSSOIdentityInUse: Identitas provider sudah tertaut ke akun lain
+	ErrSSOIdentityInUse = errors.New("this identity is already linked to another account")
+	// ErrSSODomainLookupFailed: DNS tidak bisa dihubungi saat verifikasi domain
+	ErrSSODomainLookupFailed = errors.New("domain verification lookup failed, try again later")
+	// ErrInvalidSSOSettings: Provider / domain / role di pengaturan SSO tidak valid
+	ErrInvalidSSOSettings = errors.New("invalid sso settings")
+)
+
+var ssoProviderIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+type ssoService struct {
+	orgRepo       repository.OrganizationRepository
+	userRepo      repository.UserRepository
+	identityRepo  repository.UserIdentityRepository
+	orgMemberRepo repository.OrganizationMemberRepository
+	roleRepo      repository.RoleRepository
+	permResolver  PermissionResolver
+	auth          AuthService
+	oidc          OIDCClient
+	dns           TXTResolver
+	redirectURL   string
+}
+
+// NewSSOService: redirectURL = halaman callback frontend yang terdaftar di semua provider
+// (mis. https://app.inspacemap.com/sso/callback); frontend meneruskan code & state ke CompleteLogin / CompleteLink.
+// dns dipakai untuk verifikasi domain auto-join (net.DefaultResolver).
+func NewSSOService(
+	orgRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	identityRepo repository.UserIdentityRepository,
+	orgMemberRepo repository.OrganizationMemberRepository,
+	roleRepo repository.RoleRepository,
+	permResolver PermissionResolver,
+	auth AuthService,
+	oidcClient OIDCClient,
+	dns TXTResolver,
+	redirectURL string,
+) SSOService {
+	return &ssoService{
+		orgRepo:       orgRepo,
+		userRepo:      userRepo,
+		identityRepo:  identityRepo,
+		orgMemberRepo: orgMemberRepo,
+		roleRepo:      roleRepo,
+		permResolver:  permResolver,
+		auth:          auth,
+		oidc:          oidcClient,
+		dns:           dns,
+		redirectURL:   redirectURL,
+	}
+}
+
+// StartLogin: URL login provider + state bertanda tangan. Code verifier dipegang frontend sampai callback.
+func (s *ssoService) StartLogin(ctx context.Context, req models.SSOStartRequest) (*models.SSOStartResponse, error) {
+	return s.start(ctx, req, uuid.Nil)
+}
+
+// StartLink: Sama dengan StartLogin, tetapi state hanya bisa diselesaikan lewat CompleteLink oleh user yang sama
+func (s *ssoService) StartLink(ctx context.Context, userID uuid.UUID, req models.SSOStartRequest) (*models.SSOStartResponse, error) {
+	return s.start(ctx, req, userID)
+}
+
+func (s *ssoService) start(ctx context.Context, req models.SSOStartRequest, linkUserID uuid.UUID) (*models.SSOStartResponse, error) {
+	org, err := s.orgRepo.GetByDomain(ctx, strings.TrimSpace(req.Organization))
+	if err != nil || !org.IsActive {
+		return nil, ErrSSOProviderNotFound
+	}
+	settings, err := parseSSOSettings(org.Settings)
+	if err != nil {
+		return nil, err
+	}
+	provider := findSSOProvider(settings, req.Provider)
+	if provider == nil {
+		return nil, ErrSSOProviderNotFound
+	}
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	state, err := utils.GenerateSSOState(org.ID, provider.ID, nonce, utils.HashToken(verifier), linkUserID, SSOStateTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := s.oidc.AuthCodeURL(ctx, s.providerConfig(provider), state, nonce, challenge)
+	if err != nil {
+		log.Printf("sso start org %s provider %s: %v", org.ID, provider.ID, err)
+		return nil, ErrSSOLoginFailed
+	}
+	return &models.SSOStartResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+		CodeVerifier:     verifier,
+		ExpiresIn:        int(SSOStateTTL.Seconds()),
+	}, nil
+}
+
+// CompleteLogin: Tukar code, cari / buat user, pastikan keanggotaan lalu buka sesi di organisasi tersebut
+func (s *ssoService) CompleteLogin(ctx context.Context, req models.SSOCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	cb, err := s.exchange(ctx, req, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.resolveUser(ctx, cb.org, cb.settings, cb.issuer, cb.claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.auth.StartSession(ctx, user.ID, cb.org.ID, client)
+}
+
+// CompleteLink: Tautkan identitas provider ke user yang sedang login. Sesi login adalah bukti kepemilikan akun,
+// sehingga email identitas tidak harus sama dengan email akun.
+func (s *ssoService) CompleteLink(ctx context.Context, userID uuid.UUID, req models.SSOCallbackRequest) error {
+	cb, err := s.exchange(ctx, req, userID)
+	if err != nil {
+		return err
+	}
+	email := ""
+	if cb.claims.EmailVerified {
+		email = strings.ToLower(strings.TrimSpace(cb.claims.Email))
+	}
+
+	if identity, err := s.identityRepo.GetBySubject(ctx, cb.issuer, cb.claims.Subject); err == nil {
+		if identity.UserID != userID {
+			return ErrSSOIdentityInUse
+		}
+		return nil // Sudah tertaut
+	}
+	return s.identityRepo.Create(ctx, &entity.UserIdentity{
+		UserID:  userID,
+		Issuer:  cb.issuer,
+		Subject: cb.claims.Subject,
+		Email:   email,
+	})
+}
+
+// ssoCallback: Hasil callback provider yang sudah diverifikasi
+type ssoCallback struct {
+	org      *entity.Organization
+	settings *models.SSOSettings
+	issuer   string
+	claims   *oidc.Claims
+}
+
+// exchange: State harus dibuat untuk tujuan yang sama (login: linkUserID = uuid.Nil) dan dibawa bersama verifier pasangannya
+func (s *ssoService) exchange(ctx context.Context, req models.SSOCallbackRequest, linkUserID uuid.UUID) (*ssoCallback, error) {
+	state, err := utils.ParseSSOState(req.State)
+	if err != nil || state.VerifierHash != utils.HashToken(req.CodeVerifier) || state.LinkUserID != linkUserID {
+		return nil, ErrInvalidSSOState
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, state.OrganizationID)
+	if err != nil || !org.IsActive {
+		return nil, ErrSSOProviderNotFound
+	}
+	settings, err := parseSSOSettings(org.Settings)
+	if err != nil {
+		return nil, err
+	}
+	provider := findSSOProvider(settings, state.ProviderID)
+	if provider == nil {
+		return nil, ErrSSOProviderNotFound
+	}
+
+	claims, err := s.oidc.Exchange(ctx, s.providerConfig(provider), req.Code, req.CodeVerifier, state.Nonce)
+	if err != nil {
+		// Detail dari provider hanya untuk log server
+		log.Printf("sso callback org %s provider %s: %v", org.ID, provider.ID, err)
+		return nil, ErrSSOLoginFailed
+	}
+	return &ssoCallback{org: org, settings: settings, issuer: provider.Issuer, claims: claims}, nil
+}
+
+// resolveUser: Identitas tertaut (issuer + sub) -> user baru (JIT, hanya domain auto-join terverifikasi).
+// Email yang sudah dipakai akun lokal tidak pernah ditautkan otomatis, lihat CompleteLink.
+func (s *ssoService) resolveUser(ctx context.Context, org *entity.Organization, settings *models.SSOSettings, issuer string, claims *oidc.Claims) (*entity.User, error) {
+	email := ""
+	if claims.EmailVerified {
+		email = strings.ToLower(strings.TrimSpace(claims.Email))
+	}
+
+	if identity, err := s.identityRepo.GetBySubject(ctx, issuer, claims.Subject); err == nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, ErrSSOLoginFailed
+		}
+		if err := s.ensureMembership(ctx, org, settings, user.ID, email); err != nil {
+			return nil, err
+		}
+		identity.LastLoginAt = time.Now()
+		if email != "" {
+			identity.Email = email
+		}
+		if err := s.identityRepo.Update(ctx, identity); err != nil {
+			log.Printf("sso identity %s: update last login failed: %v", identity.ID, err)
+		}
+		return user, nil
+	}
+
+	// Belum tertaut: akun baru butuh email yang diverifikasi provider
+	if email == "" {
+		return nil, ErrSSOEmailNotVerified
+	}
+	if existing, _ := s.userRepo.GetByEmail(ctx, email); existing != nil {
+		return nil, ErrSSOLinkRequired
+	}
+	if !ssoDomainAllowed(settings, email) {
+		return nil, ErrSSONotMember
+	}
+	user := &entity.User{
+		Email:           email,
+		FullName:        ssoDisplayName(claims),
+		AvatarURL:       claims.Picture,
+		IsEmailVerified: true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureMembership(ctx, org, settings, user.ID, email); err != nil {
+		return nil, err
+	}
+	identity := &entity.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      issuer,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: time.Now(),
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ensureMembership: Anggota lama lolos; selain itu hanya email dengan domain auto-join terverifikasi yang ditambahkan
+func (s *ssoService) ensureMembership(ctx context.Context, org *entity.Organization, settings *models.SSOSettings, userID uuid.UUID, email string) error {
+	member, err := s.orgMemberRepo.FindMember(ctx, org.ID, userID)
+	if err != nil {
+		return err
+	}
+	if member != nil {
+		return nil
+	}
+	if email == "" || !ssoDomainAllowed(settings, email) {
+		return ErrSSONotMember
+	}
+
+	role, err := s.autoJoinRole(ctx, org.ID, settings)
+	if err != nil {
+		return err
+	}
+	if err := s.orgMemberRepo.AddMember(ctx, &entity.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         userID,
+		RoleID:         role.ID,
+	}); err != nil {
+		return err
+	}
+	// Hasil "bukan anggota" yang mungkin masih di-cache
+	s.permResolver.InvalidateMember(org.ID, userID)
+	return nil
+}
+
+func (s *ssoService) autoJoinRole(ctx context.Context, orgID uuid.UUID, settings *models.SSOSettings) (*entity.Role, error) {
+	if settings.DefaultRoleID == nil {
+		role, err := s.roleRepo.GetByName(ctx, ssoDefaultRole)
+		if err != nil {
+			return nil, errors.New("system error: viewer role not found")
+		}
+		return role, nil
+	}
+	role, err := s.roleRepo.GetByID(ctx, *settings.DefaultRoleID)
+	if err != nil || !roleAssignableIn(role, orgID) {
+		return nil, fmt.Errorf("%w: default role not found", ErrInvalidSSOSettings)
+	}
+	return role, nil
+}
+
+// GetSettings: Client secret tidak pernah dikembalikan
+func (s *ssoService) GetSettings(ctx context.Context, orgID uuid.UUID) (*models.SSOSettings, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, ErrNotOrgMember
+	}
+	settings, err := parseSSOSettings(org.Settings)
+	if err != nil {
+		return nil, err
+	}
+	for i := range settings.Providers {
+		settings.Providers[i].ClientSecret = ""
+	}
+	return settings, nil
+}
+
+// UpdateSettings: Ganti seluruh pengaturan SSO. Client secret kosong = secret provider dengan ID sama tetap dipakai.
+func (s *ssoService) UpdateSettings(ctx context.Context, orgID uuid.UUID, req models.SSOSettings) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return ErrNotOrgMember
+	}
+	current, err := parseSSOSettings(org.Settings)
+	if err != nil {
+		return err
+	}
+
+	settings, err := normalizeSSOSettings(req, current)
+	if err != nil {
+		return err
+	}
+	if settings.DomainVerificationToken == "" && len(settings.AutoJoinDomains) > 0 {
+		if settings.DomainVerificationToken, err = utils.GenerateOpaqueToken(); err != nil {
+			return err
+		}
+	}
+	if settings.DefaultRoleID != nil {
+		if _, err := s.autoJoinRole(ctx, orgID, settings); err != nil {
+			return err
+		}
+	}
+
+	return s.saveSettings(ctx, org, settings)
+}
+
+// VerifyDomain: Cek record TXT domain auto-join. Record belum ada = Verified false (bukan error),
+// supaya admin langsung melihat record yang harus dibuat.
+func (s *ssoService) VerifyDomain(ctx context.Context, orgID uuid.UUID, domain string) (*models.SSODomainVerification, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, ErrNotOrgMember
+	}
+	settings, err := parseSSOSettings(org.Settings)
+	if err != nil {
+		return nil, err
+	}
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if !slices.Contains(settings.AutoJoinDomains, domain) || settings.DomainVerificationToken == "" {
+		return nil, fmt.Errorf("%w: %q is not an auto-join domain", ErrInvalidSSOSettings, domain)
+	}
+
+	result := &models.SSODomainVerification{
+		Domain: domain,
+		Record: ssoDomainRecordPrefix + domain,
+		Value:  ssoDomainRecordValue + settings.DomainVerificationToken,
+	}
+	if slices.Contains(settings.VerifiedDomains, domain) {
+		result.Verified = true
+		return result, nil
+	}
+
+	records, err := s.dns.LookupTXT(ctx, result.Record)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		log.Printf("sso domain verification %s: %v", result.Record, err)
+		return nil, ErrSSODomainLookupFailed
+	}
+	if !slices.Contains(records, result.Value) {
+		return result, nil
+	}
+
+	settings.VerifiedDomains = append(settings.VerifiedDomains, domain)
+	if err := s.saveSettings(ctx, org, settings); err != nil {
+		return nil, err
+	}
+	result.Verified = true
+	return result, nil
+}
+
+func (s *ssoService) saveSettings(ctx context.Context, org *entity.Organization, settings *models.SSOSettings) error {
+	raw, err := ssoSettingsToJSON(settings)
+	if err != nil {
+		return err
+	}
+	if org.Settings == nil {
+		org.Settings = entity.JSONMap{}
+	}
+	org.Settings[ssoSettingsKey] = raw
+	return s.orgRepo.Update(ctx, org)
+}
+
+func (s *ssoService) providerConfig(p *models.SSOProvider) oidc.ProviderConfig {
+	return oidc.ProviderConfig{
+		Issuer:       p.Issuer,
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  s.redirectURL,
+		Scopes:       p.Scopes,
+	}
+}
+
+// normalizeSSOSettings: Verifikasi domain tidak bisa diisi lewat request; status verifikasi domain
+// yang masih terdaftar dan token TXT organisasi dibawa dari pengaturan lama.
+func normalizeSSOSettings(req models.SSOSettings, current *models.SSOSettings) (*models.SSOSettings, error) {
+	out := &models.SSOSettings{
+		Providers:               []models.SSOProvider{},
+		DefaultRoleID:           req.DefaultRoleID,
+		DomainVerificationToken: current.DomainVerificationToken,
+	}
+	for _, p := range req.Providers {
+		p.ID = strings.TrimSpace(p.ID)
+		p.Issuer = strings.TrimSpace(p.Issuer)
+		p.ClientID = strings.TrimSpace(p.ClientID)
+		if !ssoProviderIDPattern.MatchString(p.ID) {
+			return nil, fmt.Errorf("%w: provider id %q must be lowercase letters, digits, '-' or '_'", ErrInvalidSSOSettings, p.ID)
+		}
+		if findSSOProvider(out, p.ID) != nil {
+			return nil, fmt.Errorf("%w: duplicate provider id %q", ErrInvalidSSOSettings, p.ID)
+		}
+		if !validIssuer(p.Issuer) {
+			return nil, fmt.Errorf("%w: provider %q issuer must be an https URL", ErrInvalidSSOSettings, p.ID)
+		}
+		if p.ClientID == "" {
+			return nil, fmt.Errorf("%w: provider %q client_id is required", ErrInvalidSSOSettings, p.ID)
+		}
+		if p.ClientSecret == "" {
+			if old := findSSOProvider(current, p.ID); old != nil {
+				p.ClientSecret = old.ClientSecret
+			}
+		}
+		if p.Name == "" {
+			p.Name = p.ID
+		}
+		out.Providers = append(out.Providers, p)
+	}
+
+	for _, d := range req.AutoJoinDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || strings.ContainsAny(d, "@/ ") || !strings.Contains(d, ".") {
+			return nil, fmt.Errorf("%w: invalid auto-join domain %q", ErrInvalidSSOSettings, d)
+		}
+		if !slices.Contains(out.AutoJoinDomains, d) {
+			out.AutoJoinDomains = append(out.AutoJoinDomains, d)
+			if slices.Contains(current.VerifiedDomains, d) {
+				out.VerifiedDomains = append(out.VerifiedDomains, d)
+			}
+		}
+	}
+	return out, nil
+}
+
+// validIssuer: https wajib, kecuali provider lokal (development / test)
+func validIssuer(issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	if u.Scheme == "https" {
+		return true
+	}
+	host := u.Hostname()
+	ip := net.ParseIP(host)
+	return u.Scheme == "http" && (host == "localhost" || (ip != nil && ip.IsLoopback()))
+}
+
+// ssoDomainAllowed: Domain auto-join yang record TXT-nya sudah diverifikasi
+func ssoDomainAllowed(settings *models.SSOSettings, email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	return slices.Contains(settings.AutoJoinDomains, domain) && slices.Contains(settings.VerifiedDomains, domain)
+}
+
+func ssoDisplayName(claims *oidc.Claims) string {
+	if name := strings.TrimSpace(claims.Name); name != "" {
+		return name
+	}
+	local, _, _ := strings.Cut(claims.Email, "@")
+	return local
+}
+
+func findSSOProvider(settings *models.SSOSettings, id string) *models.SSOProvider {
+	for i := range settings.Providers {
+		if settings.Providers[i].ID == id {
+			return &settings.Providers[i]
+		}
+	}
+	return nil
+}
+
+// parseSSOSettings: Organization.Settings["sso"] (jsonb) -> DTO. Belum dikonfigurasi = tanpa provider.
+func parseSSOSettings(raw entity.JSONMap) (*models.SSOSettings, error) {
+	settings := &models.SSOSettings{Providers: []models.SSOProvider{}}
+	value, ok := raw[ssoSettingsKey]
+	if !ok || value == nil {
+		return settings, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, errors.New("invalid sso settings in organization")
+	}
+	return settings, nil
+}
+
+func ssoSettingsToJSON(settings *models.SSOSettings) (map[string]interface{}, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	err = json.Unmarshal(data, &raw)
+	return raw, err
+}
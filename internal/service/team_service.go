@@ -7,39 +7,80 @@ import (
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
-	"strings"
+	"inspacemap/backend/pkg/utils"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// InvitationTTL: Masa berlaku link undangan (dihitung ulang saat resend)
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrInvalidInvitation: Token undangan tidak dikenal
+	ErrInvalidInvitation = errors.New("invalid or expired invitation token")
+	// ErrInvitationExpired: Lewat InvitationTTL, admin bisa mengirim ulang
+	ErrInvitationExpired = errors.New("invitation expired")
+	// ErrInvitationUsed: Token undangan dipakai lagi setelah diterima
+	ErrInvitationUsed = errors.New("invitation has already been accepted")
+	// ErrInvitationRevoked: Undangan dibatalkan admin
+	ErrInvitationRevoked = errors.New("invitation has been revoked")
+	// ErrInvitationNotFound: Undangan tidak ada atau milik organisasi lain
+	ErrInvitationNotFound = fmt.Errorf("invitation %w", ErrNotFound)
+	// ErrInvitationNotPending: Resend / revoke untuk undangan yang sudah diterima atau dibatalkan
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+	// ErrInvitationEmailFailed: Undangan tersimpan tetapi email gagal terkirim
+	ErrInvitationEmailFailed = errors.New("invitation saved but the email could not be sent, try resending it")
+	// ErrInvalidInvitationStatus: Filter status list tidak dikenal
+	ErrInvalidInvitationStatus = errors.New("status must be one of pending, accepted, revoked, expired")
+)
+
 type teamService struct {
 	userRepo       repository.UserRepository
 	invitationRepo repository.UserInvitationRepository
 	orgMemberRepo  repository.OrganizationMemberRepository
 	roleRepo       repository.RoleRepository
+	orgRepo        repository.OrganizationRepository
+	permResolver   PermissionResolver
+	mailer         Mailer
+	appBaseURL     string
+	audit          AuditService
 }
 
+// NewTeamService: appBaseURL = URL frontend untuk link undangan
 func NewTeamService(
 	userRepo repository.UserRepository,
 	invitationRepo repository.UserInvitationRepository,
 	orgMemberRepo repository.OrganizationMemberRepository,
 	roleRepo repository.RoleRepository,
+	orgRepo repository.OrganizationRepository,
+	permResolver PermissionResolver,
+	mailer Mailer,
+	appBaseURL string,
+	audit AuditService,
 ) TeamService {
 	return &teamService{
 		userRepo:       userRepo,
 		invitationRepo: invitationRepo,
 		orgMemberRepo:  orgMemberRepo,
 		roleRepo:       roleRepo,
+		orgRepo:        orgRepo,
+		permResolver:   permResolver,
+		mailer:         mailer,
+		appBaseURL:     appBaseURL,
+		audit:          audit,
 	}
 }
 
-func (s *teamService) InviteMember(ctx context.Context, orgID uuid.UUID, inviterID uuid.UUID, req models.InviteUserRequest) error {
+func (s *teamService) InviteMember(ctx context.Context, orgID uuid.UUID, inviterID uuid.UUID, inviterPerms []string, req models.InviteUserRequest) error {
 
 	role, err := s.roleRepo.GetByID(ctx, req.RoleID)
-	if err != nil {
+	if err != nil || !roleAssignableIn(role, orgID) {
 		return errors.New("role tidak valid atau tidak ditemukan")
 	}
+	if err := s.requireGrantableRole(ctx, role.ID, inviterPerms); err != nil {
+		return err
+	}
 
 	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
@@ -50,68 +91,216 @@ func (s *teamService) InviteMember(ctx context.Context, orgID uuid.UUID, inviter
 		}
 	}
 
+	now := time.Now()
 	existingInvites, _ := s.invitationRepo.GetByEmail(ctx, req.Email)
 	for _, inv := range existingInvites {
-		if inv.OrganizationID == orgID && inv.Status == "pending" {
+		// Undangan pending yang sudah kedaluwarsa tidak menghalangi undangan baru
+		if inv.OrganizationID == orgID && inv.Status == entity.InvitationPending && inv.ExpiresAt.After(now) {
 
 			return errors.New("user ini sudah diundang dan statusnya masih pending")
 		}
 	}
 
-	token := uuid.NewString()
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
 
 	invite := entity.UserInvitation{
 		OrganizationID:  orgID,
 		Email:           req.Email,
 		RoleID:          role.ID,
-		Token:           token,
-		ExpiresAt:       expiresAt,
+		Token:           utils.HashToken(token),
+		ExpiresAt:       now.Add(InvitationTTL),
 		InvitedByUserID: inviterID,
-		Status:          "pending",
+		Status:          entity.InvitationPending,
 	}
 
 	if err := s.invitationRepo.Create(ctx, &invite); err != nil {
 		return errors.New("gagal membuat undangan")
 	}
 
+	return s.sendInvitation(ctx, &invite, role, token)
+}
+
+func (s *teamService) ListInvitations(ctx context.Context, orgID uuid.UUID, status string) ([]models.InvitationDetail, error) {
+	switch status {
+	case "", entity.InvitationPending, entity.InvitationAccepted, entity.InvitationRevoked, entity.InvitationExpired:
+	default:
+		return nil, ErrInvalidInvitationStatus
+	}
+	// Tandai yang kedaluwarsa dulu supaya status di list (dan filter expired) akurat
+	if _, err := s.invitationRepo.MarkExpired(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var invites []entity.UserInvitation
+	var err error
+	if status == "" {
+		invites, err = s.invitationRepo.GetByOrganizationID(ctx, orgID)
+	} else {
+		invites, err = s.invitationRepo.GetByStatus(ctx, orgID, status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]models.InvitationDetail, 0, len(invites))
+	for _, inv := range invites {
+		d := models.InvitationDetail{
+			ID:         inv.ID,
+			Email:      inv.Email,
+			RoleID:     inv.RoleID,
+			RoleName:   inv.Role.Name,
+			Status:     inv.Status,
+			CreatedAt:  inv.CreatedAt,
+			ExpiresAt:  inv.ExpiresAt,
+			AcceptedAt: inv.AcceptedAt,
+		}
+		if inv.InvitedByUser != nil {
+			d.InvitedByName = inv.InvitedByUser.FullName
+		}
+		details = append(details, d)
+	}
+	return details, nil
+}
+
+// ResendInvitation: Token & masa berlaku baru; link di email sebelumnya tidak berlaku lagi
+func (s *teamService) ResendInvitation(ctx context.Context, orgID, inviteID uuid.UUID) error {
+	invite, err := s.getOrgInvitation(ctx, orgID, inviteID)
+	if err != nil {
+		return err
+	}
+	if invite.Status != entity.InvitationPending && invite.Status != entity.InvitationExpired {
+		return ErrInvitationNotPending
+	}
+
+	role, err := s.roleRepo.GetByID(ctx, invite.RoleID)
+	if err != nil {
+		return errors.New("role undangan tidak ditemukan, buat undangan baru")
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	invite.Token = utils.HashToken(token)
+	invite.ExpiresAt = time.Now().Add(InvitationTTL)
+	invite.Status = entity.InvitationPending
+	if err := s.invitationRepo.Update(ctx, invite); err != nil {
+		return err
+	}
+
+	return s.sendInvitation(ctx, invite, role, token)
+}
+
+func (s *teamService) RevokeInvitation(ctx context.Context, orgID, inviteID uuid.UUID) error {
+	invite, err := s.getOrgInvitation(ctx, orgID, inviteID)
+	if err != nil {
+		return err
+	}
+	if invite.Status != entity.InvitationPending {
+		return ErrInvitationNotPending
+	}
+	return s.invitationRepo.RevokeInvitation(ctx, invite.ID)
+}
+
+// ExpireInvitations: Dipanggil berkala dari main
+func (s *teamService) ExpireInvitations(ctx context.Context) (int64, error) {
+	return s.invitationRepo.MarkExpired(ctx, time.Now())
+}
+
+func (s *teamService) getOrgInvitation(ctx context.Context, orgID, inviteID uuid.UUID) (*entity.UserInvitation, error) {
+	invite, err := s.invitationRepo.GetByID(ctx, inviteID)
+	if err != nil || invite.OrganizationID != orgID {
+		return nil, ErrInvitationNotFound
+	}
+	return invite, nil
+}
+
+// sendInvitation: Email berisi token asli; yang tersimpan di database hanya hash-nya
+func (s *teamService) sendInvitation(ctx context.Context, invite *entity.UserInvitation, role *entity.Role, token string) error {
+	org, err := s.orgRepo.GetByID(ctx, invite.OrganizationID)
+	if err != nil {
+		return err
+	}
+	data := invitationEmailData{
+		OrganizationName: org.Name,
+		RoleName:         role.Name,
+		Link:             emailLink(s.appBaseURL, "/accept-invite", token),
+		ExpiresIn:        "7 days",
+	}
+	if inviter, err := s.userRepo.GetByID(ctx, invite.InvitedByUserID); err == nil {
+		data.InviterName = inviter.FullName
+	}
+
+	if err := sendEmail(ctx, s.mailer, invite.Email, invitationEmail, data); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvitationEmailFailed, err)
+	}
 	return nil
 }
 
-func (s *teamService) RemoveMember(ctx context.Context, orgID uuid.UUID, targetUserID uuid.UUID) error {
+func (s *teamService) RemoveMember(ctx context.Context, orgID uuid.UUID, grantorPerms []string, targetUserID uuid.UUID) error {
 
 	targetMember, err := s.orgMemberRepo.GetMember(ctx, orgID, targetUserID)
 	if err != nil {
 		return errors.New("member tidak ditemukan")
 	}
+	// Member dengan permission yang tidak dimiliki pemanggil (mis. Owner) hanya bisa dikeluarkan oleh yang setara
+	if err := s.requireGrantableRole(ctx, targetMember.RoleID, grantorPerms); err != nil {
+		return err
+	}
 
-	if strings.EqualFold(targetMember.Role.Name, "Owner") {
+	if targetMember.Role.IsOwner() {
 		if err := s.ensureOrganizationHasOtherOwner(ctx, orgID, targetUserID); err != nil {
 			return err
 		}
 	}
 
-	return s.orgMemberRepo.RemoveMember(ctx, orgID, targetUserID)
+	if err := s.orgMemberRepo.RemoveMember(ctx, orgID, targetUserID); err != nil {
+		return err
+	}
+	s.permResolver.InvalidateMember(orgID, targetUserID)
+	recordChange(ctx, s.audit, "MEMBER_REMOVE", "OrganizationMember", targetUserID, memberAuditSnapshot(targetMember.RoleID, targetMember.Role.Name), nil)
+	return nil
 }
 
-func (s *teamService) UpdateMemberRole(ctx context.Context, orgID uuid.UUID, req models.UpdateUserRoleRequest) error {
+func (s *teamService) UpdateMemberRole(ctx context.Context, orgID uuid.UUID, grantorPerms []string, req models.UpdateUserRoleRequest) error {
 
-	if _, err := s.roleRepo.GetByID(ctx, req.NewRoleID); err != nil {
+	newRole, err := s.roleRepo.GetByID(ctx, req.NewRoleID)
+	if err != nil || !roleAssignableIn(newRole, orgID) {
 		return errors.New("role baru tidak valid")
 	}
+	if err := s.requireGrantableRole(ctx, newRole.ID, grantorPerms); err != nil {
+		return err
+	}
 
 	targetMember, err := s.orgMemberRepo.GetMember(ctx, orgID, req.TargetUserID)
 	if err != nil {
 		return errors.New("member tidak ditemukan")
 	}
+	// Role lama juga harus bisa diberikan pemanggil: Admin tidak boleh menurunkan Owner
+	if err := s.requireGrantableRole(ctx, targetMember.RoleID, grantorPerms); err != nil {
+		return err
+	}
 
-	if strings.EqualFold(targetMember.Role.Name, "Owner") && targetMember.RoleID != req.NewRoleID {
+	if targetMember.Role.IsOwner() && targetMember.RoleID != req.NewRoleID {
 		if err := s.ensureOrganizationHasOtherOwner(ctx, orgID, req.TargetUserID); err != nil {
 			return err
 		}
 	}
 
-	return s.orgMemberRepo.UpdateRole(ctx, orgID, req.TargetUserID, req.NewRoleID)
+	if err := s.orgMemberRepo.UpdateRole(ctx, orgID, req.TargetUserID, req.NewRoleID); err != nil {
+		return err
+	}
+	s.permResolver.InvalidateMember(orgID, req.TargetUserID)
+	recordChange(ctx, s.audit, "MEMBER_ROLE_UPDATE", "OrganizationMember", req.TargetUserID,
+		memberAuditSnapshot(targetMember.RoleID, targetMember.Role.Name), memberAuditSnapshot(newRole.ID, newRole.Name))
+	return nil
+}
+
+func memberAuditSnapshot(roleID uuid.UUID, roleName string) map[string]interface{} {
+	return map[string]interface{}{"role_id": roleID, "role_name": roleName}
 }
 
 func (s *teamService) GetMembersList(ctx context.Context, orgID uuid.UUID) ([]models.TeamMemberDetail, error) {
@@ -146,7 +335,7 @@ func (s *teamService) ensureOrganizationHasOtherOwner(ctx context.Context, orgID
 	ownerCount := 0
 	for _, m := range members {
 
-		if strings.EqualFold(m.Role.Name, "Owner") && m.UserID != excludeUserID {
+		if m.Role.IsOwner() && m.UserID != excludeUserID {
 			ownerCount++
 		}
 	}
@@ -157,3 +346,17 @@ func (s *teamService) ensureOrganizationHasOtherOwner(ctx context.Context, orgID
 
 	return nil
 }
+
+// requireGrantableRole: Role hanya bisa diberikan jika semua permission-nya dimiliki pemberi
+func (s *teamService) requireGrantableRole(ctx context.Context, roleID uuid.UUID, grantorPerms []string) error {
+	perms, err := s.roleRepo.GetPermissions(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	return requireHeldPermissions(perms, grantorPerms)
+}
+
+// roleAssignableIn: Role bawaan atau role custom milik organisasi yang sama
+func roleAssignableIn(role *entity.Role, orgID uuid.UUID) bool {
+	return role.IsSystem() || *role.OrganizationID == orgID
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/totp"
+	"inspacemap/backend/pkg/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// TwoFactorChallengeTTL: Batas waktu memasukkan kode setelah password benar
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorIssuer: Nama akun di aplikasi authenticator
+	twoFactorIssuer = "InSpaceMap"
+	// twoFactorPolicyKey: Lokasi TwoFactorPolicy.RequireTwoFactor di Organization.Settings
+	twoFactorPolicyKey = "require_two_factor"
+	recoveryCodeCount  = 10
+)
+
+var (
+	// ErrTwoFactorAlreadyEnabled: Nonaktifkan dulu untuk mendaftarkan authenticator baru
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnabled: Aksi butuh 2FA yang sudah aktif
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTwoFactorNotStarted: Konfirmasi tanpa BeginEnrollment sebelumnya
+	ErrTwoFactorNotStarted = errors.New("two-factor enrollment has not been started")
+	// ErrInvalidTwoFactorCode: Kode salah, kedaluwarsa atau sudah pernah dipakai
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrInvalidTwoFactorChallenge: Challenge login palsu / kedaluwarsa
+	ErrInvalidTwoFactorChallenge = errors.New("invalid or expired two-factor challenge")
+	// ErrInvalidPassword: Konfirmasi password untuk aksi sensitif
+	ErrInvalidPassword = errors.New("invalid password")
+)
+
+// twoFactorProtectedPermissions: Permission yang ditahan jika organisasi mewajibkan 2FA dan user belum mengaktifkannya
+var twoFactorProtectedPermissions = []string{string(entity.PermGraphPublish), string(entity.PermOrgSettings)}
+
+type twoFactorService struct {
+	repo          repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+	orgRepo       repository.OrganizationRepository
+	orgMemberRepo repository.OrganizationMemberRepository
+	permResolver  PermissionResolver
+}
+
+func NewTwoFactorService(
+	repo repository.TwoFactorRepository,
+	userRepo repository.UserRepository,
+	orgRepo repository.OrganizationRepository,
+	orgMemberRepo repository.OrganizationMemberRepository,
+	permResolver PermissionResolver,
+) TwoFactorService {
+	return &twoFactorService{
+		repo:          repo,
+		userRepo:      userRepo,
+		orgRepo:       orgRepo,
+		orgMemberRepo: orgMemberRepo,
+		permResolver:  permResolver,
+	}
+}
+
+func (s *twoFactorService) GetStatus(ctx context.Context, userID uuid.UUID) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{}
+	tf, err := s.repo.GetByUser(ctx, userID)
+	if err != nil || tf.EnabledAt == nil {
+		return status, nil
+	}
+	status.Enabled = true
+	status.RecoveryCodesRemaining, err = s.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// BeginEnrollment: Secret baru (menggantikan pendaftaran yang belum dikonfirmasi). 2FA belum aktif sampai ConfirmEnrollment.
+func (s *twoFactorService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePending(ctx, &entity.UserTwoFactor{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURL: totp.KeyURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment: Kode pertama dari authenticator membuktikan secret tersimpan dengan benar
+func (s *twoFactorService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	tf, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, ErrTwoFactorNotStarted
+	}
+	if tf.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.repo.Enable(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	s.invalidateUser(ctx, userID)
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable: Butuh kode 2FA + password (jika akun punya password) supaya sesi yang dicuri tidak bisa mematikan 2FA
+func (s *twoFactorService) Disable(ctx context.Context, userID uuid.UUID, req models.TwoFactorDisableRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if user.PasswordHash != "" && !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
+		return ErrInvalidPassword
+	}
+	if err := s.VerifyCode(ctx, userID, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.repo.Disable(ctx, userID); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes: Semua recovery code lama tidak berlaku lagi
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	if err := s.VerifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyCode: Kode TOTP (sekali pakai per periode) atau recovery code (sekali pakai)
+func (s *twoFactorService) VerifyCode(ctx context.Context, userID uuid.UUID, code string) error {
+	tf, err := s.repo.GetByUser(ctx, userID)
+	if err != nil || tf.EnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if step, ok := totp.Validate(tf.Secret, code, time.Now()); ok {
+		consumed, err := s.repo.ConsumeStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidTwoFactorCode
+	}
+	consumed, err := s.repo.ConsumeRecoveryCode(ctx, userID, utils.HashToken(normalized))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *twoFactorService) GetPolicy(ctx context.Context, orgID uuid.UUID) (*models.TwoFactorPolicy, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, ErrNotOrgMember
+	}
+	return &models.TwoFactorPolicy{RequireTwoFactor: requiresTwoFactor(org)}, nil
+}
+
+// UpdatePolicy: Yang mengaktifkan kewajiban 2FA harus sudah memakai 2FA, supaya tidak mengunci dirinya sendiri
+func (s *twoFactorService) UpdatePolicy(ctx context.Context, orgID, userID uuid.UUID, req models.TwoFactorPolicy) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return ErrNotOrgMember
+	}
+	if req.RequireTwoFactor {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("user %w", ErrNotFound)
+		}
+		if !user.TwoFactorEnabled {
+			return fmt.Errorf("%w: enable it on your own account before requiring it", ErrTwoFactorNotEnabled)
+		}
+	}
+
+	if org.Settings == nil {
+		org.Settings = entity.JSONMap{}
+	}
+	org.Settings[twoFactorPolicyKey] = req.RequireTwoFactor
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return err
+	}
+	s.permResolver.InvalidateOrganization(orgID)
+	return nil
+}
+
+// invalidateUser: Status 2FA mengubah permission user di organisasi yang mewajibkan 2FA
+func (s *twoFactorService) invalidateUser(ctx context.Context, userID uuid.UUID) {
+	memberships, err := s.orgMemberRepo.GetMembersByUser(ctx, userID)
+	if err != nil {
+		return // Cache tetap kedaluwarsa sendiri setelah TTL
+	}
+	for _, m := range memberships {
+		s.permResolver.InvalidateMember(m.OrganizationID, userID)
+	}
+}
+
+func requiresTwoFactor(org *entity.Organization) bool {
+	required, _ := org.Settings[twoFactorPolicyKey].(bool)
+	return required
+}
+
+// applyTwoFactorPolicy: Tahan permission terlindungi jika organisasi mewajibkan 2FA dan user belum mengaktifkannya.
+// restricted = ada permission yang ditahan (user perlu mendaftarkan 2FA).
+func applyTwoFactorPolicy(org *entity.Organization, user *entity.User, perms []string) (allowed []string, restricted bool) {
+	if user.TwoFactorEnabled || !requiresTwoFactor(org) {
+		return perms, false
+	}
+	allowed = make([]string, 0, len(perms))
+	for _, p := range perms {
+		if slices.Contains(twoFactorProtectedPermissions, p) {
+			restricted = true
+			continue
+		}
+		allowed = append(allowed, p)
+	}
+	return allowed, restricted
+}
+
+// generateRecoveryCodes: 80 bit per kode, format xxxx-xxxx-xxxx-xxxx
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))
+		codes = append(codes, raw[0:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:16])
+		hashes = append(hashes, utils.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,657 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
+	"io"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidBundle: File bukan venue bundle yang bisa dibaca versi ini
+	ErrInvalidBundle = errors.New("file is not a valid venue bundle")
+	// ErrBundleTooLarge: Isi zip setelah diekstrak melebihi batas (mencegah zip bomb)
+	ErrBundleTooLarge = errors.New("venue bundle is too large when extracted")
+)
+
+var bundleFileExt = regexp.MustCompile(`^\.[A-Za-z0-9]{1,8}$`)
+
+type venueBundleService struct {
+	venueRepo       repository.VenueRepository
+	revisionRepo    repository.GraphRevisionRepository
+	areaGalleryRepo repository.AreaGalleryRepository
+	mediaRepo       repository.MediaAssetRepository
+	storage         StorageProvider
+	bucketName      string
+	cdnBaseURL      string
+	maxExtracted    int64 // Batas total ukuran isi zip setelah diekstrak (byte)
+}
+
+func NewVenueBundleService(
+	vRepo repository.VenueRepository,
+	rRepo repository.GraphRevisionRepository,
+	agRepo repository.AreaGalleryRepository,
+	mRepo repository.MediaAssetRepository,
+	storage StorageProvider,
+	bucketName string,
+	cdnBaseURL string,
+	maxExtracted int64,
+) VenueBundleService {
+	return &venueBundleService{
+		venueRepo:       vRepo,
+		revisionRepo:    rRepo,
+		areaGalleryRepo: agRepo,
+		mediaRepo:       mRepo,
+		storage:         storage,
+		bucketName:      bucketName,
+		cdnBaseURL:      cdnBaseURL,
+		maxExtracted:    maxExtracted,
+	}
+}
+
+// =================================================================
+// 1. EXPORT
+// =================================================================
+
+// ExportBundle: Validasi & query DB dilakukan di sini; fungsi hasil hanya menulis zip ke w
+// (media dibaca dari storage sambil ditulis) sehingga bundle tidak perlu ditampung di memori.
+func (s *venueBundleService) ExportBundle(ctx context.Context, venueID uuid.UUID, req models.VenueBundleExportRequest) (func(w io.Writer) error, error) {
+	mode := req.Revisions
+	if mode == "" {
+		mode = models.BundleRevisionsLive
+	}
+	if mode != models.BundleRevisionsLive && mode != models.BundleRevisionsAll {
+		return nil, errors.New("revisions must be 'live' or 'all'")
+	}
+
+	venue, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil || venue.OrganizationID != utils.OrgIDFromContext(ctx) {
+		return nil, ErrVenueNotFound
+	}
+	if venue.LiveRevisionID == uuid.Nil {
+		return nil, errors.New("venue has no published version yet")
+	}
+
+	// 1. Revisi lengkap (termasuk node/edge nonaktif)
+	revisionIDs := []uuid.UUID{venue.LiveRevisionID}
+	if mode == models.BundleRevisionsAll {
+		revisions, err := s.revisionRepo.GetByVenueID(ctx, venueID)
+		if err != nil {
+			return nil, err
+		}
+		revisionIDs = revisionIDs[:0]
+		for _, rev := range revisions {
+			revisionIDs = append(revisionIDs, rev.ID)
+		}
+	}
+
+	bundle := models.VenueBundle{
+		Version:        models.VenueBundleVersion,
+		ExportedAt:     time.Now().UTC(),
+		SourceVenueID:  venue.ID,
+		LiveRevisionID: venue.LiveRevisionID,
+		Venue: models.BundleVenue{
+			Name:         venue.Name,
+			Slug:         venue.Slug,
+			Description:  venue.Description,
+			Address:      venue.Address,
+			City:         venue.City,
+			Province:     venue.Province,
+			PostalCode:   venue.PostalCode,
+			Visibility:   string(venue.Visibility),
+			Latitude:     venue.Latitude,
+			Longitude:    venue.Longitude,
+			CoverImageID: venue.CoverImageID,
+		},
+		Gallery:   []models.BundleGalleryItem{},
+		Areas:     []models.BundleArea{},
+		Revisions: []models.BundleRevision{},
+	}
+	media := newBundleMediaSet(s.mediaRepo)
+	media.addID(ctx, venue.CoverImageID)
+
+	for _, id := range revisionIDs {
+		rev, err := s.revisionRepo.GetFullGraph(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load revision %s: %w", id, err)
+		}
+		if rev.Status == entity.StatusDraft {
+			draftID := rev.ID
+			bundle.DraftRevisionID = &draftID
+		}
+		bundle.Revisions = append(bundle.Revisions, mapBundleRevision(ctx, rev, media))
+	}
+
+	// 2. Gallery & Areas
+	for _, item := range venue.Gallery {
+		media.add(&item.MediaAsset)
+		bundle.Gallery = append(bundle.Gallery, models.BundleGalleryItem{
+			MediaAssetID: item.MediaAssetID,
+			SortOrder:    item.SortOrder,
+			Caption:      item.Caption,
+			IsVisible:    item.IsVisible,
+			IsFeatured:   item.IsFeatured,
+		})
+	}
+
+	areaGallery, err := s.areaGalleryRepo.GetByVenueID(ctx, venueID)
+	if err != nil {
+		return nil, err
+	}
+	galleryByArea := make(map[uuid.UUID][]models.BundleGalleryItem)
+	for _, item := range areaGallery {
+		media.add(&item.MediaAsset)
+		galleryByArea[item.AreaID] = append(galleryByArea[item.AreaID], models.BundleGalleryItem{
+			MediaAssetID: item.MediaAssetID,
+			SortOrder:    item.SortOrder,
+			Caption:      item.Caption,
+			IsVisible:    item.IsVisible,
+		})
+	}
+	for _, area := range venue.PointsOfInterest {
+		media.addID(ctx, area.CoverImageID)
+		gallery := galleryByArea[area.ID]
+		if gallery == nil {
+			gallery = []models.BundleGalleryItem{}
+		}
+		bundle.Areas = append(bundle.Areas, models.BundleArea{
+			ID:           area.ID,
+			FloorID:      area.FloorID,
+			Name:         area.Name,
+			Slug:         area.Slug,
+			Label:        area.Label,
+			Description:  area.Description,
+			Category:     area.Category,
+			Latitude:     area.Latitude,
+			Longitude:    area.Longitude,
+			MapX:         area.MapX,
+			MapY:         area.MapY,
+			CoverImageID: area.CoverImageID,
+			Gallery:      gallery,
+		})
+	}
+
+	// 3. Zip: media lalu bundle.json. Media selalu ikut karena node wajib punya panorama,
+	// bundle tanpa media tidak bisa diimport ulang.
+	return func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		bundle.Media = []models.BundleMedia{}
+		for _, asset := range media.assets {
+			item := mapBundleMedia(asset)
+			item.File = fmt.Sprintf("media/%s%s", asset.ID, path.Ext(asset.Key))
+			if err := s.copyMediaToZip(ctx, zw, asset, item.File); err != nil {
+				return err
+			}
+			bundle.Media = append(bundle.Media, item)
+		}
+
+		f, err := zw.Create(models.VenueBundleFileName)
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(f).Encode(bundle); err != nil {
+			return err
+		}
+		return zw.Close()
+	}, nil
+}
+
+func (s *venueBundleService) copyMediaToZip(ctx context.Context, zw *zip.Writer, asset *entity.MediaAsset, name string) error {
+	bucket := asset.Bucket
+	if bucket == "" {
+		bucket = s.bucketName
+	}
+	body, err := s.storage.GetObject(ctx, bucket, asset.Key)
+	if err != nil {
+		return fmt.Errorf("failed to read media %s: %w", asset.ID, err)
+	}
+	defer body.Close()
+
+	// Media sudah terkompresi (jpg/png/webp), cukup Store
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, body)
+	return err
+}
+
+// bundleMediaSet: Kumpulan media unik yang direferensikan venue (urutan sesuai penemuan)
+type bundleMediaSet struct {
+	mediaRepo repository.MediaAssetRepository
+	seen      map[uuid.UUID]bool
+	assets    []*entity.MediaAsset
+}
+
+func newBundleMediaSet(mediaRepo repository.MediaAssetRepository) *bundleMediaSet {
+	return &bundleMediaSet{mediaRepo: mediaRepo, seen: make(map[uuid.UUID]bool)}
+}
+
+func (m *bundleMediaSet) add(asset *entity.MediaAsset) {
+	if asset == nil || asset.ID == uuid.Nil || m.seen[asset.ID] {
+		return
+	}
+	m.seen[asset.ID] = true
+	m.assets = append(m.assets, asset)
+}
+
+// addID: Untuk relasi yang tidak di-preload. Media yang sudah terhapus dilewati.
+func (m *bundleMediaSet) addID(ctx context.Context, id *uuid.UUID) {
+	if id == nil || *id == uuid.Nil || m.seen[*id] {
+		return
+	}
+	m.seen[*id] = true
+	if asset, err := m.mediaRepo.GetByID(ctx, *id); err == nil {
+		m.assets = append(m.assets, asset)
+	}
+}
+
+func mapBundleRevision(ctx context.Context, rev *entity.GraphRevision, media *bundleMediaSet) models.BundleRevision {
+	out := models.BundleRevision{
+		ID:          rev.ID,
+		Status:      string(rev.Status),
+		Note:        rev.Note,
+		CreatedAt:   rev.CreatedAt,
+		StartNodeID: rev.StartNodeID,
+		Floors:      []models.BundleFloor{},
+	}
+	for _, floor := range rev.Floors {
+		media.addID(ctx, floor.MapImageID)
+		bf := models.BundleFloor{
+			ID:             floor.ID,
+			LineageID:      floor.LineageID,
+			Name:           floor.Name,
+			LevelIndex:     floor.LevelIndex,
+			MapImageID:     floor.MapImageID,
+			MapWidth:       floor.MapWidth,
+			MapHeight:      floor.MapHeight,
+			PixelsPerMeter: floor.PixelsPerMeter,
+			GeoReference:   floor.GeoReference,
+			IsActive:       floor.IsActive,
+			Nodes:          []models.BundleNode{},
+		}
+		for _, node := range floor.Nodes {
+			panoramaID := node.PanoramaAssetID
+			media.addID(ctx, &panoramaID)
+			bn := models.BundleNode{
+				ID:              node.ID,
+				LineageID:       node.LineageID,
+				X:               node.X,
+				Y:               node.Y,
+				AreaID:          node.AreaID,
+				PanoramaAssetID: node.PanoramaAssetID,
+				RotationOffset:  node.RotationOffset,
+				Label:           node.Label,
+				Properties:      node.Properties,
+				IsActive:        node.IsActive,
+				Edges:           []models.BundleEdge{},
+			}
+			for _, edge := range node.OutgoingEdges {
+				bn.Edges = append(bn.Edges, models.BundleEdge{
+					ToNodeID: edge.ToNodeID,
+					Heading:  edge.Heading,
+					Distance: edge.Distance,
+					Type:     edge.Type,
+					IsActive: edge.IsActive,
+				})
+			}
+			bf.Nodes = append(bf.Nodes, bn)
+		}
+		out.Floors = append(out.Floors, bf)
+	}
+	return out
+}
+
+func mapBundleMedia(asset *entity.MediaAsset) models.BundleMedia {
+	return models.BundleMedia{
+		ID:           asset.ID,
+		Bucket:       asset.Bucket,
+		Key:          asset.Key,
+		PublicURL:    asset.PublicURL,
+		ThumbnailURL: asset.ThumbnailURL,
+		FileName:     asset.FileName,
+		MimeType:     asset.MimeType,
+		Type:         asset.Type,
+		SizeInBytes:  asset.SizeInBytes,
+		Width:        asset.Width,
+		Height:       asset.Height,
+		BlurHash:     asset.BlurHash,
+		AltText:      asset.AltText,
+		Checksum:     asset.Checksum,
+	}
+}
+
+// =================================================================
+// 2. IMPORT
+// =================================================================
+
+func (s *venueBundleService) ImportBundle(ctx context.Context, orgID, userID uuid.UUID, r io.ReaderAt, size int64, req models.VenueBundleImportRequest) (*models.VenueBundleImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidBundle
+	}
+	// Ukuran dari header zip dicek sebelum ekstrak; pembacaan tetap dibatasi ukuran header
+	// supaya entry yang berbohong soal ukurannya tidak bisa mengembang melebihi batas
+	files := make(map[string]*zip.File)
+	var extracted uint64
+	for _, f := range zr.File {
+		extracted += f.UncompressedSize64
+		if f.UncompressedSize64 > uint64(s.maxExtracted) || extracted > uint64(s.maxExtracted) {
+			return nil, ErrBundleTooLarge
+		}
+		files[f.Name] = f
+	}
+
+	bundle, err := readBundleJSON(files[models.VenueBundleFileName])
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Version != models.VenueBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+
+	// 1. Media: selalu upload ulang isi zip ke prefix org tujuan. Bucket/key dari manifest diabaikan,
+	// supaya bundle buatan sendiri tidak bisa menunjuk (lalu menghapus) object milik org lain.
+	// Upload terjadi sebelum transaksi DB; jika transaksi gagal, object yatim dibersihkan lifecycle bucket.
+	mediaIDs := make(map[uuid.UUID]uuid.UUID)
+	var assets []entity.MediaAsset
+	uploaded := 0
+	for _, m := range bundle.Media {
+		asset := entity.MediaAsset{
+			BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+			OrganizationID: orgID,
+			FileName:       m.FileName,
+			MimeType:       m.MimeType,
+			Type:           m.Type,
+			SizeInBytes:    m.SizeInBytes,
+			Width:          m.Width,
+			Height:         m.Height,
+			BlurHash:       m.BlurHash,
+			AltText:        m.AltText,
+			Checksum:       m.Checksum,
+			UploadedBy:     userID,
+		}
+		f := files[m.File]
+		if m.File == "" || f == nil {
+			return nil, fmt.Errorf("bundle is missing media file for %s, export the venue again", m.FileName)
+		}
+		if err := s.reuploadMedia(ctx, orgID, &asset, f); err != nil {
+			return nil, err
+		}
+		uploaded++
+		mediaIDs[m.ID] = asset.ID
+		assets = append(assets, asset)
+	}
+
+	// 2. Venue baru (selalu private sampai dicek ulang di instance tujuan)
+	slug, err := availableSlug(ctx, s.venueRepo, orgID, firstNonEmpty(req.Slug, bundle.Venue.Slug))
+	if err != nil {
+		return nil, err
+	}
+	copySrc := &repository.VenueCopy{
+		Venue: entity.Venue{
+			OrganizationID: orgID,
+			Name:           firstNonEmpty(req.Name, bundle.Venue.Name),
+			Slug:           slug,
+			Description:    bundle.Venue.Description,
+			Address:        bundle.Venue.Address,
+			City:           bundle.Venue.City,
+			Province:       bundle.Venue.Province,
+			PostalCode:     bundle.Venue.PostalCode,
+			Visibility:     entity.VisibilityPrivate,
+			Latitude:       bundle.Venue.Latitude,
+			Longitude:      bundle.Venue.Longitude,
+			CoverImageID:   bundle.Venue.CoverImageID,
+		},
+		Media:           assets,
+		MediaIDs:        mediaIDs,
+		LiveRevisionID:  bundle.LiveRevisionID,
+		DraftRevisionID: bundle.DraftRevisionID,
+		CreatedByID:     userID,
+	}
+	for _, item := range bundle.Gallery {
+		copySrc.Gallery = append(copySrc.Gallery, entity.VenueGalleryItem{
+			MediaAssetID: item.MediaAssetID,
+			SortOrder:    item.SortOrder,
+			Caption:      item.Caption,
+			IsVisible:    item.IsVisible,
+			IsFeatured:   item.IsFeatured,
+		})
+	}
+	for _, area := range bundle.Areas {
+		copySrc.Areas = append(copySrc.Areas, entity.Area{
+			BaseEntity:   entity.BaseEntity{ID: area.ID},
+			FloorID:      area.FloorID,
+			Name:         area.Name,
+			Slug:         area.Slug,
+			Label:        area.Label,
+			Description:  area.Description,
+			Category:     area.Category,
+			Latitude:     area.Latitude,
+			Longitude:    area.Longitude,
+			MapX:         area.MapX,
+			MapY:         area.MapY,
+			CoverImageID: area.CoverImageID,
+		})
+		for _, item := range area.Gallery {
+			copySrc.AreaGallery = append(copySrc.AreaGallery, entity.AreaGalleryItem{
+				AreaID:       area.ID,
+				MediaAssetID: item.MediaAssetID,
+				SortOrder:    item.SortOrder,
+				Caption:      item.Caption,
+				IsVisible:    item.IsVisible,
+			})
+		}
+	}
+	hasLive := false
+	for _, rev := range bundle.Revisions {
+		hasLive = hasLive || rev.ID == bundle.LiveRevisionID
+		copySrc.Revisions = append(copySrc.Revisions, mapBundleRevisionEntity(rev))
+	}
+	if !hasLive {
+		return nil, errors.New("bundle does not contain its live revision")
+	}
+
+	venue, err := s.venueRepo.CreateCopy(ctx, copySrc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.VenueBundleImportResult{
+		VenueID:       venue.ID,
+		Slug:          venue.Slug,
+		RevisionCount: len(bundle.Revisions),
+		AreaCount:     len(bundle.Areas),
+		MediaCount:    len(assets),
+		UploadedMedia: uploaded,
+	}, nil
+}
+
+// =================================================================
+// 3. CLONE (dalam organisasi yang sama, media dipakai bersama)
+// =================================================================
+
+func (s *venueBundleService) CloneVenue(ctx context.Context, orgID, userID, venueID uuid.UUID, req models.CloneVenueRequest) (*models.CloneVenueResponse, error) {
+	src, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil || src.OrganizationID != orgID {
+		return nil, ErrVenueNotFound
+	}
+	if src.LiveRevisionID == uuid.Nil {
+		return nil, errors.New("venue has no published version yet")
+	}
+
+	live, err := s.revisionRepo.GetFullGraph(ctx, src.LiveRevisionID)
+	if err != nil {
+		return nil, err
+	}
+	areaGallery, err := s.areaGalleryRepo.GetByVenueID(ctx, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	slug, err := availableSlug(ctx, s.venueRepo, orgID, firstNonEmpty(req.Slug, src.Slug+"-copy"))
+	if err != nil {
+		return nil, err
+	}
+	target := *src
+	target.BaseEntity = entity.BaseEntity{}
+	target.Name = firstNonEmpty(req.Name, src.Name+" (Copy)")
+	target.Slug = slug
+	target.Visibility = entity.VisibilityPrivate // Template baru belum siap dipublikasikan
+	target.CoverImage, target.Gallery, target.PointsOfInterest = nil, nil, nil
+
+	// Draft baru dibuat dari live (DraftRevisionID nil) agar clone langsung bisa diedit
+	venue, err := s.venueRepo.CreateCopy(ctx, &repository.VenueCopy{
+		Venue:          target,
+		Gallery:        src.Gallery,
+		Areas:          src.PointsOfInterest,
+		AreaGallery:    areaGallery,
+		Revisions:      []entity.GraphRevision{*live},
+		LiveRevisionID: live.ID,
+		CreatedByID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.CloneVenueResponse{
+		VenueID:        venue.ID,
+		Slug:           venue.Slug,
+		LiveRevisionID: venue.LiveRevisionID,
+	}
+	if venue.DraftRevisionID != nil {
+		resp.DraftRevisionID = *venue.DraftRevisionID
+	}
+	return resp, nil
+}
+
+// reuploadMedia: Key mengikuti format upload biasa (<org>/<kategori>/<id><ext>)
+func (s *venueBundleService) reuploadMedia(ctx context.Context, orgID uuid.UUID, asset *entity.MediaAsset, f *zip.File) error {
+	// Type & nama file berasal dari bundle: hanya nilai yang aman dipakai sebagai bagian key
+	category := "imports"
+	switch asset.Type {
+	case "panorama", "icon", "floorplan":
+		category = asset.Type
+	}
+	ext := path.Ext(f.Name)
+	if !bundleFileExt.MatchString(ext) {
+		ext = ""
+	}
+	key := fmt.Sprintf("%s/%s/%s%s", orgID, category, asset.ID, ext)
+
+	body, err := f.Open()
+	if err != nil {
+		return ErrInvalidBundle
+	}
+	defer body.Close()
+	size := int64(f.UncompressedSize64)
+	if err := s.storage.PutObject(ctx, s.bucketName, key, asset.MimeType, io.LimitReader(body, size), size); err != nil {
+		return fmt.Errorf("failed to upload media %s: %w", asset.FileName, err)
+	}
+
+	asset.Bucket = s.bucketName
+	asset.Key = key
+	asset.PublicURL = fmt.Sprintf("%s/%s", s.cdnBaseURL, key)
+	asset.ThumbnailURL = "" // Thumbnail instance asal tidak ikut dipindah
+	return nil
+}
+
+func readBundleJSON(f *zip.File) (*models.VenueBundle, error) {
+	if f == nil {
+		return nil, ErrInvalidBundle
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, ErrInvalidBundle
+	}
+	defer rc.Close()
+
+	var bundle models.VenueBundle
+	if err := json.NewDecoder(io.LimitReader(rc, int64(f.UncompressedSize64))).Decode(&bundle); err != nil {
+		return nil, ErrInvalidBundle
+	}
+	return &bundle, nil
+}
+
+func mapBundleRevisionEntity(rev models.BundleRevision) entity.GraphRevision {
+	out := entity.GraphRevision{
+		BaseEntity:  entity.BaseEntity{ID: rev.ID},
+		Status:      entity.RevisionStatus(rev.Status),
+		Note:        rev.Note,
+		StartNodeID: rev.StartNodeID,
+	}
+	for _, floor := range rev.Floors {
+		ef := entity.Floor{
+			BaseEntity:     entity.BaseEntity{ID: floor.ID},
+			Name:           floor.Name,
+			LevelIndex:     floor.LevelIndex,
+			MapImageID:     floor.MapImageID,
+			MapWidth:       floor.MapWidth,
+			MapHeight:      floor.MapHeight,
+			PixelsPerMeter: floor.PixelsPerMeter,
+			GeoReference:   floor.GeoReference,
+			IsActive:       floor.IsActive,
+			LineageID:      floor.LineageID,
+		}
+		for _, node := range floor.Nodes {
+			en := entity.GraphNode{
+				BaseEntity:      entity.BaseEntity{ID: node.ID},
+				X:               node.X,
+				Y:               node.Y,
+				AreaID:          node.AreaID,
+				PanoramaAssetID: node.PanoramaAssetID,
+				RotationOffset:  node.RotationOffset,
+				Label:           node.Label,
+				Properties:      node.Properties,
+				IsActive:        node.IsActive,
+				LineageID:       node.LineageID,
+			}
+			for _, edge := range node.Edges {
+				en.OutgoingEdges = append(en.OutgoingEdges, entity.GraphEdge{
+					FromNodeID: node.ID,
+					ToNodeID:   edge.ToNodeID,
+					Heading:    edge.Heading,
+					Distance:   edge.Distance,
+					Type:       edge.Type,
+					IsActive:   edge.IsActive,
+				})
+			}
+			ef.Nodes = append(ef.Nodes, en)
+		}
+		out.Floors = append(out.Floors, ef)
+	}
+	return out
+}
+
+// availableSlug: base, base-2, base-3, ... yang belum dipakai venue lain di organisasi yang sama
+// (slug unik per organisasi, sama seperti ensureSlugAvailable)
+func availableSlug(ctx context.Context, venueRepo repository.VenueRepository, orgID uuid.UUID, base string) (string, error) {
+	if base == "" {
+		base = "venue"
+	}
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		existing, err := venueRepo.FilterVenues(ctx, models.VenueFilter{OrganizationID: &orgID, Slug: &candidate})
+		if err != nil {
+			return "", err
+		}
+		if len(existing) == 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no available slug for %q", base)
+}
@@ -3,20 +3,53 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	defaultShareLinkTTL = 72 * time.Hour
+	maxShareLinkTTL     = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidShareLink: Token share link rusak, kadaluarsa, atau bukan untuk venue ini
+	ErrInvalidShareLink = errors.New("share link is invalid or expired")
+	// ErrManifestRevisionGone: Revisi asal delta tidak dikenal, client harus unduh manifest penuh
+	ErrManifestRevisionGone = errors.New("base revision is not available, download the full manifest")
+	// ErrVenueNotFound: Venue tidak ada atau milik organisasi lain
+	ErrVenueNotFound = fmt.Errorf("venue %w", ErrNotFound)
+	// ErrVenueSlugTaken: Slug harus unik per organisasi
+	ErrVenueSlugTaken = errors.New("slug is already used by another venue in this organization")
+	// ErrVenueSlugAmbiguous: Route manifest lama tidak bisa memilih venue karena slug dipakai beberapa organisasi
+	ErrVenueSlugAmbiguous = errors.New("venue slug is used by several organizations, use /orgs/:org_slug/venues/:slug/manifest")
+)
+
+// venueSortOptions: Kolom sort yang boleh dipakai ListVenues (nilai langsung masuk ORDER BY)
+var venueSortOptions = map[string]bool{
+	"name asc": true, "name desc": true,
+	"city asc": true, "city desc": true,
+	"created_at asc": true, "created_at desc": true,
+	"updated_at asc": true, "updated_at desc": true,
+}
+
 type venueService struct {
-	venueRepo repository.VenueRepository
+	venueRepo     repository.VenueRepository
+	manifestCache ManifestCache
+	audit         AuditService
 }
 
-func NewVenueService(vRepo repository.VenueRepository) VenueService {
+func NewVenueService(vRepo repository.VenueRepository, manifestCache ManifestCache, audit AuditService) VenueService {
 	return &venueService{
-		venueRepo: vRepo,
+		venueRepo:     vRepo,
+		manifestCache: manifestCache,
+		audit:         audit,
 	}
 }
 
@@ -24,19 +57,27 @@ func NewVenueService(vRepo repository.VenueRepository) VenueService {
 // 1. WRITE OPERATIONS
 // =================================================================
 
-func (s *venueService) CreateVenue(ctx context.Context, req models.CreateVenueRequest) (*models.IDResponse, error) {
+func (s *venueService) CreateVenue(ctx context.Context, orgID uuid.UUID, req models.CreateVenueRequest) (*models.IDResponse, error) {
+	if req.Name == "" || req.Slug == "" {
+		return nil, errors.New("name and slug are required")
+	}
+	if err := s.ensureSlugAvailable(ctx, orgID, req.Slug, uuid.Nil); err != nil {
+		return nil, err
+	}
+
 	venue := entity.Venue{
-		Name:         req.Name,
-		Slug:         req.Slug,
-		Description:  req.Description,
-		Address:      req.Address,
-		City:         req.City,
-		Province:     req.Province,
-		PostalCode:   req.PostalCode,
-		Latitude:     req.Latitude,
-		Longitude:    req.Longitude,
-		CoverImageID: req.CoverImageID,
-		Visibility:   entity.VisibilityPrivate, // Default
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Slug:           req.Slug,
+		Description:    req.Description,
+		Address:        req.Address,
+		City:           req.City,
+		Province:       req.Province,
+		PostalCode:     req.PostalCode,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		CoverImageID:   req.CoverImageID,
+		Visibility:     entity.VisibilityPrivate, // Default
 	}
 
 	if req.Visibility != "" {
@@ -63,18 +104,23 @@ func (s *venueService) CreateVenue(ctx context.Context, req models.CreateVenueRe
 	return &models.IDResponse{ID: venue.ID}, nil
 }
 
-func (s *venueService) UpdateVenue(ctx context.Context, id uuid.UUID, req models.UpdateVenueRequest) error {
-	// 1. Get Existing
-	venue, err := s.venueRepo.GetByID(ctx, id) // Pakai BaseRepo GetByID cukup utk update field dasar
+func (s *venueService) UpdateVenue(ctx context.Context, orgID, id uuid.UUID, req models.UpdateVenueRequest) error {
+	// 1. Get Existing (milik organisasi token)
+	venue, err := s.getOwnedVenue(ctx, orgID, id)
 	if err != nil {
-		return errors.New("venue not found")
+		return err
 	}
-
 	// 2. Partial Update Logic
 	if req.Name != nil {
 		venue.Name = *req.Name
 	}
-	if req.Slug != nil {
+	if req.Slug != nil && *req.Slug != venue.Slug {
+		if *req.Slug == "" {
+			return errors.New("slug cannot be empty")
+		}
+		if err := s.ensureSlugAvailable(ctx, orgID, *req.Slug, venue.ID); err != nil {
+			return err
+		}
 		venue.Slug = *req.Slug
 	}
 	if req.Description != nil {
@@ -106,24 +152,46 @@ func (s *venueService) UpdateVenue(ctx context.Context, id uuid.UUID, req models
 	}
 
 	// 3. Save
-	return s.venueRepo.Update(ctx, venue)
+	if err := s.venueRepo.Update(ctx, venue); err != nil {
+		return err
+	}
+
+	// Nama/slug ikut tampil di manifest, jadi cache lama harus dibuang
+	s.manifestCache.DeletePrefix(manifestCachePrefix(venue.ID))
+	return nil
 }
 
-func (s *venueService) DeleteVenue(ctx context.Context, id uuid.UUID) error {
+func (s *venueService) DeleteVenue(ctx context.Context, orgID, id uuid.UUID) error {
+	venue, err := s.getOwnedVenue(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+
 	// Soft Delete via Repository
-	return s.venueRepo.Delete(ctx, id)
+	if err := s.venueRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.manifestCache.DeletePrefix(manifestCachePrefix(venue.ID))
+	recordChange(ctx, s.audit, "VENUE_DELETE", "Venue", venue.ID, map[string]interface{}{
+		"name":             venue.Name,
+		"slug":             venue.Slug,
+		"visibility":       venue.Visibility,
+		"live_revision_id": venue.LiveRevisionID,
+	}, nil)
+	return nil
 }
 
 // =================================================================
 // 2. READ OPERATIONS (ADMIN / DASHBOARD)
 // =================================================================
 
-func (s *venueService) GetVenueDetail(ctx context.Context, id uuid.UUID) (*models.VenueDetail, error) {
-	venue, err := s.venueRepo.GetByID(ctx, id)
+func (s *venueService) GetVenueDetail(ctx context.Context, orgID, id uuid.UUID) (*models.VenueDetail, error) {
+	venue, err := s.getOwnedVenue(ctx, orgID, id)
 	if err != nil {
 		return nil, err
 	}
-	return s.mapEntityToDetail(venue), nil
+	return mapVenueDetail(venue), nil
 }
 
 func (s *venueService) GetVenueBySlug(ctx context.Context, slug string) (*models.VenueDetail, error) {
@@ -136,11 +204,17 @@ func (s *venueService) GetVenueBySlug(ctx context.Context, slug string) (*models
 	// Tapi idealnya Repo GetBySlug sudah preload.
 
 	// Mapping manual atau via helper yang sama
-	// Untuk amannya kita bisa panggil mapEntityToDetail jika struct entity venue sudah terisi relasinya
-	return s.mapEntityToDetail(venue), nil
+	// Untuk amannya kita bisa panggil mapVenueDetail jika struct entity venue sudah terisi relasinya
+	return mapVenueDetail(venue), nil
 }
 
-func (s *venueService) ListVenues(ctx context.Context, query models.VenueQuery) ([]models.VenueListItem, int64, error) {
+func (s *venueService) ListVenues(ctx context.Context, orgID uuid.UUID, query models.VenueQuery) ([]models.VenueListItem, int64, error) {
+	// Dashboard hanya melihat venue milik organisasinya sendiri
+	query.OrganizationID = &orgID
+	if query.Sort != nil && !venueSortOptions[*query.Sort] {
+		return nil, 0, errors.New("invalid sort option")
+	}
+
 	venues, total, err := s.venueRepo.PagedVenues(ctx, query)
 	if err != nil {
 		return nil, 0, err
@@ -167,33 +241,259 @@ func (s *venueService) ListVenues(ctx context.Context, query models.VenueQuery)
 	return list, total, nil
 }
 
+// getOwnedVenue: Venue organisasi lain diperlakukan sama dengan venue yang tidak ada
+func (s *venueService) getOwnedVenue(ctx context.Context, orgID, id uuid.UUID) (*entity.Venue, error) {
+	venue, err := s.venueRepo.GetByID(ctx, id)
+	if err != nil || venue.OrganizationID != orgID {
+		return nil, ErrVenueNotFound
+	}
+	return venue, nil
+}
+
+func (s *venueService) ensureSlugAvailable(ctx context.Context, orgID uuid.UUID, slug string, excludeID uuid.UUID) error {
+	existing, err := s.venueRepo.FilterVenues(ctx, models.VenueFilter{OrganizationID: &orgID, Slug: &slug})
+	if err != nil {
+		return err
+	}
+	for _, v := range existing {
+		if v.ID != excludeID {
+			return ErrVenueSlugTaken
+		}
+	}
+	return nil
+}
+
 // =================================================================
 // 3. MOBILE APP CONSUMER
 // =================================================================
 
-func (s *venueService) GetMobileManifest(ctx context.Context, slug string) (*models.ManifestResponse, error) {
-	venueEntity, err := s.venueRepo.GetLiveManifestData(slug)
+func (s *venueService) GetMobileManifest(ctx context.Context, orgSlug, slug string, access models.ManifestAccess) (*models.ManifestResponse, error) {
+	// 1. Validasi share link (jika ada) sebelum menyentuh database
+	share, err := parseShareAccess(access)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Preview Draft via share link: selalu fresh (tidak di-cache karena draft terus berubah)
+	if share != nil && share.IncludeDraft {
+		venueEntity, err := s.venueRepo.GetDraftManifestData(orgSlug, slug)
+		if err != nil {
+			return nil, err
+		}
+		if share.VenueID != venueEntity.ID {
+			return nil, ErrInvalidShareLink
+		}
+		return buildManifest(venueEntity, venueEntity.DraftRevision), nil
+	}
+
+	// 3. Visibility & Tenancy dicek dengan query ringan dulu
+	info, err := s.venueRepo.GetManifestInfo(ctx, orgSlug, slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManifestAccess(info, share, access.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	// 4. Cache hit: skip preload chain yang berat
+	if cached, ok := s.manifestCache.Get(manifestCacheKey(info.ID, info.LiveRevisionID)); ok {
+		return cached, nil
+	}
+
+	venueEntity, err := s.venueRepo.GetLiveManifestData(orgSlug, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := buildManifest(venueEntity, venueEntity.LiveRevision)
+	// Key pakai revisi yang benar-benar di-load (bisa saja baru dipublish di antara dua query)
+	s.manifestCache.Set(manifestCacheKey(venueEntity.ID, venueEntity.LiveRevisionID), manifest)
+
+	return manifest, nil
+}
+
+// ResolveLegacySlug: Route lama /venues/:slug/... hanya dilayani selama slug venue unik di seluruh organisasi
+func (s *venueService) ResolveLegacySlug(ctx context.Context, slug string) (string, error) {
+	orgSlugs, err := s.venueRepo.GetOrgSlugsByVenueSlug(ctx, slug, 2)
+	if err != nil {
+		return "", err
+	}
+	switch len(orgSlugs) {
+	case 0:
+		return "", ErrVenueNotFound
+	case 1:
+		return orgSlugs[0], nil
+	}
+	return "", ErrVenueSlugAmbiguous
+}
+
+func (s *venueService) CreateShareLink(ctx context.Context, orgID uuid.UUID, venueID uuid.UUID, req models.CreateShareLinkRequest) (*models.ShareLinkResponse, error) {
+	venue, err := s.venueRepo.GetByID(ctx, venueID)
+	if err != nil || venue.OrganizationID != orgID {
+		return nil, errors.New("venue not found")
+	}
+
+	ttl := defaultShareLinkTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if ttl > maxShareLinkTTL {
+		return nil, errors.New("share link cannot be valid for more than 30 days")
+	}
+
+	token, expiresAt, err := utils.GenerateShareToken(venue.ID, req.IncludeDraft, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ShareLinkResponse{
+		Token:        token,
+		ManifestPath: fmt.Sprintf("/api/v1/orgs/%s/venues/%s/manifest?share_token=%s", venue.Organization.Slug, venue.Slug, token),
+		ExpiresAt:    expiresAt,
+		IncludeDraft: req.IncludeDraft,
+	}, nil
+}
+
+func (s *venueService) GetManifestIndex(ctx context.Context, orgSlug, slug string, access models.ManifestAccess) (*models.ManifestIndexResponse, error) {
+	// Index diturunkan dari manifest penuh (yang sudah di-cache), jadi tidak ada query tambahan
+	manifest, err := s.GetMobileManifest(ctx, orgSlug, slug, access)
+	if err != nil {
+		return nil, err
+	}
+
+	floors := make([]models.FloorSummary, 0, len(manifest.Floors))
+	for _, floor := range manifest.Floors {
+		floors = append(floors, summarizeFloor(floor))
+	}
+
+	return &models.ManifestIndexResponse{
+		VenueID:     manifest.VenueID,
+		VenueName:   manifest.VenueName,
+		RevisionID:  manifest.RevisionID,
+		LastUpdated: manifest.LastUpdated,
+		StartNodeID: manifest.StartNodeID,
+		Floors:      floors,
+	}, nil
+}
+
+func (s *venueService) GetManifestFloor(ctx context.Context, orgSlug, slug string, floorID uuid.UUID, access models.ManifestAccess) (*models.ManifestFloorResponse, error) {
+	manifest, err := s.GetMobileManifest(ctx, orgSlug, slug, access)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, floor := range manifest.Floors {
+		if floor.ID == floorID {
+			return &models.ManifestFloorResponse{
+				VenueID:     manifest.VenueID,
+				RevisionID:  manifest.RevisionID,
+				LastUpdated: manifest.LastUpdated,
+				FloorData:   floor,
+			}, nil
+		}
+	}
+	return nil, errors.New("floor not found")
+}
+
+func (s *venueService) GetManifestDelta(ctx context.Context, orgSlug, slug string, fromRevisionID uuid.UUID, access models.ManifestAccess) (*models.ManifestDeltaResponse, error) {
+	// 1. Target delta = manifest yang saat ini berhak dilihat requester (akses dicek di sini)
+	to, err := s.GetMobileManifest(ctx, orgSlug, slug, access)
 	if err != nil {
 		return nil, err
 	}
 
+	if fromRevisionID == to.RevisionID {
+		return diffManifests(to, to), nil
+	}
+
+	// 2. Revisi asal: cukup floors/nodes, nama venue dll diambil dari target
+	fromRevision, err := s.venueRepo.GetRevisionManifestData(ctx, to.VenueID, fromRevisionID)
+	if err != nil {
+		return nil, ErrManifestRevisionGone
+	}
+	from := buildManifest(&entity.Venue{BaseEntity: entity.BaseEntity{ID: to.VenueID}}, fromRevision)
+
+	return diffManifests(from, to), nil
+}
+
+func manifestCachePrefix(venueID uuid.UUID) string {
+	return venueID.String() + ":"
+}
+
+func manifestCacheKey(venueID, revisionID uuid.UUID) string {
+	return manifestCachePrefix(venueID) + revisionID.String()
+}
+
+// parseShareAccess: nil jika request tidak membawa share link
+func parseShareAccess(access models.ManifestAccess) (*utils.ShareLinkPayload, error) {
+	if access.ShareToken == "" {
+		return nil, nil
+	}
+	claims, err := utils.ParseShareToken(access.ShareToken)
+	if err != nil {
+		return nil, ErrInvalidShareLink
+	}
+	return claims, nil
+}
+
+// checkManifestAccess: Share link harus untuk venue ini; tanpa share link berlaku aturan visibility
+func checkManifestAccess(venue *entity.Venue, share *utils.ShareLinkPayload, requesterOrgID uuid.UUID) error {
+	if share != nil {
+		if share.VenueID != venue.ID {
+			return ErrInvalidShareLink
+		}
+		return nil
+	}
+	if !canViewManifest(venue, requesterOrgID) {
+		// Jangan bocorkan keberadaan venue private: samakan dengan "not found"
+		return errors.New("venue not found or not published")
+	}
+	return nil
+}
+
+// canViewManifest: Public & Unlisted boleh diakses siapa saja,
+// Private & Archived hanya untuk anggota organisasi pemilik venue.
+func canViewManifest(venue *entity.Venue, requesterOrgID uuid.UUID) bool {
+	switch venue.Visibility {
+	case entity.VisibilityPublic, entity.VisibilityUnlisted:
+		return true
+	}
+	return requesterOrgID != uuid.Nil && requesterOrgID == venue.OrganizationID
+}
+
+// buildManifest: Mapping Entity Venue + Revision -> DTO ManifestResponse
+func buildManifest(venueEntity *entity.Venue, revision *entity.GraphRevision) *models.ManifestResponse {
+	// ID yang dikirim ke client adalah StableID (sama lintas publish) agar cache per-floor & delta tetap valid
+	stableNodeIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, floor := range revision.Floors {
+		for i := range floor.Nodes {
+			stableNodeIDs[floor.Nodes[i].ID] = floor.Nodes[i].StableID()
+		}
+	}
+	stableNodeID := func(id uuid.UUID) uuid.UUID {
+		if stable, ok := stableNodeIDs[id]; ok {
+			return stable
+		}
+		return id
+	}
+
 	// Tentukan Start Node
 	var startNodeID uuid.UUID
-	if venueEntity.LiveRevision.StartNodeID != nil {
-		startNodeID = *venueEntity.LiveRevision.StartNodeID
-	} else if len(venueEntity.LiveRevision.Floors) > 0 && len(venueEntity.LiveRevision.Floors[0].Nodes) > 0 {
-		startNodeID = venueEntity.LiveRevision.Floors[0].Nodes[0].ID
+	if revision.StartNodeID != nil {
+		startNodeID = stableNodeID(*revision.StartNodeID)
+	} else if len(revision.Floors) > 0 && len(revision.Floors[0].Nodes) > 0 {
+		startNodeID = revision.Floors[0].Nodes[0].StableID()
 	}
 
 	var floorDTOs []models.FloorData
-	for _, floor := range venueEntity.LiveRevision.Floors {
+	for _, floor := range revision.Floors {
 		var nodeDTOs []models.NodeData
 		for _, node := range floor.Nodes {
 
 			var neighborDTOs []models.NeighborData
 			for _, edge := range node.OutgoingEdges {
 				neighborDTOs = append(neighborDTOs, models.NeighborData{
-					TargetNodeID: edge.ToNodeID,
+					TargetNodeID: stableNodeID(edge.ToNodeID),
 					Heading:      edge.Heading,
 					Distance:     edge.Distance,
 					Type:         edge.Type,
@@ -212,13 +512,14 @@ func (s *venueService) GetMobileManifest(ctx context.Context, slug string) (*mod
 			}
 
 			nodeDTOs = append(nodeDTOs, models.NodeData{
-				ID:             node.ID,
+				ID:             node.StableID(),
 				X:              int(node.X),
 				Y:              int(node.Y),
 				PanoramaURL:    panoURL,
 				RotationOffset: node.RotationOffset,
 				AreaID:         node.AreaID,
 				AreaName:       areaName,
+				Label:          node.Label,
 				Neighbors:      neighborDTOs,
 			})
 		}
@@ -229,7 +530,7 @@ func (s *venueService) GetMobileManifest(ctx context.Context, slug string) (*mod
 		}
 
 		floorDTOs = append(floorDTOs, models.FloorData{
-			ID:          floor.ID,
+			ID:          floor.StableID(),
 			LevelName:   floor.Name,
 			LevelIndex:  floor.LevelIndex,
 			MapImageURL: mapURL,
@@ -239,16 +540,119 @@ func (s *venueService) GetMobileManifest(ctx context.Context, slug string) (*mod
 		})
 	}
 
+	// Perubahan venue (misal rename) juga mengubah isi manifest
+	lastUpdated := revision.CreatedAt
+	if venueEntity.UpdatedAt.After(lastUpdated) {
+		lastUpdated = venueEntity.UpdatedAt
+	}
+
 	return &models.ManifestResponse{
 		VenueID:     venueEntity.ID,
 		VenueName:   venueEntity.Name,
-		LastUpdated: venueEntity.LiveRevision.CreatedAt,
+		RevisionID:  revision.ID,
+		LastUpdated: lastUpdated,
 		StartNodeID: startNodeID,
 		Floors:      floorDTOs,
-	}, nil
+	}
+}
+
+func summarizeFloor(floor models.FloorData) models.FloorSummary {
+	return models.FloorSummary{
+		ID:          floor.ID,
+		LevelName:   floor.LevelName,
+		LevelIndex:  floor.LevelIndex,
+		MapImageURL: floor.MapImageURL,
+		MapWidth:    floor.MapWidth,
+		MapHeight:   floor.MapHeight,
+		NodeCount:   len(floor.Nodes),
+	}
+}
+
+// diffManifests: Bandingkan dua manifest berdasarkan ID stabil.
+// Node pada floor yang dihapus ikut masuk RemovedNodeIDs agar client tidak perlu menebak.
+func diffManifests(from, to *models.ManifestResponse) *models.ManifestDeltaResponse {
+	delta := &models.ManifestDeltaResponse{
+		VenueID:         to.VenueID,
+		VenueName:       to.VenueName,
+		FromRevisionID:  from.RevisionID,
+		ToRevisionID:    to.RevisionID,
+		LastUpdated:     to.LastUpdated,
+		StartNodeID:     to.StartNodeID,
+		UpsertedFloors:  []models.FloorSummary{},
+		RemovedFloorIDs: []uuid.UUID{},
+		UpsertedNodes:   []models.FloorNodeData{},
+		RemovedNodeIDs:  []uuid.UUID{},
+	}
+
+	oldFloors := make(map[uuid.UUID]models.FloorSummary)
+	oldNodes := make(map[uuid.UUID]models.FloorNodeData)
+	for _, floor := range from.Floors {
+		oldFloors[floor.ID] = summarizeFloor(floor)
+		for _, node := range floor.Nodes {
+			oldNodes[node.ID] = models.FloorNodeData{FloorID: floor.ID, NodeData: node}
+		}
+	}
+
+	seenFloors := make(map[uuid.UUID]bool)
+	seenNodes := make(map[uuid.UUID]bool)
+	for _, floor := range to.Floors {
+		seenFloors[floor.ID] = true
+		summary := summarizeFloor(floor)
+		if old, ok := oldFloors[floor.ID]; !ok || old != summary {
+			delta.UpsertedFloors = append(delta.UpsertedFloors, summary)
+		}
+
+		for _, node := range floor.Nodes {
+			seenNodes[node.ID] = true
+			old, ok := oldNodes[node.ID]
+			if !ok || old.FloorID != floor.ID || !sameNodeData(old.NodeData, node) {
+				delta.UpsertedNodes = append(delta.UpsertedNodes, models.FloorNodeData{FloorID: floor.ID, NodeData: node})
+			}
+		}
+	}
+
+	for _, floor := range from.Floors {
+		if !seenFloors[floor.ID] {
+			delta.RemovedFloorIDs = append(delta.RemovedFloorIDs, floor.ID)
+		}
+		for _, node := range floor.Nodes {
+			if !seenNodes[node.ID] {
+				delta.RemovedNodeIDs = append(delta.RemovedNodeIDs, node.ID)
+			}
+		}
+	}
+
+	return delta
+}
+
+func sameNodeData(a, b models.NodeData) bool {
+	if a.ID != b.ID || a.X != b.X || a.Y != b.Y || a.PanoramaURL != b.PanoramaURL ||
+		a.RotationOffset != b.RotationOffset || a.AreaName != b.AreaName || a.Label != b.Label {
+		return false
+	}
+	if (a.AreaID == nil) != (b.AreaID == nil) || (a.AreaID != nil && *a.AreaID != *b.AreaID) {
+		return false
+	}
+	if len(a.Neighbors) != len(b.Neighbors) {
+		return false
+	}
+
+	// Urutan edge dari database tidak dijamin, bandingkan sebagai himpunan
+	remaining := make(map[models.NeighborData]int, len(a.Neighbors))
+	for _, n := range a.Neighbors {
+		remaining[n]++
+	}
+	for _, n := range b.Neighbors {
+		if remaining[n] == 0 {
+			return false
+		}
+		remaining[n]--
+	}
+	return true
 }
 
-func (s *venueService) mapEntityToDetail(venue *entity.Venue) *models.VenueDetail {
+// mapVenueDetail: Entity Venue (dengan Gallery & POI ter-preload) -> DTO VenueDetail
+func mapVenueDetail(venue *entity.Venue) *models.VenueDetail {
 	var galleryDTOs []models.VenueGalleryDetail
 	for _, item := range venue.Gallery {
 		url, thumb := "", ""
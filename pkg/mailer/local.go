@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer: Untuk development & test. Setiap email disimpan sebagai file .eml di dir
+// (bisa dibuka di mail client); dir kosong berarti email hanya ditulis ke log.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(ctx context.Context, to, subject, textBody, htmlBody string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.dir == "" {
+		log.Printf("[MAIL] to=%s subject=%q\n%s", to, subject, textBody)
+		return nil
+	}
+
+	msg, err := buildMessage(m.from, to, subject, textBody, htmlBody)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s-%s.eml", time.Now().UTC().Format("20060102T150405.000"), sanitizeFileName(to), randomID()[:6])
+	return os.WriteFile(filepath.Join(m.dir, name), msg, 0o644)
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		}
+		return '_'
+	}, s)
+}
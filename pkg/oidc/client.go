@@ -0,0 +1,283 @@
+// Package oidc: Klien OpenID Connect minimal (authorization code + PKCE) untuk SSO per organisasi.
+// Discovery & JWKS tiap issuer di-cache; ID token diverifikasi lokal dengan public key issuer.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inspacemap/backend/pkg/utils"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// metadataTTL: Discovery jarang berubah; JWKS tetap di-refresh saat kid tidak dikenal
+	metadataTTL = time.Hour
+	// jwksRefreshInterval: Batas refresh JWKS supaya token dengan kid palsu tidak membanjiri issuer
+	jwksRefreshInterval = time.Minute
+	// maxResponseSize: Respons discovery / token / JWKS yang wajar jauh di bawah ini
+	maxResponseSize = 1 << 20
+	// requestTimeout: Batas satu request ke identity provider (termasuk redirect)
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	// ErrDiscovery: Dokumen .well-known/openid-configuration tidak bisa diambil / tidak valid
+	ErrDiscovery = errors.New("oidc: provider discovery failed")
+	// ErrTokenExchange: Token endpoint menolak authorization code
+	ErrTokenExchange = errors.New("oidc: token exchange failed")
+	// ErrInvalidIDToken: Signature, issuer, audience, masa berlaku atau nonce tidak cocok
+	ErrInvalidIDToken = errors.New("oidc: invalid id token")
+	// ErrNonPublicAddress: Issuer / endpoint provider mengarah ke jaringan internal
+	ErrNonPublicAddress = errors.New("oidc: provider address is not public")
+)
+
+// sharedAddressSpace: 100.64.0.0/10 (CGNAT), tidak termasuk IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// ProviderConfig: Kredensial aplikasi di satu identity provider
+type ProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // Kosong = openid, email, profile
+}
+
+func (p ProviderConfig) scopes() string {
+	scopes := []string{"openid", "email", "profile"}
+	if len(p.Scopes) > 0 {
+		scopes = p.Scopes
+		if !slices.Contains(scopes, "openid") {
+			scopes = append([]string{"openid"}, scopes...)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
+
+// metadata: Bagian discovery document yang dipakai
+type metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// provider: Cache discovery + JWKS satu issuer
+type provider struct {
+	mu          sync.Mutex
+	meta        *metadata
+	fetchedAt   time.Time
+	keys        map[string]publicKey
+	keysFetched time.Time
+}
+
+// Client: Aman dipakai bersamaan; satu instance untuk semua organisasi
+type Client struct {
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*provider // issuer -> cache
+}
+
+// NewClient: httpClient nil = PublicHTTPClient
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = PublicHTTPClient()
+	}
+	return &Client{httpClient: httpClient, providers: make(map[string]*provider)}
+}
+
+// PublicHTTPClient: Issuer diisi admin organisasi, jadi discovery / token / JWKS hanya boleh ke alamat publik.
+// Alamat dicek saat dial (setelah DNS resolve, juga untuk redirect) sehingga DNS rebinding ikut tertolak.
+// Tanpa proxy: lewat proxy alamat tujuan tidak bisa dicek.
+func PublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: rejectNonPublicAddress}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: requestTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+}
+
+func rejectNonPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !isPublicAddr(ip) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+	}
+	return nil
+}
+
+// isPublicAddr: false untuk private, loopback, link-local (termasuk metadata cloud 169.254.169.254),
+// multicast, unspecified dan CGNAT
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// NewPKCE: Code verifier acak + challenge S256 (RFC 7636)
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, S256Challenge(verifier), nil
+}
+
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL: URL login di identity provider (response_type=code, PKCE S256)
+func (c *Client) AuthCodeURL(ctx context.Context, cfg ProviderConfig, state, nonce, codeChallenge string) (string, error) {
+	meta, err := c.metadata(ctx, cfg.Issuer)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint", ErrDiscovery)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.RedirectURL)
+	q.Set("scope", cfg.scopes())
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange: Tukar authorization code dengan token lalu kembalikan claim ID token yang sudah diverifikasi
+func (c *Client) Exchange(ctx context.Context, cfg ProviderConfig, code, codeVerifier, nonce string) (*Claims, error) {
+	meta, err := c.metadata(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", cfg.ClientID)
+	useBasic := cfg.ClientSecret != "" && useBasicAuth(meta.TokenAuthMethods)
+	if cfg.ClientSecret != "" && !useBasic {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.doJSON(req, &body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	if status != http.StatusOK || body.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrTokenExchange, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: response has no id_token", ErrTokenExchange)
+	}
+
+	return c.verifyIDToken(ctx, cfg, meta, body.IDToken, nonce)
+}
+
+// useBasicAuth: client_secret_basic adalah default spesifikasi; post hanya jika provider tidak mendukung basic
+func useBasicAuth(methods []string) bool {
+	return slices.Contains(methods, "client_secret_basic") || !slices.Contains(methods, "client_secret_post")
+}
+
+func (c *Client) provider(issuer string) *provider {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.providers[issuer]
+	if !ok {
+		p = &provider{}
+		c.providers[issuer] = p
+	}
+	return p
+}
+
+func (c *Client) metadata(ctx context.Context, issuer string) (*metadata, error) {
+	p := c.provider(issuer)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil && time.Since(p.fetchedAt) < metadataTTL {
+		return p.meta, nil
+	}
+
+	wellKnown := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	var meta metadata
+	status, err := c.doJSON(req, &meta)
+	if err != nil || status != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s (status %d, %v)", ErrDiscovery, wellKnown, status, err)
+	}
+	// OIDC Discovery 4.3: issuer di dokumen harus sama persis dengan issuer yang dikonfigurasi
+	if meta.Issuer != issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch %q != %q", ErrDiscovery, meta.Issuer, issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+
+	p.meta, p.fetchedAt = &meta, time.Now()
+	return p.meta, nil
+}
+
+// doJSON: Kirim request lalu decode body JSON apa pun status HTTP-nya
+func (c *Client) doJSON(req *http.Request, out any) (int, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
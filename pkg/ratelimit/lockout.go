@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Lockout: Kunci akun sementara setelah percobaan login berulang. Setiap percobaan setelah Threshold
+// memasang kunci dua kali lebih lama (BaseDelay, 2x, 4x, ... sampai MaxDelay). Login berhasil me-reset hitungan.
+type Lockout struct {
+	Store         Store
+	Threshold     int           // Percobaan gagal yang masih ditoleransi sebelum kunci pertama
+	BaseDelay     time.Duration // Durasi kunci pertama
+	MaxDelay      time.Duration
+	FailureWindow time.Duration // Umur hitungan kegagalan sejak kegagalan pertama
+}
+
+// NewLockout: Default 5 kegagalan, kunci 1 menit berlipat sampai 1 jam, hitungan berlaku 24 jam
+func NewLockout(store Store) *Lockout {
+	return &Lockout{
+		Store:         store,
+		Threshold:     5,
+		BaseDelay:     time.Minute,
+		MaxDelay:      time.Hour,
+		FailureWindow: 24 * time.Hour,
+	}
+}
+
+// Check: Sisa waktu kunci; 0 = boleh mencoba
+func (l *Lockout) Check(ctx context.Context, account string) (time.Duration, error) {
+	locked, ttl, err := l.Store.Get(ctx, "lock:"+account)
+	if err != nil || locked == 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// Attempt: Hitung percobaan SEBELUM kredensial dicek, lalu putuskan dari hasil increment itu.
+// Request paralel tidak bisa lolos bersama sebelum kunci terpasang: di atas Threshold hanya satu
+// percobaan per periode kunci (pemenang increment kunci). Mengembalikan sisa waktu kunci jika ditolak.
+func (l *Lockout) Attempt(ctx context.Context, account string) (time.Duration, error) {
+	if wait, err := l.Check(ctx, account); err != nil || wait > 0 {
+		return wait, err
+	}
+	attempts, _, err := l.Store.Incr(ctx, "fail:"+account, l.FailureWindow)
+	if err != nil || attempts <= int64(l.Threshold) {
+		return 0, err
+	}
+	delay := l.delay(attempts - int64(l.Threshold) - 1)
+	holders, ttl, err := l.Store.Incr(ctx, "lock:"+account, delay)
+	if err != nil {
+		return 0, err
+	}
+	if holders > 1 {
+		return ttl, nil
+	}
+	return 0, nil
+}
+
+// Reset: Dipanggil setelah login berhasil
+func (l *Lockout) Reset(ctx context.Context, account string) error {
+	if err := l.Store.Delete(ctx, "fail:"+account); err != nil {
+		return err
+	}
+	return l.Store.Delete(ctx, "lock:"+account)
+}
+
+func (l *Lockout) delay(doublings int64) time.Duration {
+	d := l.BaseDelay
+	for i := int64(0); i < doublings && d < l.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, l.MaxDelay)
+}
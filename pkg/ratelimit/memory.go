@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval: Seberapa sering counter kedaluwarsa dibersihkan dari memori
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// MemoryStore: Counter per proses. Dengan beberapa instance di belakang load balancer,
+// kuota efektif menjadi kuota x jumlah instance; pakai RedisStore untuk kuota bersama.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	nextSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.expiresAt) {
+		e = &memoryEntry{expiresAt: now.Add(window)}
+		s.entries[key] = e
+	}
+	e.count++
+	return e.count, e.expiresAt.Sub(now), nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.expiresAt) {
+		return 0, 0, nil
+	}
+	return e.count, e.expiresAt.Sub(now), nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep: Dipanggil dengan lock; map tidak tumbuh tanpa batas oleh IP yang hanya datang sekali
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for k, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.nextSweep = now.Add(sweepInterval)
+}
@@ -0,0 +1,78 @@
+// Package ratelimit: Counter fixed-window untuk rate limit dan lockout login.
+// Store bisa in-memory (satu instance) atau Redis-compatible (dibagi antar instance).
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store: Counter dengan masa berlaku. Window dimulai saat increment pertama (fixed window).
+type Store interface {
+	// Incr: Tambah counter; key baru / kedaluwarsa mulai dari 1 dengan umur window
+	Incr(ctx context.Context, key string, window time.Duration) (count int64, ttl time.Duration, err error)
+	// Get: Nilai counter tanpa menambah; 0 jika tidak ada
+	Get(ctx context.Context, key string) (count int64, ttl time.Duration, err error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Rule: Maksimal Limit request per Window
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRule: Format "jumlah/durasi", mis. "30/1m" atau "1000/1h". Kosong / "0" = tanpa batas.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Rule{}, nil
+	}
+	count, window, ok := strings.Cut(s, "/")
+	limit, err := strconv.Atoi(count)
+	if !ok || err != nil || limit < 0 {
+		return Rule{}, fmt.Errorf("ratelimit: invalid rule %q (want e.g. 30/1m)", s)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Rule{}, fmt.Errorf("ratelimit: invalid window in rule %q", s)
+	}
+	return Rule{Limit: limit, Window: d}, nil
+}
+
+// Enabled: Rule kosong tidak membatasi apa pun
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+// Result: Sisa kuota di window berjalan
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // Waktu sampai window di-reset
+}
+
+// Allow: Hitung satu request untuk key
+func Allow(ctx context.Context, store Store, key string, rule Rule) (Result, error) {
+	count, ttl, err := store.Incr(ctx, key, rule.Window)
+	if err != nil {
+		return Result{}, err
+	}
+	if ttl <= 0 {
+		ttl = rule.Window
+	}
+	remaining := rule.Limit - int(count)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:    count <= int64(rule.Limit),
+		Limit:      rule.Limit,
+		Remaining:  remaining,
+		RetryAfter: ttl,
+	}, nil
+}
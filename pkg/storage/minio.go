@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type MinIOProvider struct {
@@ -59,7 +62,49 @@ func NewMinIOProviderWithExternal(internalEndpoint, accessKey, secretKey, region
 
 // GetPresignedPutURL generates URL upload langsung (untuk frontend)
 func (m *MinIOProvider) GetPresignedPutURL(ctx context.Context, bucket, key, contentType string, expiry time.Duration) (string, error) {
-	// Buat client terpisah dengan external endpoint untuk URL yang bisa diakses frontend
+	presignClient, err := m.externalPresignClient()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = expiry
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to presign request: %w", err)
+	}
+
+	return req.URL, nil
+}
+
+// GetPresignedGetURL generates URL download sementara (misal paket offline di bucket private)
+func (m *MinIOProvider) GetPresignedGetURL(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
+	presignClient, err := m.externalPresignClient()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = expiry
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to presign request: %w", err)
+	}
+
+	return req.URL, nil
+}
+
+// externalPresignClient: Client terpisah dengan external endpoint agar URL bisa diakses dari luar
+func (m *MinIOProvider) externalPresignClient() (*s3.PresignClient, error) {
 	externalCfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithRegion("us-east-1"), // Region tidak penting untuk presigned URL
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(m.accessKey, m.secretKey, "")),
@@ -74,28 +119,54 @@ func (m *MinIOProvider) GetPresignedPutURL(ctx context.Context, bucket, key, con
 	)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to create external config: %w", err)
+		return nil, fmt.Errorf("failed to create external config: %w", err)
 	}
 
 	externalClient := s3.NewFromConfig(externalCfg, func(o *s3.Options) {
 		o.UsePathStyle = true
 	})
 
-	presignClient := s3.NewPresignClient(externalClient)
+	return s3.NewPresignClient(externalClient), nil
+}
 
-	req, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		ContentType: aws.String(contentType),
-	}, func(opts *s3.PresignOptions) {
-		opts.Expires = expiry
+// GetObject baca file fisik (caller wajib Close)
+func (m *MinIOProvider) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	out, err := m.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
 	})
-
 	if err != nil {
-		return "", fmt.Errorf("failed to presign request: %w", err)
+		return nil, err
 	}
+	return out.Body, nil
+}
 
-	return req.URL, nil
+// PutObject upload file dari backend. Body sebaiknya io.Seeker (misal *os.File) agar bisa di-sign tanpa TLS.
+func (m *MinIOProvider) PutObject(ctx context.Context, bucket, key, contentType string, body io.Reader, size int64) error {
+	_, err := m.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+		Body:          body,
+	})
+	return err
+}
+
+// ObjectExists cek keberadaan file tanpa mengunduhnya
+func (m *MinIOProvider) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	_, err := m.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 // DeleteObject hapus file fisik
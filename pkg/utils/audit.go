@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type auditActorContextKey struct{}
+
+// AuditActorContextKey: Key pelaku request di context.Context.
+// Middleware audit memasangnya lewat c.Locals seperti OrgContextKey, sehingga snapshot dari service tahu siapa pelakunya.
+var AuditActorContextKey = auditActorContextKey{}
+
+// AuditActor: Identitas pelaku satu request
+type AuditActor struct {
+	UserID    uuid.UUID // uuid.Nil untuk request lewat API key
+	APIKeyID  uuid.UUID
+	IPAddress string
+	UserAgent string
+	RequestID string // Menghubungkan log request dengan snapshot service dari request yang sama
+}
+
+// WithAuditActor: Pasang pelaku ke context (job background / test)
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, AuditActorContextKey, actor)
+}
+
+// AuditActorFromContext: ok = false jika context tidak berasal dari request yang diaudit
+func AuditActorFromContext(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(AuditActorContextKey).(AuditActor)
+	return actor, ok
+}
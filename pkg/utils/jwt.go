@@ -8,7 +8,20 @@ import (
 	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte("RAHASIA_DAPUR_SAAS_INSPACEMAP_2025")
+// Access token sengaja pendek; sesi diperpanjang lewat refresh token
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Audience khusus share link, supaya token share tidak bisa dipakai sebagai access token
+const shareLinkAudience = "venue-share"
+
+// Audience state login SSO (parameter state OIDC)
+const ssoStateAudience = "sso-state"
+
+// Audience challenge login dua langkah: password sudah benar, kode 2FA belum
+const twoFactorAudience = "2fa-challenge"
 
 type JWTPayload struct {
 	UserID         uuid.UUID `json:"user_id"`
@@ -16,38 +29,155 @@ type JWTPayload struct {
 	OrganizationID uuid.UUID `json:"org_id"`
 	Role           string    `json:"role"`
 	Permissions    []string  `json:"perms"`
+	SessionID      uuid.UUID `json:"sid,omitempty"` // Family refresh token yang menerbitkan token ini
+	jwt.RegisteredClaims
+}
+
+// ShareLinkPayload: Claim untuk share link manifest (tanpa akun)
+type ShareLinkPayload struct {
+	VenueID      uuid.UUID `json:"venue_id"`
+	IncludeDraft bool      `json:"draft,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// SSOStatePayload: Konteks login SSO yang dibawa bolak-balik lewat identity provider
+type SSOStatePayload struct {
+	OrganizationID uuid.UUID `json:"org_id"`
+	ProviderID     string    `json:"provider"`
+	Nonce          string    `json:"nonce"`
+	VerifierHash   string    `json:"vh"`       // HashToken(code_verifier): callback harus membawa verifier pasangannya
+	LinkUserID     uuid.UUID `json:"link_uid"` // Penautan identitas dari sesi user ini; kosong = login
+	jwt.RegisteredClaims
+}
+
+// TwoFactorChallengePayload: User ID ada di claim sub (bukan user_id) supaya tidak pernah lolos sebagai access token
+type TwoFactorChallengePayload struct {
+	OrganizationID uuid.UUID `json:"org_id,omitempty"` // Organisasi aktif awal sesi (login SSO); kosong = membership pertama
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, email string, orgID uuid.UUID, roleName string, permissions []string) (string, error) {
+func GenerateToken(userID uuid.UUID, email string, orgID uuid.UUID, roleName string, permissions []string, sessionID uuid.UUID) (string, error) {
 	claims := JWTPayload{
 		UserID:         userID,
 		Email:          email,
 		OrganizationID: orgID,
 		Role:           roleName,
 		Permissions:    permissions,
+		SessionID:      sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return CurrentJWTKeySet().signClaims(claims)
 }
 
 func ParseToken(tokenString string) (*JWTPayload, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTPayload{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTPayload{}, CurrentJWTKeySet().keyFunc)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTPayload); ok && token.Valid {
+	// Token tanpa user (misal share link) atau dengan audience khusus bukan access token
+	if claims, ok := token.Claims.(*JWTPayload); ok && token.Valid && claims.UserID != uuid.Nil && len(claims.Audience) == 0 {
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
+
+// GenerateShareToken membuat token share link yang hanya berlaku untuk satu venue
+func GenerateShareToken(venueID uuid.UUID, includeDraft bool, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := ShareLinkPayload{
+		VenueID:      venueID,
+		IncludeDraft: includeDraft,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{shareLinkAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	signed, err := CurrentJWTKeySet().signClaims(claims)
+	return signed, expiresAt, err
+}
+
+func ParseShareToken(tokenString string) (*ShareLinkPayload, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ShareLinkPayload{}, CurrentJWTKeySet().keyFunc, jwt.WithAudience(shareLinkAudience))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*ShareLinkPayload); ok && token.Valid && claims.VenueID != uuid.Nil {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid share token")
+}
+
+// GenerateSSOState: State OIDC bertanda tangan, jadi server tidak perlu menyimpan login yang sedang berjalan.
+// linkUserID = uuid.Nil untuk login; selain itu state hanya berlaku untuk menautkan identitas ke user tersebut.
+func GenerateSSOState(orgID uuid.UUID, providerID, nonce, verifierHash string, linkUserID uuid.UUID, ttl time.Duration) (string, error) {
+	claims := SSOStatePayload{
+		OrganizationID: orgID,
+		ProviderID:     providerID,
+		Nonce:          nonce,
+		VerifierHash:   verifierHash,
+		LinkUserID:     linkUserID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{ssoStateAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return CurrentJWTKeySet().signClaims(claims)
+}
+
+func ParseSSOState(tokenString string) (*SSOStatePayload, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &SSOStatePayload{}, CurrentJWTKeySet().keyFunc, jwt.WithAudience(ssoStateAudience))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*SSOStatePayload); ok && token.Valid && claims.OrganizationID != uuid.Nil {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid sso state")
+}
+
+// GenerateTwoFactorChallenge: Bukti bahwa langkah pertama login (password / SSO) sudah lolos
+func GenerateTwoFactorChallenge(userID, orgID uuid.UUID, ttl time.Duration) (string, error) {
+	claims := TwoFactorChallengePayload{
+		OrganizationID: orgID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{twoFactorAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return CurrentJWTKeySet().signClaims(claims)
+}
+
+// ParseTwoFactorChallenge: User ID dan organisasi aktif awal dari challenge
+func ParseTwoFactorChallenge(tokenString string) (uuid.UUID, uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &TwoFactorChallengePayload{}, CurrentJWTKeySet().keyFunc, jwt.WithAudience(twoFactorAudience))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	claims, ok := token.Claims.(*TwoFactorChallengePayload)
+	if !ok || !token.Valid {
+		return uuid.Nil, uuid.Nil, errors.New("invalid two-factor challenge")
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil || userID == uuid.Nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid two-factor challenge")
+	}
+	return userID, claims.OrganizationID, nil
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey: Satu kunci JWT. Kunci tanpa private key hanya dipakai untuk verifikasi (rotasi).
+type JWTKey struct {
+	ID     string // Dikirim sebagai header "kid"
+	Method jwt.SigningMethod
+	sign   any
+	verify any
+}
+
+// CanSign: Kunci ini punya bagian private / secret
+func (k *JWTKey) CanSign() bool {
+	return k.sign != nil
+}
+
+// VerifyOnly: Salinan kunci tanpa bagian private (untuk kunci lama yang sudah dirotasi)
+func (k *JWTKey) VerifyOnly() *JWTKey {
+	return &JWTKey{ID: k.ID, Method: k.Method, verify: k.verify}
+}
+
+// NewHMACKey: Kunci HS256. Secret minimal 32 byte.
+func NewHMACKey(id string, secret []byte) (*JWTKey, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("jwt key %q: HS256 secret must be at least 32 bytes", id)
+	}
+	return &JWTKey{ID: id, Method: jwt.SigningMethodHS256, sign: secret, verify: secret}, nil
+}
+
+// ParsePrivateKeyPEM: RSA -> RS256, Ed25519 -> EdDSA. Public key diturunkan dari private key.
+func ParsePrivateKeyPEM(id string, pemBytes []byte) (*JWTKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return &JWTKey{ID: id, Method: jwt.SigningMethodRS256, sign: key, verify: &key.PublicKey}, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		priv := key.(ed25519.PrivateKey)
+		return &JWTKey{ID: id, Method: jwt.SigningMethodEdDSA, sign: priv, verify: priv.Public()}, nil
+	}
+	return nil, fmt.Errorf("jwt key %q: unsupported private key (expected RSA or Ed25519 PEM)", id)
+}
+
+// ParsePublicKeyPEM: Kunci verify-only, misal kunci lama yang masih dipakai token aktif
+func ParsePublicKeyPEM(id string, pemBytes []byte) (*JWTKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return &JWTKey{ID: id, Method: jwt.SigningMethodRS256, verify: key}, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return &JWTKey{ID: id, Method: jwt.SigningMethodEdDSA, verify: key}, nil
+	}
+	return nil, fmt.Errorf("jwt key %q: unsupported public key (expected RSA or Ed25519 PEM)", id)
+}
+
+// JWTKeySet: Satu kunci aktif untuk menandatangani + kunci lain yang masih diterima saat verifikasi
+type JWTKeySet struct {
+	signing *JWTKey
+	keys    map[string]*JWTKey
+}
+
+func NewJWTKeySet(signing *JWTKey, verifyOnly ...*JWTKey) (*JWTKeySet, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errors.New("jwt: signing key must include a private key or secret")
+	}
+
+	ks := &JWTKeySet{signing: signing, keys: map[string]*JWTKey{}}
+	for _, k := range append([]*JWTKey{signing}, verifyOnly...) {
+		if k.ID == "" {
+			return nil, errors.New("jwt: every key needs a kid")
+		}
+		if _, dup := ks.keys[k.ID]; dup {
+			return nil, fmt.Errorf("jwt: duplicate kid %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+	return ks, nil
+}
+
+// SigningKeyID: kid kunci aktif
+func (ks *JWTKeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+func (ks *JWTKeySet) signClaims(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.sign)
+}
+
+// keyFunc: Pilih kunci dari header kid. Token lama tanpa kid diverifikasi dengan kunci aktif.
+// Algoritma token harus sama dengan algoritma kunci (mencegah alg confusion RS256 -> HS256).
+func (ks *JWTKeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := ks.signing
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = ks.keys[kid]; !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.verify, nil
+}
+
+// JWK: Format RFC 7517 untuk public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS: Public key semua kunci asimetris. Secret HS256 tidak pernah dipublikasikan.
+func (ks *JWTKeySet) JWKS() JWKS {
+	out := JWKS{Keys: []JWK{}}
+	for _, k := range ks.orderedKeys() {
+		switch pub := k.verify.(type) {
+		case *rsa.PublicKey:
+			out.Keys = append(out.Keys, JWK{
+				Kty: "RSA", Kid: k.ID, Use: "sig", Alg: k.Method.Alg(),
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			out.Keys = append(out.Keys, JWK{
+				Kty: "OKP", Kid: k.ID, Use: "sig", Alg: k.Method.Alg(),
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return out
+}
+
+// orderedKeys: Kunci aktif lebih dulu supaya output JWKS stabil
+func (ks *JWTKeySet) orderedKeys() []*JWTKey {
+	keys := []*JWTKey{ks.signing}
+	for id, k := range ks.keys {
+		if id != ks.signing.ID {
+			keys = append(keys, k)
+		}
+	}
+	rest := keys[1:]
+	sort.Slice(rest, func(i, j int) bool { return rest[i].ID < rest[j].ID })
+	return keys
+}
+
+var activeKeySet atomic.Pointer[JWTKeySet]
+
+func init() {
+	// Default: secret acak per proses. Token tidak berlaku lintas restart / instance,
+	// jadi server harus memanggil SetJWTKeySet dengan kunci dari konfigurasi.
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	key, _ := NewHMACKey("ephemeral", secret)
+	ks, _ := NewJWTKeySet(key)
+	activeKeySet.Store(ks)
+}
+
+// SetJWTKeySet: Dipanggil sekali saat startup
+func SetJWTKeySet(ks *JWTKeySet) {
+	activeKeySet.Store(ks)
+}
+
+func CurrentJWTKeySet() *JWTKeySet {
+	return activeKeySet.Load()
+}
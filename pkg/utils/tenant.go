@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type orgContextKey struct{}
+
+// OrgContextKey: Key organisasi aktif di context.Context.
+// Middleware auth memasangnya lewat c.Locals sehingga service yang menerima c.Context() bisa membacanya.
+var OrgContextKey = orgContextKey{}
+
+// WithOrgID: Pasang organisasi aktif ke context (job background / test)
+func WithOrgID(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, OrgContextKey, orgID)
+}
+
+// OrgIDFromContext: uuid.Nil jika context tidak membawa organisasi
+func OrgIDFromContext(ctx context.Context) uuid.UUID {
+	id, ok := ctx.Value(OrgContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil
+	}
+	return id
+}
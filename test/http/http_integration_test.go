@@ -36,6 +36,9 @@ func (suite *HTTPIntegrationTestSuite) SetupTest() {
 	})
 
 	// Public venue routes
+	api.Get("/orgs/:org_slug/venues/:slug/manifest", func(c *fiber.Ctx) error {
+		return c.Status(404).JSON(fiber.Map{"success": false, "message": "Venue not found or not published"})
+	})
 	api.Get("/venues/:slug/manifest", func(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"success": false, "message": "Venue not found or not published"})
 	})
@@ -71,7 +74,7 @@ func (suite *HTTPIntegrationTestSuite) TestAuthRegisterEndpoint() {
 
 func (suite *HTTPIntegrationTestSuite) TestVenueManifestEndpoint() {
 	// Test public venue manifest endpoint
-	httpReq := httptest.NewRequest("GET", "/api/v1/venues/test-slug/manifest", nil)
+	httpReq := httptest.NewRequest("GET", "/api/v1/orgs/test-org/venues/test-slug/manifest", nil)
 	resp, err := suite.app.Test(httpReq)
 
 	// Should return 404 for non-existent venue
@@ -79,6 +82,15 @@ func (suite *HTTPIntegrationTestSuite) TestVenueManifestEndpoint() {
 	assert.Equal(suite.T(), 404, resp.StatusCode)
 }
 
+func (suite *HTTPIntegrationTestSuite) TestLegacyVenueManifestEndpoint() {
+	// Route lama tanpa slug organisasi tetap ada untuk app mobile versi lama
+	httpReq := httptest.NewRequest("GET", "/api/v1/venues/test-slug/manifest", nil)
+	resp, err := suite.app.Test(httpReq)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 404, resp.StatusCode)
+}
+
 func (suite *HTTPIntegrationTestSuite) TestInvalidJSONRequest() {
 	// Test invalid JSON handling
 	httpReq := httptest.NewRequest("POST", "/api/v1/auth/register", bytes.NewReader([]byte("invalid json")))
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http/httptest"
 	"testing"
 
@@ -14,6 +15,9 @@ import (
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
 	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/cache"
+	"inspacemap/backend/pkg/mailer"
+	"inspacemap/backend/pkg/oidc"
 	"inspacemap/backend/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -63,24 +67,32 @@ func (suite *ComprehensiveHTTPTestSuite) SetupTest() {
 	areaGalleryRepo := repository.NewAreaGalleryRepository(suite.db)
 
 	auditRepo := repository.NewAuditRepository(suite.db)
+	ownershipRepo := repository.NewOwnershipRepository(suite.db)
 
 	// Initialize services
-	suite.authSvc = service.NewAuthService(suite.userRepo, suite.orgRepo, orgMemberRepo, invitationRepo, roleRepo)
-	suite.venueSvc = service.NewVenueService(venueRepo)
-	suite.graphSvc = service.NewGraphService(graphRepo, revisionRepo, floorRepo, venueRepo)
-	// Skip media service for now due to storage provider complexity
-	suite.teamSvc = service.NewTeamService(suite.userRepo, invitationRepo, orgMemberRepo, roleRepo)
-	roleSvc := service.NewRoleService(roleRepo, permRepo)
-	venueGallerySvc := service.NewVenueGalleryService(venueGalleryRepo)
-	areaGallerySvc := service.NewAreaGalleryService(areaGalleryRepo)
+	permResolver := service.NewPermissionResolver(orgMemberRepo, cache.NewLRU[*models.ResolvedPermissions](64, 0))
+	accountSvc := service.NewAccountService(suite.userRepo, repository.NewUserTokenRepository(suite.db), repository.NewRefreshTokenRepository(suite.db), mailer.NewFileMailer("", "test@inspacemap.local"), "http://localhost:3000")
+	twoFactorSvc := service.NewTwoFactorService(repository.NewTwoFactorRepository(suite.db), suite.userRepo, suite.orgRepo, orgMemberRepo, permResolver)
+	suite.authSvc = service.NewAuthService(suite.userRepo, suite.orgRepo, orgMemberRepo, invitationRepo, roleRepo, repository.NewRefreshTokenRepository(suite.db), permRepo, permResolver, accountSvc, twoFactorSvc)
 	suite.auditSvc = service.NewAuditService(auditRepo)
-	areaSvc := service.NewAreaService(areaRepo, areaGalleryRepo, graphRepo)
+	suite.venueSvc = service.NewVenueService(venueRepo, cache.NewLRU[*models.ManifestResponse](16, 0), suite.auditSvc)
+	suite.graphSvc = service.NewGraphService(graphRepo, revisionRepo, floorRepo, venueRepo, ownershipRepo, cache.NewLRU[*models.ManifestResponse](16, 0), suite.auditSvc)
+	// Skip media service for now due to storage provider complexity
+	suite.teamSvc = service.NewTeamService(suite.userRepo, invitationRepo, orgMemberRepo, roleRepo, suite.orgRepo, permResolver, mailer.NewFileMailer("", "test@inspacemap.local"), "http://localhost:3000", suite.auditSvc)
+	roleSvc := service.NewRoleService(roleRepo, permRepo, permResolver, suite.auditSvc)
+	venueGallerySvc := service.NewVenueGalleryService(venueGalleryRepo, ownershipRepo)
+	areaGallerySvc := service.NewAreaGalleryService(areaGalleryRepo, ownershipRepo)
+	areaSvc := service.NewAreaService(areaRepo, areaGalleryRepo, graphRepo, venueRepo, ownershipRepo, suite.auditSvc)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(suite.authSvc)
-	venueHandler := handler.NewVenueHandler(suite.venueSvc)
+	accountHandler := handler.NewAccountHandler(accountSvc)
+	apiKeySvc := service.NewApiKeyService(repository.NewApiKeyRepository(suite.db), permResolver)
+	ssoSvc := service.NewSSOService(suite.orgRepo, suite.userRepo, repository.NewUserIdentityRepository(suite.db), orgMemberRepo, roleRepo, permResolver, suite.authSvc, oidc.NewClient(nil), net.DefaultResolver, "http://localhost:3000/sso/callback")
+	venueHandler := handler.NewVenueHandler(suite.venueSvc, nil)
 	areaHandler := handler.NewAreaHandler(areaSvc)
-	graphHandler := handler.NewGraphHandler(suite.graphSvc)
+	graphImportSvc := service.NewGraphImportService(graphRepo, revisionRepo, areaRepo, ownershipRepo)
+	graphHandler := handler.NewGraphHandler(suite.graphSvc, graphImportSvc)
 	// Skip media handler for now
 	teamRoleHandler := handler.NewTeamRoleHandler(suite.teamSvc, roleSvc)
 	auditHandler := handler.NewAuditHandler(suite.auditSvc)
@@ -94,6 +106,7 @@ func (suite *ComprehensiveHTTPTestSuite) SetupTest() {
 	routeConfig := route.RouteConfig{
 		App:                 suite.app,
 		AuthHandler:         authHandler,
+		AccountHandler:      accountHandler,
 		VenueHandler:        venueHandler,
 		AreaHandler:         areaHandler,
 		GraphHandler:        graphHandler,
@@ -102,6 +115,12 @@ func (suite *ComprehensiveHTTPTestSuite) SetupTest() {
 		AuditHandler:        auditHandler,
 		VenueGalleryHandler: venueGalleryHandler,
 		AreaGalleryHandler:  areaGalleryHandler,
+		ApiKeyHandler:       handler.NewApiKeyHandler(apiKeySvc),
+		SSOHandler:          handler.NewSSOHandler(ssoSvc),
+		TwoFactorHandler:    handler.NewTwoFactorHandler(twoFactorSvc, suite.authSvc),
+		PermissionResolver:  permResolver,
+		APIKeyAuthenticator: apiKeySvc,
+		AuditLogger:         suite.auditSvc,
 	}
 	routeConfig.Setup()
 
@@ -115,18 +134,7 @@ func (suite *ComprehensiveHTTPTestSuite) SetupTest() {
 func (suite *ComprehensiveHTTPTestSuite) seedRolesAndPermissions() {
 	// Always seed for each test since each test has fresh database
 	// Create permissions
-	permissions := []entity.Permission{
-		{Key: "venue:create", Description: "Create Venue", Group: "Venue"},
-		{Key: "venue:update", Description: "Update Venue", Group: "Venue"},
-		{Key: "venue:delete", Description: "Delete Venue", Group: "Venue"},
-		{Key: "graph:edit", Description: "Edit Graph", Group: "Graph"},
-		{Key: "graph:publish", Description: "Publish Graph", Group: "Graph"},
-		{Key: "org:settings", Description: "Organization Settings", Group: "Organization"},
-		{Key: "org:billing", Description: "Organization Billing", Group: "Organization"},
-		{Key: "team:invite", Description: "Invite Team Members", Group: "Team"},
-		{Key: "team:manage", Description: "Manage Team", Group: "Team"},
-		{Key: "media:upload", Description: "Upload Media", Group: "Media"},
-	}
+	permissions := append([]entity.Permission(nil), entity.PermissionRegistry...)
 
 	for _, perm := range permissions {
 		perm.ID = uuid.New()
@@ -216,7 +224,11 @@ func (suite *ComprehensiveHTTPTestSuite) createTestUserAndOrg() {
 	suite.NoError(err, "Failed to create organization membership")
 
 	// Generate JWT token
-	token, err := utils.GenerateToken(userID, "test@example.com", testOrg.ID, "Owner", []string{"venue:create", "venue:update", "venue:delete"})
+	var ownerPerms []string
+	for _, perm := range entity.PermissionRegistry {
+		ownerPerms = append(ownerPerms, perm.Key)
+	}
+	token, err := utils.GenerateToken(userID, "test@example.com", testOrg.ID, "Owner", ownerPerms, uuid.Nil)
 	suite.NoError(err, "Failed to generate JWT token")
 	suite.authToken = token
 }
@@ -293,11 +305,49 @@ func (suite *ComprehensiveHTTPTestSuite) TestAuthLogin() {
 	assert.NotEmpty(suite.T(), response.AccessToken)
 }
 
+func (suite *ComprehensiveHTTPTestSuite) TestSwitchOrgAfterRevokeSession() {
+	reqBody, _ := json.Marshal(models.LoginRequest{Email: suite.testUser.Email, Password: "password123"})
+	httpReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := suite.app.Test(httpReq)
+	suite.Require().NoError(err)
+	suite.Require().Equal(200, resp.StatusCode)
+
+	var login models.AuthResponse
+	suite.Require().NoError(json.NewDecoder(resp.Body).Decode(&login))
+	claims, err := utils.ParseToken(login.AccessToken)
+	suite.Require().NoError(err)
+
+	httpReq = httptest.NewRequest("DELETE", "/api/v1/sessions/"+claims.SessionID.String(), nil)
+	httpReq.Header.Set("Authorization", "Bearer "+login.AccessToken)
+	resp, err = suite.app.Test(httpReq)
+	suite.Require().NoError(err)
+	suite.Require().Equal(200, resp.StatusCode)
+
+	// Access token sesi yang dicabut tidak boleh ditukar dengan token baru
+	reqBody, _ = json.Marshal(models.SwitchOrgRequest{OrganizationID: suite.testOrg.ID})
+	httpReq = httptest.NewRequest("POST", "/api/v1/auth/switch-org", bytes.NewReader(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+login.AccessToken)
+	resp, err = suite.app.Test(httpReq)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 401, resp.StatusCode)
+}
+
 // ============================================================================
 // PUBLIC ENDPOINTS TESTS
 // ============================================================================
 
 func (suite *ComprehensiveHTTPTestSuite) TestGetVenueManifest() {
+	httpReq := httptest.NewRequest("GET", "/api/v1/orgs/non-existent/venues/non-existent/manifest", nil)
+	resp, err := suite.app.Test(httpReq)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 404, resp.StatusCode)
+}
+
+func (suite *ComprehensiveHTTPTestSuite) TestGetVenueManifestLegacyRoute() {
 	httpReq := httptest.NewRequest("GET", "/api/v1/venues/non-existent/manifest", nil)
 	resp, err := suite.app.Test(httpReq)
 
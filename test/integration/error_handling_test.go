@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"context"
+	"inspacemap/backend/pkg/utils"
 	"testing"
 
 	"inspacemap/backend/internal/entity"
@@ -35,7 +36,7 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 		Password:         "password123",
 		OrganizationName: "First Org",
 	}
-	_, err1 := authSvc.Register(ctx, req)
+	_, err1 := authSvc.Register(ctx, req, models.ClientInfo{})
 	if err1 != nil {
 		t.Fatalf("First register should succeed: %v", err1)
 	}
@@ -47,7 +48,7 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 		Password:         "password456",
 		OrganizationName: "Second Org",
 	}
-	_, err2 := authSvc.Register(ctx, req2)
+	_, err2 := authSvc.Register(ctx, req2, models.ClientInfo{})
 
 	// 4. ASSERT: Harus ada error
 	if err2 == nil {
@@ -63,6 +64,7 @@ func TestConnectNonExistentNodes(t *testing.T) {
 
 	// 1. ARRANGE: Buat venue dan floor kosong (tanpa nodes)
 	orgID := uuid.New()
+	ctx = utils.WithOrgID(ctx, orgID)
 	venueID := uuid.New()
 
 	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestErrorOrg"})
@@ -140,6 +142,7 @@ func TestPublishEmptyDraft(t *testing.T) {
 
 	// 1. ARRANGE: Buat venue tanpa floor/node apapun
 	orgID := uuid.New()
+	ctx = utils.WithOrgID(ctx, orgID)
 	venueID := uuid.New()
 
 	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestEmptyOrg"})
@@ -193,6 +196,7 @@ func TestCreateNodeInvalidCoordinates(t *testing.T) {
 
 	// 1. ARRANGE: Buat venue dan floor
 	orgID := uuid.New()
+	ctx = utils.WithOrgID(ctx, orgID)
 	venueID := uuid.New()
 
 	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestCoordOrg"})
@@ -310,6 +314,7 @@ func TestGraphRevisionHistory(t *testing.T) {
 
 	// 1. ARRANGE: Buat venue
 	orgID := uuid.New()
+	ctx = utils.WithOrgID(ctx, orgID)
 	venueID := uuid.New()
 
 	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestHistoryOrg"})
@@ -429,6 +434,7 @@ func TestSelfConnectionPrevention(t *testing.T) {
 
 	// 1. ARRANGE: Buat venue, floor, dan node
 	orgID := uuid.New()
+	ctx = utils.WithOrgID(ctx, orgID)
 	venueID := uuid.New()
 
 	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestSelfOrg"})
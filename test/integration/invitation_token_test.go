@@ -0,0 +1,59 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"inspacemap/backend/config"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// TestHashLegacyInvitationTokens: Link undangan yang dikirim sebelum token di-hash tetap bisa dipakai
+func TestHashLegacyInvitationTokens(t *testing.T) {
+	ctx := context.Background()
+	invitationRepo := repository.NewInvitationRepository(testDB)
+
+	legacyRaw := uuid.NewString()
+	legacy := entity.UserInvitation{
+		OrganizationID: uuid.New(),
+		Email:          "legacy-" + legacyRaw[:8] + "@example.com",
+		RoleID:         uuid.New(),
+		Token:          legacyRaw,
+		ExpiresAt:      time.Now().Add(time.Hour),
+		Status:         entity.InvitationPending,
+	}
+	currentRaw, _ := utils.GenerateOpaqueToken()
+	current := legacy
+	current.Email = "current-" + legacyRaw[:8] + "@example.com"
+	current.Token = utils.HashToken(currentRaw)
+	if err := testDB.Create(&legacy).Error; err != nil {
+		t.Fatalf("Failed to create legacy invitation: %v", err)
+	}
+	if err := testDB.Create(&current).Error; err != nil {
+		t.Fatalf("Failed to create invitation: %v", err)
+	}
+
+	if _, err := config.HashLegacyInvitationTokens(testDB); err != nil {
+		t.Fatalf("HashLegacyInvitationTokens failed: %v", err)
+	}
+	// Idempoten: dijalankan di setiap startup
+	if _, err := config.HashLegacyInvitationTokens(testDB); err != nil {
+		t.Fatalf("HashLegacyInvitationTokens failed on second run: %v", err)
+	}
+
+	found, err := invitationRepo.GetByToken(ctx, utils.HashToken(legacyRaw))
+	if err != nil || found.ID != legacy.ID {
+		t.Fatalf("Legacy invitation link no longer resolves: %v", err)
+	}
+	found, err = invitationRepo.GetByToken(ctx, utils.HashToken(currentRaw))
+	if err != nil || found.ID != current.ID {
+		t.Fatalf("Already hashed token was changed: %v", err)
+	}
+
+	t.Log("✅ Legacy invitation tokens are hashed in place.")
+}
@@ -0,0 +1,53 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TestSetLiveRevisionOnlyAcceptsPublishedRevisionsOfVenue: Rollback tidak boleh menjadikan draft
+// atau revisi venue lain sebagai live
+func TestSetLiveRevisionOnlyAcceptsPublishedRevisionsOfVenue(t *testing.T) {
+	ctx := context.Background()
+	revisionRepo := repository.NewGraphRevisionRepository(testDB)
+
+	orgID := uuid.New()
+	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestRollbackOrg"})
+	venue := entity.Venue{OrganizationID: orgID, Name: "Rollback Venue", Slug: "rollback-" + uuid.NewString()[:8]}
+	other := entity.Venue{OrganizationID: orgID, Name: "Other Venue", Slug: "rollback-" + uuid.NewString()[:8]}
+	for _, v := range []*entity.Venue{&venue, &other} {
+		if err := testDB.Create(v).Error; err != nil {
+			t.Fatalf("Failed to create venue: %v", err)
+		}
+	}
+	published := entity.GraphRevision{VenueID: venue.ID, Status: entity.StatusPublished}
+	draft := entity.GraphRevision{VenueID: venue.ID, Status: entity.StatusDraft}
+	foreign := entity.GraphRevision{VenueID: other.ID, Status: entity.StatusPublished}
+	for _, rev := range []*entity.GraphRevision{&published, &draft, &foreign} {
+		if err := testDB.Create(rev).Error; err != nil {
+			t.Fatalf("Failed to create revision: %v", err)
+		}
+	}
+
+	for name, revisionID := range map[string]uuid.UUID{"draft": draft.ID, "foreign": foreign.ID} {
+		if n, err := revisionRepo.SetLiveRevision(ctx, venue.ID, revisionID); err != nil || n != 0 {
+			t.Errorf("%s revision must not become live (rows %d, err %v)", name, n, err)
+		}
+	}
+	if n, err := revisionRepo.SetLiveRevision(ctx, venue.ID, published.ID); err != nil || n != 1 {
+		t.Fatalf("SetLiveRevision failed: rows %d, err %v", n, err)
+	}
+
+	var reloaded entity.Venue
+	testDB.First(&reloaded, "id = ?", venue.ID)
+	if reloaded.LiveRevisionID != published.ID {
+		t.Errorf("Expected live revision %s, got %s", published.ID, reloaded.LiveRevisionID)
+	}
+
+	t.Log("✅ Only published revisions of the venue can be rolled back to.")
+}
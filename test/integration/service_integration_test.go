@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"context"
+	"inspacemap/backend/pkg/utils"
 	"log"
 	"os"
 	"testing"
@@ -11,6 +12,8 @@ import (
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/repository"
 	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/cache"
+	"inspacemap/backend/pkg/mailer"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -41,12 +44,18 @@ func TestMain(m *testing.M) {
 		repository.NewOrganizationMemberRepository(testDB),
 		repository.NewInvitationRepository(testDB),
 		repository.NewRoleRepository(testDB),
+		repository.NewRefreshTokenRepository(testDB),
+		repository.NewPermissionRepository(testDB),
+		service.NewPermissionResolver(repository.NewOrganizationMemberRepository(testDB), cache.NewLRU[*models.ResolvedPermissions](16, 0)),
+		service.NewAccountService(userRepo, repository.NewUserTokenRepository(testDB), repository.NewRefreshTokenRepository(testDB), mailer.NewFileMailer("", "test@inspacemap.local"), "http://localhost:3000"),
+		service.NewTwoFactorService(repository.NewTwoFactorRepository(testDB), userRepo, orgRepo, repository.NewOrganizationMemberRepository(testDB), service.NewPermissionResolver(repository.NewOrganizationMemberRepository(testDB), cache.NewLRU[*models.ResolvedPermissions](16, 0))),
 	)
 	log.Println("✅ Auth service initialized")
 
 	graphSvc = service.NewGraphService(
 		repository.NewGraphRepository(testDB), repository.NewGraphRevisionRepository(testDB),
 		repository.NewFloorRepository(testDB), repository.NewVenueRepository(testDB),
+		repository.NewOwnershipRepository(testDB), cache.NewLRU[*models.ManifestResponse](16, 0), nil,
 	)
 	log.Println("✅ Graph service initialized")
 
@@ -84,7 +93,7 @@ func TestAuthAndMembershipFlow(t *testing.T) {
 		Password:         "password",
 		OrganizationName: "Test Org Inc",
 	}
-	authResp, err := authSvc.Register(ctx, req)
+	authResp, err := authSvc.Register(ctx, req, models.ClientInfo{})
 
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
@@ -110,6 +119,7 @@ func TestGraphPublishIntegrity(t *testing.T) {
 	ctx := context.Background()
 
 	orgID := uuid.New()
+	ctx = utils.WithOrgID(ctx, orgID)
 	venueID := uuid.New()
 
 	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestPublishOrg"})
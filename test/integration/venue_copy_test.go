@@ -0,0 +1,61 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TestCreateCopySkipsAreasOnUncopiedFloors: Area di lantai yang belum dipublish tidak boleh
+// mendapat FloorID baru yang tidak menunjuk ke floor mana pun
+func TestCreateCopySkipsAreasOnUncopiedFloors(t *testing.T) {
+	ctx := context.Background()
+	venueRepo := repository.NewVenueRepository(testDB)
+
+	orgID := uuid.New()
+	testDB.Create(&entity.Organization{BaseEntity: entity.BaseEntity{ID: orgID}, Name: "TestCopyOrg"})
+
+	publishedFloor, unpublishedFloor := uuid.New(), uuid.New()
+	live := entity.GraphRevision{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Status:     entity.StatusPublished,
+		Floors: []entity.Floor{{
+			BaseEntity: entity.BaseEntity{ID: uuid.New()},
+			LineageID:  &publishedFloor,
+			Name:       "Lobby",
+		}},
+	}
+	src := &repository.VenueCopy{
+		Venue: entity.Venue{OrganizationID: orgID, Name: "Copy Source", Slug: "copy-" + uuid.NewString()[:8]},
+		Areas: []entity.Area{
+			{BaseEntity: entity.BaseEntity{ID: uuid.New()}, FloorID: publishedFloor, Name: "Atrium"},
+			{BaseEntity: entity.BaseEntity{ID: uuid.New()}, FloorID: unpublishedFloor, Name: "Draft Only"},
+		},
+		Revisions:      []entity.GraphRevision{live},
+		LiveRevisionID: live.ID,
+		CreatedByID:    uuid.New(),
+	}
+
+	venue, err := venueRepo.CreateCopy(ctx, src)
+	if err != nil {
+		t.Fatalf("CreateCopy failed: %v", err)
+	}
+
+	var areas []entity.Area
+	testDB.Where("venue_id = ?", venue.ID).Find(&areas)
+	if len(areas) != 1 || areas[0].Name != "Atrium" {
+		t.Fatalf("Expected only the area on the copied floor, got %+v", areas)
+	}
+
+	var floorCount int64
+	testDB.Model(&entity.Floor{}).Where("venue_id = ? AND id = ?", venue.ID, areas[0].FloorID).Count(&floorCount)
+	if floorCount != 1 {
+		t.Errorf("Area FloorID %s does not point to the copied draft floor", areas[0].FloorID)
+	}
+
+	t.Log("✅ Areas on uncopied floors are skipped.")
+}
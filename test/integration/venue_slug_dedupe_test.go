@@ -0,0 +1,63 @@
+package integration_test
+
+import (
+	"testing"
+
+	"inspacemap/backend/config"
+	"inspacemap/backend/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+// TestDedupeVenueSlugs: Database lama dengan slug kembar per organisasi tetap bisa dimigrasi ke index unik
+func TestDedupeVenueSlugs(t *testing.T) {
+	// Simulasi database sebelum index unik ada
+	if err := testDB.Migrator().DropIndex(&entity.Venue{}, "idx_venue_org_slug"); err != nil {
+		t.Fatalf("Failed to drop slug index: %v", err)
+	}
+	defer func() {
+		if err := testDB.AutoMigrate(&entity.Venue{}); err != nil {
+			t.Fatalf("Failed to recreate slug index: %v", err)
+		}
+	}()
+
+	org := entity.Organization{Name: "Dedupe Org", Slug: "dedupe-" + uuid.NewString()[:8]}
+	if err := testDB.Create(&org).Error; err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	venues := make([]entity.Venue, 3)
+	for i := range venues {
+		venues[i] = entity.Venue{OrganizationID: org.ID, Name: "Mall", Slug: "mall"}
+		if err := testDB.Create(&venues[i]).Error; err != nil {
+			t.Fatalf("Failed to create venue: %v", err)
+		}
+	}
+
+	n, err := config.DedupeVenueSlugs(testDB)
+	if err != nil {
+		t.Fatalf("DedupeVenueSlugs failed: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("Expected 2 renamed venues, got %d", n)
+	}
+	// Idempoten: dijalankan di setiap startup
+	if n, err := config.DedupeVenueSlugs(testDB); err != nil || n != 0 {
+		t.Fatalf("Second run should rename nothing, got %d (%v)", n, err)
+	}
+
+	var oldest entity.Venue
+	if err := testDB.First(&oldest, "id = ?", venues[0].ID).Error; err != nil || oldest.Slug != "mall" {
+		t.Fatalf("Oldest venue should keep its slug, got %q (%v)", oldest.Slug, err)
+	}
+	for _, v := range venues[1:] {
+		var renamed entity.Venue
+		if err := testDB.First(&renamed, "id = ?", v.ID).Error; err != nil {
+			t.Fatalf("Failed to load venue: %v", err)
+		}
+		if want := "mall-" + v.ID.String()[:8]; renamed.Slug != want {
+			t.Fatalf("Expected slug %q, got %q", want, renamed.Slug)
+		}
+	}
+
+	t.Log("✅ Duplicate venue slugs are renamed before the unique index is created.")
+}
@@ -0,0 +1,210 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AccountServiceTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	userRepo    *MockUserRepository
+	tokenRepo   *MockUserTokenRepository
+	refreshRepo *MockRefreshTokenRepository
+	mailer      *MockMailer
+	service     service.AccountService
+
+	ctx  context.Context
+	user *entity.User
+}
+
+func (suite *AccountServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.userRepo = NewMockUserRepository(suite.ctrl)
+	suite.tokenRepo = NewMockUserTokenRepository(suite.ctrl)
+	suite.refreshRepo = NewMockRefreshTokenRepository(suite.ctrl)
+	suite.mailer = NewMockMailer(suite.ctrl)
+	suite.service = service.NewAccountService(suite.userRepo, suite.tokenRepo, suite.refreshRepo, suite.mailer, "https://app.example.com/")
+
+	suite.ctx = context.Background()
+	suite.user = &entity.User{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Email: "jane@example.com", FullName: "Jane"}
+}
+
+func (suite *AccountServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestAccountServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountServiceTestSuite))
+}
+
+// tokenFromLink: Ambil token mentah dari link di body email
+func tokenFromLink(t *testing.T, body, path string) string {
+	start := strings.Index(body, "https://app.example.com"+path+"?")
+	require.GreaterOrEqual(t, start, 0, "link %s not found in email", path)
+	link := strings.Fields(body[start:])[0]
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	return u.Query().Get("token")
+}
+
+func (suite *AccountServiceTestSuite) usableToken(purpose entity.UserTokenPurpose, raw string) *entity.UserToken {
+	return &entity.UserToken{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		UserID:     suite.user.ID,
+		Purpose:    purpose,
+		TokenHash:  utils.HashToken(raw),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+}
+
+func (suite *AccountServiceTestSuite) TestRequestPasswordReset_SendsSingleUseLink() {
+	var stored *entity.UserToken
+	var textBody string
+	sent := make(chan struct{})
+
+	// Token & email dibuat di background dengan context sendiri
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "jane@example.com").Return(suite.user, nil)
+	suite.tokenRepo.EXPECT().InvalidateByUser(gomock.Any(), suite.user.ID, entity.TokenPurposePasswordReset).Return(nil)
+	suite.tokenRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, t *entity.UserToken) error {
+		stored = t
+		return nil
+	})
+	suite.mailer.EXPECT().Send(gomock.Any(), "jane@example.com", gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, text, html string) error {
+			defer close(sent)
+			_, hasDeadline := ctx.Deadline()
+			assert.True(suite.T(), hasDeadline)
+			textBody = text
+			assert.Contains(suite.T(), html, "https://app.example.com/reset-password?token=")
+			return nil
+		})
+
+	err := suite.service.RequestPasswordReset(suite.ctx, " jane@example.com ")
+
+	require.NoError(suite.T(), err)
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		suite.T().Fatal("password reset email was not sent")
+	}
+	raw := tokenFromLink(suite.T(), textBody, "/reset-password")
+	assert.Equal(suite.T(), utils.HashToken(raw), stored.TokenHash, "only the hash is stored")
+	assert.WithinDuration(suite.T(), time.Now().Add(service.PasswordResetTokenTTL), stored.ExpiresAt, time.Minute)
+}
+
+// Email tidak terdaftar: respons sama, tidak ada token & email
+func (suite *AccountServiceTestSuite) TestRequestPasswordReset_UnknownEmailIsSilent() {
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "ghost@example.com").Return(nil, errors.New("record not found"))
+
+	assert.NoError(suite.T(), suite.service.RequestPasswordReset(suite.ctx, "ghost@example.com"))
+}
+
+func (suite *AccountServiceTestSuite) TestResetPassword_UpdatesPasswordAndRevokesSessions() {
+	token := suite.usableToken(entity.TokenPurposePasswordReset, "raw-reset")
+
+	suite.tokenRepo.EXPECT().GetByHash(suite.ctx, entity.TokenPurposePasswordReset, utils.HashToken("raw-reset")).Return(token, nil)
+	suite.tokenRepo.EXPECT().Consume(suite.ctx, token.ID).Return(true, nil)
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.user.ID).Return(suite.user, nil)
+	suite.userRepo.EXPECT().Update(suite.ctx, suite.user).Return(nil)
+	suite.refreshRepo.EXPECT().RevokeAllByUser(suite.ctx, suite.user.ID).Return(nil)
+
+	err := suite.service.ResetPassword(suite.ctx, "raw-reset", "new-password-123")
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), utils.CheckPasswordHash("new-password-123", suite.user.PasswordHash))
+	assert.True(suite.T(), suite.user.IsEmailVerified)
+}
+
+func (suite *AccountServiceTestSuite) TestResetPassword_TokenAlreadyUsed() {
+	token := suite.usableToken(entity.TokenPurposePasswordReset, "raw-reset")
+	usedAt := time.Now()
+	token.UsedAt = &usedAt
+
+	suite.tokenRepo.EXPECT().GetByHash(suite.ctx, entity.TokenPurposePasswordReset, utils.HashToken("raw-reset")).Return(token, nil)
+
+	err := suite.service.ResetPassword(suite.ctx, "raw-reset", "new-password-123")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidEmailToken)
+}
+
+// Dua request paralel: hanya satu yang berhasil menandai token terpakai
+func (suite *AccountServiceTestSuite) TestResetPassword_LostConsumeRace() {
+	token := suite.usableToken(entity.TokenPurposePasswordReset, "raw-reset")
+
+	suite.tokenRepo.EXPECT().GetByHash(suite.ctx, entity.TokenPurposePasswordReset, utils.HashToken("raw-reset")).Return(token, nil)
+	suite.tokenRepo.EXPECT().Consume(suite.ctx, token.ID).Return(false, nil)
+
+	err := suite.service.ResetPassword(suite.ctx, "raw-reset", "new-password-123")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidEmailToken)
+}
+
+func (suite *AccountServiceTestSuite) TestResetPassword_ExpiredToken() {
+	token := suite.usableToken(entity.TokenPurposePasswordReset, "raw-reset")
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+
+	suite.tokenRepo.EXPECT().GetByHash(suite.ctx, entity.TokenPurposePasswordReset, utils.HashToken("raw-reset")).Return(token, nil)
+
+	err := suite.service.ResetPassword(suite.ctx, "raw-reset", "new-password-123")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidEmailToken)
+}
+
+func (suite *AccountServiceTestSuite) TestResetPassword_TooShort() {
+	err := suite.service.ResetPassword(suite.ctx, "raw-reset", "short")
+
+	assert.ErrorIs(suite.T(), err, service.ErrPasswordTooShort)
+}
+
+func (suite *AccountServiceTestSuite) TestSendEmailVerification_AlreadyVerified() {
+	suite.user.IsEmailVerified = true
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.user.ID).Return(suite.user, nil)
+
+	err := suite.service.SendEmailVerification(suite.ctx, suite.user.ID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrEmailAlreadyVerified)
+}
+
+func (suite *AccountServiceTestSuite) TestVerifyEmail_RoundTrip() {
+	var stored *entity.UserToken
+	var textBody string
+
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.user.ID).Return(suite.user, nil).Times(2)
+	suite.tokenRepo.EXPECT().InvalidateByUser(suite.ctx, suite.user.ID, entity.TokenPurposeEmailVerification).Return(nil)
+	suite.tokenRepo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, t *entity.UserToken) error {
+		t.ID = uuid.New()
+		stored = t
+		return nil
+	})
+	suite.mailer.EXPECT().Send(suite.ctx, suite.user.Email, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, text, _ string) error {
+			textBody = text
+			return nil
+		})
+	require.NoError(suite.T(), suite.service.SendEmailVerification(suite.ctx, suite.user.ID))
+
+	raw := tokenFromLink(suite.T(), textBody, "/verify-email")
+	suite.tokenRepo.EXPECT().GetByHash(suite.ctx, entity.TokenPurposeEmailVerification, utils.HashToken(raw)).
+		DoAndReturn(func(_ context.Context, _ entity.UserTokenPurpose, _ string) (*entity.UserToken, error) {
+			return stored, nil
+		})
+	suite.tokenRepo.EXPECT().Consume(suite.ctx, stored.ID).Return(true, nil)
+	suite.userRepo.EXPECT().Update(suite.ctx, suite.user).Return(nil)
+
+	require.NoError(suite.T(), suite.service.VerifyEmail(suite.ctx, raw))
+	assert.True(suite.T(), suite.user.IsEmailVerified)
+}
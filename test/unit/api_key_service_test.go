@@ -0,0 +1,249 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/delivery/http/route"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ApiKeyServiceTestSuite struct {
+	suite.Suite
+	ctrl     *gomock.Controller
+	repo     *MockApiKeyRepository
+	resolver *MockPermissionResolver
+	service  service.ApiKeyService
+
+	ctx   context.Context
+	orgID uuid.UUID
+	perms []string
+}
+
+func (suite *ApiKeyServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.repo = NewMockApiKeyRepository(suite.ctrl)
+	suite.resolver = NewMockPermissionResolver(suite.ctrl)
+	suite.service = service.NewApiKeyService(suite.repo, suite.resolver)
+
+	suite.ctx = context.Background()
+	suite.orgID = uuid.New()
+	suite.perms = []string{string(entity.PermOrgSettings), string(entity.PermVenueCreate), string(entity.PermGraphEdit)}
+}
+
+func (suite *ApiKeyServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestApiKeyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ApiKeyServiceTestSuite))
+}
+
+func (suite *ApiKeyServiceTestSuite) activeKey(raw string) *entity.ApiKey {
+	return &entity.ApiKey{
+		BaseEntity:      entity.BaseEntity{ID: uuid.New()},
+		OrganizationID:  suite.orgID,
+		Organization:    &entity.Organization{IsActive: true},
+		KeyHash:         utils.HashToken(raw),
+		Scopes:          []string{string(entity.PermGraphEdit)},
+		CreatedByUserID: uuid.New(),
+		IsActive:        true,
+	}
+}
+
+// expectCreator: Permission pembuat key saat request (bukan saat key dibuat)
+func (suite *ApiKeyServiceTestSuite) expectCreator(key *entity.ApiKey, perms []string, isMember bool) {
+	suite.resolver.EXPECT().ResolvePermissions(suite.ctx, key.CreatedByUserID, key.OrganizationID).Return(perms, isMember, nil)
+}
+
+func (suite *ApiKeyServiceTestSuite) TestCreateKey_StoresHashOnly() {
+	var stored *entity.ApiKey
+	suite.repo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, k *entity.ApiKey) error {
+		stored = k
+		return nil
+	})
+
+	resp, err := suite.service.CreateKey(suite.ctx, suite.orgID, uuid.New(), suite.perms, models.CreateApiKeyRequest{
+		Name:   " CI pipeline ",
+		Scopes: []string{string(entity.PermGraphEdit), string(entity.PermGraphEdit)},
+	})
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(resp.Key, service.APIKeyPrefix))
+	assert.Equal(suite.T(), utils.HashToken(resp.Key), stored.KeyHash)
+	assert.NotContains(suite.T(), stored.KeyHash, resp.Key)
+	assert.Equal(suite.T(), resp.Key[:len(stored.Prefix)], stored.Prefix)
+	assert.Equal(suite.T(), "CI pipeline", stored.Name)
+	assert.Equal(suite.T(), []string{string(entity.PermGraphEdit)}, stored.Scopes, "duplicate scopes are collapsed")
+}
+
+// Pembuat key tidak bisa memberi permission yang tidak ia miliki
+func (suite *ApiKeyServiceTestSuite) TestCreateKey_RejectsEscalation() {
+	_, err := suite.service.CreateKey(suite.ctx, suite.orgID, uuid.New(), suite.perms, models.CreateApiKeyRequest{
+		Name:   "Publisher",
+		Scopes: []string{string(entity.PermGraphPublish)},
+	})
+
+	assert.ErrorIs(suite.T(), err, service.ErrAPIKeyScopeNotHeld)
+}
+
+func (suite *ApiKeyServiceTestSuite) TestCreateKey_InvalidRequest() {
+	past := time.Now().Add(-time.Hour)
+	cases := map[string]models.CreateApiKeyRequest{
+		"unknown scope": {Name: "Legacy", Scopes: []string{"user:invite"}},
+		"no scopes":     {Name: "Empty"},
+		"short name":    {Name: "x", Scopes: []string{string(entity.PermGraphEdit)}},
+		"expired":       {Name: "Expired", Scopes: []string{string(entity.PermGraphEdit)}, ExpiresAt: &past},
+	}
+	for name, req := range cases {
+		_, err := suite.service.CreateKey(suite.ctx, suite.orgID, uuid.New(), suite.perms, req)
+		assert.ErrorIs(suite.T(), err, service.ErrInvalidAPIKeyRequest, name)
+	}
+}
+
+func (suite *ApiKeyServiceTestSuite) TestRevokeKey_ForeignOrganization() {
+	keyID := uuid.New()
+	suite.repo.EXPECT().Revoke(suite.ctx, suite.orgID, keyID).Return(int64(0), nil)
+
+	err := suite.service.RevokeKey(suite.ctx, suite.orgID, keyID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrNotFound)
+}
+
+func (suite *ApiKeyServiceTestSuite) TestAuthenticate_TracksLastUsed() {
+	key := suite.activeKey("ism_secret")
+	suite.repo.EXPECT().GetByHash(suite.ctx, utils.HashToken("ism_secret")).Return(key, nil)
+	suite.expectCreator(key, suite.perms, true)
+	suite.repo.EXPECT().TouchLastUsed(suite.ctx, key.ID, gomock.Any()).Return(nil)
+
+	principal, err := suite.service.AuthenticateAPIKey(suite.ctx, "ism_secret")
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.orgID, principal.OrganizationID)
+	assert.Equal(suite.T(), key.ID, principal.KeyID)
+	assert.Equal(suite.T(), []string{string(entity.PermGraphEdit)}, principal.Scopes)
+}
+
+// Dipakai kurang dari semenit lalu: tidak perlu menulis ke database lagi
+func (suite *ApiKeyServiceTestSuite) TestAuthenticate_ThrottlesLastUsedWrites() {
+	key := suite.activeKey("ism_secret")
+	recent := time.Now().Add(-10 * time.Second)
+	key.LastUsedAt = &recent
+	suite.repo.EXPECT().GetByHash(suite.ctx, utils.HashToken("ism_secret")).Return(key, nil)
+	suite.expectCreator(key, suite.perms, true)
+
+	_, err := suite.service.AuthenticateAPIKey(suite.ctx, "ism_secret")
+
+	assert.NoError(suite.T(), err)
+}
+
+// Role pembuat diturunkan setelah key dibuat: scope yang tidak lagi ia miliki hilang dari key
+func (suite *ApiKeyServiceTestSuite) TestAuthenticate_ScopesLimitedToCreatorPermissions() {
+	key := suite.activeKey("ism_secret")
+	key.Scopes = []string{string(entity.PermGraphEdit), string(entity.PermVenueCreate)}
+	recent := time.Now()
+	key.LastUsedAt = &recent
+	suite.repo.EXPECT().GetByHash(suite.ctx, utils.HashToken("ism_secret")).Return(key, nil)
+	suite.expectCreator(key, []string{string(entity.PermVenueCreate)}, true)
+
+	principal, err := suite.service.AuthenticateAPIKey(suite.ctx, "ism_secret")
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{string(entity.PermVenueCreate)}, principal.Scopes)
+}
+
+// Pembuat sudah dikeluarkan dari organisasi: key ikut tidak berlaku
+func (suite *ApiKeyServiceTestSuite) TestAuthenticate_RejectsKeyOfFormerMember() {
+	key := suite.activeKey("ism_secret")
+	suite.repo.EXPECT().GetByHash(suite.ctx, utils.HashToken("ism_secret")).Return(key, nil)
+	suite.expectCreator(key, nil, false)
+
+	_, err := suite.service.AuthenticateAPIKey(suite.ctx, "ism_secret")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidAPIKey)
+}
+
+func (suite *ApiKeyServiceTestSuite) TestAuthenticate_RejectsUnusableKeys() {
+	expired := time.Now().Add(-time.Minute)
+	cases := map[string]func(k *entity.ApiKey){
+		"revoked":      func(k *entity.ApiKey) { k.IsActive = false },
+		"expired":      func(k *entity.ApiKey) { k.ExpiresAt = &expired },
+		"inactive org": func(k *entity.ApiKey) { k.Organization.IsActive = false },
+	}
+	for name, mutate := range cases {
+		key := suite.activeKey("ism_secret")
+		mutate(key)
+		suite.repo.EXPECT().GetByHash(suite.ctx, utils.HashToken("ism_secret")).Return(key, nil)
+
+		_, err := suite.service.AuthenticateAPIKey(suite.ctx, "ism_secret")
+		assert.ErrorIs(suite.T(), err, service.ErrInvalidAPIKey, name)
+	}
+
+	suite.repo.EXPECT().GetByHash(suite.ctx, utils.HashToken("ism_unknown")).Return(nil, errors.New("record not found"))
+	_, err := suite.service.AuthenticateAPIKey(suite.ctx, "ism_unknown")
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidAPIKey)
+
+	// Bukan format key: tidak perlu query
+	_, err = suite.service.AuthenticateAPIKey(suite.ctx, "not-a-key")
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidAPIKey)
+}
+
+// X-API-Key diterima di route tenant sesuai scope, tapi tidak di endpoint milik akun user
+func TestRoutes_APIKeyAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := NewMockApiKeyRepository(ctrl)
+	resolver := NewMockPermissionResolver(ctrl)
+	app := fiber.New()
+	cfg := &route.RouteConfig{App: app, APIKeyAuthenticator: service.NewApiKeyService(repo, resolver)}
+	cfg.Setup()
+
+	key := &entity.ApiKey{
+		BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+		OrganizationID: uuid.New(),
+		Organization:   &entity.Organization{IsActive: true},
+		Scopes:         []string{string(entity.PermOrgSettings)},
+		IsActive:       true,
+	}
+	now := time.Now()
+	key.LastUsedAt = &now
+	repo.EXPECT().GetByHash(gomock.Any(), utils.HashToken("ism_valid")).Return(key, nil).AnyTimes()
+	repo.EXPECT().GetByHash(gomock.Any(), utils.HashToken("ism_revoked")).Return(nil, errors.New("record not found")).AnyTimes()
+	inviterKey := *key
+	inviterKey.Scopes = []string{string(entity.PermTeamInvite)}
+	repo.EXPECT().GetByHash(gomock.Any(), utils.HashToken("ism_inviter")).Return(&inviterKey, nil).AnyTimes()
+	creatorPerms := []string{string(entity.PermOrgSettings), string(entity.PermTeamInvite)}
+	resolver.EXPECT().ResolvePermissions(gomock.Any(), key.CreatedByUserID, key.OrganizationID).Return(creatorPerms, true, nil).AnyTimes()
+
+	send := func(method, path, apiKey string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusUnauthorized, send(fiber.MethodGet, "/api/v1/venues", "ism_revoked"))
+	// Scope tidak mencakup venue:create
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, "/api/v1/venues", "ism_valid"))
+	// Endpoint akun & kelola API key butuh sesi user, walau scope org:settings ada
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodGet, "/api/v1/me", "ism_valid"))
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, "/api/v1/api-keys", "ism_valid"))
+	// Undangan butuh user pengundang, scope team:invite saja tidak cukup
+	invitePath := "/api/v1/orgs/" + key.OrganizationID.String()
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, invitePath+"/invite", "ism_inviter"))
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, invitePath+"/invitations/"+uuid.NewString()+"/resend", "ism_inviter"))
+}
@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type areaDetailFixture struct {
+	svc         service.AreaService
+	areaRepo    *MockAreaRepository
+	galleryRepo *MockAreaGalleryRepository
+	venueRepo   *MockVenueRepository
+}
+
+func newAreaDetailFixture(t *testing.T) areaDetailFixture {
+	ctrl := gomock.NewController(t)
+	f := areaDetailFixture{
+		areaRepo:    NewMockAreaRepository(ctrl),
+		galleryRepo: NewMockAreaGalleryRepository(ctrl),
+		venueRepo:   NewMockVenueRepository(ctrl),
+	}
+	f.svc = service.NewAreaService(f.areaRepo, f.galleryRepo, NewMockGraphRepository(ctrl), f.venueRepo, NewMockOwnershipRepository(ctrl), nil)
+	return f
+}
+
+// expectArea: Area milik venue dengan visibility & status publish tertentu
+func (f areaDetailFixture) expectArea(ctx context.Context, visibility entity.VisibilityStatus, published bool) (*entity.Area, *entity.Venue) {
+	venue := &entity.Venue{
+		BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+		OrganizationID: uuid.New(),
+		Visibility:     visibility,
+	}
+	if published {
+		venue.LiveRevisionID = uuid.New()
+	}
+	area := &entity.Area{BaseEntity: entity.BaseEntity{ID: uuid.New()}, VenueID: venue.ID, Name: "Atrium"}
+	f.areaRepo.EXPECT().GetByID(ctx, area.ID).Return(area, nil)
+	f.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	return area, venue
+}
+
+func TestGetAreaDetail_PublishedPublicVenue(t *testing.T) {
+	f := newAreaDetailFixture(t)
+	ctx := context.Background()
+	area, _ := f.expectArea(ctx, entity.VisibilityPublic, true)
+	f.galleryRepo.EXPECT().GetByAreaID(ctx, area.ID).Return(nil, nil)
+
+	detail, err := f.svc.GetAreaDetail(ctx, area.ID, models.ManifestAccess{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Atrium", detail.Name)
+}
+
+func TestGetAreaDetail_PrivateVenueHiddenFromOtherOrganization(t *testing.T) {
+	f := newAreaDetailFixture(t)
+	ctx := context.Background()
+	area, _ := f.expectArea(ctx, entity.VisibilityPrivate, true)
+
+	detail, err := f.svc.GetAreaDetail(ctx, area.ID, models.ManifestAccess{OrganizationID: uuid.New()})
+
+	assert.Error(t, err)
+	assert.Nil(t, detail)
+}
+
+func TestGetAreaDetail_UnpublishedVenueHidden(t *testing.T) {
+	f := newAreaDetailFixture(t)
+	ctx := context.Background()
+	area, venue := f.expectArea(ctx, entity.VisibilityPublic, false)
+
+	// Anggota org pemilik pun memakai endpoint tenant untuk venue yang belum dipublish
+	detail, err := f.svc.GetAreaDetail(ctx, area.ID, models.ManifestAccess{OrganizationID: venue.OrganizationID})
+
+	assert.Error(t, err)
+	assert.Nil(t, detail)
+}
+
+func TestGetAreaDetail_ShareLinkOpensPrivateVenue(t *testing.T) {
+	f := newAreaDetailFixture(t)
+	ctx := context.Background()
+	area, venue := f.expectArea(ctx, entity.VisibilityPrivate, false)
+	f.galleryRepo.EXPECT().GetByAreaID(ctx, area.ID).Return(nil, nil)
+	token, _, err := utils.GenerateShareToken(venue.ID, true, time.Hour)
+	require.NoError(t, err)
+
+	detail, err := f.svc.GetAreaDetail(ctx, area.ID, models.ManifestAccess{ShareToken: token})
+
+	require.NoError(t, err)
+	assert.Equal(t, area.ID, detail.ID)
+}
+
+func TestGetAreaDetail_ShareLinkForOtherVenue(t *testing.T) {
+	f := newAreaDetailFixture(t)
+	ctx := context.Background()
+	area, _ := f.expectArea(ctx, entity.VisibilityPrivate, true)
+	token, _, err := utils.GenerateShareToken(uuid.New(), false, time.Hour)
+	require.NoError(t, err)
+
+	detail, err := f.svc.GetAreaDetail(ctx, area.ID, models.ManifestAccess{ShareToken: token})
+
+	assert.ErrorIs(t, err, service.ErrInvalidShareLink)
+	assert.Nil(t, detail)
+}
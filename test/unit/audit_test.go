@@ -0,0 +1,199 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"inspacemap/backend/internal/delivery/http/middleware"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// recordingAuditRepo: Kumpulkan log yang ditulis worker audit
+func recordingAuditRepo(ctrl *gomock.Controller) (*MockAuditLogRepository, func() []entity.AuditLog) {
+	var mu sync.Mutex
+	var written []entity.AuditLog
+	repo := NewMockAuditLogRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, log *entity.AuditLog) error {
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, *log)
+		return nil
+	}).AnyTimes()
+	return repo, func() []entity.AuditLog {
+		mu.Lock()
+		defer mu.Unlock()
+		return written
+	}
+}
+
+func auditedContext(actor utils.AuditActor) context.Context {
+	return utils.WithAuditActor(orgContext(), actor)
+}
+
+func TestAuditService_CloseFlushesQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, written := recordingAuditRepo(ctrl)
+	audit := service.NewAuditService(repo)
+
+	for i := 0; i < 10; i++ {
+		audit.LogActivity(context.Background(), models.CreateAuditLogRequest{OrganizationID: testOrgID, Action: "HTTP_POST"})
+	}
+	audit.Close()
+	audit.LogActivity(context.Background(), models.CreateAuditLogRequest{OrganizationID: testOrgID, Action: "HTTP_POST"})
+
+	assert.Len(t, written(), 10, "log setelah Close dibuang, bukan panic")
+}
+
+func TestAuditService_RecordChangeUsesRequestActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, written := recordingAuditRepo(ctrl)
+	audit := service.NewAuditService(repo)
+
+	userID := uuid.New()
+	ctx := auditedContext(utils.AuditActor{UserID: userID, IPAddress: "10.0.0.1", UserAgent: "test", RequestID: "req-1"})
+	before := map[string]interface{}{"name": "Mapper"}
+	audit.RecordChange(ctx, "ROLE_UPDATE", "Role", "role-1", before, map[string]interface{}{"name": "Surveyor"})
+	before["name"] = "changed after record" // Snapshot diambil saat dicatat
+
+	// Tanpa pelaku (job background) tidak dicatat
+	audit.RecordChange(orgContext(), "ROLE_UPDATE", "Role", "role-2", nil, nil)
+	audit.Close()
+
+	logs := written()
+	require.Len(t, logs, 1)
+	assert.Equal(t, testOrgID, logs[0].OrganizationID)
+	assert.Equal(t, userID, logs[0].UserID)
+	assert.Equal(t, "10.0.0.1", logs[0].IPAddress)
+	assert.Equal(t, "req-1", logs[0].Details["request_id"])
+
+	details, err := json.Marshal(logs[0].Details)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"request_id":"req-1","before":{"name":"Mapper"},"after":{"name":"Surveyor"}}`, string(details))
+}
+
+func TestRoleService_DeleteRoleRecordsSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, written := recordingAuditRepo(ctrl)
+	audit := service.NewAuditService(repo)
+	roleRepo := NewMockRoleRepository(ctrl)
+	svc := service.NewRoleService(roleRepo, NewMockPermissionRepository(ctrl), NewMockPermissionResolver(ctrl), audit)
+
+	orgID := testOrgID
+	role := &entity.Role{
+		BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+		OrganizationID: &orgID,
+		Name:           "Mapper",
+		Permissions:    []entity.Permission{{Key: string(entity.PermGraphEdit)}},
+	}
+	ctx := auditedContext(utils.AuditActor{UserID: uuid.New(), RequestID: "req-2"})
+	roleRepo.EXPECT().GetByID(ctx, role.ID).Return(role, nil)
+	roleRepo.EXPECT().CountAssignments(ctx, role.ID).Return(int64(0), int64(0), nil)
+	roleRepo.EXPECT().Delete(ctx, role.ID).Return(nil)
+
+	require.NoError(t, svc.DeleteRole(ctx, orgID, role.ID))
+	audit.Close()
+
+	logs := written()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "ROLE_DELETE", logs[0].Action)
+	assert.Equal(t, role.ID.String(), logs[0].EntityID)
+	details, _ := json.Marshal(logs[0].Details["before"])
+	assert.JSONEq(t, `{"name":"Mapper","description":"","permissions":["graph:edit"]}`, string(details))
+	assert.NotContains(t, logs[0].Details, "after")
+}
+
+type capturedAuditLogs struct {
+	mu   sync.Mutex
+	logs []models.CreateAuditLogRequest
+}
+
+func (c *capturedAuditLogs) LogActivity(_ context.Context, payload models.CreateAuditLogRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, payload)
+}
+
+func TestAuditMiddleware_RecordsMutationsWithActor(t *testing.T) {
+	userID := uuid.New()
+	captured := &capturedAuditLogs{}
+	var handlerActor utils.AuditActor
+
+	app := fiber.New()
+	authenticated := func(c *fiber.Ctx) error {
+		c.Locals(middleware.CtxUserID, userID)
+		c.Locals(middleware.CtxOrgID, testOrgID)
+		return c.Next()
+	}
+	api := app.Group("/api/v1", authenticated, middleware.Audit(captured))
+	api.Delete("/venues/:id", func(c *fiber.Ctx) error {
+		handlerActor, _ = utils.AuditActorFromContext(c.Context()) // Yang dilihat service
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	api.Get("/venues/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	api.Post("/roles", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusForbidden) })
+
+	venueID := uuid.NewString()
+	for _, req := range []struct{ method, path string }{
+		{fiber.MethodGet, "/api/v1/venues/" + venueID},
+		{fiber.MethodDelete, "/api/v1/venues/" + venueID},
+		{fiber.MethodPost, "/api/v1/roles"},
+	} {
+		httpReq := httptest.NewRequest(req.method, req.path, nil)
+		httpReq.Header.Set(fiber.HeaderUserAgent, "audit-test")
+		_, err := app.Test(httpReq)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, captured.logs, 2, "GET tidak dicatat")
+	deleted := captured.logs[0]
+	assert.Equal(t, "HTTP_DELETE", deleted.Action)
+	assert.Equal(t, "/venues/:id", deleted.Entity)
+	assert.Equal(t, venueID, deleted.EntityID)
+	assert.Equal(t, testOrgID, deleted.OrganizationID)
+	assert.Equal(t, userID, deleted.UserID)
+	assert.Equal(t, "audit-test", deleted.UserAgent)
+	assert.Equal(t, fiber.StatusNoContent, deleted.Details["status"])
+	assert.Equal(t, userID, handlerActor.UserID)
+	assert.Equal(t, deleted.Details["request_id"], handlerActor.RequestID)
+
+	denied := captured.logs[1]
+	assert.Equal(t, "/roles", denied.Entity)
+	assert.Empty(t, denied.EntityID)
+	assert.Equal(t, fiber.StatusForbidden, denied.Details["status"])
+}
+
+func TestGraphService_UpdateNodePositionRecordsBeforeAndAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, written := recordingAuditRepo(ctrl)
+	audit := service.NewAuditService(repo)
+	graphRepo := NewMockGraphRepository(ctrl)
+	svc := service.NewGraphService(graphRepo, NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), NewMockVenueRepository(ctrl), ownedResources(ctrl), newManifestCache(), audit)
+
+	nodeID := uuid.New()
+	ctx := auditedContext(utils.AuditActor{UserID: uuid.New()})
+	graphRepo.EXPECT().GetNode(ctx, nodeID).Return(&entity.GraphNode{BaseEntity: entity.BaseEntity{ID: nodeID}, X: 1, Y: 2}, nil)
+	graphRepo.EXPECT().UpdateNodePosition(ctx, nodeID, 10.0, 20.0).Return(nil)
+
+	require.NoError(t, svc.UpdateNodePosition(ctx, nodeID, models.UpdateNodePositionRequest{X: 10, Y: 20}))
+	audit.Close()
+
+	logs := written()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "NODE_UPDATE", logs[0].Action)
+	var before entity.GraphNode
+	require.NoError(t, json.Unmarshal(logs[0].Details["before"].(json.RawMessage), &before))
+	assert.Equal(t, 1.0, before.X)
+	after, _ := json.Marshal(logs[0].Details["after"])
+	assert.JSONEq(t, `{"x":10,"y":20}`, string(after))
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *AuthServiceTestSuite) expectUserWithOrgs(userID uuid.UUID, memberships ...entity.OrganizationMember) {
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&entity.User{BaseEntity: entity.BaseEntity{ID: userID}, Email: "multi@example.com"}, nil)
+	suite.orgMemberRepo.EXPECT().GetMembersByUser(gomock.Any(), userID).Return(memberships, nil)
+}
+
+func (suite *AuthServiceTestSuite) TestSwitchOrganization_RecomputesPermissions() {
+	ctx := context.Background()
+	userID, sessionID, orgA, orgB := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	suite.expectUserWithOrgs(userID,
+		entity.OrganizationMember{OrganizationID: orgA, Role: entity.Role{Name: "Owner"}},
+		entity.OrganizationMember{OrganizationID: orgB, Role: entity.Role{Name: "Editor"}},
+	)
+	suite.permRepo.EXPECT().GetByUserAndOrg(ctx, userID, orgB).Return([]entity.Permission{{Key: string(entity.PermGraphEdit)}}, nil)
+	suite.refreshRepo.EXPECT().SetOrganization(ctx, userID, sessionID, orgB).Return(int64(1), nil)
+
+	resp, err := suite.authService.SwitchOrganization(ctx, userID, sessionID, orgB)
+
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), resp.RefreshToken)
+	assert.Len(suite.T(), resp.User.Organizations, 2)
+
+	claims, err := utils.ParseToken(resp.AccessToken)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), orgB, claims.OrganizationID)
+	assert.Equal(suite.T(), "Editor", claims.Role)
+	assert.Equal(suite.T(), []string{string(entity.PermGraphEdit)}, claims.Permissions)
+	assert.Equal(suite.T(), sessionID, claims.SessionID)
+}
+
+func (suite *AuthServiceTestSuite) TestSwitchOrganization_NotAMember() {
+	userID := uuid.New()
+	suite.expectUserWithOrgs(userID, entity.OrganizationMember{OrganizationID: uuid.New()})
+
+	resp, err := suite.authService.SwitchOrganization(context.Background(), userID, uuid.New(), uuid.New())
+
+	assert.Nil(suite.T(), resp)
+	assert.ErrorIs(suite.T(), err, service.ErrNotOrgMember)
+	assert.ErrorIs(suite.T(), err, service.ErrNotFound)
+}
+
+func (suite *AuthServiceTestSuite) TestSwitchOrganization_RevokedSession() {
+	ctx := context.Background()
+	userID, sessionID, orgB := uuid.New(), uuid.New(), uuid.New()
+	suite.expectUserWithOrgs(userID, entity.OrganizationMember{OrganizationID: orgB, Role: entity.Role{Name: "Editor"}})
+	suite.permRepo.EXPECT().GetByUserAndOrg(ctx, userID, orgB).Return(nil, nil)
+	// Access token lama masih berlaku, tetapi family refresh token-nya sudah dicabut
+	suite.refreshRepo.EXPECT().SetOrganization(ctx, userID, sessionID, orgB).Return(int64(0), nil)
+
+	resp, err := suite.authService.SwitchOrganization(ctx, userID, sessionID, orgB)
+
+	assert.Nil(suite.T(), resp)
+	assert.ErrorIs(suite.T(), err, service.ErrSessionRevoked)
+}
+
+func (suite *AuthServiceTestSuite) TestSwitchOrganization_RequiresSession() {
+	resp, err := suite.authService.SwitchOrganization(context.Background(), uuid.New(), uuid.Nil, uuid.New())
+
+	assert.Nil(suite.T(), resp)
+	assert.ErrorIs(suite.T(), err, service.ErrSessionRevoked)
+}
+
+func (suite *AuthServiceTestSuite) TestGetMe_ListsMembershipsAndActivePermissions() {
+	ctx := context.Background()
+	userID, orgA, orgB, roleB := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	suite.expectUserWithOrgs(userID,
+		entity.OrganizationMember{OrganizationID: orgA, Role: entity.Role{Name: "Owner"}},
+		entity.OrganizationMember{OrganizationID: orgB, RoleID: roleB, Role: entity.Role{Name: "Viewer"}},
+	)
+	suite.permRepo.EXPECT().GetByUserAndOrg(ctx, userID, orgB).Return(nil, nil)
+
+	me, err := suite.authService.GetMe(ctx, userID, orgB)
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), me.User.Organizations, 2)
+	assert.Equal(suite.T(), roleB, me.User.Organizations[1].RoleID)
+	assert.Equal(suite.T(), orgB, me.ActiveOrganizationID)
+	assert.Equal(suite.T(), "Viewer", me.ActiveRoleName)
+	assert.NotNil(suite.T(), me.Permissions)
+}
+
+func (suite *AuthServiceTestSuite) TestGetMe_RemovedFromActiveOrganization() {
+	userID := uuid.New()
+	suite.expectUserWithOrgs(userID, entity.OrganizationMember{OrganizationID: uuid.New()})
+
+	me, err := suite.authService.GetMe(context.Background(), userID, uuid.New())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uuid.Nil, me.ActiveOrganizationID)
+	assert.Empty(suite.T(), me.Permissions)
+}
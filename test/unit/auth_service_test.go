@@ -6,6 +6,7 @@ import (
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
 	"testing"
 	"time"
 
@@ -23,6 +24,11 @@ type AuthServiceTestSuite struct {
 	orgMemberRepo  *MockOrganizationMemberRepository
 	invitationRepo *MockUserInvitationRepository
 	roleRepo       *MockRoleRepository
+	refreshRepo    *MockRefreshTokenRepository
+	permRepo       *MockPermissionRepository
+	permResolver   *MockPermissionResolver
+	accounts       *MockAccountService
+	twoFactor      *MockTwoFactorService
 	authService    service.AuthService
 }
 
@@ -33,6 +39,11 @@ func (suite *AuthServiceTestSuite) SetupTest() {
 	suite.orgMemberRepo = NewMockOrganizationMemberRepository(suite.ctrl)
 	suite.invitationRepo = NewMockUserInvitationRepository(suite.ctrl)
 	suite.roleRepo = NewMockRoleRepository(suite.ctrl)
+	suite.refreshRepo = NewMockRefreshTokenRepository(suite.ctrl)
+	suite.permRepo = NewMockPermissionRepository(suite.ctrl)
+	suite.permResolver = NewMockPermissionResolver(suite.ctrl)
+	suite.accounts = NewMockAccountService(suite.ctrl)
+	suite.twoFactor = NewMockTwoFactorService(suite.ctrl)
 
 	suite.authService = service.NewAuthService(
 		suite.userRepo,
@@ -40,6 +51,11 @@ func (suite *AuthServiceTestSuite) SetupTest() {
 		suite.orgMemberRepo,
 		suite.invitationRepo,
 		suite.roleRepo,
+		suite.refreshRepo,
+		suite.permRepo,
+		suite.permResolver,
+		suite.accounts,
+		suite.twoFactor,
 	)
 }
 
@@ -71,7 +87,7 @@ func (suite *AuthServiceTestSuite) TestRegister_EmailAlreadyExists() {
 	suite.userRepo.EXPECT().GetByEmail(ctx, req.Email).Return(existingUser, nil)
 
 	// Execute
-	result, err := suite.authService.Register(ctx, req)
+	result, err := suite.authService.Register(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.Error(suite.T(), err)
@@ -93,7 +109,7 @@ func (suite *AuthServiceTestSuite) TestRegister_OrganizationCreationFails() {
 	suite.orgRepo.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("database error"))
 
 	// Execute
-	result, err := suite.authService.Register(ctx, req)
+	result, err := suite.authService.Register(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.Error(suite.T(), err)
@@ -116,7 +132,7 @@ func (suite *AuthServiceTestSuite) TestRegister_OwnerRoleNotFound() {
 	suite.roleRepo.EXPECT().GetByName(ctx, "Owner").Return(nil, errors.New("role not found"))
 
 	// Execute
-	result, err := suite.authService.Register(ctx, req)
+	result, err := suite.authService.Register(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.Error(suite.T(), err)
@@ -155,6 +171,8 @@ func (suite *AuthServiceTestSuite) TestRegister_Success() {
 		return nil
 	})
 	suite.orgMemberRepo.EXPECT().AddMember(ctx, gomock.Any()).Return(nil)
+	// Email verifikasi gagal terkirim tidak menggagalkan registrasi
+	suite.accounts.EXPECT().SendEmailVerification(ctx, userID).Return(errors.New("smtp down"))
 	suite.userRepo.EXPECT().GetByEmail(ctx, req.Email).Return(&entity.User{
 		BaseEntity: entity.BaseEntity{ID: userID},
 		FullName:   req.FullName,
@@ -178,8 +196,10 @@ func (suite *AuthServiceTestSuite) TestRegister_Success() {
 		},
 	}, nil)
 
+	suite.refreshRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
 	// Execute
-	result, err := suite.authService.Register(ctx, req)
+	result, err := suite.authService.Register(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -187,7 +207,8 @@ func (suite *AuthServiceTestSuite) TestRegister_Success() {
 	assert.Equal(suite.T(), "John Doe", result.User.FullName)
 	assert.Equal(suite.T(), "john@example.com", result.User.Email)
 	assert.NotEmpty(suite.T(), result.AccessToken)
-	assert.Equal(suite.T(), int(86400), result.ExpiresIn)
+	assert.Equal(suite.T(), int(900), result.ExpiresIn)
+	assert.NotEmpty(suite.T(), result.RefreshToken)
 }
 
 func (suite *AuthServiceTestSuite) TestAcceptInvitation_Success_ExistingUser() {
@@ -218,13 +239,16 @@ func (suite *AuthServiceTestSuite) TestAcceptInvitation_Success_ExistingUser() {
 	}
 
 	// Mock expectations
-	suite.invitationRepo.EXPECT().GetByToken(ctx, req.Token).Return(invitation, nil)
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(invitation, nil)
 	suite.userRepo.EXPECT().GetByEmail(ctx, invitation.Email).Return(existingUser, nil)
-	suite.orgMemberRepo.EXPECT().AddMember(ctx, gomock.Any()).Return(nil)
+	suite.invitationRepo.EXPECT().Accept(ctx, invitation.ID, gomock.Any()).Return(true, nil)
 	suite.userRepo.EXPECT().GetByEmail(ctx, invitation.Email).Return(existingUser, nil)
 
+	suite.permResolver.EXPECT().InvalidateMember(orgID, gomock.Any())
+	suite.refreshRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
 	// Execute
-	result, err := suite.authService.AcceptInvitation(ctx, req)
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -256,14 +280,14 @@ func (suite *AuthServiceTestSuite) TestAcceptInvitation_Success_NewUser() {
 	}
 
 	// Mock expectations
-	suite.invitationRepo.EXPECT().GetByToken(ctx, req.Token).Return(invitation, nil)
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(invitation, nil)
 	suite.userRepo.EXPECT().GetByEmail(ctx, invitation.Email).Return(nil, nil) // No existing user
 	suite.userRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user *entity.User) error {
 		user.ID = newUserID
 		user.IsEmailVerified = true
 		return nil
 	})
-	suite.orgMemberRepo.EXPECT().AddMember(ctx, gomock.Any()).Return(nil)
+	suite.invitationRepo.EXPECT().Accept(ctx, invitation.ID, gomock.Any()).Return(true, nil)
 	suite.userRepo.EXPECT().GetByEmail(ctx, invitation.Email).Return(&entity.User{
 		BaseEntity:      entity.BaseEntity{ID: newUserID},
 		FullName:        req.FullName,
@@ -288,8 +312,11 @@ func (suite *AuthServiceTestSuite) TestAcceptInvitation_Success_NewUser() {
 		},
 	}, nil)
 
+	suite.permResolver.EXPECT().InvalidateMember(orgID, gomock.Any())
+	suite.refreshRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
 	// Execute
-	result, err := suite.authService.AcceptInvitation(ctx, req)
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -308,10 +335,10 @@ func (suite *AuthServiceTestSuite) TestAcceptInvitation_InvalidToken() {
 	}
 
 	// Mock expectations
-	suite.invitationRepo.EXPECT().GetByToken(ctx, req.Token).Return(nil, errors.New("invitation not found"))
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(nil, errors.New("invitation not found"))
 
 	// Execute
-	result, err := suite.authService.AcceptInvitation(ctx, req)
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.Error(suite.T(), err)
@@ -334,10 +361,10 @@ func (suite *AuthServiceTestSuite) TestAcceptInvitation_ExpiredToken() {
 	}
 
 	// Mock expectations
-	suite.invitationRepo.EXPECT().GetByToken(ctx, req.Token).Return(expiredInvitation, nil)
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(expiredInvitation, nil)
 
 	// Execute
-	result, err := suite.authService.AcceptInvitation(ctx, req)
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.Error(suite.T(), err)
@@ -371,15 +398,58 @@ func (suite *AuthServiceTestSuite) TestAcceptInvitation_AddMemberFails() {
 	}
 
 	// Mock expectations
-	suite.invitationRepo.EXPECT().GetByToken(ctx, req.Token).Return(invitation, nil)
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(invitation, nil)
 	suite.userRepo.EXPECT().GetByEmail(ctx, invitation.Email).Return(existingUser, nil)
-	suite.orgMemberRepo.EXPECT().AddMember(ctx, gomock.Any()).Return(errors.New("database error"))
+	suite.invitationRepo.EXPECT().Accept(ctx, invitation.ID, gomock.Any()).Return(false, errors.New("database error"))
 
 	// Execute
-	result, err := suite.authService.AcceptInvitation(ctx, req)
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
 
 	// Assert
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
 	assert.Equal(suite.T(), "database error", err.Error())
 }
+
+func (suite *AuthServiceTestSuite) TestAcceptInvitation_RejectsReuse() {
+	ctx := context.Background()
+	req := models.AcceptInviteRequest{Token: "used-token", Password: "password123", FullName: "John Doe"}
+	acceptedAt := time.Now().Add(-time.Hour)
+	invitation := &entity.UserInvitation{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Email:      "john@example.com",
+		Status:     entity.InvitationAccepted,
+		AcceptedAt: &acceptedAt,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(invitation, nil)
+
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
+
+	assert.Nil(suite.T(), result)
+	assert.ErrorIs(suite.T(), err, service.ErrInvitationUsed)
+}
+
+// Dua request paralel dengan token yang sama: hanya satu yang menjadi member
+func (suite *AuthServiceTestSuite) TestAcceptInvitation_LostAcceptRace() {
+	ctx := context.Background()
+	req := models.AcceptInviteRequest{Token: "valid-token", Password: "password123", FullName: "John Doe"}
+	invitation := &entity.UserInvitation{
+		BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+		OrganizationID: uuid.New(),
+		Email:          "john@example.com",
+		Status:         entity.InvitationPending,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+	existingUser := &entity.User{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Email: invitation.Email}
+
+	suite.invitationRepo.EXPECT().GetByToken(ctx, utils.HashToken(req.Token)).Return(invitation, nil)
+	suite.userRepo.EXPECT().GetByEmail(ctx, invitation.Email).Return(existingUser, nil)
+	suite.invitationRepo.EXPECT().Accept(ctx, invitation.ID, gomock.Any()).Return(false, nil)
+
+	result, err := suite.authService.AcceptInvitation(ctx, req, models.ClientInfo{})
+
+	assert.Nil(suite.T(), result)
+	assert.ErrorIs(suite.T(), err, service.ErrInvitationUsed)
+}
@@ -0,0 +1,175 @@
+package unit
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *AuthServiceTestSuite) activeRefreshToken(userID uuid.UUID, raw string) *entity.RefreshToken {
+	return &entity.RefreshToken{
+		BaseEntity:       entity.BaseEntity{ID: uuid.New()},
+		UserID:           userID,
+		FamilyID:         uuid.New(),
+		TokenHash:        utils.HashToken(raw),
+		UserAgent:        "old-agent",
+		SessionStartedAt: time.Now().Add(-48 * time.Hour),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_PersistsHashedSession() {
+	ctx := context.Background()
+	hash, err := utils.HashPassword("password123")
+	require.NoError(suite.T(), err)
+	user := &entity.User{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Email: "john@example.com", PasswordHash: hash}
+
+	var saved *entity.RefreshToken
+	suite.userRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil)
+	suite.refreshRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, t *entity.RefreshToken) error {
+		saved = t
+		return nil
+	})
+
+	resp, err := suite.authService.Login(ctx, models.LoginRequest{Email: user.Email, Password: "password123"},
+		models.ClientInfo{UserAgent: "Firefox", IPAddress: "10.0.0.1"})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), utils.HashToken(resp.RefreshToken), saved.TokenHash)
+	assert.NotEqual(suite.T(), resp.RefreshToken, saved.TokenHash)
+	assert.Equal(suite.T(), "Firefox", saved.UserAgent)
+	assert.Equal(suite.T(), "10.0.0.1", saved.IPAddress)
+
+	claims, err := utils.ParseToken(resp.AccessToken)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), saved.FamilyID, claims.SessionID)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_RotatesWithinFamily() {
+	ctx := context.Background()
+	userID, orgA, orgB := uuid.New(), uuid.New(), uuid.New()
+	current := suite.activeRefreshToken(userID, "raw-token")
+	current.OrganizationID = orgB
+
+	suite.refreshRepo.EXPECT().GetByHash(ctx, utils.HashToken("raw-token")).Return(current, nil)
+	suite.userRepo.EXPECT().GetByID(ctx, userID).Return(&entity.User{BaseEntity: entity.BaseEntity{ID: userID}}, nil)
+	suite.orgMemberRepo.EXPECT().GetMembersByUser(ctx, userID).Return([]entity.OrganizationMember{
+		{OrganizationID: orgA, Role: entity.Role{Name: "Viewer"}},
+		{OrganizationID: orgB, Role: entity.Role{Name: "Editor", Permissions: []entity.Permission{{Key: string(entity.PermGraphEdit)}}}},
+	}, nil)
+
+	var next *entity.RefreshToken
+	suite.refreshRepo.EXPECT().Rotate(ctx, current, gomock.Any()).DoAndReturn(func(_ context.Context, _, n *entity.RefreshToken) (bool, error) {
+		next = n
+		return true, nil
+	})
+
+	resp, err := suite.authService.Refresh(ctx, "raw-token", models.ClientInfo{IPAddress: "10.0.0.2"})
+
+	require.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), current.TokenHash, next.TokenHash)
+	assert.Equal(suite.T(), current.FamilyID, next.FamilyID)
+	assert.Equal(suite.T(), current.SessionStartedAt, next.SessionStartedAt)
+	assert.Equal(suite.T(), "old-agent", next.UserAgent)
+	assert.Equal(suite.T(), "10.0.0.2", next.IPAddress)
+
+	// Konteks organisasi sesi dipertahankan, bukan membership pertama
+	claims, err := utils.ParseToken(resp.AccessToken)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), orgB, claims.OrganizationID)
+	assert.Equal(suite.T(), []string{string(entity.PermGraphEdit)}, claims.Permissions)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_ReusedTokenRevokesFamily() {
+	ctx := context.Background()
+	current := suite.activeRefreshToken(uuid.New(), "stolen")
+	rotatedAt := time.Now().Add(-time.Minute)
+	current.RotatedAt = &rotatedAt
+
+	suite.refreshRepo.EXPECT().GetByHash(ctx, utils.HashToken("stolen")).Return(current, nil)
+	suite.refreshRepo.EXPECT().RevokeFamily(ctx, current.UserID, current.FamilyID).Return(int64(2), nil)
+
+	_, err := suite.authService.Refresh(ctx, "stolen", models.ClientInfo{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrRefreshTokenReused)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_LostRotationRaceKeepsFamily() {
+	ctx := context.Background()
+	userID := uuid.New()
+	current := suite.activeRefreshToken(userID, "raw-token")
+
+	// RevokeFamily tidak boleh dipanggil: pemenang race sudah memegang token pengganti yang sah
+	suite.refreshRepo.EXPECT().GetByHash(ctx, gomock.Any()).Return(current, nil)
+	suite.userRepo.EXPECT().GetByID(ctx, userID).Return(&entity.User{BaseEntity: entity.BaseEntity{ID: userID}}, nil)
+	suite.orgMemberRepo.EXPECT().GetMembersByUser(ctx, userID).Return(nil, nil)
+	suite.refreshRepo.EXPECT().Rotate(ctx, current, gomock.Any()).Return(false, nil)
+
+	_, err := suite.authService.Refresh(ctx, "raw-token", models.ClientInfo{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidRefreshToken)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_ReuseWithinGraceKeepsFamily() {
+	ctx := context.Background()
+	current := suite.activeRefreshToken(uuid.New(), "raw-token")
+	rotatedAt := time.Now().Add(-service.RefreshReuseGrace / 2)
+	current.RotatedAt = &rotatedAt
+
+	suite.refreshRepo.EXPECT().GetByHash(ctx, utils.HashToken("raw-token")).Return(current, nil)
+
+	_, err := suite.authService.Refresh(ctx, "raw-token", models.ClientInfo{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidRefreshToken)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_ExpiredOrRevoked() {
+	ctx := context.Background()
+	expired := suite.activeRefreshToken(uuid.New(), "expired")
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	revoked := suite.activeRefreshToken(uuid.New(), "revoked")
+	revokedAt := time.Now()
+	revoked.RevokedAt = &revokedAt
+
+	suite.refreshRepo.EXPECT().GetByHash(ctx, utils.HashToken("expired")).Return(expired, nil)
+	suite.refreshRepo.EXPECT().GetByHash(ctx, utils.HashToken("revoked")).Return(revoked, nil)
+
+	_, err := suite.authService.Refresh(ctx, "expired", models.ClientInfo{})
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidRefreshToken)
+	_, err = suite.authService.Refresh(ctx, "revoked", models.ClientInfo{})
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidRefreshToken)
+}
+
+func (suite *AuthServiceTestSuite) TestListSessions_MarksCurrent() {
+	ctx := context.Background()
+	userID := uuid.New()
+	a := suite.activeRefreshToken(userID, "a")
+	b := suite.activeRefreshToken(userID, "b")
+	suite.refreshRepo.EXPECT().GetActiveByUser(ctx, userID).Return([]entity.RefreshToken{*a, *b}, nil)
+
+	sessions, err := suite.authService.ListSessions(ctx, userID, b.FamilyID)
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), sessions, 2)
+	assert.Equal(suite.T(), a.FamilyID, sessions[0].ID)
+	assert.False(suite.T(), sessions[0].Current)
+	assert.True(suite.T(), sessions[1].Current)
+}
+
+func (suite *AuthServiceTestSuite) TestRevokeSession_UnknownIsNotFound() {
+	ctx := context.Background()
+	userID, sessionID := uuid.New(), uuid.New()
+	suite.refreshRepo.EXPECT().RevokeFamily(ctx, userID, sessionID).Return(int64(0), nil)
+
+	err := suite.authService.RevokeSession(ctx, userID, sessionID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrNotFound)
+}
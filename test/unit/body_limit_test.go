@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"inspacemap/backend/internal/delivery/http/middleware"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit_RejectsOversizedBodyExceptSkippedPath(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: 16, StreamRequestBody: true})
+	app.Use(middleware.BodyLimit(16, "/import"))
+	echo := func(c *fiber.Ctx) error { return c.SendString(strconv.Itoa(len(c.Body()))) }
+	app.Post("/import", middleware.BodyLimit(64), echo)
+	app.Post("/", echo)
+
+	post := func(path string, size int) (int, string) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(strings.Repeat("a", size))))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := post("/", 16)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "16", body)
+	status, _ = post("/", 17)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, status)
+
+	status, body = post("/import", 64)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "64", body)
+	status, _ = post("/import", 65)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, status)
+}
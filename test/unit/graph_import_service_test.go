@@ -0,0 +1,201 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+)
+
+type GraphImportServiceTestSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	graphRepo    *MockGraphRepository
+	revisionRepo *MockGraphRevisionRepository
+	areaRepo     *MockAreaRepository
+	ownership    *MockOwnershipRepository
+	service      service.GraphImportService
+
+	venueID  uuid.UUID
+	draft    *entity.GraphRevision
+	existing uuid.UUID
+	panorama uuid.UUID
+}
+
+func (suite *GraphImportServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.graphRepo = NewMockGraphRepository(suite.ctrl)
+	suite.revisionRepo = NewMockGraphRevisionRepository(suite.ctrl)
+	suite.areaRepo = NewMockAreaRepository(suite.ctrl)
+	suite.ownership = NewMockOwnershipRepository(suite.ctrl)
+	suite.service = service.NewGraphImportService(suite.graphRepo, suite.revisionRepo, suite.areaRepo, suite.ownership)
+
+	// Draft: satu lantai terkalibrasi (anchor pixel 0,0, 10 px/m) dengan satu node yang sudah ada
+	suite.venueID, suite.existing, suite.panorama = uuid.New(), uuid.New(), uuid.New()
+	floorID := uuid.New()
+	suite.draft = &entity.GraphRevision{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Floors: []entity.Floor{{
+			BaseEntity: entity.BaseEntity{ID: floorID}, Name: "Lobby", LevelIndex: 0, IsActive: true, PixelsPerMeter: 10,
+			GeoReference: entity.JSONMap{"anchor_x": 0, "anchor_y": 0, "latitude": -6.2, "longitude": 106.8},
+			Nodes:        []entity.GraphNode{{BaseEntity: entity.BaseEntity{ID: suite.existing}, FloorID: floorID, X: 40, Y: 40}},
+		}},
+	}
+}
+
+func (suite *GraphImportServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestGraphImportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(GraphImportServiceTestSuite))
+}
+
+func (suite *GraphImportServiceTestSuite) expectDraft() {
+	suite.ownership.EXPECT().VenueBelongsTo(gomock.Any(), testOrgID, suite.venueID).Return(true, nil)
+	suite.revisionRepo.EXPECT().GetDraftByVenueID(gomock.Any(), suite.venueID).Return(suite.draft, nil)
+	suite.areaRepo.EXPECT().GetByVenueID(gomock.Any(), suite.venueID).Return(nil, nil)
+}
+
+func (suite *GraphImportServiceTestSuite) TestImportJSON_CreatesGraphWithMappedIDs() {
+	level := 0
+	floorID := suite.draft.Floors[0].ID
+	doc := models.GraphImportDocument{
+		Areas: []models.ImportArea{{ExternalID: "shop-1", Level: &level, Name: "Book Store", X: 10, Y: 10}},
+		Nodes: []models.ImportNode{
+			{ExternalID: "n1", Level: &level, X: 10, Y: 10, AreaID: "shop-1", PanoramaAssetID: &suite.panorama},
+			{ExternalID: "n2", FloorID: &floorID, X: 40, Y: 10, PanoramaAssetID: &suite.panorama},
+		},
+		Edges: []models.ImportEdge{
+			{From: "n1", To: "n2", Bidirectional: true},
+			{From: "n2", To: suite.existing.String(), Type: "Stairs"},
+		},
+	}
+
+	suite.expectDraft()
+	suite.ownership.EXPECT().MediaBelongsTo(gomock.Any(), testOrgID, suite.panorama).Return(true, nil).Times(1)
+	suite.graphRepo.EXPECT().ImportGraph(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, areas []entity.Area, nodes []entity.GraphNode, edges []entity.GraphEdge) error {
+			require.Len(suite.T(), areas, 1)
+			require.Len(suite.T(), nodes, 2)
+			require.Len(suite.T(), edges, 3)
+			assert.Equal(suite.T(), suite.venueID, areas[0].VenueID)
+			assert.Equal(suite.T(), areas[0].ID, *nodes[0].AreaID)
+			assert.Equal(suite.T(), nodes[0].ID, edges[0].FromNodeID)
+			assert.Equal(suite.T(), 90.0, edges[0].Heading)
+			assert.Equal(suite.T(), 30.0, edges[0].Distance)
+			assert.Equal(suite.T(), nodes[0].ID, edges[1].ToNodeID) // arah balik
+			assert.Equal(suite.T(), suite.existing, edges[2].ToNodeID)
+			assert.Equal(suite.T(), "stairs", edges[2].Type)
+			return nil
+		})
+
+	result, err := suite.service.ImportJSON(orgContext(), suite.venueID, doc, false)
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Valid)
+	assert.Empty(suite.T(), result.Errors)
+	assert.Equal(suite.T(), 3, result.EdgeCount)
+	assert.NotEqual(suite.T(), uuid.Nil, result.NodeIDs["n1"])
+	assert.NotEqual(suite.T(), uuid.Nil, result.AreaIDs["shop-1"])
+}
+
+func (suite *GraphImportServiceTestSuite) TestImportCSV_DryRunReportsRowErrors() {
+	nodes := "id,level,x,y,panorama_asset_id\n" +
+		"n1,0,10,10," + suite.panorama.String() + "\n" +
+		"n2,0,abc,10," + suite.panorama.String() + "\n" +
+		"n3,5,10,10," + suite.panorama.String() + "\n" +
+		"n1,0,20,20,\n"
+	edges := "from,to,bidirectional\n" +
+		"n1,ghost,true\n" +
+		"n1," + suite.existing.String() + ",yes\n" +
+		"n1," + suite.existing.String() + ",false\n"
+
+	suite.expectDraft()
+	suite.ownership.EXPECT().MediaBelongsTo(gomock.Any(), testOrgID, suite.panorama).Return(true, nil)
+
+	result, err := suite.service.ImportCSV(orgContext(), suite.venueID, strings.NewReader(nodes), strings.NewReader(edges), nil, true)
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), result.DryRun)
+	assert.False(suite.T(), result.Valid)
+	assert.Equal(suite.T(), 1, result.NodeCount)
+	assert.Equal(suite.T(), 1, result.EdgeCount)
+	assert.Nil(suite.T(), result.NodeIDs)
+
+	type rowKey struct {
+		source, field string
+		row           int
+	}
+	got := make(map[rowKey]bool)
+	for _, e := range result.Errors {
+		got[rowKey{e.Source, e.Field, e.Row}] = true
+	}
+	assert.Equal(suite.T(), map[rowKey]bool{
+		{"nodes.csv", "x", 3}:                 true,
+		{"nodes.csv", "level", 4}:             true,
+		{"nodes.csv", "id", 5}:                true,
+		{"nodes.csv", "panorama_asset_id", 5}: true,
+		{"edges.csv", "to", 2}:                true,
+		{"edges.csv", "bidirectional", 3}:     true,
+	}, got)
+}
+
+func (suite *GraphImportServiceTestSuite) TestImportGeoJSON_ConvertsCoordinatesToPixels() {
+	fc := models.GeoJSONFeatureCollection{
+		Type: models.GeoJSONFeatureCollectionType,
+		Features: []models.GeoJSONFeature{
+			{ID: "node:a", Geometry: models.GeoJSONGeometry{Type: models.GeoJSONPoint, Coordinates: []interface{}{106.8, -6.2}},
+				Properties: map[string]interface{}{"feature_type": "node", "level": 0.0, "panorama_asset_id": suite.panorama.String()}},
+			// 100 m ke timur dari anchor = 1000 px
+			{ID: "node:b", Geometry: models.GeoJSONGeometry{Type: models.GeoJSONPoint, Coordinates: []interface{}{106.8009036, -6.2}},
+				Properties: map[string]interface{}{"feature_type": "node", "level": 0.0, "panorama_asset_id": suite.panorama.String()}},
+			{Geometry: models.GeoJSONGeometry{Type: models.GeoJSONLineString},
+				Properties: map[string]interface{}{"feature_type": "edge", "from_node_id": "a", "to_node_id": "b"}},
+		},
+	}
+
+	suite.expectDraft()
+	suite.ownership.EXPECT().MediaBelongsTo(gomock.Any(), testOrgID, suite.panorama).Return(true, nil)
+	suite.graphRepo.EXPECT().ImportGraph(gomock.Any(), gomock.Len(0), gomock.Len(2), gomock.Len(1)).DoAndReturn(
+		func(_ context.Context, _ []entity.Area, nodes []entity.GraphNode, _ []entity.GraphEdge) error {
+			assert.Equal(suite.T(), 0.0, nodes[0].X)
+			assert.InDelta(suite.T(), 1000, nodes[1].X, 0.1)
+			assert.InDelta(suite.T(), 0, nodes[1].Y, 0.01)
+			return nil
+		})
+
+	result, err := suite.service.ImportGeoJSON(orgContext(), suite.venueID, fc, false)
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Valid)
+}
+
+func (suite *GraphImportServiceTestSuite) TestImport_NoDraft() {
+	suite.ownership.EXPECT().VenueBelongsTo(gomock.Any(), testOrgID, suite.venueID).Return(true, nil)
+	suite.revisionRepo.EXPECT().GetDraftByVenueID(gomock.Any(), suite.venueID).Return(nil, errors.New("record not found"))
+
+	result, err := suite.service.ImportJSON(orgContext(), suite.venueID, models.GraphImportDocument{}, true)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *GraphImportServiceTestSuite) TestImport_ForeignVenueIsNotFound() {
+	suite.ownership.EXPECT().VenueBelongsTo(gomock.Any(), testOrgID, suite.venueID).Return(false, nil)
+
+	result, err := suite.service.ImportJSON(orgContext(), suite.venueID, models.GraphImportDocument{}, true)
+
+	suite.Nil(result)
+	suite.ErrorIs(err, service.ErrVenueNotFound)
+}
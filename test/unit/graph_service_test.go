@@ -23,7 +23,7 @@ func TestGraphService_CreateFloor(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -128,7 +128,7 @@ func TestGraphService_CreateFloor(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			_, err := graphService.CreateFloor(context.Background(), tt.venueID, tt.req)
+			_, err := graphService.CreateFloor(orgContext(), tt.venueID, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -151,7 +151,7 @@ func TestGraphService_CreateNode(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -232,7 +232,7 @@ func TestGraphService_CreateNode(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			_, err := graphService.CreateNode(context.Background(), tt.req)
+			_, err := graphService.CreateNode(orgContext(), tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -255,7 +255,7 @@ func TestGraphService_UpdateNodePosition(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -300,7 +300,7 @@ func TestGraphService_UpdateNodePosition(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := graphService.UpdateNodePosition(context.Background(), tt.nodeID, tt.req)
+			err := graphService.UpdateNodePosition(orgContext(), tt.nodeID, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -323,7 +323,7 @@ func TestGraphService_UpdateNodeCalibration(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -366,7 +366,7 @@ func TestGraphService_UpdateNodeCalibration(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := graphService.UpdateNodeCalibration(context.Background(), tt.nodeID, tt.req)
+			err := graphService.UpdateNodeCalibration(orgContext(), tt.nodeID, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -389,7 +389,7 @@ func TestGraphService_DeleteNode(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -425,7 +425,7 @@ func TestGraphService_DeleteNode(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := graphService.DeleteNode(context.Background(), tt.nodeID)
+			err := graphService.DeleteNode(orgContext(), tt.nodeID)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -448,7 +448,7 @@ func TestGraphService_ConnectNodes(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -505,7 +505,7 @@ func TestGraphService_ConnectNodes(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := graphService.ConnectNodes(context.Background(), tt.req)
+			err := graphService.ConnectNodes(orgContext(), tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -528,7 +528,7 @@ func TestGraphService_DeleteConnection(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -567,7 +567,7 @@ func TestGraphService_DeleteConnection(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := graphService.DeleteConnection(context.Background(), tt.fromID, tt.toID)
+			err := graphService.DeleteConnection(orgContext(), tt.fromID, tt.toID)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -590,7 +590,7 @@ func TestGraphService_GetEditorData(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -649,7 +649,7 @@ func TestGraphService_GetEditorData(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			_, err := graphService.GetEditorData(context.Background(), tt.venueID)
+			_, err := graphService.GetEditorData(orgContext(), tt.venueID)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -672,7 +672,7 @@ func TestGraphService_PublishChanges(t *testing.T) {
 	mockFloorRepo := NewMockFloorRepository(ctrl)
 	mockVenueRepo := NewMockVenueRepository(ctrl)
 
-	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo)
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
 
 	tests := []struct {
 		name          string
@@ -692,6 +692,9 @@ func TestGraphService_PublishChanges(t *testing.T) {
 				mockGraphRevisionRepo.EXPECT().
 					PublishDraft(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil)
+				mockVenueRepo.EXPECT().
+					GetByID(gomock.Any(), gomock.Any()).
+					Return(&entity.Venue{Slug: "test-venue"}, nil)
 			},
 			expectedError: false,
 		},
@@ -715,7 +718,92 @@ func TestGraphService_PublishChanges(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := graphService.PublishChanges(context.Background(), tt.venueID, tt.req)
+			err := graphService.PublishChanges(orgContext(), tt.venueID, tt.req)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGraphService_UpdateFloorGeoReference(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphRepo := NewMockGraphRepository(ctrl)
+	mockGraphRevisionRepo := NewMockGraphRevisionRepository(ctrl)
+	mockFloorRepo := NewMockFloorRepository(ctrl)
+	mockVenueRepo := NewMockVenueRepository(ctrl)
+
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
+	floorID := uuid.New()
+
+	tests := []struct {
+		name          string
+		req           *models.GeoReference
+		mockSetup     func()
+		expectedError bool
+		errorContains string
+	}{
+		{
+			name: "successful anchor georeference",
+			req:  &models.GeoReference{AnchorX: 100, AnchorY: 200, Latitude: -6.2, Longitude: 106.8, Bearing: 15},
+			mockSetup: func() {
+				mockGraphRevisionRepo.EXPECT().GetDraftByFloorID(gomock.Any(), floorID).Return(&entity.GraphRevision{}, nil)
+				mockFloorRepo.EXPECT().GetByID(gomock.Any(), floorID).Return(&entity.Floor{PixelsPerMeter: 20}, nil)
+				mockFloorRepo.EXPECT().UpdateGeoReference(gomock.Any(), floorID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ uuid.UUID, geo entity.JSONMap) error {
+						assert.Equal(t, 106.8, geo["longitude"])
+						return nil
+					})
+			},
+			expectedError: false,
+		},
+		{
+			name: "collinear control points rejected",
+			req: &models.GeoReference{ControlPoints: []models.GeoControlPoint{
+				{X: 0, Y: 0, Latitude: -6.2, Longitude: 106.8},
+				{X: 10, Y: 10, Latitude: -6.21, Longitude: 106.81},
+				{X: 20, Y: 20, Latitude: -6.22, Longitude: 106.82},
+			}},
+			mockSetup: func() {
+				mockGraphRevisionRepo.EXPECT().GetDraftByFloorID(gomock.Any(), floorID).Return(&entity.GraphRevision{}, nil)
+				mockFloorRepo.EXPECT().GetByID(gomock.Any(), floorID).Return(&entity.Floor{PixelsPerMeter: 20}, nil)
+			},
+			expectedError: true,
+			errorContains: "collinear",
+		},
+		{
+			name: "clear georeference",
+			req:  nil,
+			mockSetup: func() {
+				mockGraphRevisionRepo.EXPECT().GetDraftByFloorID(gomock.Any(), floorID).Return(&entity.GraphRevision{}, nil)
+				mockFloorRepo.EXPECT().UpdateGeoReference(gomock.Any(), floorID, entity.JSONMap(nil)).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "published floor",
+			req:  &models.GeoReference{Latitude: -6.2, Longitude: 106.8},
+			mockSetup: func() {
+				mockGraphRevisionRepo.EXPECT().GetDraftByFloorID(gomock.Any(), floorID).Return(nil, errors.New("not found"))
+			},
+			expectedError: true,
+			errorContains: "published version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := graphService.UpdateFloorGeoReference(orgContext(), floorID, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -728,3 +816,129 @@ func TestGraphService_PublishChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestGraphService_ExportGeoJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphRepo := NewMockGraphRepository(ctrl)
+	mockGraphRevisionRepo := NewMockGraphRevisionRepository(ctrl)
+	mockFloorRepo := NewMockFloorRepository(ctrl)
+	mockVenueRepo := NewMockVenueRepository(ctrl)
+
+	graphService := service.NewGraphService(mockGraphRepo, mockGraphRevisionRepo, mockFloorRepo, mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
+
+	// Lantai 0 terkalibrasi (anchor di pixel 0,0, 10 px/m, menghadap utara); lantai 1 belum
+	lobbyID, upperID := uuid.New(), uuid.New()
+	nodeA, nodeB, nodeUpper := uuid.New(), uuid.New(), uuid.New()
+	lobby := entity.Floor{
+		BaseEntity: entity.BaseEntity{ID: lobbyID}, Name: "Lobby", LevelIndex: 0, PixelsPerMeter: 10,
+		GeoReference: entity.JSONMap{"anchor_x": 0, "anchor_y": 0, "latitude": -6.2, "longitude": 106.8},
+		Nodes: []entity.GraphNode{
+			{BaseEntity: entity.BaseEntity{ID: nodeA}, X: 0, Y: 0, IsActive: true, Label: "Entrance", OutgoingEdges: []entity.GraphEdge{
+				{FromNodeID: nodeA, ToNodeID: nodeB, Heading: 90, Distance: 100, Type: "walk", IsActive: true},
+				{FromNodeID: nodeA, ToNodeID: nodeUpper, Type: "stairs", IsActive: true}, // lantai tanpa georeference
+			}},
+			{BaseEntity: entity.BaseEntity{ID: nodeB}, X: 1000, Y: 0, IsActive: true},
+			{BaseEntity: entity.BaseEntity{ID: uuid.New()}, X: 5, Y: 5, IsActive: false},
+		},
+	}
+	upper := entity.Floor{
+		BaseEntity: entity.BaseEntity{ID: upperID}, Name: "Level 1", LevelIndex: 1, PixelsPerMeter: 10,
+		Nodes: []entity.GraphNode{{BaseEntity: entity.BaseEntity{ID: nodeUpper}, IsActive: true}},
+	}
+	venue := &entity.Venue{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		PointsOfInterest: []entity.Area{
+			{BaseEntity: entity.BaseEntity{ID: uuid.New()}, FloorID: lobbyID, Name: "Atrium", MapX: 0, MapY: -1000},
+			{BaseEntity: entity.BaseEntity{ID: uuid.New()}, FloorID: upperID, Name: "Food Court", Latitude: -6.1, Longitude: 106.7},
+			{BaseEntity: entity.BaseEntity{ID: uuid.New()}, FloorID: upperID, Name: "Unknown"},
+		},
+	}
+
+	mockVenueRepo.EXPECT().GetByID(gomock.Any(), venue.ID).Return(venue, nil)
+	mockGraphRevisionRepo.EXPECT().GetDraftByVenueID(gomock.Any(), venue.ID).
+		Return(&entity.GraphRevision{Floors: []entity.Floor{lobby, upper}}, nil)
+
+	fc, err := graphService.ExportGeoJSON(orgContext(), venue.ID, "draft")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.GeoJSONFeatureCollectionType, fc.Type)
+	assert.Len(t, fc.Warnings, 2) // lantai 1 + area tanpa koordinat
+
+	features := make(map[string]models.GeoJSONFeature)
+	for _, f := range fc.Features {
+		features[f.ID] = f
+	}
+	assert.Len(t, features, 5) // 2 node, 1 edge, 2 area
+
+	// 100 m ke timur dari anchor
+	b := features["node:"+nodeB.String()].Geometry.Coordinates.([]float64)
+	assert.InDelta(t, 106.8009043, b[0], 1e-6)
+	assert.InDelta(t, -6.2, b[1], 1e-9)
+
+	edge := features["edge:"+nodeA.String()+":"+nodeB.String()]
+	assert.Equal(t, models.GeoJSONLineString, edge.Geometry.Type)
+	assert.Equal(t, 0, edge.Properties["level"])
+	assert.Equal(t, "walk", edge.Properties["type"])
+
+	// Area di lantai terkalibrasi memakai MapX/MapY (100 m ke utara), lainnya fallback ke lat/lng
+	atrium := features["area:"+venue.PointsOfInterest[0].ID.String()].Geometry.Coordinates.([]float64)
+	assert.InDelta(t, -6.1991017, atrium[1], 1e-6)
+	foodCourt := features["area:"+venue.PointsOfInterest[1].ID.String()].Geometry.Coordinates.([]float64)
+	assert.Equal(t, []float64{106.7, -6.1}, foodCourt)
+}
+
+func TestGraphService_ExportGeoJSON_InvalidRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVenueRepo := NewMockVenueRepository(ctrl)
+	graphService := service.NewGraphService(NewMockGraphRepository(ctrl), NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
+
+	mockVenueRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(&entity.Venue{}, nil).Times(2)
+
+	_, err := graphService.ExportGeoJSON(orgContext(), uuid.New(), "latest")
+	assert.ErrorContains(t, err, "revision must be")
+
+	_, err = graphService.ExportGeoJSON(orgContext(), uuid.New(), "live")
+	assert.ErrorContains(t, err, "no published version")
+}
+
+func TestGraphService_RollbackLiveInvalidatesManifestCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	revisionRepo := NewMockGraphRevisionRepository(ctrl)
+	venueRepo := NewMockVenueRepository(ctrl)
+	manifests := newManifestCache()
+	graphService := service.NewGraphService(NewMockGraphRepository(ctrl), revisionRepo, NewMockFloorRepository(ctrl), venueRepo, ownedResources(ctrl), manifests, nil)
+
+	ctx := orgContext()
+	venue := &entity.Venue{BaseEntity: entity.BaseEntity{ID: uuid.New()}, LiveRevisionID: uuid.New()}
+	previous := uuid.New()
+	manifests.Set(venue.ID.String()+":"+venue.LiveRevisionID.String(), &models.ManifestResponse{VenueID: venue.ID})
+	venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	revisionRepo.EXPECT().SetLiveRevision(ctx, venue.ID, previous).Return(int64(1), nil)
+
+	err := graphService.RollbackLive(ctx, venue.ID, models.RollbackRevisionRequest{RevisionID: previous})
+
+	assert.NoError(t, err)
+	_, cached := manifests.Get(venue.ID.String() + ":" + venue.LiveRevisionID.String())
+	assert.False(t, cached, "manifest revisi lama dibuang dari cache")
+}
+
+func TestGraphService_RollbackLiveUnknownRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	revisionRepo := NewMockGraphRevisionRepository(ctrl)
+	venueRepo := NewMockVenueRepository(ctrl)
+	graphService := service.NewGraphService(NewMockGraphRepository(ctrl), revisionRepo, NewMockFloorRepository(ctrl), venueRepo, ownedResources(ctrl), newManifestCache(), nil)
+
+	ctx := orgContext()
+	venueID, draftID := uuid.New(), uuid.New()
+	venueRepo.EXPECT().GetByID(ctx, venueID).Return(&entity.Venue{BaseEntity: entity.BaseEntity{ID: venueID}}, nil)
+	// Draft, revisi venue lain, atau ID asal: tidak ada baris yang diubah
+	revisionRepo.EXPECT().SetLiveRevision(ctx, venueID, draftID).Return(int64(0), nil)
+
+	err := graphService.RollbackLive(ctx, venueID, models.RollbackRevisionRequest{RevisionID: draftID})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+)
+
+func imdfTestVenue() (*entity.Venue, *entity.GraphRevision) {
+	shopID, toiletID := uuid.New(), uuid.New()
+	lobbyID, upperID := uuid.New(), uuid.New()
+	shopNode, corridor, toiletNode, upperNode := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	geo := entity.JSONMap{"anchor_x": 0, "anchor_y": 0, "latitude": -6.2, "longitude": 106.8}
+
+	revision := &entity.GraphRevision{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Floors: []entity.Floor{
+			{
+				BaseEntity: entity.BaseEntity{ID: lobbyID}, Name: "Lobby", LevelIndex: 0, IsActive: true,
+				MapWidth: 800, MapHeight: 600, PixelsPerMeter: 10, GeoReference: geo,
+				Nodes: []entity.GraphNode{
+					{BaseEntity: entity.BaseEntity{ID: shopNode}, FloorID: lobbyID, X: 100, Y: 100, AreaID: &shopID, IsActive: true, OutgoingEdges: []entity.GraphEdge{
+						{ToNodeID: corridor, Type: "walk", IsActive: true},
+						{ToNodeID: upperNode, Type: "stairs", IsActive: true},
+					}},
+					{BaseEntity: entity.BaseEntity{ID: corridor}, FloorID: lobbyID, X: 300, Y: 100, IsActive: true, OutgoingEdges: []entity.GraphEdge{
+						{ToNodeID: shopNode, Type: "walk", IsActive: true}, // arah balik, opening yang sama
+					}},
+					{BaseEntity: entity.BaseEntity{ID: toiletNode}, FloorID: lobbyID, X: 300, Y: 300, AreaID: &toiletID, IsActive: true, OutgoingEdges: []entity.GraphEdge{
+						{ToNodeID: corridor, Type: "walk", IsActive: true},
+					}},
+				},
+			},
+			{
+				BaseEntity: entity.BaseEntity{ID: upperID}, Name: "Level 1", LevelIndex: 1, IsActive: true,
+				MapWidth: 800, MapHeight: 600, PixelsPerMeter: 10, GeoReference: geo,
+				Nodes: []entity.GraphNode{{BaseEntity: entity.BaseEntity{ID: upperNode}, FloorID: upperID, X: 100, Y: 100, IsActive: true}},
+			},
+		},
+	}
+	venue := &entity.Venue{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Grand Mall", Address: "Jl. Sudirman 1", City: "Jakarta",
+		Province: "ID-JK", Latitude: -6.2, Longitude: 106.8, LiveRevisionID: revision.ID,
+		PointsOfInterest: []entity.Area{
+			{BaseEntity: entity.BaseEntity{ID: shopID}, FloorID: lobbyID, Name: "Book Store", Category: "Retail"},
+			{BaseEntity: entity.BaseEntity{ID: toiletID}, FloorID: lobbyID, Name: "Toilet", Category: "toilet", MapX: 300, MapY: 300},
+		},
+	}
+	return venue, revision
+}
+
+func TestGraphService_ExportIMDF(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVenueRepo := NewMockVenueRepository(ctrl)
+	graphService := service.NewGraphService(NewMockGraphRepository(ctrl), NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
+	venue, revision := imdfTestVenue()
+
+	mockVenueRepo.EXPECT().GetByID(gomock.Any(), venue.ID).Return(venue, nil)
+	mockVenueRepo.EXPECT().GetRevisionManifestData(gomock.Any(), venue.ID, revision.ID).Return(revision, nil)
+
+	data, err := graphService.ExportIMDF(orgContext(), venue.ID, models.IMDFExportRequest{Category: "shoppingcenter", Country: "id"})
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	collections := make(map[string]models.IMDFFeatureCollection)
+	var manifest models.IMDFManifest
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		raw, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == "manifest.json" {
+			require.NoError(t, json.Unmarshal(raw, &manifest))
+			continue
+		}
+		var fc models.IMDFFeatureCollection
+		require.NoError(t, json.Unmarshal(raw, &fc))
+		collections[f.Name] = fc
+	}
+
+	assert.Equal(t, models.IMDFVersion, manifest.Version)
+	assert.Equal(t, "en", manifest.Language)
+	for name, count := range map[string]int{
+		"address.geojson": 1, "venue.geojson": 1, "building.geojson": 1, "footprint.geojson": 1,
+		"level.geojson": 2, "unit.geojson": 2, "amenity.geojson": 2, "opening.geojson": 2,
+	} {
+		assert.Len(t, collections[name].Features, count, name)
+	}
+
+	address := collections["address.geojson"].Features[0]
+	assert.Nil(t, address.Geometry)
+	assert.Equal(t, "ID", address.Properties["country"])
+	assert.Equal(t, "ID-JK", address.Properties["province"])
+	assert.Equal(t, address.ID.String(), collections["venue.geojson"].Features[0].Properties["address_id"])
+
+	units := collections["unit.geojson"].Features
+	assert.Equal(t, "room", units[0].Properties["category"])
+	assert.Equal(t, "restroom", units[1].Properties["category"])
+	assert.Equal(t, map[string]interface{}{"en": "Toilet"}, units[1].Properties["name"])
+	assert.Equal(t, models.GeoJSONPolygon, units[0].Geometry.Type)
+
+	amenities := collections["amenity.geojson"].Features
+	assert.Equal(t, "restroom", amenities[0].Properties["category"])
+	assert.Equal(t, "stairs", amenities[1].Properties["category"])
+
+	opening := collections["opening.geojson"].Features[0]
+	assert.Equal(t, models.GeoJSONLineString, opening.Geometry.Type)
+	assert.Equal(t, revision.Floors[0].ID.String(), opening.Properties["level_id"])
+}
+
+func TestGraphService_ExportIMDF_ValidationErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVenueRepo := NewMockVenueRepository(ctrl)
+	graphService := service.NewGraphService(NewMockGraphRepository(ctrl), NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), mockVenueRepo, ownedResources(ctrl), newManifestCache(), nil)
+	venue, revision := imdfTestVenue()
+	venue.City = ""
+	revision.Floors[1].GeoReference = nil
+
+	mockVenueRepo.EXPECT().GetByID(gomock.Any(), venue.ID).Return(venue, nil)
+	mockVenueRepo.EXPECT().GetRevisionManifestData(gomock.Any(), venue.ID, revision.ID).Return(revision, nil)
+
+	data, err := graphService.ExportIMDF(orgContext(), venue.ID, models.IMDFExportRequest{Category: "mall"})
+
+	assert.Nil(t, data)
+	var invalid *service.IMDFValidationError
+	require.True(t, errors.As(err, &invalid))
+	assert.Len(t, invalid.Issues, 4)
+	assert.Contains(t, invalid.Issues[0], "category \"mall\"")
+	assert.Contains(t, invalid.Issues[3], "Level 1")
+}
@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"inspacemap/backend/config"
+	"inspacemap/backend/pkg/utils"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useJWTKeys: Pasang key set selama test berjalan lalu kembalikan yang lama
+func useJWTKeys(t *testing.T, ks *utils.JWTKeySet) {
+	prev := utils.CurrentJWTKeySet()
+	utils.SetJWTKeySet(ks)
+	t.Cleanup(func() { utils.SetJWTKeySet(prev) })
+}
+
+func rsaKey(t *testing.T, kid string) *utils.JWTKey {
+	key, _ := rsaKeyWithPublicPEM(t, kid)
+	return key
+}
+
+func rsaKeyWithPublicPEM(t *testing.T, kid string) (*utils.JWTKey, []byte) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := utils.ParsePrivateKeyPEM(kid, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func edKey(t *testing.T, kid string) *utils.JWTKey {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	key, err := utils.ParsePrivateKeyPEM(kid, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	return key
+}
+
+func issueTestToken(t *testing.T) string {
+	token, err := utils.GenerateToken(uuid.New(), "a@example.com", uuid.New(), "Owner", nil, uuid.Nil)
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTKeys_AsymmetricTokensCarryKid(t *testing.T) {
+	for _, key := range []*utils.JWTKey{rsaKey(t, "rsa-1"), edKey(t, "ed-1")} {
+		ks, err := utils.NewJWTKeySet(key)
+		require.NoError(t, err)
+		useJWTKeys(t, ks)
+
+		token := issueTestToken(t)
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &utils.JWTPayload{})
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, parsed.Header["kid"])
+		assert.Equal(t, key.Method.Alg(), parsed.Method.Alg())
+
+		_, err = utils.ParseToken(token)
+		assert.NoError(t, err, key.ID)
+	}
+}
+
+func TestJWTKeys_RotationKeepsOldTokensValid(t *testing.T) {
+	oldKey, newKey := rsaKey(t, "2025-01"), edKey(t, "2025-02")
+
+	oldSet, err := utils.NewJWTKeySet(oldKey)
+	require.NoError(t, err)
+	useJWTKeys(t, oldSet)
+	oldToken := issueTestToken(t)
+
+	rotated, err := utils.NewJWTKeySet(newKey, oldKey.VerifyOnly())
+	require.NoError(t, err)
+	utils.SetJWTKeySet(rotated)
+
+	_, err = utils.ParseToken(oldToken)
+	assert.NoError(t, err, "token from the previous key must verify during rotation")
+	_, err = utils.ParseToken(issueTestToken(t))
+	assert.NoError(t, err)
+
+	// Setelah kunci lama dibuang, tokennya ditolak
+	retired, err := utils.NewJWTKeySet(newKey)
+	require.NoError(t, err)
+	utils.SetJWTKeySet(retired)
+	_, err = utils.ParseToken(oldToken)
+	assert.Error(t, err)
+}
+
+func TestJWTKeys_RejectsAlgorithmConfusion(t *testing.T) {
+	key, pubPEM := rsaKeyWithPublicPEM(t, "rsa-1")
+	ks, err := utils.NewJWTKeySet(key)
+	require.NoError(t, err)
+	useJWTKeys(t, ks)
+
+	// HS256 yang ditandatangani pakai public key RSA (yang dipublikasikan) sebagai secret harus ditolak
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.JWTPayload{UserID: uuid.New()})
+	forged.Header["kid"] = "rsa-1"
+	signed, err := forged.SignedString(pubPEM)
+	require.NoError(t, err)
+
+	_, err = utils.ParseToken(signed)
+	assert.Error(t, err)
+}
+
+func TestJWTKeys_JWKSPublishesOnlyPublicKeys(t *testing.T) {
+	hmac, err := utils.NewHMACKey("legacy", []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	active := edKey(t, "ed-2")
+	ks, err := utils.NewJWTKeySet(active, rsaKey(t, "rsa-1").VerifyOnly(), hmac.VerifyOnly())
+	require.NoError(t, err)
+
+	jwks := ks.JWKS()
+
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "ed-2", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+	assert.Equal(t, "rsa-1", jwks.Keys[1].Kid)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+}
+
+func TestJWTKeys_Validation(t *testing.T) {
+	_, err := utils.NewHMACKey("short", []byte("too-short"))
+	assert.Error(t, err)
+
+	_, err = utils.NewJWTKeySet(rsaKey(t, "rsa-1").VerifyOnly())
+	assert.Error(t, err, "verify-only key cannot sign")
+
+	key := edKey(t, "dup")
+	_, err = utils.NewJWTKeySet(key, key.VerifyOnly())
+	assert.Error(t, err)
+}
+
+func TestLoadJWTKeys_RejectsPublishedSecret(t *testing.T) {
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_VERIFY_KEYS", "")
+	t.Setenv("JWT_VERIFY_SECRETS", "")
+	t.Setenv("JWT_SECRET", "RAHASIA_DAPUR_SAAS_INSPACEMAP_2025")
+	_, err := config.LoadJWTKeys()
+	assert.ErrorContains(t, err, "publicly known")
+
+	t.Setenv("JWT_SECRET", "a-fresh-secret-that-is-at-least-32-bytes")
+	t.Setenv("JWT_VERIFY_SECRETS", "legacy=RAHASIA_DAPUR_SAAS_INSPACEMAP_2025")
+	_, err = config.LoadJWTKeys()
+	assert.ErrorContains(t, err, "publicly known", "secret lama yang bocor juga tidak boleh diterima")
+
+	t.Setenv("JWT_VERIFY_SECRETS", "")
+	ks, err := config.LoadJWTKeys()
+	require.NoError(t, err)
+	assert.Equal(t, "primary", ks.SigningKeyID())
+}
@@ -0,0 +1,54 @@
+package unit
+
+import (
+	"inspacemap/backend/pkg/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.NewLRU[int](2, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, _ = c.Get("a") // "a" jadi paling baru dipakai
+	c.Set("c", 3)
+
+	_, okA := c.Get("a")
+	_, okB := c.Get("b")
+	_, okC := c.Get("c")
+
+	assert.True(t, okA)
+	assert.False(t, okB)
+	assert.True(t, okC)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRU_DeletePrefix(t *testing.T) {
+	c := cache.NewLRU[string](10, 0)
+
+	c.Set("mall:rev-1", "old")
+	c.Set("mall:rev-2", "new")
+	c.Set("museum:rev-1", "other")
+
+	c.DeletePrefix("mall:")
+
+	_, okMall := c.Get("mall:rev-2")
+	value, okMuseum := c.Get("museum:rev-1")
+
+	assert.False(t, okMall)
+	assert.True(t, okMuseum)
+	assert.Equal(t, "other", value)
+}
+
+func TestLRU_ExpiresAfterTTL(t *testing.T) {
+	c := cache.NewLRU[string](10, 10*time.Millisecond)
+
+	c.Set("key", "value")
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok := c.Get("key")
+	assert.False(t, ok)
+}
@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"inspacemap/backend/pkg/mailer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer_WritesMultipartEML(t *testing.T) {
+	dir := t.TempDir()
+	m := mailer.NewFileMailer(dir, "InSpaceMap <no-reply@example.com>")
+
+	err := m.Send(context.Background(), "jane@example.com", "Reset your password", "plain body", "<p>html body</p>")
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	msg, err := mail.ReadMessage(f)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Reset your password", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(part)
+		require.NoError(t, err)
+		bodies = append(bodies, string(b))
+	}
+	assert.Equal(t, []string{"plain body", "<p>html body</p>"}, bodies)
+}
+
+func TestFileMailer_RejectsHeaderInjection(t *testing.T) {
+	m := mailer.NewFileMailer(t.TempDir(), "no-reply@example.com")
+
+	err := m.Send(context.Background(), "jane@example.com\r\nBcc: all@example.com", "Hi", "body", "")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"encoding/json"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/msgpack"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compactTestManifest() *models.ManifestResponse {
+	areaID := uuid.New()
+	lobbyA, lobbyB, upper := uuid.New(), uuid.New(), uuid.New()
+
+	return &models.ManifestResponse{
+		VenueID:     uuid.New(),
+		VenueName:   "Grand Mall",
+		RevisionID:  uuid.New(),
+		LastUpdated: time.UnixMilli(1700000000123),
+		StartNodeID: lobbyB,
+		Floors: []models.FloorData{
+			{
+				ID: uuid.New(), LevelName: "Lobby", LevelIndex: 0, MapWidth: 800, MapHeight: 600,
+				Nodes: []models.NodeData{
+					{ID: lobbyA, X: 10, Y: 20, AreaID: &areaID, AreaName: "Atrium", Neighbors: []models.NeighborData{
+						{TargetNodeID: lobbyB, Heading: 90, Distance: 12.5, Type: "walk", IsActive: true},
+						{TargetNodeID: uuid.New(), Heading: 0, Distance: 1, Type: "walk", IsActive: true}, // node nonaktif
+					}},
+					{ID: lobbyB, X: 30, Y: 40, AreaID: &areaID, AreaName: "Atrium", Neighbors: []models.NeighborData{
+						{TargetNodeID: upper, Heading: 180, Distance: 4, Type: "stairs", IsActive: false},
+					}},
+				},
+			},
+			{
+				ID: uuid.New(), LevelName: "Level 1", LevelIndex: 1,
+				Nodes: []models.NodeData{{ID: upper, X: -5, Y: 70000, Label: "Food Court"}},
+			},
+		},
+	}
+}
+
+func TestEncodeCompactManifest_IndexTables(t *testing.T) {
+	manifest := compactTestManifest()
+
+	decoded, err := msgpack.Decode(service.EncodeCompactManifest(manifest))
+	require.NoError(t, err)
+	root := decoded.(map[string]any)
+
+	assert.Equal(t, int64(service.CompactManifestVersion), root["v"])
+	assert.Equal(t, manifest.VenueID[:], root["venue_id"])
+	assert.Equal(t, "Grand Mall", root["venue_name"])
+	assert.Equal(t, uint64(manifest.LastUpdated.UnixMilli()), root["last_updated"])
+	assert.Equal(t, int64(1), root["start"])
+	assert.Equal(t, []any{"walk", "stairs"}, root["edge_types"])
+
+	// Area yang dipakai dua node hanya ditulis sekali
+	areas := root["areas"].([]any)
+	require.Len(t, areas, 1)
+	assert.Equal(t, "Atrium", areas[0].([]any)[1])
+
+	floors := root["floors"].([]any)
+	require.Len(t, floors, 2)
+	assert.Equal(t, []any{int64(2), int64(1)}, floors[1].([]any)[6:])
+
+	nodes := root["nodes"].([]any)
+	require.Len(t, nodes, 3)
+	upper := nodes[2].([]any)
+	assert.Equal(t, int64(-5), upper[1])
+	assert.Equal(t, uint64(70000), upper[2])
+	assert.Equal(t, int64(-1), upper[5])
+	assert.Equal(t, "Food Court", upper[6])
+
+	// Edge ke node di luar manifest dibuang, sisanya memakai index node
+	edges := root["edges"].([]any)
+	require.Len(t, edges, 2)
+	assert.Equal(t, []any{int64(0), int64(1), float32(90), float32(12.5), int64(0), true}, edges[0])
+	assert.Equal(t, []any{int64(1), int64(2), float32(180), float32(4), int64(1), false}, edges[1])
+}
+
+func TestEncodeCompactManifest_SmallerThanJSON(t *testing.T) {
+	manifest := compactTestManifest()
+
+	asJSON, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	assert.Less(t, len(service.EncodeCompactManifest(manifest)), len(asJSON)/2)
+}
+
+func TestMsgpack_RoundTripScalars(t *testing.T) {
+	enc := msgpack.NewEncoder(0)
+	enc.WriteArrayHeader(8)
+	enc.WriteNil()
+	enc.WriteInt(-33)
+	enc.WriteInt(-70000)
+	enc.WriteInt(1 << 40)
+	enc.WriteFloat64(3.25)
+	enc.WriteString(string(make([]byte, 40)))
+	enc.WriteBinary([]byte{1, 2, 3})
+	enc.WriteMapHeader(0)
+
+	decoded, err := msgpack.Decode(enc.Bytes())
+
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, int64(-33), int64(-70000), uint64(1 << 40), 3.25, string(make([]byte, 40)), []byte{1, 2, 3}, map[string]any{}}, decoded)
+}
+
+func TestMsgpack_DecodeTruncated(t *testing.T) {
+	enc := msgpack.NewEncoder(0)
+	enc.WriteString("truncated payload")
+
+	_, err := msgpack.Decode(enc.Bytes()[:5])
+
+	assert.ErrorIs(t, err, msgpack.ErrShortBuffer)
+}
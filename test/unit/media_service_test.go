@@ -21,9 +21,9 @@ func TestMediaService_InitDirectUpload(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	orgID := uuid.New()
 	req := models.PresignedUploadRequest{
 		FileName: "test.jpg",
@@ -77,9 +77,9 @@ func TestMediaService_InitDirectUpload_StorageError(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	orgID := uuid.New()
 	req := models.PresignedUploadRequest{
 		FileName: "test.jpg",
@@ -110,9 +110,9 @@ func TestMediaService_InitDirectUpload_RepoError(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	orgID := uuid.New()
 	req := models.PresignedUploadRequest{
 		FileName: "test.jpg",
@@ -144,9 +144,9 @@ func TestMediaService_ConfirmUpload(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	assetID := uuid.New()
 	req := models.ConfirmUploadRequest{
 		AssetID: assetID,
@@ -185,9 +185,9 @@ func TestMediaService_ConfirmUpload_AssetNotFound(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	assetID := uuid.New()
 	req := models.ConfirmUploadRequest{
 		AssetID: assetID,
@@ -216,9 +216,9 @@ func TestMediaService_GetAsset(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	assetID := uuid.New()
 	expectedAsset := &entity.MediaAsset{
 		BaseEntity: entity.BaseEntity{ID: assetID},
@@ -243,10 +243,10 @@ func TestMediaService_ListAssets(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
-	orgID := uuid.New()
+	ctx := orgContext()
+	orgID := testOrgID
 	query := models.MediaAssetQuery{
 		MediaAssetFilter: models.MediaAssetFilter{
 			OrganizationID: &orgID,
@@ -280,9 +280,9 @@ func TestMediaService_DeleteAsset(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	assetID := uuid.New()
 	existingAsset := &entity.MediaAsset{
 		BaseEntity: entity.BaseEntity{ID: assetID},
@@ -317,9 +317,9 @@ func TestMediaService_DeleteAsset_AssetNotFound(t *testing.T) {
 	mockRepo := NewMockMediaAssetRepository(ctrl)
 	mockStorage := NewMockStorageProvider(ctrl)
 
-	mediaSvc := service.NewMediaService(mockRepo, mockStorage, "test-bucket", "https://cdn.example.com")
+	mediaSvc := service.NewMediaService(mockRepo, ownedResources(ctrl), mockStorage, "test-bucket", "https://cdn.example.com", nil)
 
-	ctx := context.Background()
+	ctx := orgContext()
 	assetID := uuid.New()
 
 	mockRepo.EXPECT().
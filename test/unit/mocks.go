@@ -13,6 +13,8 @@ import (
 	context "context"
 	entity "inspacemap/backend/internal/entity"
 	models "inspacemap/backend/internal/models"
+	repository "inspacemap/backend/internal/repository"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -468,6 +470,21 @@ func (mr *MockOrganizationMemberRepositoryMockRecorder) Delete(ctx, id any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrganizationMemberRepository)(nil).Delete), ctx, id)
 }
 
+// FindMember mocks base method.
+func (m *MockOrganizationMemberRepository) FindMember(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (*entity.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMember", ctx, orgID, userID)
+	ret0, _ := ret[0].(*entity.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMember indicates an expected call of FindMember.
+func (mr *MockOrganizationMemberRepositoryMockRecorder) FindMember(ctx, orgID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMember", reflect.TypeOf((*MockOrganizationMemberRepository)(nil).FindMember), ctx, orgID, userID)
+}
+
 // GetByID mocks base method.
 func (m *MockOrganizationMemberRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.OrganizationMember, error) {
 	m.ctrl.T.Helper()
@@ -594,6 +611,21 @@ func (m *MockUserInvitationRepository) EXPECT() *MockUserInvitationRepositoryMoc
 	return m.recorder
 }
 
+// Accept mocks base method.
+func (m *MockUserInvitationRepository) Accept(ctx context.Context, inviteID uuid.UUID, member *entity.OrganizationMember) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, inviteID, member)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockUserInvitationRepositoryMockRecorder) Accept(ctx, inviteID, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockUserInvitationRepository)(nil).Accept), ctx, inviteID, member)
+}
+
 // Create mocks base method.
 func (m *MockUserInvitationRepository) Create(ctx context.Context, arg1 *entity.UserInvitation) error {
 	m.ctrl.T.Helper()
@@ -713,18 +745,33 @@ func (mr *MockUserInvitationRepositoryMockRecorder) GetByStatus(ctx, orgID, stat
 }
 
 // GetByToken mocks base method.
-func (m *MockUserInvitationRepository) GetByToken(ctx context.Context, token string) (*entity.UserInvitation, error) {
+func (m *MockUserInvitationRepository) GetByToken(ctx context.Context, tokenHash string) (*entity.UserInvitation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByToken", ctx, token)
+	ret := m.ctrl.Call(m, "GetByToken", ctx, tokenHash)
 	ret0, _ := ret[0].(*entity.UserInvitation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByToken indicates an expected call of GetByToken.
-func (mr *MockUserInvitationRepositoryMockRecorder) GetByToken(ctx, token any) *gomock.Call {
+func (mr *MockUserInvitationRepositoryMockRecorder) GetByToken(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByToken", reflect.TypeOf((*MockUserInvitationRepository)(nil).GetByToken), ctx, tokenHash)
+}
+
+// MarkExpired mocks base method.
+func (m *MockUserInvitationRepository) MarkExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExpired", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkExpired indicates an expected call of MarkExpired.
+func (mr *MockUserInvitationRepositoryMockRecorder) MarkExpired(ctx, now any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByToken", reflect.TypeOf((*MockUserInvitationRepository)(nil).GetByToken), ctx, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExpired", reflect.TypeOf((*MockUserInvitationRepository)(nil).MarkExpired), ctx, now)
 }
 
 // RevokeInvitation mocks base method.
@@ -779,6 +826,22 @@ func (m *MockRoleRepository) EXPECT() *MockRoleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountAssignments mocks base method.
+func (m *MockRoleRepository) CountAssignments(ctx context.Context, roleID uuid.UUID) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAssignments", ctx, roleID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountAssignments indicates an expected call of CountAssignments.
+func (mr *MockRoleRepositoryMockRecorder) CountAssignments(ctx, roleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAssignments", reflect.TypeOf((*MockRoleRepository)(nil).CountAssignments), ctx, roleID)
+}
+
 // Create mocks base method.
 func (m *MockRoleRepository) Create(ctx context.Context, arg1 *entity.Role) error {
 	m.ctrl.T.Helper()
@@ -852,6 +915,21 @@ func (mr *MockRoleRepositoryMockRecorder) GetByName(ctx, name any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockRoleRepository)(nil).GetByName), ctx, name)
 }
 
+// GetByOrganizationID mocks base method.
+func (m *MockRoleRepository) GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrganizationID", ctx, orgID)
+	ret0, _ := ret[0].([]entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrganizationID indicates an expected call of GetByOrganizationID.
+func (mr *MockRoleRepositoryMockRecorder) GetByOrganizationID(ctx, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrganizationID", reflect.TypeOf((*MockRoleRepository)(nil).GetByOrganizationID), ctx, orgID)
+}
+
 // GetPermissions mocks base method.
 func (m *MockRoleRepository) GetPermissions(ctx context.Context, roleID uuid.UUID) ([]entity.Permission, error) {
 	m.ctrl.T.Helper()
@@ -881,6 +959,20 @@ func (mr *MockRoleRepositoryMockRecorder) Update(ctx, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRoleRepository)(nil).Update), ctx, arg1)
 }
 
+// UpdateWithPermissions mocks base method.
+func (m *MockRoleRepository) UpdateWithPermissions(ctx context.Context, role *entity.Role, perms []entity.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithPermissions", ctx, role, perms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWithPermissions indicates an expected call of UpdateWithPermissions.
+func (mr *MockRoleRepositoryMockRecorder) UpdateWithPermissions(ctx, role, perms any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithPermissions", reflect.TypeOf((*MockRoleRepository)(nil).UpdateWithPermissions), ctx, role, perms)
+}
+
 // MockPermissionRepository is a mock of PermissionRepository interface.
 type MockPermissionRepository struct {
 	ctrl     *gomock.Controller
@@ -963,6 +1055,21 @@ func (mr *MockPermissionRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPermissionRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIDs mocks base method.
+func (m *MockPermissionRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockPermissionRepositoryMockRecorder) GetByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockPermissionRepository)(nil).GetByIDs), ctx, ids)
+}
+
 // GetByUserAndOrg mocks base method.
 func (m *MockPermissionRepository) GetByUserAndOrg(ctx context.Context, userID, orgID uuid.UUID) ([]entity.Permission, error) {
 	m.ctrl.T.Helper()
@@ -1084,6 +1191,21 @@ func (mr *MockVenueRepositoryMockRecorder) Create(ctx, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockVenueRepository)(nil).Create), ctx, arg1)
 }
 
+// CreateCopy mocks base method.
+func (m *MockVenueRepository) CreateCopy(ctx context.Context, src *repository.VenueCopy) (*entity.Venue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCopy", ctx, src)
+	ret0, _ := ret[0].(*entity.Venue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCopy indicates an expected call of CreateCopy.
+func (mr *MockVenueRepositoryMockRecorder) CreateCopy(ctx, src any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCopy", reflect.TypeOf((*MockVenueRepository)(nil).CreateCopy), ctx, src)
+}
+
 // CursorVenues mocks base method.
 func (m *MockVenueRepository) CursorVenues(ctx context.Context, query models.VenueQueryCursor) ([]entity.Venue, string, error) {
 	m.ctrl.T.Helper()
@@ -1174,19 +1296,79 @@ func (mr *MockVenueRepositoryMockRecorder) GetBySlug(ctx, slug any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySlug", reflect.TypeOf((*MockVenueRepository)(nil).GetBySlug), ctx, slug)
 }
 
+// GetDraftManifestData mocks base method.
+func (m *MockVenueRepository) GetDraftManifestData(orgSlug, venueSlug string) (*entity.Venue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraftManifestData", orgSlug, venueSlug)
+	ret0, _ := ret[0].(*entity.Venue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraftManifestData indicates an expected call of GetDraftManifestData.
+func (mr *MockVenueRepositoryMockRecorder) GetDraftManifestData(orgSlug, venueSlug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraftManifestData", reflect.TypeOf((*MockVenueRepository)(nil).GetDraftManifestData), orgSlug, venueSlug)
+}
+
 // GetLiveManifestData mocks base method.
-func (m *MockVenueRepository) GetLiveManifestData(venueSlug string) (*entity.Venue, error) {
+func (m *MockVenueRepository) GetLiveManifestData(orgSlug, venueSlug string) (*entity.Venue, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLiveManifestData", venueSlug)
+	ret := m.ctrl.Call(m, "GetLiveManifestData", orgSlug, venueSlug)
 	ret0, _ := ret[0].(*entity.Venue)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLiveManifestData indicates an expected call of GetLiveManifestData.
-func (mr *MockVenueRepositoryMockRecorder) GetLiveManifestData(venueSlug any) *gomock.Call {
+func (mr *MockVenueRepositoryMockRecorder) GetLiveManifestData(orgSlug, venueSlug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLiveManifestData", reflect.TypeOf((*MockVenueRepository)(nil).GetLiveManifestData), orgSlug, venueSlug)
+}
+
+// GetManifestInfo mocks base method.
+func (m *MockVenueRepository) GetManifestInfo(ctx context.Context, orgSlug, slug string) (*entity.Venue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManifestInfo", ctx, orgSlug, slug)
+	ret0, _ := ret[0].(*entity.Venue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManifestInfo indicates an expected call of GetManifestInfo.
+func (mr *MockVenueRepositoryMockRecorder) GetManifestInfo(ctx, orgSlug, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManifestInfo", reflect.TypeOf((*MockVenueRepository)(nil).GetManifestInfo), ctx, orgSlug, slug)
+}
+
+// GetOrgSlugsByVenueSlug mocks base method.
+func (m *MockVenueRepository) GetOrgSlugsByVenueSlug(ctx context.Context, slug string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrgSlugsByVenueSlug", ctx, slug, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrgSlugsByVenueSlug indicates an expected call of GetOrgSlugsByVenueSlug.
+func (mr *MockVenueRepositoryMockRecorder) GetOrgSlugsByVenueSlug(ctx, slug, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrgSlugsByVenueSlug", reflect.TypeOf((*MockVenueRepository)(nil).GetOrgSlugsByVenueSlug), ctx, slug, limit)
+}
+
+// GetRevisionManifestData mocks base method.
+func (m *MockVenueRepository) GetRevisionManifestData(ctx context.Context, venueID, revisionID uuid.UUID) (*entity.GraphRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisionManifestData", ctx, venueID, revisionID)
+	ret0, _ := ret[0].(*entity.GraphRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisionManifestData indicates an expected call of GetRevisionManifestData.
+func (mr *MockVenueRepositoryMockRecorder) GetRevisionManifestData(ctx, venueID, revisionID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLiveManifestData", reflect.TypeOf((*MockVenueRepository)(nil).GetLiveManifestData), venueSlug)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisionManifestData", reflect.TypeOf((*MockVenueRepository)(nil).GetRevisionManifestData), ctx, venueID, revisionID)
 }
 
 // PagedVenues mocks base method.
@@ -1498,6 +1680,35 @@ func (mr *MockGraphRepositoryMockRecorder) DeleteNode(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockGraphRepository)(nil).DeleteNode), ctx, id)
 }
 
+// GetNode mocks base method.
+func (m *MockGraphRepository) GetNode(ctx context.Context, id uuid.UUID) (*entity.GraphNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNode", ctx, id)
+	ret0, _ := ret[0].(*entity.GraphNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNode indicates an expected call of GetNode.
+func (mr *MockGraphRepositoryMockRecorder) GetNode(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNode", reflect.TypeOf((*MockGraphRepository)(nil).GetNode), ctx, id)
+}
+
+// ImportGraph mocks base method.
+func (m *MockGraphRepository) ImportGraph(ctx context.Context, areas []entity.Area, nodes []entity.GraphNode, edges []entity.GraphEdge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportGraph", ctx, areas, nodes, edges)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportGraph indicates an expected call of ImportGraph.
+func (mr *MockGraphRepositoryMockRecorder) ImportGraph(ctx, areas, nodes, edges any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportGraph", reflect.TypeOf((*MockGraphRepository)(nil).ImportGraph), ctx, areas, nodes, edges)
+}
+
 // UpdateNodeCalibration mocks base method.
 func (m *MockGraphRepository) UpdateNodeCalibration(ctx context.Context, id uuid.UUID, offset float64) error {
 	m.ctrl.T.Helper()
@@ -1713,6 +1924,21 @@ func (mr *MockGraphRevisionRepositoryMockRecorder) GetDraftByVenueID(ctx, venueI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraftByVenueID", reflect.TypeOf((*MockGraphRevisionRepository)(nil).GetDraftByVenueID), ctx, venueID)
 }
 
+// GetFullGraph mocks base method.
+func (m *MockGraphRevisionRepository) GetFullGraph(ctx context.Context, revisionID uuid.UUID) (*entity.GraphRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFullGraph", ctx, revisionID)
+	ret0, _ := ret[0].(*entity.GraphRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFullGraph indicates an expected call of GetFullGraph.
+func (mr *MockGraphRevisionRepositoryMockRecorder) GetFullGraph(ctx, revisionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFullGraph", reflect.TypeOf((*MockGraphRevisionRepository)(nil).GetFullGraph), ctx, revisionID)
+}
+
 // GetLiveByFloorID mocks base method.
 func (m *MockGraphRevisionRepository) GetLiveByFloorID(ctx context.Context, floorID uuid.UUID) (*entity.GraphRevision, error) {
 	m.ctrl.T.Helper()
@@ -1787,6 +2013,21 @@ func (mr *MockGraphRevisionRepositoryMockRecorder) PublishDraft(ctx, revisionID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDraft", reflect.TypeOf((*MockGraphRevisionRepository)(nil).PublishDraft), ctx, revisionID, note)
 }
 
+// SetLiveRevision mocks base method.
+func (m *MockGraphRevisionRepository) SetLiveRevision(ctx context.Context, venueID, revisionID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLiveRevision", ctx, venueID, revisionID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLiveRevision indicates an expected call of SetLiveRevision.
+func (mr *MockGraphRevisionRepositoryMockRecorder) SetLiveRevision(ctx, venueID, revisionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLiveRevision", reflect.TypeOf((*MockGraphRevisionRepository)(nil).SetLiveRevision), ctx, venueID, revisionID)
+}
+
 // Update mocks base method.
 func (m *MockGraphRevisionRepository) Update(ctx context.Context, arg1 *entity.GraphRevision) error {
 	m.ctrl.T.Helper()
@@ -2360,6 +2601,20 @@ func (mr *MockFloorRepositoryMockRecorder) UpdateFloorMap(ctx, id, mapImageID, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFloorMap", reflect.TypeOf((*MockFloorRepository)(nil).UpdateFloorMap), ctx, id, mapImageID, pixelsPerMeter)
 }
 
+// UpdateGeoReference mocks base method.
+func (m *MockFloorRepository) UpdateGeoReference(ctx context.Context, id uuid.UUID, geoReference entity.JSONMap) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGeoReference", ctx, id, geoReference)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGeoReference indicates an expected call of UpdateGeoReference.
+func (mr *MockFloorRepositoryMockRecorder) UpdateGeoReference(ctx, id, geoReference any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGeoReference", reflect.TypeOf((*MockFloorRepository)(nil).UpdateGeoReference), ctx, id, geoReference)
+}
+
 // MockMediaAssetRepository is a mock of MediaAssetRepository interface.
 type MockMediaAssetRepository struct {
 	ctrl     *gomock.Controller
@@ -2669,6 +2924,36 @@ func (mr *MockStorageProviderMockRecorder) DeleteObject(ctx, bucket, key any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockStorageProvider)(nil).DeleteObject), ctx, bucket, key)
 }
 
+// GetObject mocks base method.
+func (m *MockStorageProvider) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObject", ctx, bucket, key)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObject indicates an expected call of GetObject.
+func (mr *MockStorageProviderMockRecorder) GetObject(ctx, bucket, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockStorageProvider)(nil).GetObject), ctx, bucket, key)
+}
+
+// GetPresignedGetURL mocks base method.
+func (m *MockStorageProvider) GetPresignedGetURL(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPresignedGetURL", ctx, bucket, key, expiry)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPresignedGetURL indicates an expected call of GetPresignedGetURL.
+func (mr *MockStorageProviderMockRecorder) GetPresignedGetURL(ctx, bucket, key, expiry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresignedGetURL", reflect.TypeOf((*MockStorageProvider)(nil).GetPresignedGetURL), ctx, bucket, key, expiry)
+}
+
 // GetPresignedPutURL mocks base method.
 func (m *MockStorageProvider) GetPresignedPutURL(ctx context.Context, bucket, key, contentType string, expiry time.Duration) (string, error) {
 	m.ctrl.T.Helper()
@@ -2683,3 +2968,1366 @@ func (mr *MockStorageProviderMockRecorder) GetPresignedPutURL(ctx, bucket, key,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresignedPutURL", reflect.TypeOf((*MockStorageProvider)(nil).GetPresignedPutURL), ctx, bucket, key, contentType, expiry)
 }
+
+// ObjectExists mocks base method.
+func (m *MockStorageProvider) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObjectExists", ctx, bucket, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ObjectExists indicates an expected call of ObjectExists.
+func (mr *MockStorageProviderMockRecorder) ObjectExists(ctx, bucket, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObjectExists", reflect.TypeOf((*MockStorageProvider)(nil).ObjectExists), ctx, bucket, key)
+}
+
+// PutObject mocks base method.
+func (m *MockStorageProvider) PutObject(ctx context.Context, bucket, key, contentType string, body io.Reader, size int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutObject", ctx, bucket, key, contentType, body, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutObject indicates an expected call of PutObject.
+func (mr *MockStorageProviderMockRecorder) PutObject(ctx, bucket, key, contentType, body, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockStorageProvider)(nil).PutObject), ctx, bucket, key, contentType, body, size)
+}
+
+// MockOwnershipRepository is a mock of OwnershipRepository interface.
+type MockOwnershipRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOwnershipRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOwnershipRepositoryMockRecorder is the mock recorder for MockOwnershipRepository.
+type MockOwnershipRepositoryMockRecorder struct {
+	mock *MockOwnershipRepository
+}
+
+// NewMockOwnershipRepository creates a new mock instance.
+func NewMockOwnershipRepository(ctrl *gomock.Controller) *MockOwnershipRepository {
+	mock := &MockOwnershipRepository{ctrl: ctrl}
+	mock.recorder = &MockOwnershipRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOwnershipRepository) EXPECT() *MockOwnershipRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AreaBelongsTo mocks base method.
+func (m *MockOwnershipRepository) AreaBelongsTo(ctx context.Context, orgID, areaID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AreaBelongsTo", ctx, orgID, areaID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AreaBelongsTo indicates an expected call of AreaBelongsTo.
+func (mr *MockOwnershipRepositoryMockRecorder) AreaBelongsTo(ctx, orgID, areaID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AreaBelongsTo", reflect.TypeOf((*MockOwnershipRepository)(nil).AreaBelongsTo), ctx, orgID, areaID)
+}
+
+// FloorBelongsTo mocks base method.
+func (m *MockOwnershipRepository) FloorBelongsTo(ctx context.Context, orgID, floorID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FloorBelongsTo", ctx, orgID, floorID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FloorBelongsTo indicates an expected call of FloorBelongsTo.
+func (mr *MockOwnershipRepositoryMockRecorder) FloorBelongsTo(ctx, orgID, floorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FloorBelongsTo", reflect.TypeOf((*MockOwnershipRepository)(nil).FloorBelongsTo), ctx, orgID, floorID)
+}
+
+// MediaBelongsTo mocks base method.
+func (m *MockOwnershipRepository) MediaBelongsTo(ctx context.Context, orgID, mediaID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MediaBelongsTo", ctx, orgID, mediaID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MediaBelongsTo indicates an expected call of MediaBelongsTo.
+func (mr *MockOwnershipRepositoryMockRecorder) MediaBelongsTo(ctx, orgID, mediaID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MediaBelongsTo", reflect.TypeOf((*MockOwnershipRepository)(nil).MediaBelongsTo), ctx, orgID, mediaID)
+}
+
+// NodeBelongsTo mocks base method.
+func (m *MockOwnershipRepository) NodeBelongsTo(ctx context.Context, orgID, nodeID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeBelongsTo", ctx, orgID, nodeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeBelongsTo indicates an expected call of NodeBelongsTo.
+func (mr *MockOwnershipRepositoryMockRecorder) NodeBelongsTo(ctx, orgID, nodeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeBelongsTo", reflect.TypeOf((*MockOwnershipRepository)(nil).NodeBelongsTo), ctx, orgID, nodeID)
+}
+
+// VenueBelongsTo mocks base method.
+func (m *MockOwnershipRepository) VenueBelongsTo(ctx context.Context, orgID, venueID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VenueBelongsTo", ctx, orgID, venueID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VenueBelongsTo indicates an expected call of VenueBelongsTo.
+func (mr *MockOwnershipRepositoryMockRecorder) VenueBelongsTo(ctx, orgID, venueID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VenueBelongsTo", reflect.TypeOf((*MockOwnershipRepository)(nil).VenueBelongsTo), ctx, orgID, venueID)
+}
+
+// MockRefreshTokenRepository is a mock of RefreshTokenRepository interface.
+type MockRefreshTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRefreshTokenRepositoryMockRecorder is the mock recorder for MockRefreshTokenRepository.
+type MockRefreshTokenRepositoryMockRecorder struct {
+	mock *MockRefreshTokenRepository
+}
+
+// NewMockRefreshTokenRepository creates a new mock instance.
+func NewMockRefreshTokenRepository(ctrl *gomock.Controller) *MockRefreshTokenRepository {
+	mock := &MockRefreshTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshTokenRepository) EXPECT() *MockRefreshTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, entity *entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefreshTokenRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefreshTokenRepository)(nil).Create), ctx, entity)
+}
+
+// Delete mocks base method.
+func (m *MockRefreshTokenRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRefreshTokenRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRefreshTokenRepository)(nil).Delete), ctx, id)
+}
+
+// GetActiveByUser mocks base method.
+func (m *MockRefreshTokenRepository) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveByUser", ctx, userID)
+	ret0, _ := ret[0].([]entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveByUser indicates an expected call of GetActiveByUser.
+func (mr *MockRefreshTokenRepositoryMockRecorder) GetActiveByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveByUser", reflect.TypeOf((*MockRefreshTokenRepository)(nil).GetActiveByUser), ctx, userID)
+}
+
+// GetByHash mocks base method.
+func (m *MockRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockRefreshTokenRepositoryMockRecorder) GetByHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockRefreshTokenRepository)(nil).GetByHash), ctx, tokenHash)
+}
+
+// GetByID mocks base method.
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockRefreshTokenRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRefreshTokenRepository)(nil).GetByID), ctx, id)
+}
+
+// RevokeAllByUser mocks base method.
+func (m *MockRefreshTokenRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllByUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllByUser indicates an expected call of RevokeAllByUser.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeAllByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllByUser", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeAllByUser), ctx, userID)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, userID, familyID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, userID, familyID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeFamily(ctx, userID, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeFamily), ctx, userID, familyID)
+}
+
+// Rotate mocks base method.
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, current, next)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockRefreshTokenRepositoryMockRecorder) Rotate(ctx, current, next any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockRefreshTokenRepository)(nil).Rotate), ctx, current, next)
+}
+
+// SetOrganization mocks base method.
+func (m *MockRefreshTokenRepository) SetOrganization(ctx context.Context, userID, familyID, orgID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrganization", ctx, userID, familyID, orgID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrganization indicates an expected call of SetOrganization.
+func (mr *MockRefreshTokenRepositoryMockRecorder) SetOrganization(ctx, userID, familyID, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrganization", reflect.TypeOf((*MockRefreshTokenRepository)(nil).SetOrganization), ctx, userID, familyID, orgID)
+}
+
+// Update mocks base method.
+func (m *MockRefreshTokenRepository) Update(ctx context.Context, entity *entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRefreshTokenRepositoryMockRecorder) Update(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRefreshTokenRepository)(nil).Update), ctx, entity)
+}
+
+// MockPermissionResolver is a mock of PermissionResolver interface.
+type MockPermissionResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockPermissionResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockPermissionResolverMockRecorder is the mock recorder for MockPermissionResolver.
+type MockPermissionResolverMockRecorder struct {
+	mock *MockPermissionResolver
+}
+
+// NewMockPermissionResolver creates a new mock instance.
+func NewMockPermissionResolver(ctrl *gomock.Controller) *MockPermissionResolver {
+	mock := &MockPermissionResolver{ctrl: ctrl}
+	mock.recorder = &MockPermissionResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPermissionResolver) EXPECT() *MockPermissionResolverMockRecorder {
+	return m.recorder
+}
+
+// InvalidateMember mocks base method.
+func (m *MockPermissionResolver) InvalidateMember(orgID, userID uuid.UUID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateMember", orgID, userID)
+}
+
+// InvalidateMember indicates an expected call of InvalidateMember.
+func (mr *MockPermissionResolverMockRecorder) InvalidateMember(orgID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateMember", reflect.TypeOf((*MockPermissionResolver)(nil).InvalidateMember), orgID, userID)
+}
+
+// InvalidateOrganization mocks base method.
+func (m *MockPermissionResolver) InvalidateOrganization(orgID uuid.UUID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateOrganization", orgID)
+}
+
+// InvalidateOrganization indicates an expected call of InvalidateOrganization.
+func (mr *MockPermissionResolverMockRecorder) InvalidateOrganization(orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateOrganization", reflect.TypeOf((*MockPermissionResolver)(nil).InvalidateOrganization), orgID)
+}
+
+// ResolvePermissions mocks base method.
+func (m *MockPermissionResolver) ResolvePermissions(ctx context.Context, userID, orgID uuid.UUID) ([]string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolvePermissions", ctx, userID, orgID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolvePermissions indicates an expected call of ResolvePermissions.
+func (mr *MockPermissionResolverMockRecorder) ResolvePermissions(ctx, userID, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePermissions", reflect.TypeOf((*MockPermissionResolver)(nil).ResolvePermissions), ctx, userID, orgID)
+}
+
+// MockUserTokenRepository is a mock of UserTokenRepository interface.
+type MockUserTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserTokenRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserTokenRepositoryMockRecorder is the mock recorder for MockUserTokenRepository.
+type MockUserTokenRepositoryMockRecorder struct {
+	mock *MockUserTokenRepository
+}
+
+// NewMockUserTokenRepository creates a new mock instance.
+func NewMockUserTokenRepository(ctrl *gomock.Controller) *MockUserTokenRepository {
+	mock := &MockUserTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockUserTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserTokenRepository) EXPECT() *MockUserTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockUserTokenRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockUserTokenRepositoryMockRecorder) Consume(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockUserTokenRepository)(nil).Consume), ctx, id)
+}
+
+// Create mocks base method.
+func (m *MockUserTokenRepository) Create(ctx context.Context, entity *entity.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserTokenRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserTokenRepository)(nil).Create), ctx, entity)
+}
+
+// Delete mocks base method.
+func (m *MockUserTokenRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserTokenRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserTokenRepository)(nil).Delete), ctx, id)
+}
+
+// GetByHash mocks base method.
+func (m *MockUserTokenRepository) GetByHash(ctx context.Context, purpose entity.UserTokenPurpose, tokenHash string) (*entity.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(*entity.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockUserTokenRepositoryMockRecorder) GetByHash(ctx, purpose, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockUserTokenRepository)(nil).GetByHash), ctx, purpose, tokenHash)
+}
+
+// GetByID mocks base method.
+func (m *MockUserTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockUserTokenRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserTokenRepository)(nil).GetByID), ctx, id)
+}
+
+// InvalidateByUser mocks base method.
+func (m *MockUserTokenRepository) InvalidateByUser(ctx context.Context, userID uuid.UUID, purpose entity.UserTokenPurpose) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateByUser", ctx, userID, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateByUser indicates an expected call of InvalidateByUser.
+func (mr *MockUserTokenRepositoryMockRecorder) InvalidateByUser(ctx, userID, purpose any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateByUser", reflect.TypeOf((*MockUserTokenRepository)(nil).InvalidateByUser), ctx, userID, purpose)
+}
+
+// Update mocks base method.
+func (m *MockUserTokenRepository) Update(ctx context.Context, entity *entity.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserTokenRepositoryMockRecorder) Update(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserTokenRepository)(nil).Update), ctx, entity)
+}
+
+// MockAccountService is a mock of AccountService interface.
+type MockAccountService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountServiceMockRecorder is the mock recorder for MockAccountService.
+type MockAccountServiceMockRecorder struct {
+	mock *MockAccountService
+}
+
+// NewMockAccountService creates a new mock instance.
+func NewMockAccountService(ctrl *gomock.Controller) *MockAccountService {
+	mock := &MockAccountService{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountService) EXPECT() *MockAccountServiceMockRecorder {
+	return m.recorder
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockAccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockAccountServiceMockRecorder) RequestPasswordReset(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockAccountService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAccountServiceMockRecorder) ResetPassword(ctx, token, newPassword any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAccountService)(nil).ResetPassword), ctx, token, newPassword)
+}
+
+// SendEmailVerification mocks base method.
+func (m *MockAccountService) SendEmailVerification(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailVerification", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmailVerification indicates an expected call of SendEmailVerification.
+func (mr *MockAccountServiceMockRecorder) SendEmailVerification(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailVerification", reflect.TypeOf((*MockAccountService)(nil).SendEmailVerification), ctx, userID)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAccountService) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAccountServiceMockRecorder) VerifyEmail(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAccountService)(nil).VerifyEmail), ctx, token)
+}
+
+// MockMailer is a mock of Mailer interface.
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+	isgomock struct{}
+}
+
+// MockMailerMockRecorder is the mock recorder for MockMailer.
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+// NewMockMailer creates a new mock instance.
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailer) Send(ctx context.Context, to, subject, textBody, htmlBody string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, to, subject, textBody, htmlBody)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailerMockRecorder) Send(ctx, to, subject, textBody, htmlBody any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), ctx, to, subject, textBody, htmlBody)
+}
+
+// MockApiKeyRepository is a mock of ApiKeyRepository interface.
+type MockApiKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockApiKeyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockApiKeyRepositoryMockRecorder is the mock recorder for MockApiKeyRepository.
+type MockApiKeyRepositoryMockRecorder struct {
+	mock *MockApiKeyRepository
+}
+
+// NewMockApiKeyRepository creates a new mock instance.
+func NewMockApiKeyRepository(ctrl *gomock.Controller) *MockApiKeyRepository {
+	mock := &MockApiKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockApiKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApiKeyRepository) EXPECT() *MockApiKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockApiKeyRepository) Create(ctx context.Context, entity *entity.ApiKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockApiKeyRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApiKeyRepository)(nil).Create), ctx, entity)
+}
+
+// Delete mocks base method.
+func (m *MockApiKeyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockApiKeyRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockApiKeyRepository)(nil).Delete), ctx, id)
+}
+
+// GetByHash mocks base method.
+func (m *MockApiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*entity.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockApiKeyRepositoryMockRecorder) GetByHash(ctx, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockApiKeyRepository)(nil).GetByHash), ctx, keyHash)
+}
+
+// GetByID mocks base method.
+func (m *MockApiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockApiKeyRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockApiKeyRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByOrganizationID mocks base method.
+func (m *MockApiKeyRepository) GetByOrganizationID(ctx context.Context, orgID uuid.UUID) ([]entity.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrganizationID", ctx, orgID)
+	ret0, _ := ret[0].([]entity.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrganizationID indicates an expected call of GetByOrganizationID.
+func (mr *MockApiKeyRepositoryMockRecorder) GetByOrganizationID(ctx, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrganizationID", reflect.TypeOf((*MockApiKeyRepository)(nil).GetByOrganizationID), ctx, orgID)
+}
+
+// Revoke mocks base method.
+func (m *MockApiKeyRepository) Revoke(ctx context.Context, orgID, id uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, orgID, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockApiKeyRepositoryMockRecorder) Revoke(ctx, orgID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockApiKeyRepository)(nil).Revoke), ctx, orgID, id)
+}
+
+// TouchLastUsed mocks base method.
+func (m *MockApiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchLastUsed", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchLastUsed indicates an expected call of TouchLastUsed.
+func (mr *MockApiKeyRepositoryMockRecorder) TouchLastUsed(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchLastUsed", reflect.TypeOf((*MockApiKeyRepository)(nil).TouchLastUsed), ctx, id, at)
+}
+
+// Update mocks base method.
+func (m *MockApiKeyRepository) Update(ctx context.Context, entity *entity.ApiKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockApiKeyRepositoryMockRecorder) Update(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockApiKeyRepository)(nil).Update), ctx, entity)
+}
+
+// MockUserIdentityRepository is a mock of UserIdentityRepository interface.
+type MockUserIdentityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserIdentityRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserIdentityRepositoryMockRecorder is the mock recorder for MockUserIdentityRepository.
+type MockUserIdentityRepositoryMockRecorder struct {
+	mock *MockUserIdentityRepository
+}
+
+// NewMockUserIdentityRepository creates a new mock instance.
+func NewMockUserIdentityRepository(ctrl *gomock.Controller) *MockUserIdentityRepository {
+	mock := &MockUserIdentityRepository{ctrl: ctrl}
+	mock.recorder = &MockUserIdentityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserIdentityRepository) EXPECT() *MockUserIdentityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUserIdentityRepository) Create(ctx context.Context, entity *entity.UserIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserIdentityRepositoryMockRecorder) Create(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserIdentityRepository)(nil).Create), ctx, entity)
+}
+
+// Delete mocks base method.
+func (m *MockUserIdentityRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserIdentityRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserIdentityRepository)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockUserIdentityRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockUserIdentityRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserIdentityRepository)(nil).GetByID), ctx, id)
+}
+
+// GetBySubject mocks base method.
+func (m *MockUserIdentityRepository) GetBySubject(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySubject", ctx, issuer, subject)
+	ret0, _ := ret[0].(*entity.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySubject indicates an expected call of GetBySubject.
+func (mr *MockUserIdentityRepositoryMockRecorder) GetBySubject(ctx, issuer, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySubject", reflect.TypeOf((*MockUserIdentityRepository)(nil).GetBySubject), ctx, issuer, subject)
+}
+
+// Update mocks base method.
+func (m *MockUserIdentityRepository) Update(ctx context.Context, entity *entity.UserIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserIdentityRepositoryMockRecorder) Update(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserIdentityRepository)(nil).Update), ctx, entity)
+}
+
+// MockAuthService is a mock of AuthService interface.
+type MockAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAuthServiceMockRecorder is the mock recorder for MockAuthService.
+type MockAuthServiceMockRecorder struct {
+	mock *MockAuthService
+}
+
+// NewMockAuthService creates a new mock instance.
+func NewMockAuthService(ctrl *gomock.Controller) *MockAuthService {
+	mock := &MockAuthService{ctrl: ctrl}
+	mock.recorder = &MockAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockAuthService) AcceptInvitation(ctx context.Context, req models.AcceptInviteRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, req, client)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockAuthServiceMockRecorder) AcceptInvitation(ctx, req, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockAuthService)(nil).AcceptInvitation), ctx, req, client)
+}
+
+// GetMe mocks base method.
+func (m *MockAuthService) GetMe(ctx context.Context, userID, activeOrgID uuid.UUID) (*models.MeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMe", ctx, userID, activeOrgID)
+	ret0, _ := ret[0].(*models.MeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMe indicates an expected call of GetMe.
+func (mr *MockAuthServiceMockRecorder) GetMe(ctx, userID, activeOrgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMe", reflect.TypeOf((*MockAuthService)(nil).GetMe), ctx, userID, activeOrgID)
+}
+
+// ListSessions mocks base method.
+func (m *MockAuthService) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]models.SessionDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userID, currentSessionID)
+	ret0, _ := ret[0].([]models.SessionDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockAuthServiceMockRecorder) ListSessions(ctx, userID, currentSessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockAuthService)(nil).ListSessions), ctx, userID, currentSessionID)
+}
+
+// Login mocks base method.
+func (m *MockAuthService) Login(ctx context.Context, req models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, req, client)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockAuthServiceMockRecorder) Login(ctx, req, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), ctx, req, client)
+}
+
+// Logout mocks base method.
+func (m *MockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceMockRecorder) Logout(ctx, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthService)(nil).Logout), ctx, refreshToken)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken, client)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthServiceMockRecorder) Refresh(ctx, refreshToken, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthService)(nil).Refresh), ctx, refreshToken, client)
+}
+
+// Register mocks base method.
+func (m *MockAuthService) Register(ctx context.Context, req models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, req, client)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockAuthServiceMockRecorder) Register(ctx, req, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), ctx, req, client)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockAuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockAuthServiceMockRecorder) RevokeAllSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockAuthService)(nil).RevokeAllSessions), ctx, userID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockAuthServiceMockRecorder) RevokeSession(ctx, userID, sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockAuthService)(nil).RevokeSession), ctx, userID, sessionID)
+}
+
+// StartSession mocks base method.
+func (m *MockAuthService) StartSession(ctx context.Context, userID, orgID uuid.UUID, client models.ClientInfo) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSession", ctx, userID, orgID, client)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSession indicates an expected call of StartSession.
+func (mr *MockAuthServiceMockRecorder) StartSession(ctx, userID, orgID, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSession", reflect.TypeOf((*MockAuthService)(nil).StartSession), ctx, userID, orgID, client)
+}
+
+// SwitchOrganization mocks base method.
+func (m *MockAuthService) SwitchOrganization(ctx context.Context, userID, sessionID, orgID uuid.UUID) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwitchOrganization", ctx, userID, sessionID, orgID)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SwitchOrganization indicates an expected call of SwitchOrganization.
+func (mr *MockAuthServiceMockRecorder) SwitchOrganization(ctx, userID, sessionID, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwitchOrganization", reflect.TypeOf((*MockAuthService)(nil).SwitchOrganization), ctx, userID, sessionID, orgID)
+}
+
+// VerifyTwoFactor mocks base method.
+func (m *MockAuthService) VerifyTwoFactor(ctx context.Context, req models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTwoFactor", ctx, req, client)
+	ret0, _ := ret[0].(*models.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTwoFactor indicates an expected call of VerifyTwoFactor.
+func (mr *MockAuthServiceMockRecorder) VerifyTwoFactor(ctx, req, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTwoFactor", reflect.TypeOf((*MockAuthService)(nil).VerifyTwoFactor), ctx, req, client)
+}
+
+// MockTwoFactorRepository is a mock of TwoFactorRepository interface.
+type MockTwoFactorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTwoFactorRepositoryMockRecorder is the mock recorder for MockTwoFactorRepository.
+type MockTwoFactorRepositoryMockRecorder struct {
+	mock *MockTwoFactorRepository
+}
+
+// NewMockTwoFactorRepository creates a new mock instance.
+func NewMockTwoFactorRepository(ctrl *gomock.Controller) *MockTwoFactorRepository {
+	mock := &MockTwoFactorRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorRepository) EXPECT() *MockTwoFactorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeRecoveryCode mocks base method.
+func (m *MockTwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeRecoveryCode indicates an expected call of ConsumeRecoveryCode.
+func (mr *MockTwoFactorRepositoryMockRecorder) ConsumeRecoveryCode(ctx, userID, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeRecoveryCode", reflect.TypeOf((*MockTwoFactorRepository)(nil).ConsumeRecoveryCode), ctx, userID, codeHash)
+}
+
+// ConsumeStep mocks base method.
+func (m *MockTwoFactorRepository) ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeStep indicates an expected call of ConsumeStep.
+func (mr *MockTwoFactorRepositoryMockRecorder) ConsumeStep(ctx, userID, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeStep", reflect.TypeOf((*MockTwoFactorRepository)(nil).ConsumeStep), ctx, userID, step)
+}
+
+// CountRecoveryCodes mocks base method.
+func (m *MockTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecoveryCodes indicates an expected call of CountRecoveryCodes.
+func (mr *MockTwoFactorRepositoryMockRecorder) CountRecoveryCodes(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecoveryCodes", reflect.TypeOf((*MockTwoFactorRepository)(nil).CountRecoveryCodes), ctx, userID)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactorRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorRepositoryMockRecorder) Disable(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactorRepository)(nil).Disable), ctx, userID)
+}
+
+// Enable mocks base method.
+func (m *MockTwoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, userID, step, codeHashes)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTwoFactorRepositoryMockRecorder) Enable(ctx, userID, step, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTwoFactorRepository)(nil).Enable), ctx, userID, step, codeHashes)
+}
+
+// GetByUser mocks base method.
+func (m *MockTwoFactorRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*entity.UserTwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUser", ctx, userID)
+	ret0, _ := ret[0].(*entity.UserTwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUser indicates an expected call of GetByUser.
+func (mr *MockTwoFactorRepositoryMockRecorder) GetByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUser", reflect.TypeOf((*MockTwoFactorRepository)(nil).GetByUser), ctx, userID)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockTwoFactorRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockTwoFactorRepository)(nil).ReplaceRecoveryCodes), ctx, userID, codeHashes)
+}
+
+// SavePending mocks base method.
+func (m *MockTwoFactorRepository) SavePending(ctx context.Context, tf *entity.UserTwoFactor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePending", ctx, tf)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePending indicates an expected call of SavePending.
+func (mr *MockTwoFactorRepositoryMockRecorder) SavePending(ctx, tf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePending", reflect.TypeOf((*MockTwoFactorRepository)(nil).SavePending), ctx, tf)
+}
+
+// MockTwoFactorService is a mock of TwoFactorService interface.
+type MockTwoFactorService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockTwoFactorServiceMockRecorder is the mock recorder for MockTwoFactorService.
+type MockTwoFactorServiceMockRecorder struct {
+	mock *MockTwoFactorService
+}
+
+// NewMockTwoFactorService creates a new mock instance.
+func NewMockTwoFactorService(ctrl *gomock.Controller) *MockTwoFactorService {
+	mock := &MockTwoFactorService{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorService) EXPECT() *MockTwoFactorServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginEnrollment mocks base method.
+func (m *MockTwoFactorService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginEnrollment", ctx, userID)
+	ret0, _ := ret[0].(*models.TwoFactorSetupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginEnrollment indicates an expected call of BeginEnrollment.
+func (mr *MockTwoFactorServiceMockRecorder) BeginEnrollment(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginEnrollment", reflect.TypeOf((*MockTwoFactorService)(nil).BeginEnrollment), ctx, userID)
+}
+
+// ConfirmEnrollment mocks base method.
+func (m *MockTwoFactorService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEnrollment", ctx, userID, code)
+	ret0, _ := ret[0].(*models.RecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEnrollment indicates an expected call of ConfirmEnrollment.
+func (mr *MockTwoFactorServiceMockRecorder) ConfirmEnrollment(ctx, userID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEnrollment", reflect.TypeOf((*MockTwoFactorService)(nil).ConfirmEnrollment), ctx, userID, code)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactorService) Disable(ctx context.Context, userID uuid.UUID, req models.TwoFactorDisableRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorServiceMockRecorder) Disable(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactorService)(nil).Disable), ctx, userID, req)
+}
+
+// GetPolicy mocks base method.
+func (m *MockTwoFactorService) GetPolicy(ctx context.Context, orgID uuid.UUID) (*models.TwoFactorPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicy", ctx, orgID)
+	ret0, _ := ret[0].(*models.TwoFactorPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicy indicates an expected call of GetPolicy.
+func (mr *MockTwoFactorServiceMockRecorder) GetPolicy(ctx, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockTwoFactorService)(nil).GetPolicy), ctx, orgID)
+}
+
+// GetStatus mocks base method.
+func (m *MockTwoFactorService) GetStatus(ctx context.Context, userID uuid.UUID) (*models.TwoFactorStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatus", ctx, userID)
+	ret0, _ := ret[0].(*models.TwoFactorStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus.
+func (mr *MockTwoFactorServiceMockRecorder) GetStatus(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockTwoFactorService)(nil).GetStatus), ctx, userID)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userID, code)
+	ret0, _ := ret[0].(*models.RecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockTwoFactorServiceMockRecorder) RegenerateRecoveryCodes(ctx, userID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockTwoFactorService)(nil).RegenerateRecoveryCodes), ctx, userID, code)
+}
+
+// UpdatePolicy mocks base method.
+func (m *MockTwoFactorService) UpdatePolicy(ctx context.Context, orgID, userID uuid.UUID, req models.TwoFactorPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePolicy", ctx, orgID, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePolicy indicates an expected call of UpdatePolicy.
+func (mr *MockTwoFactorServiceMockRecorder) UpdatePolicy(ctx, orgID, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePolicy", reflect.TypeOf((*MockTwoFactorService)(nil).UpdatePolicy), ctx, orgID, userID, req)
+}
+
+// VerifyCode mocks base method.
+func (m *MockTwoFactorService) VerifyCode(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCode", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyCode indicates an expected call of VerifyCode.
+func (mr *MockTwoFactorServiceMockRecorder) VerifyCode(ctx, userID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCode", reflect.TypeOf((*MockTwoFactorService)(nil).VerifyCode), ctx, userID, code)
+}
+
+// MockTXTResolver is a mock of TXTResolver interface.
+type MockTXTResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockTXTResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockTXTResolverMockRecorder is the mock recorder for MockTXTResolver.
+type MockTXTResolverMockRecorder struct {
+	mock *MockTXTResolver
+}
+
+// NewMockTXTResolver creates a new mock instance.
+func NewMockTXTResolver(ctrl *gomock.Controller) *MockTXTResolver {
+	mock := &MockTXTResolver{ctrl: ctrl}
+	mock.recorder = &MockTXTResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTXTResolver) EXPECT() *MockTXTResolverMockRecorder {
+	return m.recorder
+}
+
+// LookupTXT mocks base method.
+func (m *MockTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTXT", ctx, name)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupTXT indicates an expected call of LookupTXT.
+func (mr *MockTXTResolverMockRecorder) LookupTXT(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTXT", reflect.TypeOf((*MockTXTResolver)(nil).LookupTXT), ctx, name)
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"encoding/hex"
+	"inspacemap/backend/pkg/msgpack"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Vektor byte diambil dari spesifikasi MessagePack (https://github.com/msgpack/msgpack/blob/master/spec.md),
+// di setiap batas format, supaya encoder & decoder diuji terhadap referensi, bukan terhadap satu sama lain.
+func TestMsgpack_IntegerFormatsMatchSpec(t *testing.T) {
+	for _, tc := range []struct {
+		value int64
+		hex   string
+		want  any // Hasil Decode: uint untuk format unsigned, int untuk fixint & signed
+	}{
+		{0, "00", int64(0)},
+		{127, "7f", int64(127)},              // positive fixint
+		{128, "cc80", uint64(128)},           // uint 8
+		{255, "ccff", uint64(255)},           // uint 8
+		{256, "cd0100", uint64(256)},         // uint 16
+		{65535, "cdffff", uint64(65535)},     // uint 16
+		{65536, "ce00010000", uint64(65536)}, // uint 32
+		{math.MaxUint32 + 1, "cf0000000100000000", uint64(math.MaxUint32 + 1)}, // uint 64
+		{-1, "ff", int64(-1)},                                       // negative fixint
+		{-32, "e0", int64(-32)},                                     // negative fixint
+		{-33, "d0df", int64(-33)},                                   // int 8
+		{-128, "d080", int64(-128)},                                 // int 8
+		{-129, "d1ff7f", int64(-129)},                               // int 16
+		{-32769, "d2ffff7fff", int64(-32769)},                       // int 32
+		{math.MinInt64, "d38000000000000000", int64(math.MinInt64)}, // int 64
+	} {
+		enc := msgpack.NewEncoder(0)
+		enc.WriteInt(tc.value)
+		assert.Equal(t, tc.hex, hex.EncodeToString(enc.Bytes()), "encode %d", tc.value)
+
+		decoded, err := msgpack.Decode(enc.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, decoded, "decode %d", tc.value)
+	}
+}
+
+func TestMsgpack_StringAndContainerHeadersMatchSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		write  func(*msgpack.Encoder)
+		prefix string
+	}{
+		{"fixstr", func(e *msgpack.Encoder) { e.WriteString(strings.Repeat("a", 31)) }, "bf"},
+		{"str 8", func(e *msgpack.Encoder) { e.WriteString(strings.Repeat("a", 32)) }, "d920"},
+		{"str 16", func(e *msgpack.Encoder) { e.WriteString(strings.Repeat("a", 256)) }, "da0100"},
+		{"str 32", func(e *msgpack.Encoder) { e.WriteString(strings.Repeat("a", 65536)) }, "db00010000"},
+		{"bin 8", func(e *msgpack.Encoder) { e.WriteBinary([]byte{1, 2}) }, "c40201"},
+		{"bin 16", func(e *msgpack.Encoder) { e.WriteBinary(make([]byte, 256)) }, "c50100"},
+		{"fixarray", func(e *msgpack.Encoder) { e.WriteArrayHeader(15) }, "9f"},
+		{"array 16", func(e *msgpack.Encoder) { e.WriteArrayHeader(16) }, "dc0010"},
+		{"array 32", func(e *msgpack.Encoder) { e.WriteArrayHeader(65536) }, "dd00010000"},
+		{"fixmap", func(e *msgpack.Encoder) { e.WriteMapHeader(15) }, "8f"},
+		{"map 16", func(e *msgpack.Encoder) { e.WriteMapHeader(16) }, "de0010"},
+		{"nil", func(e *msgpack.Encoder) { e.WriteNil() }, "c0"},
+		{"false", func(e *msgpack.Encoder) { e.WriteBool(false) }, "c2"},
+		{"true", func(e *msgpack.Encoder) { e.WriteBool(true) }, "c3"},
+		{"float 32", func(e *msgpack.Encoder) { e.WriteFloat32(1.5) }, "ca3fc00000"},
+		{"float 64", func(e *msgpack.Encoder) { e.WriteFloat64(1.5) }, "cb3ff8000000000000"},
+	} {
+		enc := msgpack.NewEncoder(0)
+		tc.write(enc)
+		assert.True(t, strings.HasPrefix(hex.EncodeToString(enc.Bytes()), tc.prefix), tc.name)
+	}
+}
+
+func TestMsgpack_RoundTripNestedValues(t *testing.T) {
+	enc := msgpack.NewEncoder(64)
+	enc.WriteMapHeader(3)
+	enc.WriteString("name")
+	enc.WriteString(strings.Repeat("x", 300))
+	enc.WriteString("items")
+	enc.WriteArrayHeader(20)
+	for i := 0; i < 20; i++ {
+		enc.WriteInt(int64(i * -1000))
+	}
+	enc.WriteString("blob")
+	enc.WriteBinary([]byte{0xde, 0xad})
+
+	decoded, err := msgpack.Decode(enc.Bytes())
+	require.NoError(t, err)
+	root := decoded.(map[string]any)
+	assert.Equal(t, strings.Repeat("x", 300), root["name"])
+	items := root["items"].([]any)
+	require.Len(t, items, 20)
+	assert.Equal(t, int64(-19000), items[19])
+	assert.Equal(t, []byte{0xde, 0xad}, root["blob"])
+}
+
+func TestMsgpack_DecodeRejectsMalformedInput(t *testing.T) {
+	for name, input := range map[string]string{
+		"truncated uint16":   "cd01",
+		"truncated string":   "a36162",
+		"trailing bytes":     "c0c0",
+		"reserved type 0xc1": "c1",
+		"non-string map key": "810102",
+		"oversized array":    "dd7fffffff", // Panjang dari header tidak boleh dialokasikan sebelum datanya ada
+		"empty":              "",
+	} {
+		data, err := hex.DecodeString(input)
+		require.NoError(t, err, name)
+		_, err = msgpack.Decode(data)
+		assert.Error(t, err, name)
+	}
+}
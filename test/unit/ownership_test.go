@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var testOrgID = uuid.MustParse("0b9a6f0e-6a7c-4c55-9d36-2f4f8f1f0a01")
+
+// orgContext: Context request yang sudah melewati middleware auth organisasi test
+func orgContext() context.Context {
+	return utils.WithOrgID(context.Background(), testOrgID)
+}
+
+// ownedResources: Semua resource dianggap milik organisasi test
+func ownedResources(ctrl *gomock.Controller) *MockOwnershipRepository {
+	m := NewMockOwnershipRepository(ctrl)
+	m.EXPECT().VenueBelongsTo(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	m.EXPECT().FloorBelongsTo(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	m.EXPECT().NodeBelongsTo(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	m.EXPECT().AreaBelongsTo(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	m.EXPECT().MediaBelongsTo(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	return m
+}
+
+// Repository lain tidak boleh disentuh jika resource milik organisasi lain (mock tanpa expectation)
+func TestOwnership_ForeignResourcesAreNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ownership := NewMockOwnershipRepository(ctrl)
+	ctx := orgContext()
+	foreignID := uuid.New()
+
+	graphSvc := service.NewGraphService(NewMockGraphRepository(ctrl), NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), NewMockVenueRepository(ctrl), ownership, newManifestCache(), nil)
+	mediaSvc := service.NewMediaService(NewMockMediaAssetRepository(ctrl), ownership, NewMockStorageProvider(ctrl), "test-bucket", "https://cdn.example.com", nil)
+	areaSvc := service.NewAreaService(NewMockAreaRepository(ctrl), NewMockAreaGalleryRepository(ctrl), NewMockGraphRepository(ctrl), NewMockVenueRepository(ctrl), ownership, nil)
+	areaGallerySvc := service.NewAreaGalleryService(NewMockAreaGalleryRepository(ctrl), ownership)
+	venueGallerySvc := service.NewVenueGalleryService(NewMockVenueGalleryRepository(ctrl), ownership)
+
+	ownership.EXPECT().NodeBelongsTo(ctx, testOrgID, foreignID).Return(false, nil).AnyTimes()
+	ownership.EXPECT().MediaBelongsTo(ctx, testOrgID, foreignID).Return(false, nil).AnyTimes()
+	ownership.EXPECT().AreaBelongsTo(ctx, testOrgID, foreignID).Return(false, nil).AnyTimes()
+	ownership.EXPECT().VenueBelongsTo(ctx, testOrgID, foreignID).Return(false, nil).AnyTimes()
+
+	cases := map[string]error{
+		"DeleteNode":            graphSvc.DeleteNode(ctx, foreignID),
+		"DeleteAsset":           mediaSvc.DeleteAsset(ctx, foreignID),
+		"UpdateArea":            areaSvc.UpdateArea(ctx, foreignID, models.CreateAreaRequest{Name: "Lobby"}),
+		"RemoveAreaGalleryItem": areaGallerySvc.RemoveGalleryItem(ctx, foreignID, uuid.New()),
+		"ReorderVenueGallery":   venueGallerySvc.ReorderGallery(ctx, models.ReorderVenueGalleryRequest{VenueID: foreignID}),
+	}
+	for name, err := range cases {
+		assert.ErrorIs(t, err, service.ErrNotFound, name)
+	}
+
+	_, err := mediaSvc.GetAsset(ctx, foreignID)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.ErrorIs(t, graphSvc.PublishChanges(ctx, foreignID, models.PublishDraftRequest{}), service.ErrVenueNotFound)
+}
+
+func TestOwnership_RejectsContextWithoutOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	graphSvc := service.NewGraphService(NewMockGraphRepository(ctrl), NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), NewMockVenueRepository(ctrl), NewMockOwnershipRepository(ctrl), newManifestCache(), nil)
+
+	err := graphSvc.DeleteNode(context.Background(), uuid.New())
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestOwnership_ConnectNodesChecksBothEnds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ownership := NewMockOwnershipRepository(ctrl)
+	graphSvc := service.NewGraphService(NewMockGraphRepository(ctrl), NewMockGraphRevisionRepository(ctrl), NewMockFloorRepository(ctrl), NewMockVenueRepository(ctrl), ownership, newManifestCache(), nil)
+	ctx := orgContext()
+	ownNode, foreignNode := uuid.New(), uuid.New()
+
+	ownership.EXPECT().NodeBelongsTo(ctx, testOrgID, ownNode).Return(true, nil)
+	ownership.EXPECT().NodeBelongsTo(ctx, testOrgID, foreignNode).Return(false, nil)
+
+	err := graphSvc.ConnectNodes(ctx, models.ConnectNodesRequest{FromNodeID: ownNode, ToNodeID: foreignNode})
+
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestOwnership_RepositoryErrorIsNotMaskedAsNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ownership := NewMockOwnershipRepository(ctrl)
+	mediaSvc := service.NewMediaService(NewMockMediaAssetRepository(ctrl), ownership, NewMockStorageProvider(ctrl), "test-bucket", "https://cdn.example.com", nil)
+
+	ownership.EXPECT().MediaBelongsTo(gomock.Any(), testOrgID, gomock.Any()).Return(false, errors.New("db down"))
+
+	err := mediaSvc.DeleteAsset(orgContext(), uuid.New())
+
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, service.ErrNotFound))
+}
+
+func TestOwnership_ListAssetsForcesCallerOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mediaRepo := NewMockMediaAssetRepository(ctrl)
+	mediaSvc := service.NewMediaService(mediaRepo, ownedResources(ctrl), NewMockStorageProvider(ctrl), "test-bucket", "https://cdn.example.com", nil)
+	otherOrg := uuid.New()
+
+	mediaRepo.EXPECT().PagedMediaAssets(gomock.Any(), gomock.Cond(func(q models.MediaAssetQuery) bool {
+		return q.OrganizationID != nil && *q.OrganizationID == testOrgID
+	})).Return(nil, int64(0), nil)
+
+	_, _, err := mediaSvc.ListAssets(orgContext(), models.MediaAssetQuery{MediaAssetFilter: models.MediaAssetFilter{OrganizationID: &otherOrg}})
+	assert.NoError(t, err)
+
+	_, _, err = mediaSvc.ListAssets(context.Background(), models.MediaAssetQuery{})
+	assert.Error(t, err)
+}
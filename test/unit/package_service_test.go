@@ -0,0 +1,219 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type VenuePackageServiceTestSuite struct {
+	suite.Suite
+	ctrl            *gomock.Controller
+	venueRepo       *MockVenueRepository
+	areaRepo        *MockAreaRepository
+	areaGalleryRepo *MockAreaGalleryRepository
+	storage         *MockStorageProvider
+	service         service.VenuePackageService
+}
+
+func (suite *VenuePackageServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.venueRepo = NewMockVenueRepository(suite.ctrl)
+	suite.areaRepo = NewMockAreaRepository(suite.ctrl)
+	suite.areaGalleryRepo = NewMockAreaGalleryRepository(suite.ctrl)
+	suite.storage = NewMockStorageProvider(suite.ctrl)
+	suite.service = service.NewVenuePackageService(suite.venueRepo, suite.areaRepo, suite.areaGalleryRepo, suite.storage, "test-bucket", "https://cdn.example.com")
+}
+
+func (suite *VenuePackageServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestVenuePackageServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(VenuePackageServiceTestSuite))
+}
+
+func packageAsset(key string) *entity.MediaAsset {
+	return &entity.MediaAsset{
+		BaseEntity:   entity.BaseEntity{ID: uuid.New()},
+		Bucket:       "test-bucket",
+		Key:          key,
+		PublicURL:    "https://cdn.example.com/" + key,
+		ThumbnailURL: "https://cdn.example.com/thumb/" + key,
+		Checksum:     "upload-checksum",
+	}
+}
+
+func (suite *VenuePackageServiceTestSuite) TestRequestPackage_BuildsAndUploadsZip() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+	mapImage := packageAsset("org/map/lobby.png")
+	panorama := packageAsset("org/panorama/node.jpg")
+	venue.LiveRevision.Floors[0].MapImage = mapImage
+	venue.LiveRevision.Floors[0].Nodes[0].Panorama = panorama
+	area := entity.Area{BaseEntity: entity.BaseEntity{ID: uuid.New()}, VenueID: venue.ID, Name: "Atrium"}
+
+	objects := map[string]string{
+		mapImage.Key:            "map-bytes",
+		"thumb/" + panorama.Key: "preview-bytes",
+		panorama.Key:            "full-panorama-bytes",
+	}
+	uploaded := make(chan []byte, 1)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(gomock.Any(), venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.storage.EXPECT().ObjectExists(gomock.Any(), "test-bucket", gomock.Any()).Return(false, nil)
+	suite.venueRepo.EXPECT().GetByID(gomock.Any(), venue.ID).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetRevisionManifestData(gomock.Any(), venue.ID, venue.LiveRevisionID).Return(venue.LiveRevision, nil)
+	suite.areaRepo.EXPECT().GetByVenueID(gomock.Any(), venue.ID).Return([]entity.Area{area}, nil)
+	suite.areaGalleryRepo.EXPECT().GetByVenueID(gomock.Any(), venue.ID).Return(nil, nil)
+	suite.storage.EXPECT().GetObject(gomock.Any(), "test-bucket", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, key string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(objects[key])), nil
+		}).Times(2)
+	suite.storage.EXPECT().PutObject(gomock.Any(), "test-bucket", gomock.Any(), "application/zip", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, key, _ string, body io.Reader, size int64) error {
+			assert.Contains(suite.T(), key, venue.LiveRevisionID.String())
+			data, err := io.ReadAll(body)
+			assert.NoError(suite.T(), err)
+			assert.Equal(suite.T(), size, int64(len(data)))
+			uploaded <- data
+			return nil
+		})
+
+	status, err := suite.service.RequestPackage(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{}, models.PackagePanoramaPreview)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.PackageStatusBuilding, status.Status)
+
+	var data []byte
+	select {
+	case data = <-uploaded:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("package was not uploaded")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(suite.T(), err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(suite.T(), err)
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	mapPath := "media/maps/" + mapImage.ID.String() + ".png"
+	panoPath := "media/panoramas/" + panorama.ID.String() + "-preview.jpg"
+	assert.Equal(suite.T(), "map-bytes", string(files[mapPath]))
+	assert.Equal(suite.T(), "preview-bytes", string(files[panoPath]))
+
+	// Manifest di dalam paket menunjuk ke file lokal
+	var manifest models.ManifestResponse
+	require.NoError(suite.T(), json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(suite.T(), mapPath, manifest.Floors[0].MapImageURL)
+	assert.Equal(suite.T(), panoPath, manifest.Floors[0].Nodes[0].PanoramaURL)
+
+	var areas []models.PackageArea
+	require.NoError(suite.T(), json.Unmarshal(files["areas.json"], &areas))
+	assert.Equal(suite.T(), "Atrium", areas[0].Name)
+
+	// package.json berisi checksum setiap file
+	var contents models.PackageContents
+	require.NoError(suite.T(), json.Unmarshal(files["package.json"], &contents))
+	assert.Equal(suite.T(), venue.LiveRevisionID, contents.RevisionID)
+	require.Len(suite.T(), contents.Files, 5) // map, panorama, manifest, venue, areas
+	for _, f := range contents.Files {
+		sum := sha256.Sum256(files[f.Path])
+		assert.Equal(suite.T(), hex.EncodeToString(sum[:]), f.SHA256, f.Path)
+	}
+	assert.Equal(suite.T(), "upload-checksum", contents.Files[0].Checksum)
+	assert.Empty(suite.T(), contents.Files[1].Checksum) // preview bukan file asli
+}
+
+func (suite *VenuePackageServiceTestSuite) TestRequestPackage_ReusesStoredPackage() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.storage.EXPECT().ObjectExists(ctx, "test-bucket", "packages/"+venue.ID.String()+"/"+venue.LiveRevisionID.String()+"-full.zip").Return(true, nil)
+	suite.storage.EXPECT().GetPresignedGetURL(ctx, "test-bucket", gomock.Any(), gomock.Any()).Return("https://minio.example.com/package.zip", nil)
+
+	status, err := suite.service.RequestPackage(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{}, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.PackageStatusReady, status.Status)
+	assert.Equal(suite.T(), "https://minio.example.com/package.zip", status.DownloadURL)
+	assert.NotNil(suite.T(), status.ExpiresAt)
+}
+
+func (suite *VenuePackageServiceTestSuite) TestRequestPackage_FailedBuildReportedOnce() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+	failed := make(chan struct{})
+
+	suite.venueRepo.EXPECT().GetManifestInfo(gomock.Any(), venue.Organization.Slug, venue.Slug).Return(venue, nil).AnyTimes()
+	gomock.InOrder(
+		suite.storage.EXPECT().ObjectExists(gomock.Any(), "test-bucket", gomock.Any()).Return(false, nil),
+		// Setelah kegagalan dilaporkan, request berikutnya kembali mengecek storage
+		suite.storage.EXPECT().ObjectExists(gomock.Any(), "test-bucket", gomock.Any()).Return(true, nil),
+	)
+	suite.storage.EXPECT().GetPresignedGetURL(gomock.Any(), "test-bucket", gomock.Any(), gomock.Any()).Return("https://minio.example.com/package.zip", nil)
+	suite.venueRepo.EXPECT().GetByID(gomock.Any(), venue.ID).DoAndReturn(
+		func(context.Context, uuid.UUID) (*entity.Venue, error) {
+			defer close(failed)
+			return nil, errors.New("db down")
+		})
+
+	_, err := suite.service.RequestPackage(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{}, models.PackagePanoramaNone)
+	require.NoError(suite.T(), err)
+	<-failed
+
+	var status *models.VenuePackageStatus
+	assert.Eventually(suite.T(), func() bool {
+		status, err = suite.service.RequestPackage(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{}, models.PackagePanoramaNone)
+		return err == nil && status.Status == models.PackageStatusFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(suite.T(), status.Error, "db down")
+
+	status, err = suite.service.RequestPackage(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{}, models.PackagePanoramaNone)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.PackageStatusReady, status.Status)
+}
+
+func (suite *VenuePackageServiceTestSuite) TestRequestPackage_InvalidResolution() {
+	status, err := suite.service.RequestPackage(context.Background(), "any", "any", models.ManifestAccess{}, "8k")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidPanoramaResolution)
+	assert.Nil(suite.T(), status)
+}
+
+func (suite *VenuePackageServiceTestSuite) TestRequestPackage_PrivateVenueHidden() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPrivate)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	status, err := suite.service.RequestPackage(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{}, "")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), status)
+}
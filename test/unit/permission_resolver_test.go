@@ -0,0 +1,220 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/delivery/http/route"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/cache"
+	"inspacemap/backend/pkg/utils"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newPermissionCache() *cache.LRU[*models.ResolvedPermissions] {
+	return cache.NewLRU[*models.ResolvedPermissions](16, time.Minute)
+}
+
+func TestPermissionResolver_CachesUntilInvalidated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	resolver := service.NewPermissionResolver(memberRepo, newPermissionCache())
+	ctx := context.Background()
+	userID, orgID := uuid.New(), uuid.New()
+
+	editor := &entity.OrganizationMember{Role: entity.Role{Permissions: []entity.Permission{{Key: string(entity.PermGraphEdit)}}}}
+	viewer := &entity.OrganizationMember{Role: entity.Role{}}
+	gomock.InOrder(
+		memberRepo.EXPECT().FindMember(ctx, orgID, userID).Return(editor, nil),
+		memberRepo.EXPECT().FindMember(ctx, orgID, userID).Return(viewer, nil),
+	)
+
+	perms, isMember, err := resolver.ResolvePermissions(ctx, userID, orgID)
+	require.NoError(t, err)
+	assert.True(t, isMember)
+	assert.Equal(t, []string{string(entity.PermGraphEdit)}, perms)
+
+	// Cache hit: repository tidak dipanggil lagi
+	perms, _, _ = resolver.ResolvePermissions(ctx, userID, orgID)
+	assert.Len(t, perms, 1)
+
+	resolver.InvalidateMember(orgID, userID)
+	perms, isMember, err = resolver.ResolvePermissions(ctx, userID, orgID)
+	require.NoError(t, err)
+	assert.True(t, isMember)
+	assert.Empty(t, perms)
+}
+
+func TestPermissionResolver_NonMemberAndErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	resolver := service.NewPermissionResolver(memberRepo, newPermissionCache())
+	ctx := context.Background()
+	userID, removedFrom, broken := uuid.New(), uuid.New(), uuid.New()
+
+	memberRepo.EXPECT().FindMember(ctx, removedFrom, userID).Return(nil, nil).Times(1)
+	memberRepo.EXPECT().FindMember(ctx, broken, userID).Return(nil, errors.New("db down")).Times(2)
+
+	for i := 0; i < 2; i++ {
+		perms, isMember, err := resolver.ResolvePermissions(ctx, userID, removedFrom)
+		require.NoError(t, err)
+		assert.False(t, isMember)
+		assert.Empty(t, perms)
+	}
+
+	// Error database tidak di-cache
+	for i := 0; i < 2; i++ {
+		_, _, err := resolver.ResolvePermissions(ctx, userID, broken)
+		assert.Error(t, err)
+	}
+}
+
+func TestPermissionResolver_InvalidateOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	resolver := service.NewPermissionResolver(memberRepo, newPermissionCache())
+	ctx := context.Background()
+	orgID, otherOrg, alice, bob := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	memberRepo.EXPECT().FindMember(ctx, orgID, alice).Return(&entity.OrganizationMember{}, nil).Times(2)
+	memberRepo.EXPECT().FindMember(ctx, orgID, bob).Return(&entity.OrganizationMember{}, nil).Times(2)
+	memberRepo.EXPECT().FindMember(ctx, otherOrg, alice).Return(&entity.OrganizationMember{}, nil).Times(1)
+
+	for pass := 0; pass < 2; pass++ {
+		for _, u := range []uuid.UUID{alice, bob} {
+			_, _, err := resolver.ResolvePermissions(ctx, u, orgID)
+			require.NoError(t, err, "pass %d", pass)
+		}
+		_, _, err := resolver.ResolvePermissions(ctx, alice, otherOrg)
+		require.NoError(t, err)
+		resolver.InvalidateOrganization(orgID)
+	}
+}
+
+func TestTeamService_RoleChangesInvalidatePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	roleRepo := NewMockRoleRepository(ctrl)
+	resolver := NewMockPermissionResolver(ctrl)
+	svc := service.NewTeamService(NewMockUserRepository(ctrl), NewMockUserInvitationRepository(ctrl), memberRepo, roleRepo, NewMockOrganizationRepository(ctrl), resolver, NewMockMailer(ctrl), "", nil)
+	ctx := context.Background()
+	orgID, userID := uuid.New(), uuid.New()
+	viewer := &entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Viewer"}
+	editor := entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Editor"}
+
+	roleRepo.EXPECT().GetByID(ctx, viewer.ID).Return(viewer, nil)
+	roleRepo.EXPECT().GetPermissions(ctx, viewer.ID).Return(nil, nil) // Viewer hanya baca
+	roleRepo.EXPECT().GetPermissions(ctx, editor.ID).Return(nil, nil).Times(2)
+	memberRepo.EXPECT().GetMember(ctx, orgID, userID).Return(&entity.OrganizationMember{RoleID: editor.ID, Role: editor}, nil).Times(2)
+	memberRepo.EXPECT().UpdateRole(ctx, orgID, userID, viewer.ID).Return(nil)
+	memberRepo.EXPECT().RemoveMember(ctx, orgID, userID).Return(nil)
+	resolver.EXPECT().InvalidateMember(orgID, userID).Times(2)
+
+	require.NoError(t, svc.UpdateMemberRole(ctx, orgID, []string{string(entity.PermTeamManage)}, models.UpdateUserRoleRequest{TargetUserID: userID, NewRoleID: viewer.ID}))
+	require.NoError(t, svc.RemoveMember(ctx, orgID, []string{string(entity.PermTeamManage)}, userID))
+}
+
+func TestTeamService_UpdateMemberRoleRejectsUnheldPermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	roleRepo := NewMockRoleRepository(ctrl)
+	// Member & resolver tidak boleh disentuh (mock tanpa expectation)
+	svc := service.NewTeamService(NewMockUserRepository(ctrl), NewMockUserInvitationRepository(ctrl), NewMockOrganizationMemberRepository(ctrl), roleRepo, NewMockOrganizationRepository(ctrl), NewMockPermissionResolver(ctrl), NewMockMailer(ctrl), "", nil)
+	ctx := context.Background()
+	orgID := uuid.New()
+	owner := &entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Owner"}
+
+	roleRepo.EXPECT().GetByID(ctx, owner.ID).Return(owner, nil)
+	roleRepo.EXPECT().GetPermissions(ctx, owner.ID).Return([]entity.Permission{{Key: string(entity.PermTeamManage)}, {Key: string(entity.PermOrgBilling)}}, nil)
+
+	err := svc.UpdateMemberRole(ctx, orgID, []string{string(entity.PermTeamManage)}, models.UpdateUserRoleRequest{TargetUserID: uuid.New(), NewRoleID: owner.ID})
+
+	assert.ErrorIs(t, err, service.ErrPermissionNotHeld)
+}
+
+// Admin (team:manage tanpa billing) tidak boleh menurunkan atau mengeluarkan Owner
+func TestTeamService_TargetRoleAboveCallerIsRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	roleRepo := NewMockRoleRepository(ctrl)
+	// Resolver tidak boleh disentuh: tidak ada perubahan membership
+	svc := service.NewTeamService(NewMockUserRepository(ctrl), NewMockUserInvitationRepository(ctrl), memberRepo, roleRepo, NewMockOrganizationRepository(ctrl), NewMockPermissionResolver(ctrl), NewMockMailer(ctrl), "", nil)
+	ctx := context.Background()
+	orgID, ownerID := uuid.New(), uuid.New()
+	owner := entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: entity.SystemRoleOwner}
+	viewer := &entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Viewer"}
+	adminPerms := []string{string(entity.PermTeamManage)}
+
+	roleRepo.EXPECT().GetByID(ctx, viewer.ID).Return(viewer, nil)
+	roleRepo.EXPECT().GetPermissions(ctx, viewer.ID).Return(nil, nil)
+	roleRepo.EXPECT().GetPermissions(ctx, owner.ID).Return([]entity.Permission{{Key: string(entity.PermTeamManage)}, {Key: string(entity.PermOrgBilling)}}, nil).Times(2)
+	memberRepo.EXPECT().GetMember(ctx, orgID, ownerID).Return(&entity.OrganizationMember{UserID: ownerID, RoleID: owner.ID, Role: owner}, nil).Times(2)
+
+	err := svc.UpdateMemberRole(ctx, orgID, adminPerms, models.UpdateUserRoleRequest{TargetUserID: ownerID, NewRoleID: viewer.ID})
+	assert.ErrorIs(t, err, service.ErrPermissionNotHeld)
+
+	err = svc.RemoveMember(ctx, orgID, adminPerms, ownerID)
+	assert.ErrorIs(t, err, service.ErrPermissionNotHeld)
+}
+
+// Role custom bernama "Owner" bukan Owner organisasi: tidak ikut aturan minimal satu owner
+func TestTeamService_CustomRoleNamedOwnerIsNotOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	roleRepo := NewMockRoleRepository(ctrl)
+	resolver := NewMockPermissionResolver(ctrl)
+	svc := service.NewTeamService(NewMockUserRepository(ctrl), NewMockUserInvitationRepository(ctrl), memberRepo, roleRepo, NewMockOrganizationRepository(ctrl), resolver, NewMockMailer(ctrl), "", nil)
+	ctx := context.Background()
+	orgID, userID := uuid.New(), uuid.New()
+	custom := entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: &orgID, Name: "owner"}
+
+	roleRepo.EXPECT().GetPermissions(ctx, custom.ID).Return(nil, nil)
+	memberRepo.EXPECT().GetMember(ctx, orgID, userID).Return(&entity.OrganizationMember{UserID: userID, RoleID: custom.ID, Role: custom}, nil)
+	// GetMembersByOrg (cek owner tersisa) tidak dipanggil
+	memberRepo.EXPECT().RemoveMember(ctx, orgID, userID).Return(nil)
+	resolver.EXPECT().InvalidateMember(orgID, userID)
+
+	require.NoError(t, svc.RemoveMember(ctx, orgID, []string{string(entity.PermTeamManage)}, userID))
+}
+
+// Permission di token tidak lagi dipercaya: yang menentukan adalah membership saat request
+func TestRoutes_UseLivePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	resolver := NewMockPermissionResolver(ctrl)
+	app := fiber.New()
+	cfg := &route.RouteConfig{App: app, PermissionResolver: resolver}
+	cfg.Setup()
+
+	userID, orgID := uuid.New(), uuid.New()
+	token, err := utils.GenerateToken(userID, "demoted@example.com", orgID, "Owner", []string{string(entity.PermVenueCreate)}, uuid.Nil)
+	require.NoError(t, err)
+
+	send := func(method, path string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Role diturunkan setelah token terbit
+	resolver.EXPECT().ResolvePermissions(gomock.Any(), userID, orgID).Return([]string{}, true, nil)
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodPost, "/api/v1/venues"))
+
+	// Dikeluarkan dari organisasi: route member pun ditolak
+	resolver.EXPECT().ResolvePermissions(gomock.Any(), userID, orgID).Return([]string{}, false, nil)
+	assert.Equal(t, fiber.StatusForbidden, send(fiber.MethodGet, "/api/v1/venues"))
+
+	resolver.EXPECT().ResolvePermissions(gomock.Any(), userID, orgID).Return(nil, false, errors.New("db down"))
+	assert.Equal(t, fiber.StatusInternalServerError, send(fiber.MethodGet, "/api/v1/venues"))
+}
@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"context"
+	"inspacemap/backend/internal/delivery/http/middleware"
+	"inspacemap/backend/pkg/ratelimit"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := ratelimit.ParseRule("30/1m")
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Rule{Limit: 30, Window: time.Minute}, rule)
+
+	disabled, err := ratelimit.ParseRule("0")
+	require.NoError(t, err)
+	assert.False(t, disabled.Enabled())
+
+	for _, invalid := range []string{"30", "x/1m", "30/abc", "30/0s", "-1/1m"} {
+		_, err := ratelimit.ParseRule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAllow_BlocksAfterLimitUntilWindowEnds(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	rule := ratelimit.Rule{Limit: 2, Window: 30 * time.Millisecond}
+	ctx := context.Background()
+
+	first, _ := ratelimit.Allow(ctx, store, "ip:1", rule)
+	second, _ := ratelimit.Allow(ctx, store, "ip:1", rule)
+	third, _ := ratelimit.Allow(ctx, store, "ip:1", rule)
+	other, _ := ratelimit.Allow(ctx, store, "ip:2", rule)
+
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, 0, third.Remaining)
+	assert.True(t, third.RetryAfter > 0 && third.RetryAfter <= rule.Window)
+	assert.True(t, other.Allowed, "bucket per key")
+
+	time.Sleep(40 * time.Millisecond)
+	next, _ := ratelimit.Allow(ctx, store, "ip:1", rule)
+	assert.True(t, next.Allowed)
+}
+
+func TestLockout_DelayDoublesUpToMax(t *testing.T) {
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore())
+	lockout.Threshold = 2
+	lockout.BaseDelay = 20 * time.Millisecond
+	lockout.MaxDelay = 50 * time.Millisecond
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		wait, err := lockout.Attempt(ctx, "user@example.com")
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	}
+	// Di atas threshold: satu percobaan lolos lalu kunci berlipat sampai MaxDelay
+	for _, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond} {
+		wait, err := lockout.Attempt(ctx, "user@example.com")
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+
+		locked, err := lockout.Attempt(ctx, "user@example.com")
+		require.NoError(t, err)
+		assert.True(t, locked > want/2 && locked <= want, "lock %v, want about %v", locked, want)
+		time.Sleep(locked + 5*time.Millisecond)
+	}
+
+	other, _ := lockout.Check(ctx, "other@example.com")
+	assert.Zero(t, other)
+}
+
+func TestLockout_ConcurrentAttemptsCountedBeforeCheck(t *testing.T) {
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore())
+	lockout.Threshold = 3
+	ctx := context.Background()
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if wait, err := lockout.Attempt(ctx, "user@example.com"); err == nil && wait == 0 {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Threshold percobaan + satu percobaan yang memasang kunci; sisanya ditolak walau datang bersamaan
+	assert.Equal(t, int32(4), allowed.Load())
+	require.NoError(t, lockout.Reset(ctx, "user@example.com"))
+	wait, err := lockout.Attempt(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "login berhasil membuka kunci")
+}
+
+func TestRateLimitMiddleware_Returns429WithRetryAfter(t *testing.T) {
+	app := fiber.New()
+	limit := middleware.RateLimit(ratelimit.NewMemoryStore(), "test", ratelimit.Rule{Limit: 1, Window: time.Minute}, middleware.ClientIPKey)
+	app.Get("/", limit, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get(fiber.HeaderRetryAfter))
+}
+
+func TestRateLimitConfig_NilIsNoop(t *testing.T) {
+	var cfg *middleware.RateLimitConfig
+	app := fiber.New()
+	app.Get("/", cfg.AuthLimit(), cfg.LoginLockout(middleware.LoginAccountKey), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-RateLimit-Limit"))
+}
+
+func TestLoginLockout_LocksAfterFailuresAndResetsOnSuccess(t *testing.T) {
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore())
+	lockout.Threshold = 2
+
+	app := fiber.New()
+	app.Post("/login", middleware.LoginLockout(lockout, middleware.LoginAccountKey), func(c *fiber.Ctx) error {
+		if strings.Contains(string(c.Body()), `"password":"correct"`) {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid credentials"})
+	})
+	login := func(email, password string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/login", strings.NewReader(`{"email":"`+email+`","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Sukses me-reset hitungan: dua kegagalan berikutnya belum memicu kunci
+	assert.Equal(t, fiber.StatusUnauthorized, login("user@example.com", "wrong"))
+	assert.Equal(t, fiber.StatusOK, login("user@example.com", "correct"))
+	assert.Equal(t, fiber.StatusUnauthorized, login("user@example.com", "wrong"))
+	assert.Equal(t, fiber.StatusUnauthorized, login("User@Example.com", "wrong"))
+	assert.Equal(t, fiber.StatusOK, login("user@example.com", "correct"))
+
+	for i := 0; i < 3; i++ {
+		login("user@example.com", "wrong")
+	}
+	// Terkunci: password benar pun ditolak sampai kunci habis
+	assert.Equal(t, fiber.StatusTooManyRequests, login("USER@example.com", "correct"))
+	assert.Equal(t, fiber.StatusOK, login("other@example.com", "correct"))
+}
@@ -0,0 +1,313 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"inspacemap/backend/pkg/ratelimit"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// respServer: Server RESP palsu dengan balasan per perintah; balasan "" = tidak menjawab (simulasi server hang)
+type respServer struct {
+	addr  string
+	dials atomic.Int32
+
+	mu       sync.Mutex
+	commands [][]string
+	raw      []string
+}
+
+func newRESPServer(t *testing.T, reply func(args []string) string) *respServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	srv := &respServer{addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			srv.dials.Add(1)
+			go srv.serve(conn, reply)
+		}
+	}()
+	return srv
+}
+
+func (s *respServer) serve(conn net.Conn, reply func(args []string) string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, raw, err := readRESPCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.raw = append(s.raw, raw)
+		s.mu.Unlock()
+
+		if out := reply(args); out != "" {
+			conn.Write([]byte(out))
+		}
+	}
+}
+
+// snapshot: Salinan perintah (argumen & bytes mentah) yang sudah diterima
+func (s *respServer) snapshot() ([][]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.commands...), append([]string(nil), s.raw...)
+}
+
+func (s *respServer) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.commands))
+	for i, args := range s.commands {
+		names[i] = args[0]
+	}
+	return names
+}
+
+// readRESPCommand: Perintah client selalu berupa array bulk string
+func readRESPCommand(r *bufio.Reader) ([]string, string, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, "", err
+	}
+	raw := header
+	n, err := strconv.Atoi(strings.TrimSuffix(header[1:], "\r\n"))
+	if header[0] != '*' || err != nil {
+		return nil, "", errors.New("not an array")
+	}
+	args := make([]string, n)
+	for i := range args {
+		size, err := r.ReadString('\n')
+		if err != nil {
+			return nil, "", err
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(size[1:], "\r\n"))
+		if size[0] != '$' || err != nil {
+			return nil, "", errors.New("not a bulk string")
+		}
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, "", err
+		}
+		raw += size + string(arg)
+		args[i] = string(arg[:length])
+	}
+	return args, raw, nil
+}
+
+func okReply(args []string) string {
+	switch args[0] {
+	case "EVAL":
+		return "*2\r\n:1\r\n:60000\r\n"
+	case "DEL":
+		return ":1\r\n"
+	}
+	return "+OK\r\n"
+}
+
+func TestRedisStore_EncodesCommandAsBulkStringArray(t *testing.T) {
+	srv := newRESPServer(t, okReply)
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	require.NoError(t, store.Delete(context.Background(), "rl:login:a b"))
+
+	// Argumen berisi spasi tetap satu bulk string; key selalu diberi prefix
+	_, raw := srv.snapshot()
+	require.Len(t, raw, 1)
+	assert.Equal(t, "*2\r\n$3\r\nDEL\r\n$33\r\ninspacemap:ratelimit:rl:login:a b\r\n", raw[0])
+}
+
+func TestRedisStore_IncrParsesCountAndTTL(t *testing.T) {
+	srv := newRESPServer(t, func(args []string) string { return "*2\r\n:3\r\n:45000\r\n" })
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	count, ttl, err := store.Incr(context.Background(), "rl:auth:ip:1", time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, 45*time.Second, ttl)
+}
+
+func TestRedisStore_IncrSendsWindowInMilliseconds(t *testing.T) {
+	srv := newRESPServer(t, okReply)
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	_, _, err := store.Incr(context.Background(), "key", 90*time.Second)
+
+	require.NoError(t, err)
+	commands, _ := srv.snapshot()
+	args := commands[0]
+	require.Len(t, args, 5)
+	assert.Equal(t, []string{"EVAL", "1", "inspacemap:ratelimit:key", "90000"}, []string{args[0], args[2], args[3], args[4]})
+}
+
+func TestRedisStore_AuthAndSelectOncePerConnection(t *testing.T) {
+	srv := newRESPServer(t, okReply)
+	store := ratelimit.NewRedisStore(srv.addr, "s3cret", 2)
+
+	for i := 0; i < 3; i++ {
+		_, _, err := store.Get(context.Background(), "key")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), srv.dials.Load())
+	assert.Equal(t, []string{"AUTH", "SELECT", "EVAL", "EVAL", "EVAL"}, srv.names())
+	commands, _ := srv.snapshot()
+	assert.Equal(t, []string{"AUTH", "s3cret"}, commands[0])
+	assert.Equal(t, []string{"SELECT", "2"}, commands[1])
+}
+
+func TestRedisStore_AuthErrorIsReturned(t *testing.T) {
+	srv := newRESPServer(t, func(args []string) string {
+		if args[0] == "AUTH" {
+			return "-WRONGPASS invalid username-password pair\r\n"
+		}
+		return okReply(args)
+	})
+	store := ratelimit.NewRedisStore(srv.addr, "wrong", 0)
+
+	err := store.Ping(context.Background())
+	assert.ErrorContains(t, err, "WRONGPASS")
+	// Koneksi gagal auth tidak masuk pool: percobaan berikutnya dial ulang
+	assert.Error(t, store.Ping(context.Background()))
+	assert.Equal(t, int32(2), srv.dials.Load())
+	assert.NotContains(t, srv.names(), "PING")
+}
+
+func TestRedisStore_ErrorReplyDiscardsConnection(t *testing.T) {
+	var calls atomic.Int32
+	srv := newRESPServer(t, func(args []string) string {
+		if calls.Add(1) == 1 {
+			return "-BUSY Redis is busy running a script\r\n"
+		}
+		return okReply(args)
+	})
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	_, _, err := store.Incr(context.Background(), "key", time.Minute)
+	assert.ErrorContains(t, err, "BUSY")
+
+	count, ttl, err := store.Incr(context.Background(), "key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, time.Minute, ttl)
+	assert.Equal(t, int32(2), srv.dials.Load())
+}
+
+func TestRedisStore_ReusesIdleConnections(t *testing.T) {
+	srv := newRESPServer(t, okReply)
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	for i := 0; i < 10; i++ {
+		_, _, err := store.Incr(context.Background(), "key", time.Minute)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), srv.dials.Load())
+	assert.Len(t, srv.names(), 10)
+}
+
+func TestRedisStore_ConcurrentCallsUseSeparateConnections(t *testing.T) {
+	// Balasan ditahan sampai semua perintah tiba: satu koneksi tidak boleh dipakai dua request sekaligus
+	const workers = 4
+	var arrived sync.WaitGroup
+	arrived.Add(workers)
+	srv := newRESPServer(t, func(args []string) string {
+		arrived.Done()
+		arrived.Wait()
+		return okReply(args)
+	})
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	var done sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			_, _, err := store.Incr(context.Background(), "key", time.Minute)
+			assert.NoError(t, err)
+		}()
+	}
+	done.Wait()
+
+	assert.Equal(t, int32(workers), srv.dials.Load())
+}
+
+func TestRedisStore_TimesOutWhenServerStalls(t *testing.T) {
+	var calls atomic.Int32
+	srv := newRESPServer(t, func(args []string) string {
+		if calls.Add(1) == 1 {
+			return "" // Tidak pernah menjawab
+		}
+		return okReply(args)
+	})
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := store.Incr(ctx, "key", time.Minute)
+
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Koneksi yang timeout bisa berisi balasan terlambat, jadi tidak dipakai ulang
+	_, _, err = store.Incr(context.Background(), "key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), srv.dials.Load())
+}
+
+func TestRedisStore_ParsesNilAndNegativeTTL(t *testing.T) {
+	// GET untuk key yang tidak ada: nil bulk string & PTTL -2
+	srv := newRESPServer(t, func(args []string) string { return "*2\r\n$-1\r\n:-2\r\n" })
+	store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+	count, ttl, err := store.Get(context.Background(), "missing")
+
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Zero(t, ttl)
+}
+
+func TestRedisStore_RejectsMalformedReplies(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":    "?what\r\n",
+		"missing CR":      ":1\n",
+		"wrong shape":     "*1\r\n:1\r\n",
+		"bad integer":     "*2\r\n:abc\r\n:1\r\n",
+		"truncated array": "*2\r\n:1\r\n",
+	}
+	for name, reply := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := newRESPServer(t, func(args []string) string { return reply })
+			store := ratelimit.NewRedisStore(srv.addr, "", 0)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			_, _, err := store.Incr(ctx, "key", time.Minute)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package unit
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type RoleServiceTestSuite struct {
+	suite.Suite
+	ctrl     *gomock.Controller
+	roleRepo *MockRoleRepository
+	permRepo *MockPermissionRepository
+	resolver *MockPermissionResolver
+	service  service.RoleService
+
+	ctx       context.Context
+	orgID     uuid.UUID
+	owner     entity.Role
+	permEdit  entity.Permission
+	permMedia entity.Permission
+	held      []string // Permission pemanggil
+}
+
+func (suite *RoleServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.roleRepo = NewMockRoleRepository(suite.ctrl)
+	suite.permRepo = NewMockPermissionRepository(suite.ctrl)
+	suite.resolver = NewMockPermissionResolver(suite.ctrl)
+	suite.service = service.NewRoleService(suite.roleRepo, suite.permRepo, suite.resolver, nil)
+
+	suite.ctx = context.Background()
+	suite.orgID = uuid.New()
+	suite.owner = entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Owner"}
+	suite.permEdit = entity.Permission{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Key: string(entity.PermGraphEdit)}
+	suite.permMedia = entity.Permission{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Key: string(entity.PermMediaUpload)}
+	suite.held = []string{string(entity.PermGraphEdit), string(entity.PermMediaUpload), string(entity.PermTeamManage)}
+}
+
+func (suite *RoleServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestRoleServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RoleServiceTestSuite))
+}
+
+func (suite *RoleServiceTestSuite) customRole(orgID uuid.UUID, name string) *entity.Role {
+	return &entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: &orgID, Name: name}
+}
+
+func (suite *RoleServiceTestSuite) TestCreateRole_Success() {
+	suite.roleRepo.EXPECT().GetByOrganizationID(suite.ctx, suite.orgID).Return([]entity.Role{suite.owner}, nil)
+	suite.permRepo.EXPECT().GetByIDs(suite.ctx, []uuid.UUID{suite.permEdit.ID, suite.permMedia.ID}).
+		Return([]entity.Permission{suite.permEdit, suite.permMedia}, nil)
+
+	var created *entity.Role
+	suite.roleRepo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, r *entity.Role) error {
+		created = r
+		return nil
+	})
+
+	_, err := suite.service.CreateRole(suite.ctx, suite.orgID, suite.held, models.CreateRoleRequest{
+		Name:          " Mapper ",
+		PermissionIDs: []uuid.UUID{suite.permEdit.ID, suite.permMedia.ID},
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Mapper", created.Name)
+	assert.Equal(suite.T(), suite.orgID, *created.OrganizationID)
+	assert.Len(suite.T(), created.Permissions, 2)
+}
+
+func (suite *RoleServiceTestSuite) TestCreateRole_NameClashesWithBuiltIn() {
+	suite.roleRepo.EXPECT().GetByOrganizationID(suite.ctx, suite.orgID).Return([]entity.Role{suite.owner}, nil)
+
+	_, err := suite.service.CreateRole(suite.ctx, suite.orgID, suite.held, models.CreateRoleRequest{
+		Name:          "owner",
+		PermissionIDs: []uuid.UUID{suite.permEdit.ID},
+	})
+
+	assert.ErrorIs(suite.T(), err, service.ErrRoleNameTaken)
+}
+
+func (suite *RoleServiceTestSuite) TestCreateRole_UnknownPermission() {
+	unknown := uuid.New()
+	suite.roleRepo.EXPECT().GetByOrganizationID(suite.ctx, suite.orgID).Return(nil, nil)
+	suite.permRepo.EXPECT().GetByIDs(suite.ctx, []uuid.UUID{suite.permEdit.ID, unknown}).
+		Return([]entity.Permission{suite.permEdit}, nil)
+
+	_, err := suite.service.CreateRole(suite.ctx, suite.orgID, suite.held, models.CreateRoleRequest{
+		Name:          "Mapper",
+		PermissionIDs: []uuid.UUID{suite.permEdit.ID, unknown},
+	})
+
+	assert.ErrorContains(suite.T(), err, unknown.String())
+}
+
+func (suite *RoleServiceTestSuite) TestUpdateRole_ReplacesPermissions() {
+	role := suite.customRole(suite.orgID, "Mapper")
+	desc := "Edit graph only"
+	perms := []uuid.UUID{suite.permEdit.ID}
+
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, role.ID).Return(role, nil)
+	suite.permRepo.EXPECT().GetByIDs(suite.ctx, perms).Return([]entity.Permission{suite.permEdit}, nil)
+	suite.roleRepo.EXPECT().UpdateWithPermissions(suite.ctx, role, []entity.Permission{suite.permEdit}).Return(nil)
+	suite.resolver.EXPECT().InvalidateOrganization(suite.orgID)
+
+	err := suite.service.UpdateRole(suite.ctx, suite.orgID, role.ID, suite.held, models.UpdateRoleRequest{Description: &desc, PermissionIDs: &perms})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), desc, role.Description)
+}
+
+func (suite *RoleServiceTestSuite) TestCreateRole_PermissionNotHeld() {
+	suite.roleRepo.EXPECT().GetByOrganizationID(suite.ctx, suite.orgID).Return(nil, nil)
+	suite.permRepo.EXPECT().GetByIDs(suite.ctx, []uuid.UUID{suite.permEdit.ID, suite.permMedia.ID}).
+		Return([]entity.Permission{suite.permEdit, suite.permMedia}, nil)
+
+	_, err := suite.service.CreateRole(suite.ctx, suite.orgID, []string{string(entity.PermGraphEdit)}, models.CreateRoleRequest{
+		Name:          "Mapper",
+		PermissionIDs: []uuid.UUID{suite.permEdit.ID, suite.permMedia.ID},
+	})
+
+	assert.ErrorIs(suite.T(), err, service.ErrPermissionNotHeld)
+	assert.ErrorContains(suite.T(), err, string(entity.PermMediaUpload))
+}
+
+func (suite *RoleServiceTestSuite) TestUpdateRole_PermissionNotHeld() {
+	role := suite.customRole(suite.orgID, "Mapper")
+	perms := []uuid.UUID{suite.permMedia.ID}
+
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, role.ID).Return(role, nil)
+	suite.permRepo.EXPECT().GetByIDs(suite.ctx, perms).Return([]entity.Permission{suite.permMedia}, nil)
+
+	err := suite.service.UpdateRole(suite.ctx, suite.orgID, role.ID, []string{string(entity.PermGraphEdit)}, models.UpdateRoleRequest{PermissionIDs: &perms})
+
+	assert.ErrorIs(suite.T(), err, service.ErrPermissionNotHeld)
+}
+
+func (suite *RoleServiceTestSuite) TestUpdateRole_BuiltInIsProtected() {
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, suite.owner.ID).Return(&suite.owner, nil)
+	name := "Super Owner"
+
+	err := suite.service.UpdateRole(suite.ctx, suite.orgID, suite.owner.ID, suite.held, models.UpdateRoleRequest{Name: &name})
+
+	assert.ErrorIs(suite.T(), err, service.ErrRoleProtected)
+}
+
+func (suite *RoleServiceTestSuite) TestUpdateRole_ForeignOrganization() {
+	role := suite.customRole(uuid.New(), "Mapper")
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, role.ID).Return(role, nil)
+
+	err := suite.service.UpdateRole(suite.ctx, suite.orgID, role.ID, suite.held, models.UpdateRoleRequest{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrRoleNotFound)
+	assert.ErrorIs(suite.T(), err, service.ErrNotFound)
+}
+
+func (suite *RoleServiceTestSuite) TestDeleteRole_StillAssigned() {
+	role := suite.customRole(suite.orgID, "Mapper")
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, role.ID).Return(role, nil)
+	suite.roleRepo.EXPECT().CountAssignments(suite.ctx, role.ID).Return(int64(0), int64(2), nil)
+
+	err := suite.service.DeleteRole(suite.ctx, suite.orgID, role.ID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrRoleInUse)
+	assert.ErrorContains(suite.T(), err, "2 pending invitation(s)")
+}
+
+func (suite *RoleServiceTestSuite) TestDeleteRole_Success() {
+	role := suite.customRole(suite.orgID, "Mapper")
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, role.ID).Return(role, nil)
+	suite.roleRepo.EXPECT().CountAssignments(suite.ctx, role.ID).Return(int64(0), int64(0), nil)
+	suite.roleRepo.EXPECT().Delete(suite.ctx, role.ID).Return(nil)
+
+	assert.NoError(suite.T(), suite.service.DeleteRole(suite.ctx, suite.orgID, role.ID))
+}
+
+func (suite *RoleServiceTestSuite) TestListRoles_MarksBuiltIn() {
+	custom := suite.customRole(suite.orgID, "Mapper")
+	custom.Permissions = []entity.Permission{suite.permEdit}
+	suite.roleRepo.EXPECT().GetByOrganizationID(suite.ctx, suite.orgID).Return([]entity.Role{suite.owner, *custom}, nil)
+
+	roles, err := suite.service.ListRoles(suite.ctx, suite.orgID)
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), roles, 2)
+	assert.True(suite.T(), roles[0].IsSystem)
+	assert.False(suite.T(), roles[1].IsSystem)
+	assert.Equal(suite.T(), []string{string(entity.PermGraphEdit)}, roles[1].Permissions)
+}
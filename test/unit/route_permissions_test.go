@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/delivery/http/route"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupRoutes() (*fiber.App, *route.RouteConfig) {
+	app := fiber.New()
+	cfg := &route.RouteConfig{App: app}
+	cfg.Setup()
+	return app, cfg
+}
+
+// Gagal jika ada route baru yang didaftarkan langsung ke fiber tanpa menyatakan aksesnya
+func TestRoutes_EveryRouteDeclaresAccess(t *testing.T) {
+	app, cfg := setupRoutes()
+	access := cfg.RouteAccess()
+
+	routes := app.GetRoutes(true)
+	require.NotEmpty(t, routes)
+	for _, r := range routes {
+		method := r.Method
+		if method == fiber.MethodHead {
+			method = fiber.MethodGet
+		}
+		_, ok := access[method+" "+r.Path]
+		assert.True(t, ok, "route %s %s has no declared permission", r.Method, r.Path)
+	}
+}
+
+func TestRoutes_ProtectedMutationsRequirePermission(t *testing.T) {
+	_, cfg := setupRoutes()
+
+	for key, perm := range cfg.RouteAccess() {
+		method, path, _ := strings.Cut(key, " ")
+		if method == fiber.MethodGet || perm == route.AccessPublic || perm == route.AccessSelf {
+			continue
+		}
+		assert.True(t, entity.IsRegisteredPermission(perm), "%s %s must require a registry permission, got %q", method, path, perm)
+	}
+	assert.Equal(t, entity.PermVenueCreate, cfg.RouteAccess()["POST /api/v1/venues/"])
+	assert.Equal(t, entity.PermGraphPublish, cfg.RouteAccess()["POST /api/v1/editor/:venue_id/publish"])
+	assert.Equal(t, entity.PermGraphPublish, cfg.RouteAccess()["POST /api/v1/editor/:venue_id/rollback"])
+	assert.Equal(t, route.AccessSelf, cfg.RouteAccess()["DELETE /api/v1/sessions/:id"])
+}
+
+func TestRoutes_RejectsTokenWithoutPermission(t *testing.T) {
+	app, _ := setupRoutes()
+	token, err := utils.GenerateToken(uuid.New(), "viewer@example.com", uuid.New(), "Viewer", []string{string(entity.PermGraphEdit)}, uuid.Nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/venues", strings.NewReader(`{}`))
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestPermissionRegistry_UniqueKeys(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, p := range entity.PermissionRegistry {
+		assert.False(t, seen[p.Key], "duplicate permission %s", p.Key)
+		assert.NotEmpty(t, p.Group)
+		seen[p.Key] = true
+	}
+	assert.True(t, entity.IsRegisteredPermission(entity.PermTeamInvite))
+	assert.False(t, entity.IsRegisteredPermission("user:invite"))
+}
+
+func TestVerifyPermissionRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	permRepo := NewMockPermissionRepository(ctrl)
+	svc := service.NewRoleService(NewMockRoleRepository(ctrl), permRepo, NewMockPermissionResolver(ctrl), nil)
+	ctx := context.Background()
+
+	permRepo.EXPECT().GetAll(ctx).Return(append([]entity.Permission(nil), entity.PermissionRegistry...), nil)
+	assert.NoError(t, svc.VerifyPermissionRegistry(ctx))
+
+	// Seeder lama: media:delete belum ada, user:invite sudah tidak dipakai
+	var stale []entity.Permission
+	for _, p := range entity.PermissionRegistry {
+		if p.Key != string(entity.PermMediaDelete) {
+			stale = append(stale, p)
+		}
+	}
+	stale = append(stale, entity.Permission{Key: "user:invite"})
+	permRepo.EXPECT().GetAll(ctx).Return(stale, nil)
+
+	err := svc.VerifyPermissionRegistry(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "media:delete")
+	assert.Contains(t, err.Error(), "user:invite")
+
+	permRepo.EXPECT().GetAll(ctx).Return(nil, errors.New("db down"))
+	assert.Error(t, svc.VerifyPermissionRegistry(ctx))
+}
@@ -0,0 +1,358 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/oidc"
+	"inspacemap/backend/pkg/oidc/oidctest"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type SSOServiceTestSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	orgRepo      *MockOrganizationRepository
+	userRepo     *MockUserRepository
+	identityRepo *MockUserIdentityRepository
+	memberRepo   *MockOrganizationMemberRepository
+	roleRepo     *MockRoleRepository
+	resolver     *MockPermissionResolver
+	auth         *MockAuthService
+	dns          *MockTXTResolver
+	idp          *oidctest.Server
+	service      service.SSOService
+
+	ctx    context.Context
+	org    *entity.Organization
+	client models.ClientInfo
+}
+
+func (suite *SSOServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.orgRepo = NewMockOrganizationRepository(suite.ctrl)
+	suite.userRepo = NewMockUserRepository(suite.ctrl)
+	suite.identityRepo = NewMockUserIdentityRepository(suite.ctrl)
+	suite.memberRepo = NewMockOrganizationMemberRepository(suite.ctrl)
+	suite.roleRepo = NewMockRoleRepository(suite.ctrl)
+	suite.resolver = NewMockPermissionResolver(suite.ctrl)
+	suite.auth = NewMockAuthService(suite.ctrl)
+	suite.dns = NewMockTXTResolver(suite.ctrl)
+	suite.idp = oidctest.NewServer("inspacemap-app", "s3cret")
+	// IdP uji berjalan di 127.0.0.1: pakai client tanpa pembatasan alamat
+	suite.service = service.NewSSOService(suite.orgRepo, suite.userRepo, suite.identityRepo, suite.memberRepo, suite.roleRepo,
+		suite.resolver, suite.auth, oidc.NewClient(&http.Client{Timeout: 5 * time.Second}), suite.dns, "http://localhost:3000/sso/callback")
+
+	suite.ctx = context.Background()
+	suite.client = models.ClientInfo{IPAddress: "10.0.0.1", UserAgent: "test"}
+	suite.org = &entity.Organization{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Slug:       "acme",
+		IsActive:   true,
+		Settings: entity.JSONMap{"sso": map[string]interface{}{
+			"providers": []interface{}{map[string]interface{}{
+				"id": "corp", "name": "Acme Login", "issuer": suite.idp.Issuer(),
+				"client_id": "inspacemap-app", "client_secret": "s3cret",
+			}},
+			"auto_join_domains":         []interface{}{"acme.com", "partner.org"},
+			"verified_domains":          []interface{}{"acme.com"},
+			"domain_verification_token": "txt-token",
+		}},
+	}
+	suite.orgRepo.EXPECT().GetByDomain(suite.ctx, "acme").Return(suite.org, nil).AnyTimes()
+	suite.orgRepo.EXPECT().GetByID(suite.ctx, suite.org.ID).Return(suite.org, nil).AnyTimes()
+}
+
+func (suite *SSOServiceTestSuite) TearDownTest() {
+	suite.idp.Close()
+	suite.ctrl.Finish()
+}
+
+func TestSSOServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SSOServiceTestSuite))
+}
+
+// login: Alur browser lengkap: StartLogin -> provider -> CompleteLogin
+func (suite *SSOServiceTestSuite) login(user oidctest.User) (*models.AuthResponse, error) {
+	suite.idp.SetUser(user)
+	start, err := suite.service.StartLogin(suite.ctx, models.SSOStartRequest{Organization: "acme", Provider: "corp"})
+	require.NoError(suite.T(), err)
+	code, state, err := suite.idp.Authorize(start.AuthorizationURL)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), start.State, state)
+
+	return suite.service.CompleteLogin(suite.ctx, models.SSOCallbackRequest{Code: code, State: state, CodeVerifier: start.CodeVerifier}, suite.client)
+}
+
+// link: Alur penautan dari sesi login: StartLink -> provider -> CompleteLink
+func (suite *SSOServiceTestSuite) link(startUserID, completeUserID uuid.UUID, user oidctest.User) error {
+	suite.idp.SetUser(user)
+	start, err := suite.service.StartLink(suite.ctx, startUserID, models.SSOStartRequest{Organization: "acme", Provider: "corp"})
+	require.NoError(suite.T(), err)
+	code, state, err := suite.idp.Authorize(start.AuthorizationURL)
+	require.NoError(suite.T(), err)
+
+	return suite.service.CompleteLink(suite.ctx, completeUserID, models.SSOCallbackRequest{Code: code, State: state, CodeVerifier: start.CodeVerifier})
+}
+
+func (suite *SSOServiceTestSuite) expectNoIdentity(subject string) {
+	suite.identityRepo.EXPECT().GetBySubject(suite.ctx, suite.idp.Issuer(), subject).Return(nil, errors.New("record not found"))
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_ProvisionsNewUserFromAutoJoinDomain() {
+	userID := uuid.New()
+	viewer := &entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Viewer"}
+	session := &models.AuthResponse{AccessToken: "access"}
+
+	suite.expectNoIdentity("u-1")
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "jane@acme.com").Return(nil, errors.New("record not found"))
+	suite.userRepo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, u *entity.User) error {
+		assert.Equal(suite.T(), "Jane Doe", u.FullName)
+		assert.Empty(suite.T(), u.PasswordHash)
+		assert.True(suite.T(), u.IsEmailVerified)
+		u.ID = userID
+		return nil
+	})
+	suite.memberRepo.EXPECT().FindMember(suite.ctx, suite.org.ID, userID).Return(nil, nil)
+	suite.roleRepo.EXPECT().GetByName(suite.ctx, "Viewer").Return(viewer, nil)
+	suite.memberRepo.EXPECT().AddMember(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, m *entity.OrganizationMember) error {
+		assert.Equal(suite.T(), viewer.ID, m.RoleID)
+		return nil
+	})
+	suite.resolver.EXPECT().InvalidateMember(suite.org.ID, userID)
+	suite.identityRepo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, i *entity.UserIdentity) error {
+		assert.Equal(suite.T(), suite.idp.Issuer(), i.Issuer)
+		assert.Equal(suite.T(), "u-1", i.Subject)
+		return nil
+	})
+	suite.auth.EXPECT().StartSession(suite.ctx, userID, suite.org.ID, suite.client).Return(session, nil)
+
+	resp, err := suite.login(oidctest.User{Subject: "u-1", Email: "Jane@Acme.com", EmailVerified: true, Name: "Jane Doe"})
+
+	require.NoError(suite.T(), err)
+	assert.Same(suite.T(), session, resp)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_LinkedIdentity() {
+	userID := uuid.New()
+	identity := &entity.UserIdentity{UserID: userID, Issuer: suite.idp.Issuer(), Subject: "u-2"}
+
+	suite.identityRepo.EXPECT().GetBySubject(suite.ctx, suite.idp.Issuer(), "u-2").Return(identity, nil)
+	suite.userRepo.EXPECT().GetByID(suite.ctx, userID).Return(&entity.User{BaseEntity: entity.BaseEntity{ID: userID}}, nil)
+	suite.memberRepo.EXPECT().FindMember(suite.ctx, suite.org.ID, userID).Return(&entity.OrganizationMember{}, nil)
+	suite.identityRepo.EXPECT().Update(suite.ctx, identity).Return(nil)
+	suite.auth.EXPECT().StartSession(suite.ctx, userID, suite.org.ID, suite.client).Return(&models.AuthResponse{}, nil)
+
+	// Email dari partner di luar domain auto-join: identitas tertaut tetap bisa masuk sebagai anggota lama
+	_, err := suite.login(oidctest.User{Subject: "u-2", Email: "bob@partner.org", EmailVerified: true})
+
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), identity.LastLoginAt.IsZero())
+	assert.Equal(suite.T(), "bob@partner.org", identity.Email)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_ExistingEmailRequiresLink() {
+	user := &entity.User{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Email: "jane@acme.com", PasswordHash: "hash"}
+
+	suite.expectNoIdentity("u-3")
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "jane@acme.com").Return(user, nil)
+
+	// Email sama bukan bukti kepemilikan akun: tidak ada identitas, sesi, atau perubahan kredensial
+	_, err := suite.login(oidctest.User{Subject: "u-3", Email: "jane@acme.com", EmailVerified: true})
+
+	assert.ErrorIs(suite.T(), err, service.ErrSSOLinkRequired)
+	assert.Equal(suite.T(), "hash", user.PasswordHash)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_RejectsOutsiders() {
+	suite.expectNoIdentity("u-4")
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "eve@other.com").Return(nil, errors.New("record not found"))
+
+	_, err := suite.login(oidctest.User{Subject: "u-4", Email: "eve@other.com", EmailVerified: true})
+
+	assert.ErrorIs(suite.T(), err, service.ErrSSONotMember)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_UnverifiedDomainDoesNotAutoJoin() {
+	suite.expectNoIdentity("u-7")
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "mallory@partner.org").Return(nil, errors.New("record not found"))
+
+	// partner.org terdaftar sebagai auto-join tetapi record TXT-nya belum diverifikasi
+	_, err := suite.login(oidctest.User{Subject: "u-7", Email: "mallory@partner.org", EmailVerified: true})
+
+	assert.ErrorIs(suite.T(), err, service.ErrSSONotMember)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_RequiresVerifiedEmail() {
+	suite.expectNoIdentity("u-5")
+
+	_, err := suite.login(oidctest.User{Subject: "u-5", Email: "jane@acme.com", EmailVerified: false})
+
+	assert.ErrorIs(suite.T(), err, service.ErrSSOEmailNotVerified)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_RejectsForeignAudience() {
+	suite.idp.IDTokenClaims = func(claims jwt.MapClaims) { claims["aud"] = "another-app" }
+
+	_, err := suite.login(oidctest.User{Subject: "u-6", Email: "jane@acme.com", EmailVerified: true})
+
+	assert.ErrorIs(suite.T(), err, service.ErrSSOLoginFailed)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_StateBoundToVerifier() {
+	start, err := suite.service.StartLogin(suite.ctx, models.SSOStartRequest{Organization: "acme", Provider: "corp"})
+	require.NoError(suite.T(), err)
+	code, state, err := suite.idp.Authorize(start.AuthorizationURL)
+	require.NoError(suite.T(), err)
+
+	// State curian tanpa code verifier pasangannya tidak berguna
+	_, err = suite.service.CompleteLogin(suite.ctx, models.SSOCallbackRequest{Code: code, State: state, CodeVerifier: "attacker-verifier"}, suite.client)
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidSSOState)
+
+	_, err = suite.service.CompleteLogin(suite.ctx, models.SSOCallbackRequest{Code: code, State: "garbage", CodeVerifier: start.CodeVerifier}, suite.client)
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidSSOState)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLogin_RejectsLinkState() {
+	userID := uuid.New()
+	start, err := suite.service.StartLink(suite.ctx, userID, models.SSOStartRequest{Organization: "acme", Provider: "corp"})
+	require.NoError(suite.T(), err)
+	code, state, err := suite.idp.Authorize(start.AuthorizationURL)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.service.CompleteLogin(suite.ctx, models.SSOCallbackRequest{Code: code, State: state, CodeVerifier: start.CodeVerifier}, suite.client)
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidSSOState)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLink_LinksIdentityToSessionUser() {
+	userID := uuid.New()
+	suite.identityRepo.EXPECT().GetBySubject(suite.ctx, suite.idp.Issuer(), "u-8").Return(nil, errors.New("record not found"))
+	suite.identityRepo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, i *entity.UserIdentity) error {
+		assert.Equal(suite.T(), userID, i.UserID)
+		assert.Equal(suite.T(), "u-8", i.Subject)
+		assert.Equal(suite.T(), "jane@acme.com", i.Email)
+		return nil
+	})
+
+	err := suite.link(userID, userID, oidctest.User{Subject: "u-8", Email: "Jane@Acme.com", EmailVerified: true})
+
+	require.NoError(suite.T(), err)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLink_StateBoundToUser() {
+	// State penautan milik user lain (mis. dikirim lewat link) tidak bisa dipakai
+	err := suite.link(uuid.New(), uuid.New(), oidctest.User{Subject: "u-9", Email: "jane@acme.com", EmailVerified: true})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidSSOState)
+}
+
+func (suite *SSOServiceTestSuite) TestCompleteLink_IdentityOwnedByAnotherUser() {
+	userID := uuid.New()
+	suite.identityRepo.EXPECT().GetBySubject(suite.ctx, suite.idp.Issuer(), "u-10").
+		Return(&entity.UserIdentity{UserID: uuid.New(), Issuer: suite.idp.Issuer(), Subject: "u-10"}, nil)
+
+	err := suite.link(userID, userID, oidctest.User{Subject: "u-10", Email: "jane@acme.com", EmailVerified: true})
+
+	assert.ErrorIs(suite.T(), err, service.ErrSSOIdentityInUse)
+}
+
+func (suite *SSOServiceTestSuite) TestVerifyDomain_MatchingRecord() {
+	var saved *entity.Organization
+	suite.dns.EXPECT().LookupTXT(suite.ctx, "_inspacemap-verification.partner.org").
+		Return([]string{"v=spf1 -all", "inspacemap-domain-verification=txt-token"}, nil)
+	suite.orgRepo.EXPECT().Update(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, o *entity.Organization) error {
+		saved = o
+		return nil
+	})
+
+	result, err := suite.service.VerifyDomain(suite.ctx, suite.org.ID, "Partner.org")
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Verified)
+	require.NotNil(suite.T(), saved)
+	settings, err := suite.service.GetSettings(suite.ctx, suite.org.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"acme.com", "partner.org"}, settings.VerifiedDomains)
+}
+
+func (suite *SSOServiceTestSuite) TestVerifyDomain_MissingRecord() {
+	suite.dns.EXPECT().LookupTXT(suite.ctx, "_inspacemap-verification.partner.org").
+		Return(nil, &net.DNSError{Err: "no such host", Name: "_inspacemap-verification.partner.org", IsNotFound: true})
+
+	result, err := suite.service.VerifyDomain(suite.ctx, suite.org.ID, "partner.org")
+
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), result.Verified)
+	assert.Equal(suite.T(), "inspacemap-domain-verification=txt-token", result.Value)
+
+	suite.dns.EXPECT().LookupTXT(suite.ctx, gomock.Any()).Return(nil, &net.DNSError{Err: "i/o timeout", IsTimeout: true})
+	_, err = suite.service.VerifyDomain(suite.ctx, suite.org.ID, "partner.org")
+	assert.ErrorIs(suite.T(), err, service.ErrSSODomainLookupFailed)
+}
+
+func (suite *SSOServiceTestSuite) TestDefaultClientRejectsPrivateIssuer() {
+	// Client bawaan production: discovery ke 127.0.0.1 (atau metadata cloud) ditolak sebelum koneksi dibuka
+	_, err := oidc.NewClient(nil).AuthCodeURL(suite.ctx, oidc.ProviderConfig{Issuer: suite.idp.Issuer()}, "state", "nonce", "challenge")
+
+	assert.ErrorIs(suite.T(), err, oidc.ErrDiscovery)
+	assert.ErrorContains(suite.T(), err, oidc.ErrNonPublicAddress.Error())
+}
+
+func (suite *SSOServiceTestSuite) TestStartLogin_UnknownProvider() {
+	_, err := suite.service.StartLogin(suite.ctx, models.SSOStartRequest{Organization: "acme", Provider: "github"})
+
+	assert.ErrorIs(suite.T(), err, service.ErrNotFound)
+}
+
+func (suite *SSOServiceTestSuite) TestUpdateSettings_KeepsSecretAndValidates() {
+	var saved *entity.Organization
+	suite.orgRepo.EXPECT().Update(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, o *entity.Organization) error {
+		saved = o
+		return nil
+	}).Times(2)
+
+	err := suite.service.UpdateSettings(suite.ctx, suite.org.ID, models.SSOSettings{
+		Providers:       []models.SSOProvider{{ID: "corp", Issuer: suite.idp.Issuer(), ClientID: "inspacemap-app"}},
+		AutoJoinDomains: []string{" ACME.com", "acme.com", "new.io"},
+		VerifiedDomains: []string{"new.io"}, // Diisi server, bukan client
+	})
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), saved)
+
+	settings, err := suite.service.GetSettings(suite.ctx, suite.org.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"acme.com", "new.io"}, settings.AutoJoinDomains)
+	assert.Equal(suite.T(), []string{"acme.com"}, settings.VerifiedDomains)
+	assert.Equal(suite.T(), "txt-token", settings.DomainVerificationToken)
+	assert.Equal(suite.T(), "corp", settings.Providers[0].Name)
+	assert.Empty(suite.T(), settings.Providers[0].ClientSecret)
+
+	// Secret lama tetap tersimpan dan dipakai untuk token exchange
+	sso := saved.Settings["sso"].(map[string]interface{})
+	provider := sso["providers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(suite.T(), "s3cret", provider["client_secret"])
+
+	// Organisasi tanpa token TXT mendapat token baru saat domain auto-join pertama ditambahkan
+	delete(sso, "domain_verification_token")
+	require.NoError(suite.T(), suite.service.UpdateSettings(suite.ctx, suite.org.ID, models.SSOSettings{AutoJoinDomains: []string{"acme.com"}}))
+	settings, err = suite.service.GetSettings(suite.ctx, suite.org.ID)
+	require.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), settings.DomainVerificationToken)
+
+	err = suite.service.UpdateSettings(suite.ctx, suite.org.ID, models.SSOSettings{
+		Providers: []models.SSOProvider{{ID: "corp", Issuer: "http://idp.example.com", ClientID: "x"}},
+	})
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidSSOSettings)
+}
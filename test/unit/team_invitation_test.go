@@ -0,0 +1,203 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type TeamInvitationTestSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	userRepo       *MockUserRepository
+	invitationRepo *MockUserInvitationRepository
+	orgMemberRepo  *MockOrganizationMemberRepository
+	roleRepo       *MockRoleRepository
+	orgRepo        *MockOrganizationRepository
+	mailer         *MockMailer
+	service        service.TeamService
+
+	ctx          context.Context
+	org          *entity.Organization
+	editor       *entity.Role
+	inviter      *entity.User
+	inviterPerms []string
+}
+
+func (suite *TeamInvitationTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.userRepo = NewMockUserRepository(suite.ctrl)
+	suite.invitationRepo = NewMockUserInvitationRepository(suite.ctrl)
+	suite.orgMemberRepo = NewMockOrganizationMemberRepository(suite.ctrl)
+	suite.roleRepo = NewMockRoleRepository(suite.ctrl)
+	suite.orgRepo = NewMockOrganizationRepository(suite.ctrl)
+	suite.mailer = NewMockMailer(suite.ctrl)
+	suite.service = service.NewTeamService(suite.userRepo, suite.invitationRepo, suite.orgMemberRepo, suite.roleRepo,
+		suite.orgRepo, NewMockPermissionResolver(suite.ctrl), suite.mailer, "https://app.example.com", nil)
+
+	suite.ctx = context.Background()
+	suite.org = &entity.Organization{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Acme Mall"}
+	suite.editor = &entity.Role{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Name: "Editor"}
+	suite.inviter = &entity.User{BaseEntity: entity.BaseEntity{ID: uuid.New()}, FullName: "Olivia Owner"}
+	suite.inviterPerms = []string{string(entity.PermTeamInvite), string(entity.PermGraphEdit)}
+}
+
+func (suite *TeamInvitationTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestTeamInvitationTestSuite(t *testing.T) {
+	suite.Run(t, new(TeamInvitationTestSuite))
+}
+
+func (suite *TeamInvitationTestSuite) expectEditorRole() {
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, suite.editor.ID).Return(suite.editor, nil)
+	suite.roleRepo.EXPECT().GetPermissions(suite.ctx, suite.editor.ID).Return([]entity.Permission{{Key: string(entity.PermGraphEdit)}}, nil)
+}
+
+// expectInvitationEmail: Kembalikan pointer ke body teks email yang terkirim
+func (suite *TeamInvitationTestSuite) expectInvitationEmail(to string) *string {
+	var body string
+	suite.orgRepo.EXPECT().GetByID(suite.ctx, suite.org.ID).Return(suite.org, nil)
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.inviter.ID).Return(suite.inviter, nil)
+	suite.mailer.EXPECT().Send(suite.ctx, to, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, text, _ string) error {
+			body = text
+			return nil
+		})
+	return &body
+}
+
+func (suite *TeamInvitationTestSuite) pendingInvite() *entity.UserInvitation {
+	return &entity.UserInvitation{
+		BaseEntity:      entity.BaseEntity{ID: uuid.New()},
+		OrganizationID:  suite.org.ID,
+		Email:           "new@example.com",
+		RoleID:          suite.editor.ID,
+		Token:           utils.HashToken("old-token"),
+		ExpiresAt:       time.Now().Add(-time.Hour),
+		InvitedByUserID: suite.inviter.ID,
+		Status:          entity.InvitationExpired,
+	}
+}
+
+func (suite *TeamInvitationTestSuite) TestInviteMember_StoresHashAndEmailsLink() {
+	var stored *entity.UserInvitation
+	suite.expectEditorRole()
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "new@example.com").Return(nil, errors.New("record not found"))
+	// Undangan lama yang sudah kedaluwarsa tidak menghalangi
+	suite.invitationRepo.EXPECT().GetByEmail(suite.ctx, "new@example.com").Return([]entity.UserInvitation{
+		{OrganizationID: suite.org.ID, Status: entity.InvitationPending, ExpiresAt: time.Now().Add(-time.Minute)},
+	}, nil)
+	suite.invitationRepo.EXPECT().Create(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, inv *entity.UserInvitation) error {
+		stored = inv
+		return nil
+	})
+	body := suite.expectInvitationEmail("new@example.com")
+
+	err := suite.service.InviteMember(suite.ctx, suite.org.ID, suite.inviter.ID, suite.inviterPerms, models.InviteUserRequest{Email: "new@example.com", RoleID: suite.editor.ID})
+
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), *body, "Olivia Owner has invited you to join Acme Mall on InSpaceMap as Editor")
+	raw := tokenFromLink(suite.T(), *body, "/accept-invite")
+	assert.Equal(suite.T(), utils.HashToken(raw), stored.Token, "only the token hash is stored")
+	assert.Equal(suite.T(), entity.InvitationPending, stored.Status)
+}
+
+func (suite *TeamInvitationTestSuite) TestInviteMember_EmailFailureIsReported() {
+	suite.expectEditorRole()
+	suite.userRepo.EXPECT().GetByEmail(suite.ctx, "new@example.com").Return(nil, errors.New("record not found"))
+	suite.invitationRepo.EXPECT().GetByEmail(suite.ctx, "new@example.com").Return(nil, nil)
+	suite.invitationRepo.EXPECT().Create(suite.ctx, gomock.Any()).Return(nil)
+	suite.orgRepo.EXPECT().GetByID(suite.ctx, suite.org.ID).Return(suite.org, nil)
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.inviter.ID).Return(suite.inviter, nil)
+	suite.mailer.EXPECT().Send(suite.ctx, "new@example.com", gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("smtp down"))
+
+	err := suite.service.InviteMember(suite.ctx, suite.org.ID, suite.inviter.ID, suite.inviterPerms, models.InviteUserRequest{Email: "new@example.com", RoleID: suite.editor.ID})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvitationEmailFailed)
+}
+
+func (suite *TeamInvitationTestSuite) TestInviteMember_RoleAboveInviterPermissions() {
+	suite.expectEditorRole()
+
+	err := suite.service.InviteMember(suite.ctx, suite.org.ID, suite.inviter.ID, []string{string(entity.PermTeamInvite)}, models.InviteUserRequest{Email: "new@example.com", RoleID: suite.editor.ID})
+
+	assert.ErrorIs(suite.T(), err, service.ErrPermissionNotHeld)
+}
+
+func (suite *TeamInvitationTestSuite) TestResendInvitation_RegeneratesTokenAndExpiry() {
+	invite := suite.pendingInvite()
+	suite.invitationRepo.EXPECT().GetByID(suite.ctx, invite.ID).Return(invite, nil)
+	suite.roleRepo.EXPECT().GetByID(suite.ctx, suite.editor.ID).Return(suite.editor, nil)
+	suite.invitationRepo.EXPECT().Update(suite.ctx, invite).Return(nil)
+	body := suite.expectInvitationEmail(invite.Email)
+
+	err := suite.service.ResendInvitation(suite.ctx, suite.org.ID, invite.ID)
+
+	require.NoError(suite.T(), err)
+	raw := tokenFromLink(suite.T(), *body, "/accept-invite")
+	assert.Equal(suite.T(), utils.HashToken(raw), invite.Token)
+	assert.NotEqual(suite.T(), utils.HashToken("old-token"), invite.Token)
+	assert.Equal(suite.T(), entity.InvitationPending, invite.Status)
+	assert.WithinDuration(suite.T(), time.Now().Add(service.InvitationTTL), invite.ExpiresAt, time.Minute)
+}
+
+func (suite *TeamInvitationTestSuite) TestResendInvitation_AcceptedIsConflict() {
+	invite := suite.pendingInvite()
+	invite.Status = entity.InvitationAccepted
+	suite.invitationRepo.EXPECT().GetByID(suite.ctx, invite.ID).Return(invite, nil)
+
+	err := suite.service.ResendInvitation(suite.ctx, suite.org.ID, invite.ID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvitationNotPending)
+}
+
+func (suite *TeamInvitationTestSuite) TestRevokeInvitation() {
+	invite := suite.pendingInvite()
+	invite.Status = entity.InvitationPending
+	suite.invitationRepo.EXPECT().GetByID(suite.ctx, invite.ID).Return(invite, nil)
+	suite.invitationRepo.EXPECT().RevokeInvitation(suite.ctx, invite.ID).Return(nil)
+
+	assert.NoError(suite.T(), suite.service.RevokeInvitation(suite.ctx, suite.org.ID, invite.ID))
+}
+
+func (suite *TeamInvitationTestSuite) TestRevokeInvitation_ForeignOrganization() {
+	invite := suite.pendingInvite()
+	suite.invitationRepo.EXPECT().GetByID(suite.ctx, invite.ID).Return(invite, nil)
+
+	err := suite.service.RevokeInvitation(suite.ctx, uuid.New(), invite.ID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrNotFound)
+}
+
+func (suite *TeamInvitationTestSuite) TestListInvitations_MarksExpiredAndFilters() {
+	suite.invitationRepo.EXPECT().MarkExpired(suite.ctx, gomock.Any()).Return(int64(1), nil)
+	suite.invitationRepo.EXPECT().GetByStatus(suite.ctx, suite.org.ID, entity.InvitationExpired).Return([]entity.UserInvitation{
+		{Email: "late@example.com", Status: entity.InvitationExpired, Role: *suite.editor, InvitedByUser: suite.inviter},
+	}, nil)
+
+	list, err := suite.service.ListInvitations(suite.ctx, suite.org.ID, entity.InvitationExpired)
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), list, 1)
+	assert.Equal(suite.T(), "Editor", list[0].RoleName)
+	assert.Equal(suite.T(), "Olivia Owner", list[0].InvitedByName)
+}
+
+func (suite *TeamInvitationTestSuite) TestListInvitations_UnknownStatus() {
+	_, err := suite.service.ListInvitations(suite.ctx, suite.org.ID, "bogus")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidInvitationStatus)
+}
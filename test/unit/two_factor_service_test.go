@@ -0,0 +1,275 @@
+package unit
+
+import (
+	"context"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/totp"
+	"inspacemap/backend/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type TwoFactorServiceTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	repo       *MockTwoFactorRepository
+	userRepo   *MockUserRepository
+	orgRepo    *MockOrganizationRepository
+	memberRepo *MockOrganizationMemberRepository
+	resolver   *MockPermissionResolver
+	service    service.TwoFactorService
+
+	ctx    context.Context
+	userID uuid.UUID
+	secret string
+}
+
+func (suite *TwoFactorServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.repo = NewMockTwoFactorRepository(suite.ctrl)
+	suite.userRepo = NewMockUserRepository(suite.ctrl)
+	suite.orgRepo = NewMockOrganizationRepository(suite.ctrl)
+	suite.memberRepo = NewMockOrganizationMemberRepository(suite.ctrl)
+	suite.resolver = NewMockPermissionResolver(suite.ctrl)
+	suite.service = service.NewTwoFactorService(suite.repo, suite.userRepo, suite.orgRepo, suite.memberRepo, suite.resolver)
+
+	suite.ctx = context.Background()
+	suite.userID = uuid.New()
+	suite.secret, _ = totp.GenerateSecret()
+}
+
+func (suite *TwoFactorServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestTwoFactorServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorServiceTestSuite))
+}
+
+func (suite *TwoFactorServiceTestSuite) enabled() *entity.UserTwoFactor {
+	now := time.Now()
+	return &entity.UserTwoFactor{UserID: suite.userID, Secret: suite.secret, EnabledAt: &now}
+}
+
+func (suite *TwoFactorServiceTestSuite) currentCode() (string, int64) {
+	step := totp.Step(time.Now())
+	code, err := totp.Code(suite.secret, step)
+	require.NoError(suite.T(), err)
+	return code, step
+}
+
+func TestTOTP_RFC6238Vector(t *testing.T) {
+	// RFC 6238 Appendix B (SHA1, T=59): 94287082 -> 6 digit terakhir
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // base32("12345678901234567890")
+	code, err := totp.Code(secret, totp.Step(time.Unix(59, 0)))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	step, ok := totp.Validate(secret, "287 082", time.Unix(59+30, 0)) // Satu periode terlambat masih diterima
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+	_, ok = totp.Validate(secret, "287082", time.Unix(59+90, 0))
+	assert.False(t, ok)
+
+	uri := totp.KeyURI("InSpaceMap", "jane@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/InSpaceMap:jane@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestEnrollment_ConfirmEnablesWithHashedRecoveryCodes() {
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.userID).Return(&entity.User{Email: "jane@example.com"}, nil)
+	var pending *entity.UserTwoFactor
+	suite.repo.EXPECT().SavePending(suite.ctx, gomock.Any()).DoAndReturn(func(_ context.Context, tf *entity.UserTwoFactor) error {
+		pending = tf
+		return nil
+	})
+
+	setup, err := suite.service.BeginEnrollment(suite.ctx, suite.userID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), pending.Secret, setup.Secret)
+	assert.Contains(suite.T(), setup.OTPAuthURL, "jane@example.com")
+
+	suite.secret = setup.Secret
+	code, step := suite.currentCode()
+	orgID := uuid.New()
+	var stored []string
+	suite.repo.EXPECT().GetByUser(suite.ctx, suite.userID).Return(pending, nil)
+	suite.repo.EXPECT().Enable(suite.ctx, suite.userID, step, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, _ int64, hashes []string) (bool, error) {
+		stored = hashes
+		return true, nil
+	})
+	suite.memberRepo.EXPECT().GetMembersByUser(suite.ctx, suite.userID).Return([]entity.OrganizationMember{{OrganizationID: orgID}}, nil)
+	suite.resolver.EXPECT().InvalidateMember(orgID, suite.userID)
+
+	resp, err := suite.service.ConfirmEnrollment(suite.ctx, suite.userID, code)
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), resp.RecoveryCodes, 10)
+	require.Len(suite.T(), stored, 10)
+	assert.Equal(suite.T(), utils.HashToken(strings.ReplaceAll(resp.RecoveryCodes[0], "-", "")), stored[0])
+	assert.NotContains(suite.T(), stored, resp.RecoveryCodes[0])
+}
+
+func (suite *TwoFactorServiceTestSuite) TestConfirmEnrollment_WrongCode() {
+	suite.repo.EXPECT().GetByUser(suite.ctx, suite.userID).Return(&entity.UserTwoFactor{Secret: suite.secret}, nil)
+
+	_, err := suite.service.ConfirmEnrollment(suite.ctx, suite.userID, "000000x")
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidTwoFactorCode)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestVerifyCode_RejectsReplayedTOTP() {
+	code, step := suite.currentCode()
+	suite.repo.EXPECT().GetByUser(suite.ctx, suite.userID).Return(suite.enabled(), nil).Times(2)
+	gomock.InOrder(
+		suite.repo.EXPECT().ConsumeStep(suite.ctx, suite.userID, step).Return(true, nil),
+		suite.repo.EXPECT().ConsumeStep(suite.ctx, suite.userID, step).Return(false, nil),
+	)
+
+	require.NoError(suite.T(), suite.service.VerifyCode(suite.ctx, suite.userID, code))
+	assert.ErrorIs(suite.T(), suite.service.VerifyCode(suite.ctx, suite.userID, code), service.ErrInvalidTwoFactorCode)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestVerifyCode_RecoveryCodeIsNormalized() {
+	suite.repo.EXPECT().GetByUser(suite.ctx, suite.userID).Return(suite.enabled(), nil).Times(2)
+	suite.repo.EXPECT().ConsumeRecoveryCode(suite.ctx, suite.userID, utils.HashToken("abcdefghijklmnop")).Return(true, nil)
+	suite.repo.EXPECT().ConsumeRecoveryCode(suite.ctx, suite.userID, utils.HashToken("zzzz")).Return(false, nil)
+
+	require.NoError(suite.T(), suite.service.VerifyCode(suite.ctx, suite.userID, " ABCD-efgh-IJKL-mnop "))
+	assert.ErrorIs(suite.T(), suite.service.VerifyCode(suite.ctx, suite.userID, "zzzz"), service.ErrInvalidTwoFactorCode)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestDisable_RequiresPassword() {
+	hash, err := utils.HashPassword("password123")
+	require.NoError(suite.T(), err)
+	user := &entity.User{BaseEntity: entity.BaseEntity{ID: suite.userID}, PasswordHash: hash, TwoFactorEnabled: true}
+	suite.userRepo.EXPECT().GetByID(suite.ctx, suite.userID).Return(user, nil).Times(2)
+	code, step := suite.currentCode()
+
+	err = suite.service.Disable(suite.ctx, suite.userID, models.TwoFactorDisableRequest{Password: "wrong", Code: code})
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidPassword)
+
+	suite.repo.EXPECT().GetByUser(suite.ctx, suite.userID).Return(suite.enabled(), nil)
+	suite.repo.EXPECT().ConsumeStep(suite.ctx, suite.userID, step).Return(true, nil)
+	suite.repo.EXPECT().Disable(suite.ctx, suite.userID).Return(nil)
+	suite.memberRepo.EXPECT().GetMembersByUser(suite.ctx, suite.userID).Return(nil, nil)
+
+	err = suite.service.Disable(suite.ctx, suite.userID, models.TwoFactorDisableRequest{Password: "password123", Code: code})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestUpdatePolicy_RequesterMustUseTwoFactor() {
+	org := &entity.Organization{BaseEntity: entity.BaseEntity{ID: uuid.New()}}
+	suite.orgRepo.EXPECT().GetByID(suite.ctx, org.ID).Return(org, nil).Times(2)
+	gomock.InOrder(
+		suite.userRepo.EXPECT().GetByID(suite.ctx, suite.userID).Return(&entity.User{}, nil),
+		suite.userRepo.EXPECT().GetByID(suite.ctx, suite.userID).Return(&entity.User{TwoFactorEnabled: true}, nil),
+	)
+
+	err := suite.service.UpdatePolicy(suite.ctx, org.ID, suite.userID, models.TwoFactorPolicy{RequireTwoFactor: true})
+	assert.ErrorIs(suite.T(), err, service.ErrTwoFactorNotEnabled)
+
+	suite.orgRepo.EXPECT().Update(suite.ctx, org).Return(nil)
+	suite.resolver.EXPECT().InvalidateOrganization(org.ID)
+	err = suite.service.UpdatePolicy(suite.ctx, org.ID, suite.userID, models.TwoFactorPolicy{RequireTwoFactor: true})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), true, org.Settings["require_two_factor"])
+}
+
+func TestPermissionResolver_WithholdsProtectedPermissionsWithoutTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	memberRepo := NewMockOrganizationMemberRepository(ctrl)
+	resolver := service.NewPermissionResolver(memberRepo, newPermissionCache())
+	ctx := context.Background()
+	userID, orgID := uuid.New(), uuid.New()
+
+	member := &entity.OrganizationMember{
+		Organization: entity.Organization{Settings: entity.JSONMap{"require_two_factor": true}},
+		Role: entity.Role{Permissions: []entity.Permission{
+			{Key: string(entity.PermGraphEdit)}, {Key: string(entity.PermGraphPublish)}, {Key: string(entity.PermOrgSettings)},
+		}},
+	}
+	memberRepo.EXPECT().FindMember(ctx, orgID, userID).Return(member, nil).Times(2)
+
+	perms, isMember, err := resolver.ResolvePermissions(ctx, userID, orgID)
+	require.NoError(t, err)
+	assert.True(t, isMember)
+	assert.Equal(t, []string{string(entity.PermGraphEdit)}, perms)
+
+	member.User.TwoFactorEnabled = true
+	resolver.InvalidateMember(orgID, userID)
+	perms, _, _ = resolver.ResolvePermissions(ctx, userID, orgID)
+	assert.Len(t, perms, 3)
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_TwoFactorChallengeThenSession() {
+	ctx := context.Background()
+	hash, err := utils.HashPassword("password123")
+	require.NoError(suite.T(), err)
+	user := &entity.User{BaseEntity: entity.BaseEntity{ID: uuid.New()}, Email: "jane@example.com", PasswordHash: hash, TwoFactorEnabled: true}
+	suite.userRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil)
+
+	challenge, err := suite.authService.Login(ctx, models.LoginRequest{Email: user.Email, Password: "password123"}, models.ClientInfo{})
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), challenge.TwoFactorRequired)
+	assert.Empty(suite.T(), challenge.AccessToken)
+	assert.Empty(suite.T(), challenge.RefreshToken)
+	// Challenge bukan access token
+	_, err = utils.ParseToken(challenge.ChallengeToken)
+	assert.Error(suite.T(), err)
+
+	suite.twoFactor.EXPECT().VerifyCode(ctx, user.ID, "wrong").Return(service.ErrInvalidTwoFactorCode)
+	_, err = suite.authService.VerifyTwoFactor(ctx, models.TwoFactorVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "wrong"}, models.ClientInfo{})
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidTwoFactorCode)
+
+	suite.twoFactor.EXPECT().VerifyCode(ctx, user.ID, "123456").Return(nil)
+	suite.userRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	suite.orgMemberRepo.EXPECT().GetMembersByUser(ctx, user.ID).Return(nil, nil)
+	suite.refreshRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	resp, err := suite.authService.VerifyTwoFactor(ctx, models.TwoFactorVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "123456"}, models.ClientInfo{})
+
+	require.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), resp.AccessToken)
+	assert.NotEmpty(suite.T(), resp.RefreshToken)
+	assert.True(suite.T(), resp.User.TwoFactorEnabled)
+}
+
+func (suite *AuthServiceTestSuite) TestVerifyTwoFactor_RejectsOtherTokens() {
+	token, err := utils.GenerateToken(uuid.New(), "jane@example.com", uuid.Nil, "", nil, uuid.Nil)
+	require.NoError(suite.T(), err)
+
+	for _, challenge := range []string{"garbage", token} {
+		_, err := suite.authService.VerifyTwoFactor(context.Background(), models.TwoFactorVerifyRequest{ChallengeToken: challenge, Code: "123456"}, models.ClientInfo{})
+		assert.ErrorIs(suite.T(), err, service.ErrInvalidTwoFactorChallenge)
+	}
+}
+
+func (suite *AuthServiceTestSuite) TestGetMe_ReportsTwoFactorEnrollmentRequired() {
+	ctx := context.Background()
+	userID, orgID := uuid.New(), uuid.New()
+	suite.expectUserWithOrgs(userID, entity.OrganizationMember{
+		OrganizationID: orgID,
+		Organization:   entity.Organization{Settings: entity.JSONMap{"require_two_factor": true}},
+		Role:           entity.Role{Name: "Owner"},
+	})
+	suite.permRepo.EXPECT().GetByUserAndOrg(ctx, userID, orgID).Return([]entity.Permission{
+		{Key: string(entity.PermOrgSettings)}, {Key: string(entity.PermVenueCreate)},
+	}, nil)
+
+	me, err := suite.authService.GetMe(ctx, userID, orgID)
+
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), me.TwoFactorEnrollmentRequired)
+	assert.Equal(suite.T(), []string{string(entity.PermVenueCreate)}, me.Permissions)
+}
@@ -0,0 +1,400 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"inspacemap/backend/internal/entity"
+	"inspacemap/backend/internal/models"
+	"inspacemap/backend/internal/repository"
+	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/utils"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type VenueBundleServiceTestSuite struct {
+	suite.Suite
+	ctrl            *gomock.Controller
+	venueRepo       *MockVenueRepository
+	revisionRepo    *MockGraphRevisionRepository
+	areaGalleryRepo *MockAreaGalleryRepository
+	mediaRepo       *MockMediaAssetRepository
+	storage         *MockStorageProvider
+	service         service.VenueBundleService
+}
+
+func (suite *VenueBundleServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.venueRepo = NewMockVenueRepository(suite.ctrl)
+	suite.revisionRepo = NewMockGraphRevisionRepository(suite.ctrl)
+	suite.areaGalleryRepo = NewMockAreaGalleryRepository(suite.ctrl)
+	suite.mediaRepo = NewMockMediaAssetRepository(suite.ctrl)
+	suite.storage = NewMockStorageProvider(suite.ctrl)
+	suite.service = service.NewVenueBundleService(suite.venueRepo, suite.revisionRepo, suite.areaGalleryRepo, suite.mediaRepo, suite.storage, "test-bucket", "https://cdn.example.com", 1<<20)
+}
+
+func (suite *VenueBundleServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestVenueBundleServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(VenueBundleServiceTestSuite))
+}
+
+// expectSlugTaken: Cek slug selalu di dalam organisasi tujuan
+func (suite *VenueBundleServiceTestSuite) expectSlugTaken(ctx context.Context, orgID uuid.UUID, slug string, taken bool) {
+	var existing []entity.Venue
+	if taken {
+		existing = []entity.Venue{{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: orgID, Slug: slug}}
+	}
+	suite.venueRepo.EXPECT().FilterVenues(ctx, models.VenueFilter{OrganizationID: &orgID, Slug: &slug}).Return(existing, nil)
+}
+
+// bundleTestVenue: 1 lantai, 2 node (edge A->B), node A di dalam area dengan cover
+func bundleTestVenue() (*entity.Venue, *entity.GraphRevision, []*entity.MediaAsset) {
+	mapImage := packageAsset("org/map/lobby.png")
+	panorama := packageAsset("org/panorama/a.jpg")
+	cover := packageAsset("org/area/cover.jpg")
+
+	floorLineage, nodeA, nodeB := uuid.New(), uuid.New(), uuid.New()
+	areaID := uuid.New()
+	live := &entity.GraphRevision{
+		BaseEntity:  entity.BaseEntity{ID: uuid.New()},
+		Status:      entity.StatusPublished,
+		StartNodeID: &nodeA,
+		Floors: []entity.Floor{{
+			BaseEntity: entity.BaseEntity{ID: uuid.New()},
+			LineageID:  &floorLineage,
+			Name:       "Lobby",
+			MapImageID: &mapImage.ID,
+			IsActive:   true,
+			Nodes: []entity.GraphNode{
+				{
+					BaseEntity:      entity.BaseEntity{ID: nodeA},
+					AreaID:          &areaID,
+					PanoramaAssetID: panorama.ID,
+					IsActive:        true,
+					OutgoingEdges:   []entity.GraphEdge{{FromNodeID: nodeA, ToNodeID: nodeB, Distance: 4, Type: "walk", IsActive: true}},
+				},
+				{BaseEntity: entity.BaseEntity{ID: nodeB}, PanoramaAssetID: panorama.ID, IsActive: true},
+			},
+		}},
+	}
+	venue := &entity.Venue{
+		BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+		OrganizationID: uuid.New(),
+		Name:           "Mall Staging",
+		Slug:           "mall",
+		Visibility:     entity.VisibilityPublic,
+		LiveRevisionID: live.ID,
+		PointsOfInterest: []entity.Area{{
+			BaseEntity:   entity.BaseEntity{ID: areaID},
+			FloorID:      floorLineage,
+			Name:         "Atrium",
+			CoverImageID: &cover.ID,
+		}},
+	}
+	return venue, live, []*entity.MediaAsset{mapImage, panorama, cover}
+}
+
+func (suite *VenueBundleServiceTestSuite) exportBundle() ([]byte, *entity.Venue, *entity.GraphRevision) {
+	venue, live, assets := bundleTestVenue()
+	ctx := utils.WithOrgID(context.Background(), venue.OrganizationID)
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	suite.revisionRepo.EXPECT().GetFullGraph(ctx, live.ID).Return(live, nil)
+	suite.areaGalleryRepo.EXPECT().GetByVenueID(ctx, venue.ID).Return(nil, nil)
+	for _, asset := range assets {
+		suite.mediaRepo.EXPECT().GetByID(ctx, asset.ID).Return(asset, nil)
+		suite.storage.EXPECT().GetObject(ctx, "test-bucket", asset.Key).Return(io.NopCloser(strings.NewReader("bytes:"+asset.Key)), nil)
+	}
+
+	write, err := suite.service.ExportBundle(ctx, venue.ID, models.VenueBundleExportRequest{})
+	require.NoError(suite.T(), err)
+	var buf bytes.Buffer
+	require.NoError(suite.T(), write(&buf))
+	return buf.Bytes(), venue, live
+}
+
+func (suite *VenueBundleServiceTestSuite) TestExportImport_RoundTripReuploadsMedia() {
+	ctx := context.Background()
+	data, venue, live := suite.exportBundle()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), zr.File, 4) // 3 media + bundle.json
+
+	orgID, userID := uuid.New(), uuid.New()
+	suite.expectSlugTaken(ctx, orgID, "mall", true)
+	suite.expectSlugTaken(ctx, orgID, "mall-2", false)
+	var uploadedKeys []string
+	suite.storage.EXPECT().PutObject(ctx, "test-bucket", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, key, _ string, body io.Reader, _ int64) error {
+			content, _ := io.ReadAll(body)
+			assert.True(suite.T(), strings.HasPrefix(string(content), "bytes:org/"))
+			uploadedKeys = append(uploadedKeys, key)
+			return nil
+		}).Times(3)
+
+	var copySrc *repository.VenueCopy
+	suite.venueRepo.EXPECT().CreateCopy(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, src *repository.VenueCopy) (*entity.Venue, error) {
+			copySrc = src
+			created := src.Venue
+			created.ID = uuid.New()
+			return &created, nil
+		})
+
+	result, err := suite.service.ImportBundle(ctx, orgID, userID, bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "mall-2", result.Slug)
+	assert.Equal(suite.T(), 1, result.RevisionCount)
+	assert.Equal(suite.T(), 3, result.UploadedMedia)
+
+	require.NotNil(suite.T(), copySrc)
+	assert.Equal(suite.T(), orgID, copySrc.Venue.OrganizationID)
+	assert.Equal(suite.T(), entity.VisibilityPrivate, copySrc.Venue.Visibility)
+	assert.Equal(suite.T(), live.ID, copySrc.LiveRevisionID)
+	assert.Nil(suite.T(), copySrc.DraftRevisionID)
+
+	// Media baru milik org tujuan, key & URL mengikuti storage instance ini
+	require.Len(suite.T(), copySrc.Media, 3)
+	for i, asset := range copySrc.Media {
+		assert.Equal(suite.T(), orgID, asset.OrganizationID)
+		assert.Equal(suite.T(), uploadedKeys[i], asset.Key)
+		assert.True(suite.T(), strings.HasPrefix(asset.Key, orgID.String()+"/"))
+		assert.Equal(suite.T(), "https://cdn.example.com/"+asset.Key, asset.PublicURL)
+	}
+	panoramaID := live.Floors[0].Nodes[0].PanoramaAssetID
+	assert.Contains(suite.T(), copySrc.MediaIDs, panoramaID)
+	assert.NotEqual(suite.T(), panoramaID, copySrc.MediaIDs[panoramaID])
+
+	// Graph & area tetap memakai ID sumber, remap dilakukan CreateCopy
+	require.Len(suite.T(), copySrc.Revisions, 1)
+	floor := copySrc.Revisions[0].Floors[0]
+	assert.Equal(suite.T(), live.Floors[0].LineageID, floor.LineageID)
+	require.Len(suite.T(), floor.Nodes, 2)
+	assert.Equal(suite.T(), venue.PointsOfInterest[0].ID, *floor.Nodes[0].AreaID)
+	require.Len(suite.T(), floor.Nodes[0].OutgoingEdges, 1)
+	assert.Equal(suite.T(), floor.Nodes[1].ID, floor.Nodes[0].OutgoingEdges[0].ToNodeID)
+	require.Len(suite.T(), copySrc.Areas, 1)
+	assert.Equal(suite.T(), *live.Floors[0].LineageID, copySrc.Areas[0].FloorID)
+}
+
+func (suite *VenueBundleServiceTestSuite) TestImportBundle_RequiresMediaFiles() {
+	ctx := context.Background()
+	data, _, _ := suite.exportBundle()
+	// Bundle lama (diexport tanpa media) tidak menyertakan path file
+	data = rewriteBundleJSON(suite.T(), data, func(b *models.VenueBundle) {
+		for i := range b.Media {
+			b.Media[i].File = ""
+		}
+	})
+
+	result, err := suite.service.ImportBundle(ctx, uuid.New(), uuid.New(), bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "missing media file")
+	assert.Nil(suite.T(), result)
+}
+
+// rewriteBundleJSON: Ubah manifest di dalam zip (simulasi bundle yang dimodifikasi pengirim)
+func rewriteBundleJSON(t *testing.T, data []byte, mutate func(*models.VenueBundle)) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+
+		if f.Name == models.VenueBundleFileName {
+			var bundle models.VenueBundle
+			require.NoError(t, json.Unmarshal(content, &bundle))
+			mutate(&bundle)
+			content, err = json.Marshal(bundle)
+			require.NoError(t, err)
+		}
+		w, err := zw.Create(f.Name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
+
+func (suite *VenueBundleServiceTestSuite) TestImportBundle_IgnoresManifestStorageLocation() {
+	ctx := context.Background()
+	data, _, _ := suite.exportBundle()
+	data = rewriteBundleJSON(suite.T(), data, func(b *models.VenueBundle) {
+		for i := range b.Media {
+			b.Media[i].Bucket = "victim-bucket"
+			b.Media[i].Key = "victim-org/panorama/secret.jpg"
+			b.Media[i].PublicURL = "https://cdn.example.com/victim-org/panorama/secret.jpg"
+			b.Media[i].Type = "../../victim-org"
+		}
+		b.Media[0].File = "media/../../victim.jpg"
+	})
+
+	orgID := uuid.New()
+	result, err := suite.service.ImportBundle(ctx, orgID, uuid.New(), bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+	assert.Error(suite.T(), err, "file media yang tidak ada di zip tidak boleh jatuh ke referensi manifest")
+	assert.Nil(suite.T(), result)
+
+	data, _, _ = suite.exportBundle()
+	data = rewriteBundleJSON(suite.T(), data, func(b *models.VenueBundle) {
+		for i := range b.Media {
+			b.Media[i].Bucket = "victim-bucket"
+			b.Media[i].Key = "victim-org/panorama/secret.jpg"
+			b.Media[i].Type = "../../victim-org"
+		}
+	})
+	suite.expectSlugTaken(ctx, orgID, "mall", false)
+	suite.storage.EXPECT().PutObject(ctx, "test-bucket", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	var copySrc *repository.VenueCopy
+	suite.venueRepo.EXPECT().CreateCopy(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, src *repository.VenueCopy) (*entity.Venue, error) {
+			copySrc = src
+			created := src.Venue
+			created.ID = uuid.New()
+			return &created, nil
+		})
+
+	_, err = suite.service.ImportBundle(ctx, orgID, uuid.New(), bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), copySrc.Media, 3)
+	for _, asset := range copySrc.Media {
+		assert.Equal(suite.T(), "test-bucket", asset.Bucket)
+		assert.True(suite.T(), strings.HasPrefix(asset.Key, orgID.String()+"/imports/"+asset.ID.String()), asset.Key)
+		assert.NotContains(suite.T(), asset.PublicURL, "victim")
+	}
+}
+
+func (suite *VenueBundleServiceTestSuite) TestImportBundle_RejectsNonBundle() {
+	data := []byte("not a zip")
+
+	result, err := suite.service.ImportBundle(context.Background(), uuid.New(), uuid.New(), bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidBundle)
+	assert.Nil(suite.T(), result)
+}
+
+// zipOfZeros: Zip dengan entry berisi nol yang terkompresi jauh lebih kecil dari ukuran aslinya
+func zipOfZeros(t *testing.T, sizes map[string]int) []byte {
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for name, size := range sizes {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(make([]byte, size))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
+
+func (suite *VenueBundleServiceTestSuite) TestImportBundle_RejectsOversizedEntry() {
+	// Batas ekstrak di suite = 1 MiB; storage tidak boleh dipanggil sama sekali
+	data := zipOfZeros(suite.T(), map[string]int{models.VenueBundleFileName: 2 << 20})
+	assert.Less(suite.T(), len(data), 64<<10)
+
+	result, err := suite.service.ImportBundle(context.Background(), uuid.New(), uuid.New(), bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrBundleTooLarge)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueBundleServiceTestSuite) TestImportBundle_RejectsOversizedTotal() {
+	data := zipOfZeros(suite.T(), map[string]int{
+		"media/a.jpg": 600 << 10,
+		"media/b.jpg": 600 << 10,
+	})
+
+	result, err := suite.service.ImportBundle(context.Background(), uuid.New(), uuid.New(), bytes.NewReader(data), int64(len(data)), models.VenueBundleImportRequest{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrBundleTooLarge)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueBundleServiceTestSuite) TestCloneVenue_CopiesLiveGraphWithNewSlug() {
+	ctx := context.Background()
+	venue, live, _ := bundleTestVenue()
+	venue.Gallery = []entity.VenueGalleryItem{{VenueID: venue.ID, MediaAssetID: uuid.New(), SortOrder: 1}}
+	areaGallery := []entity.AreaGalleryItem{{AreaID: venue.PointsOfInterest[0].ID, MediaAssetID: uuid.New()}}
+	userID := uuid.New()
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	suite.revisionRepo.EXPECT().GetFullGraph(ctx, live.ID).Return(live, nil)
+	suite.areaGalleryRepo.EXPECT().GetByVenueID(ctx, venue.ID).Return(areaGallery, nil)
+	suite.expectSlugTaken(ctx, venue.OrganizationID, "mall-copy", false)
+
+	draftID := uuid.New()
+	var copySrc *repository.VenueCopy
+	suite.venueRepo.EXPECT().CreateCopy(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, src *repository.VenueCopy) (*entity.Venue, error) {
+			copySrc = src
+			created := src.Venue
+			created.ID, created.LiveRevisionID, created.DraftRevisionID = uuid.New(), uuid.New(), &draftID
+			return &created, nil
+		})
+
+	result, err := suite.service.CloneVenue(ctx, venue.OrganizationID, userID, venue.ID, models.CloneVenueRequest{})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "mall-copy", result.Slug)
+	assert.Equal(suite.T(), draftID, result.DraftRevisionID)
+
+	require.NotNil(suite.T(), copySrc)
+	assert.Equal(suite.T(), "Mall Staging (Copy)", copySrc.Venue.Name)
+	assert.Equal(suite.T(), venue.OrganizationID, copySrc.Venue.OrganizationID)
+	assert.Equal(suite.T(), entity.VisibilityPrivate, copySrc.Venue.Visibility)
+	assert.Equal(suite.T(), uuid.Nil, copySrc.Venue.ID)
+	assert.Nil(suite.T(), copySrc.MediaIDs) // Media dipakai bersama
+	assert.Nil(suite.T(), copySrc.DraftRevisionID)
+	assert.Equal(suite.T(), live.ID, copySrc.LiveRevisionID)
+	assert.Len(suite.T(), copySrc.Revisions, 1)
+	assert.Len(suite.T(), copySrc.Areas, 1)
+	assert.Len(suite.T(), copySrc.Gallery, 1)
+	assert.Equal(suite.T(), areaGallery, copySrc.AreaGallery)
+	assert.Equal(suite.T(), userID, copySrc.CreatedByID)
+}
+
+func (suite *VenueBundleServiceTestSuite) TestCloneVenue_ForeignOrganization() {
+	ctx := context.Background()
+	venue, _, _ := bundleTestVenue()
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+
+	result, err := suite.service.CloneVenue(ctx, uuid.New(), uuid.New(), venue.ID, models.CloneVenueRequest{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrVenueNotFound)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueBundleServiceTestSuite) TestExportBundle_ForeignOrganization() {
+	ctx := utils.WithOrgID(context.Background(), uuid.New())
+	venue, _, _ := bundleTestVenue()
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+
+	write, err := suite.service.ExportBundle(ctx, venue.ID, models.VenueBundleExportRequest{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrVenueNotFound)
+	assert.Nil(suite.T(), write)
+}
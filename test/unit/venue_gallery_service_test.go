@@ -1,7 +1,6 @@
 package unit
 
 import (
-	"context"
 	"errors"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
@@ -24,7 +23,7 @@ type VenueGalleryServiceTestSuite struct {
 func (suite *VenueGalleryServiceTestSuite) SetupTest() {
 	suite.ctrl = gomock.NewController(suite.T())
 	suite.galleryRepo = NewMockVenueGalleryRepository(suite.ctrl)
-	suite.service = service.NewVenueGalleryService(suite.galleryRepo)
+	suite.service = service.NewVenueGalleryService(suite.galleryRepo, ownedResources(suite.ctrl))
 }
 
 func (suite *VenueGalleryServiceTestSuite) TearDownTest() {
@@ -36,7 +35,7 @@ func TestVenueGalleryServiceTestSuite(t *testing.T) {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestAddGalleryItems_Success() {
-	ctx := context.Background()
+	ctx := orgContext()
 	venueID := uuid.New()
 	req := models.AddGalleryVenueItemsRequest{
 		VenueID: venueID,
@@ -66,7 +65,7 @@ func (suite *VenueGalleryServiceTestSuite) TestAddGalleryItems_Success() {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestAddGalleryItems_DatabaseError() {
-	ctx := context.Background()
+	ctx := orgContext()
 	venueID := uuid.New()
 	req := models.AddGalleryVenueItemsRequest{
 		VenueID: venueID,
@@ -90,7 +89,7 @@ func (suite *VenueGalleryServiceTestSuite) TestAddGalleryItems_DatabaseError() {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestReorderGallery_Success() {
-	ctx := context.Background()
+	ctx := orgContext()
 	req := models.ReorderVenueGalleryRequest{
 		VenueID:       uuid.New(),
 		MediaAssetIDs: []uuid.UUID{uuid.New(), uuid.New(), uuid.New()},
@@ -104,7 +103,7 @@ func (suite *VenueGalleryServiceTestSuite) TestReorderGallery_Success() {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestReorderGallery_DatabaseError() {
-	ctx := context.Background()
+	ctx := orgContext()
 	req := models.ReorderVenueGalleryRequest{
 		VenueID:       uuid.New(),
 		MediaAssetIDs: []uuid.UUID{uuid.New(), uuid.New()},
@@ -119,7 +118,7 @@ func (suite *VenueGalleryServiceTestSuite) TestReorderGallery_DatabaseError() {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestUpdateGalleryItem_Success() {
-	ctx := context.Background()
+	ctx := orgContext()
 	venueID := uuid.New()
 	mediaID := uuid.New()
 	req := models.UpdateVenueGalleryItemRequest{
@@ -152,7 +151,7 @@ func (suite *VenueGalleryServiceTestSuite) TestUpdateGalleryItem_Success() {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestUpdateGalleryItem_DatabaseError() {
-	ctx := context.Background()
+	ctx := orgContext()
 	venueID := uuid.New()
 	mediaID := uuid.New()
 	req := models.UpdateVenueGalleryItemRequest{
@@ -170,7 +169,7 @@ func (suite *VenueGalleryServiceTestSuite) TestUpdateGalleryItem_DatabaseError()
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestRemoveGalleryItem_Success() {
-	ctx := context.Background()
+	ctx := orgContext()
 	venueID := uuid.New()
 	mediaID := uuid.New()
 
@@ -182,7 +181,7 @@ func (suite *VenueGalleryServiceTestSuite) TestRemoveGalleryItem_Success() {
 }
 
 func (suite *VenueGalleryServiceTestSuite) TestRemoveGalleryItem_DatabaseError() {
-	ctx := context.Background()
+	ctx := orgContext()
 	venueID := uuid.New()
 	mediaID := uuid.New()
 
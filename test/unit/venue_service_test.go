@@ -3,13 +3,21 @@ package unit
 import (
 	"context"
 	"errors"
+	"inspacemap/backend/internal/delivery/http/handler"
 	"inspacemap/backend/internal/entity"
 	"inspacemap/backend/internal/models"
 	"inspacemap/backend/internal/service"
+	"inspacemap/backend/pkg/cache"
+	"inspacemap/backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 )
@@ -24,7 +32,7 @@ type VenueServiceTestSuite struct {
 func (suite *VenueServiceTestSuite) SetupTest() {
 	suite.ctrl = gomock.NewController(suite.T())
 	suite.venueRepo = NewMockVenueRepository(suite.ctrl)
-	suite.service = service.NewVenueService(suite.venueRepo)
+	suite.service = service.NewVenueService(suite.venueRepo, newManifestCache(), nil)
 }
 
 func (suite *VenueServiceTestSuite) TearDownTest() {
@@ -61,12 +69,15 @@ func (suite *VenueServiceTestSuite) TestCreateVenue_Success() {
 	}
 
 	venueID := uuid.New()
+	orgID := uuid.New()
+	suite.venueRepo.EXPECT().FilterVenues(ctx, models.VenueFilter{OrganizationID: &orgID, Slug: &req.Slug}).Return(nil, nil)
 	suite.venueRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, venue *entity.Venue) error {
+		assert.Equal(suite.T(), orgID, venue.OrganizationID)
 		venue.ID = venueID
 		return nil
 	})
 
-	result, err := suite.service.CreateVenue(ctx, req)
+	result, err := suite.service.CreateVenue(ctx, orgID, req)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
@@ -88,9 +99,10 @@ func (suite *VenueServiceTestSuite) TestCreateVenue_DatabaseError() {
 		Visibility:  "private",
 	}
 
+	suite.venueRepo.EXPECT().FilterVenues(ctx, gomock.Any()).Return(nil, nil)
 	suite.venueRepo.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("database error"))
 
-	result, err := suite.service.CreateVenue(ctx, req)
+	result, err := suite.service.CreateVenue(ctx, uuid.New(), req)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -107,17 +119,19 @@ func (suite *VenueServiceTestSuite) TestUpdateVenue_Success() {
 		Visibility:  stringPtr("public"),
 	}
 
+	orgID := uuid.New()
 	existingVenue := &entity.Venue{
-		BaseEntity: entity.BaseEntity{ID: venueID},
-		Name:       "Old Name",
-		City:       "Old City",
-		Visibility: entity.VisibilityPrivate,
+		BaseEntity:     entity.BaseEntity{ID: venueID},
+		OrganizationID: orgID,
+		Name:           "Old Name",
+		City:           "Old City",
+		Visibility:     entity.VisibilityPrivate,
 	}
 
 	suite.venueRepo.EXPECT().GetByID(ctx, venueID).Return(existingVenue, nil)
 	suite.venueRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
 
-	err := suite.service.UpdateVenue(ctx, venueID, req)
+	err := suite.service.UpdateVenue(ctx, orgID, venueID, req)
 
 	assert.NoError(suite.T(), err)
 }
@@ -131,7 +145,7 @@ func (suite *VenueServiceTestSuite) TestUpdateVenue_NotFound() {
 
 	suite.venueRepo.EXPECT().GetByID(ctx, venueID).Return(nil, errors.New("not found"))
 
-	err := suite.service.UpdateVenue(ctx, venueID, req)
+	err := suite.service.UpdateVenue(ctx, uuid.New(), venueID, req)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), "venue not found", err.Error())
@@ -141,9 +155,12 @@ func (suite *VenueServiceTestSuite) TestDeleteVenue_Success() {
 	ctx := context.Background()
 	venueID := uuid.New()
 
+	orgID := uuid.New()
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venueID).Return(&entity.Venue{BaseEntity: entity.BaseEntity{ID: venueID}, OrganizationID: orgID, Slug: "test-venue"}, nil)
 	suite.venueRepo.EXPECT().Delete(ctx, venueID).Return(nil)
 
-	err := suite.service.DeleteVenue(ctx, venueID)
+	err := suite.service.DeleteVenue(ctx, orgID, venueID)
 
 	assert.NoError(suite.T(), err)
 }
@@ -151,9 +168,11 @@ func (suite *VenueServiceTestSuite) TestDeleteVenue_Success() {
 func (suite *VenueServiceTestSuite) TestGetVenueDetail_Success() {
 	ctx := context.Background()
 	venueID := uuid.New()
+	orgID := uuid.New()
 
 	venue := &entity.Venue{
 		BaseEntity:       entity.BaseEntity{ID: venueID},
+		OrganizationID:   orgID,
 		Name:             "Test Venue",
 		Slug:             "test-venue",
 		City:             "Test City",
@@ -164,7 +183,7 @@ func (suite *VenueServiceTestSuite) TestGetVenueDetail_Success() {
 
 	suite.venueRepo.EXPECT().GetByID(ctx, venueID).Return(venue, nil)
 
-	result, err := suite.service.GetVenueDetail(ctx, venueID)
+	result, err := suite.service.GetVenueDetail(ctx, orgID, venueID)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
@@ -222,9 +241,12 @@ func (suite *VenueServiceTestSuite) TestListVenues_Success() {
 		},
 	}
 
-	suite.venueRepo.EXPECT().PagedVenues(ctx, query).Return(venues, int64(2), nil)
+	orgID := uuid.New()
+	scoped := query
+	scoped.OrganizationID = &orgID
+	suite.venueRepo.EXPECT().PagedVenues(ctx, scoped).Return(venues, int64(2), nil)
 
-	result, total, err := suite.service.ListVenues(ctx, query)
+	result, total, err := suite.service.ListVenues(ctx, orgID, query)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), int64(2), total)
@@ -233,7 +255,468 @@ func (suite *VenueServiceTestSuite) TestListVenues_Success() {
 	assert.Equal(suite.T(), "Venue 2", result[1].Name)
 }
 
+func (suite *VenueServiceTestSuite) TestCreateVenue_SlugTakenInOrganization() {
+	ctx := context.Background()
+	orgID := uuid.New()
+	req := models.CreateVenueRequest{Name: "Mall", Slug: "mall"}
+
+	suite.venueRepo.EXPECT().FilterVenues(ctx, models.VenueFilter{OrganizationID: &orgID, Slug: &req.Slug}).
+		Return([]entity.Venue{{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: orgID, Slug: "mall"}}, nil)
+
+	result, err := suite.service.CreateVenue(ctx, orgID, req)
+
+	assert.ErrorIs(suite.T(), err, service.ErrVenueSlugTaken)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueServiceTestSuite) TestUpdateVenue_SlugChangeChecksUniqueness() {
+	ctx := context.Background()
+	orgID := uuid.New()
+	venue := &entity.Venue{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: orgID, Slug: "old"}
+	newSlug := "taken"
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	suite.venueRepo.EXPECT().FilterVenues(ctx, models.VenueFilter{OrganizationID: &orgID, Slug: &newSlug}).
+		Return([]entity.Venue{{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: orgID, Slug: newSlug}}, nil)
+
+	err := suite.service.UpdateVenue(ctx, orgID, venue.ID, models.UpdateVenueRequest{Slug: &newSlug})
+
+	assert.ErrorIs(suite.T(), err, service.ErrVenueSlugTaken)
+}
+
+func (suite *VenueServiceTestSuite) TestDeleteVenue_ForeignOrganization() {
+	ctx := context.Background()
+	venue := &entity.Venue{BaseEntity: entity.BaseEntity{ID: uuid.New()}, OrganizationID: uuid.New(), Slug: "other-org"}
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+
+	err := suite.service.DeleteVenue(ctx, uuid.New(), venue.ID)
+
+	assert.ErrorIs(suite.T(), err, service.ErrVenueNotFound)
+}
+
+func (suite *VenueServiceTestSuite) TestListVenues_RejectsUnknownSort() {
+	sort := "name; DROP TABLE venues"
+
+	result, total, err := suite.service.ListVenues(context.Background(), uuid.New(), models.VenueQuery{Sort: &sort})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Zero(suite.T(), total)
+}
+
+func manifestVenue(visibility entity.VisibilityStatus) *entity.Venue {
+	nodeID := uuid.New()
+	return &entity.Venue{
+		BaseEntity:     entity.BaseEntity{ID: uuid.New()},
+		OrganizationID: uuid.New(),
+		Organization:   entity.Organization{Slug: "manifest-org"},
+		Name:           "Manifest Venue",
+		Slug:           "manifest-venue",
+		Visibility:     visibility,
+		LiveRevision: &entity.GraphRevision{
+			BaseEntity: entity.BaseEntity{ID: uuid.New()},
+			Floors: []entity.Floor{
+				{
+					BaseEntity: entity.BaseEntity{ID: uuid.New()},
+					Name:       "Lobby",
+					Nodes:      []entity.GraphNode{{BaseEntity: entity.BaseEntity{ID: nodeID}, X: 10, Y: 20}},
+				},
+			},
+		},
+	}
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_PublicVenue() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevision.Floors[0].Nodes[0].Label = "Main Entrance"
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), venue.ID, result.VenueID)
+	assert.Len(suite.T(), result.Floors, 1)
+	assert.Equal(suite.T(), venue.LiveRevision.Floors[0].Nodes[0].ID, result.StartNodeID)
+	assert.Equal(suite.T(), "Main Entrance", result.Floors[0].Nodes[0].Label)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_PrivateVenueHiddenFromPublic() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPrivate)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{OrganizationID: uuid.New()})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_PrivateVenueOwnerOrg() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPrivate)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{OrganizationID: venue.OrganizationID})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), venue.ID, result.VenueID)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_PrivateVenueWithShareLink() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPrivate)
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	link, err := suite.service.CreateShareLink(ctx, venue.OrganizationID, venue.ID, models.CreateShareLinkRequest{ExpiresInHours: 1})
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), link.ManifestPath, "/api/v1/orgs/manifest-org/venues/manifest-venue/manifest?share_token=")
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{ShareToken: link.Token})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), venue.ID, result.VenueID)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_ShareLinkForOtherVenue() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPrivate)
+	other := manifestVenue(entity.VisibilityPrivate)
+
+	suite.venueRepo.EXPECT().GetByID(ctx, other.ID).Return(other, nil)
+	link, err := suite.service.CreateShareLink(ctx, other.OrganizationID, other.ID, models.CreateShareLinkRequest{})
+	assert.NoError(suite.T(), err)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{ShareToken: link.Token})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidShareLink)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_ServedFromCache() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil).Times(2)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil).Times(1)
+
+	first, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+	assert.NoError(suite.T(), err)
+
+	second, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first.RevisionID, second.RevisionID)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_SameSlugInOtherOrganization() {
+	ctx := context.Background()
+	venueA := manifestVenue(entity.VisibilityPublic)
+	venueB := manifestVenue(entity.VisibilityPublic)
+	venueB.Organization.Slug = "other-org"
+	venueA.LiveRevisionID, venueB.LiveRevisionID = venueA.LiveRevision.ID, venueA.LiveRevision.ID // Cache key tidak boleh bertabrakan
+
+	for _, venue := range []*entity.Venue{venueA, venueB} {
+		suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+		suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	}
+
+	first, err := suite.service.GetMobileManifest(ctx, venueA.Organization.Slug, venueA.Slug, models.ManifestAccess{})
+	assert.NoError(suite.T(), err)
+	second, err := suite.service.GetMobileManifest(ctx, venueB.Organization.Slug, venueB.Slug, models.ManifestAccess{})
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), venueA.ID, first.VenueID)
+	assert.Equal(suite.T(), venueB.ID, second.VenueID)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_CacheInvalidatedOnUpdate() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil).Times(2)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil).Times(2)
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+	suite.venueRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+	_, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+	assert.NoError(suite.T(), err)
+
+	err = suite.service.UpdateVenue(ctx, venue.OrganizationID, venue.ID, models.UpdateVenueRequest{Name: stringPtr("Renamed")})
+	assert.NoError(suite.T(), err)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Renamed", result.VenueName)
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_InvalidShareToken() {
+	ctx := context.Background()
+
+	result, err := suite.service.GetMobileManifest(ctx, "any-org", "any-venue", models.ManifestAccess{ShareToken: "not-a-token"})
+
+	assert.ErrorIs(suite.T(), err, service.ErrInvalidShareLink)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueServiceTestSuite) TestCreateShareLink_ForeignOrganization() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPrivate)
+
+	suite.venueRepo.EXPECT().GetByID(ctx, venue.ID).Return(venue, nil)
+
+	result, err := suite.service.CreateShareLink(ctx, uuid.New(), venue.ID, models.CreateShareLinkRequest{})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+}
+
+// liveNode: Node revisi live hasil publish (ID baru, LineageID = ID node draft asal)
+func liveNode(lineage uuid.UUID, x float64) entity.GraphNode {
+	return entity.GraphNode{BaseEntity: entity.BaseEntity{ID: uuid.New()}, X: x, Y: 5, LineageID: &lineage}
+}
+
+func (suite *VenueServiceTestSuite) TestGetMobileManifest_ExposesStableIDs() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	floorLineage, lineageA, lineageB := uuid.New(), uuid.New(), uuid.New()
+	nodeA, nodeB := liveNode(lineageA, 1), liveNode(lineageB, 2)
+	nodeA.OutgoingEdges = []entity.GraphEdge{{FromNodeID: nodeA.ID, ToNodeID: nodeB.ID, IsActive: true}}
+	venue.LiveRevision.StartNodeID = &nodeB.ID
+	venue.LiveRevision.Floors = []entity.Floor{{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		LineageID:  &floorLineage,
+		Nodes:      []entity.GraphNode{nodeA, nodeB},
+	}}
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetMobileManifest(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), floorLineage, result.Floors[0].ID)
+	assert.Equal(suite.T(), lineageA, result.Floors[0].Nodes[0].ID)
+	assert.Equal(suite.T(), lineageB, result.Floors[0].Nodes[0].Neighbors[0].TargetNodeID)
+	assert.Equal(suite.T(), lineageB, result.StartNodeID)
+}
+
+func (suite *VenueServiceTestSuite) TestGetManifestIndex_Success() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetManifestIndex(ctx, venue.Organization.Slug, venue.Slug, models.ManifestAccess{})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Floors, 1)
+	assert.Equal(suite.T(), "Lobby", result.Floors[0].LevelName)
+	assert.Equal(suite.T(), 1, result.Floors[0].NodeCount)
+}
+
+func (suite *VenueServiceTestSuite) TestGetManifestFloor_Success() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	floorID := venue.LiveRevision.Floors[0].ID
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetManifestFloor(ctx, venue.Organization.Slug, venue.Slug, floorID, models.ManifestAccess{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), floorID, result.ID)
+	assert.Len(suite.T(), result.Nodes, 1)
+}
+
+func (suite *VenueServiceTestSuite) TestGetManifestFloor_NotFound() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetManifestFloor(ctx, venue.Organization.Slug, venue.Slug, uuid.New(), models.ManifestAccess{})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *VenueServiceTestSuite) TestGetManifestDelta_Success() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	floorLineage, kept, moved, removed, added := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	oldRevision := &entity.GraphRevision{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Floors: []entity.Floor{{
+			BaseEntity: entity.BaseEntity{ID: uuid.New()},
+			Name:       "Lobby",
+			LineageID:  &floorLineage,
+			Nodes:      []entity.GraphNode{liveNode(kept, 1), liveNode(moved, 2), liveNode(removed, 3)},
+		}},
+	}
+	venue.LiveRevision.Floors = []entity.Floor{{
+		BaseEntity: entity.BaseEntity{ID: uuid.New()},
+		Name:       "Lobby",
+		LineageID:  &floorLineage,
+		Nodes:      []entity.GraphNode{liveNode(kept, 1), liveNode(moved, 20), liveNode(added, 4)},
+	}}
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetRevisionManifestData(ctx, venue.ID, oldRevision.ID).Return(oldRevision, nil)
+
+	result, err := suite.service.GetManifestDelta(ctx, venue.Organization.Slug, venue.Slug, oldRevision.ID, models.ManifestAccess{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), oldRevision.ID, result.FromRevisionID)
+	assert.Equal(suite.T(), venue.LiveRevision.ID, result.ToRevisionID)
+	assert.Empty(suite.T(), result.UpsertedFloors)
+	assert.Empty(suite.T(), result.RemovedFloorIDs)
+	if assert.Len(suite.T(), result.UpsertedNodes, 2) {
+		assert.Equal(suite.T(), moved, result.UpsertedNodes[0].ID)
+		assert.Equal(suite.T(), floorLineage, result.UpsertedNodes[0].FloorID)
+		assert.Equal(suite.T(), added, result.UpsertedNodes[1].ID)
+	}
+	assert.Equal(suite.T(), []uuid.UUID{removed}, result.RemovedNodeIDs)
+}
+
+func (suite *VenueServiceTestSuite) TestGetManifestDelta_SameRevision() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	result, err := suite.service.GetManifestDelta(ctx, venue.Organization.Slug, venue.Slug, venue.LiveRevision.ID, models.ManifestAccess{})
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), result.UpsertedNodes)
+	assert.Empty(suite.T(), result.RemovedNodeIDs)
+}
+
+func (suite *VenueServiceTestSuite) TestGetManifestDelta_UnknownBaseRevision() {
+	ctx := context.Background()
+	venue := manifestVenue(entity.VisibilityPublic)
+	fromID := uuid.New()
+
+	suite.venueRepo.EXPECT().GetManifestInfo(ctx, venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	suite.venueRepo.EXPECT().GetRevisionManifestData(ctx, venue.ID, fromID).Return(nil, errors.New("record not found"))
+
+	result, err := suite.service.GetManifestDelta(ctx, venue.Organization.Slug, venue.Slug, fromID, models.ManifestAccess{})
+
+	assert.ErrorIs(suite.T(), err, service.ErrManifestRevisionGone)
+	assert.Nil(suite.T(), result)
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
 }
+
+func newManifestCache() service.ManifestCache {
+	return cache.NewLRU[*models.ManifestResponse](16, 0)
+}
+
+// manifestHandlerApp: Handler manifest asli di atas service dengan repository mock
+func manifestHandlerApp(t *testing.T) (*fiber.App, *MockVenueRepository) {
+	venueRepo := NewMockVenueRepository(gomock.NewController(t))
+	h := handler.NewVenueHandler(service.NewVenueService(venueRepo, newManifestCache(), nil), nil)
+	app := fiber.New()
+	app.Get("/orgs/:org_slug/venues/:slug/manifest", h.GetManifest)
+	app.Get("/api/v1/venues/:slug/manifest", h.LegacyManifestSlug, h.GetManifest)
+	return app, venueRepo
+}
+
+func getManifest(t *testing.T, app *fiber.App, path, ifNoneMatch string) *http.Response {
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestGetManifestHandler_LiveManifestNotModified(t *testing.T) {
+	app, venueRepo := manifestHandlerApp(t)
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+	venueRepo.EXPECT().GetManifestInfo(gomock.Any(), venue.Organization.Slug, venue.Slug).Return(venue, nil).Times(2)
+	venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	path := "/orgs/manifest-org/venues/manifest-venue/manifest"
+
+	first := getManifest(t, app, path, "")
+	require.Equal(t, fiber.StatusOK, first.StatusCode)
+	etag := first.Header.Get(fiber.HeaderETag)
+	require.NotEmpty(t, etag)
+
+	assert.Equal(t, fiber.StatusNotModified, getManifest(t, app, path, etag).StatusCode)
+}
+
+func TestGetManifestHandler_DraftPreviewNeverNotModified(t *testing.T) {
+	app, venueRepo := manifestHandlerApp(t)
+	venue := manifestVenue(entity.VisibilityPrivate)
+	venue.DraftRevision, venue.LiveRevision = venue.LiveRevision, nil
+	venueRepo.EXPECT().GetDraftManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil).Times(2)
+	token, _, err := utils.GenerateShareToken(venue.ID, true, time.Hour)
+	require.NoError(t, err)
+	path := "/orgs/manifest-org/venues/manifest-venue/manifest?share_token=" + token
+
+	// Edit node/edge draft tidak mengubah revisi maupun waktu update venue: tidak boleh ada 304 basi
+	first := getManifest(t, app, path, "")
+	require.Equal(t, fiber.StatusOK, first.StatusCode)
+	assert.Empty(t, first.Header.Get(fiber.HeaderETag))
+	assert.Equal(t, "private, no-store", first.Header.Get(fiber.HeaderCacheControl))
+
+	assert.Equal(t, fiber.StatusOK, getManifest(t, app, path, "*").StatusCode)
+}
+
+func TestGetManifestHandler_LegacyRouteResolvesUniqueSlug(t *testing.T) {
+	app, venueRepo := manifestHandlerApp(t)
+	venue := manifestVenue(entity.VisibilityPublic)
+	venue.LiveRevisionID = venue.LiveRevision.ID
+	venueRepo.EXPECT().GetOrgSlugsByVenueSlug(gomock.Any(), venue.Slug, 2).Return([]string{venue.Organization.Slug}, nil)
+	venueRepo.EXPECT().GetManifestInfo(gomock.Any(), venue.Organization.Slug, venue.Slug).Return(venue, nil)
+	venueRepo.EXPECT().GetLiveManifestData(venue.Organization.Slug, venue.Slug).Return(venue, nil)
+
+	resp := getManifest(t, app, "/api/v1/venues/manifest-venue/manifest", "")
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get("Deprecation"))
+	assert.Equal(t, `</api/v1/orgs/manifest-org/venues/manifest-venue/manifest>; rel="successor-version"`, resp.Header.Get(fiber.HeaderLink))
+}
+
+func TestGetManifestHandler_LegacyRouteAmbiguousSlug(t *testing.T) {
+	app, venueRepo := manifestHandlerApp(t)
+	venueRepo.EXPECT().GetOrgSlugsByVenueSlug(gomock.Any(), "lobby", 2).Return([]string{"org-a", "org-b"}, nil)
+
+	resp := getManifest(t, app, "/api/v1/venues/lobby/manifest", "")
+
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestGetManifestHandler_LegacyRouteUnknownSlug(t *testing.T) {
+	app, venueRepo := manifestHandlerApp(t)
+	venueRepo.EXPECT().GetOrgSlugsByVenueSlug(gomock.Any(), "missing", 2).Return(nil, nil)
+
+	resp := getManifest(t, app, "/api/v1/venues/missing/manifest", "")
+
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}